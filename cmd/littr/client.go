@@ -19,6 +19,9 @@ func (c *client) Run() {
 	app.RouteWithRegexp("^/activation/[a-f0-9]{8}-[a-f0-9]{4}-4[a-f0-9]{3}-[89aAbB][a-f0-9]{3}-[a-f0-9]{12}$", func() app.Composer {
 		return &fe.LoginView{}
 	})
+	app.Route("/bookmarks", func() app.Composer {
+		return &fe.BookmarksView{}
+	})
 	app.RouteWithRegexp("^/(flow|flow/posts/[0-9]+|flow/hashtags/[a-zA-Z]+|flow/users/[a-zA-Z0-9]+)$", func() app.Composer {
 		return &fe.FlowView{}
 	})
@@ -37,6 +40,9 @@ func (c *client) Run() {
 	app.Route("/post", func() app.Composer {
 		return &fe.PostView{}
 	})
+	app.RouteWithRegexp("^/post/[0-9]+/thread$", func() app.Composer {
+		return &fe.ThreadView{}
+	})
 	app.Route("/register", func() app.Composer {
 		return &fe.RegisterView{}
 	})
@@ -46,6 +52,9 @@ func (c *client) Run() {
 	app.RouteWithRegexp("^/reset/[a-f0-9]{8}-[a-f0-9]{4}-4[a-f0-9]{3}-[89aAbB][a-f0-9]{3}-[a-f0-9]{12}$", func() app.Composer {
 		return &fe.ResetView{}
 	})
+	app.Route("/search", func() app.Composer {
+		return &fe.SearchView{}
+	})
 	app.Route("/settings", func() app.Composer {
 		return &fe.SettingsView{}
 	})
@@ -0,0 +1,124 @@
+//go:build !wasm
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/config"
+)
+
+const dumpUsage = `usage: littr dump [-url URL] [-dry-run] <command>
+
+commands:
+  dump                  dump the running data into the snapshot files
+  load                  load the snapshot files into the running caches
+  migrate               run the pending data migrations
+  caches                report the item counts of the caches
+  export <cache>        export the items of such cache (e.g. UserCache)
+  snapshots             list the snapshot generations
+  restore <generation>  restore such snapshot generation
+
+The requests are signed using the API_TOKEN secret (see pkg/backend/db/dump_auth.go).
+`
+
+// runDumpCommand is the "littr dump" subcommand to call the data-intervention API of the running server. It returns the process' exit code.
+func runDumpCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("dump", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, dumpUsage)
+	}
+
+	baseURL := flags.String("url", "http://localhost:"+config.ServerPort, "the server's base URL")
+	dryRun := flags.Bool("dry-run", false, "report the migration changes only, do not write anything")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var (
+		method = http.MethodGet
+		path   string
+		body   []byte
+	)
+
+	switch flags.Arg(0) {
+	case "", "dump":
+		path = "/api/v1/dump"
+
+	case "load":
+		method, path = http.MethodPost, "/api/v1/dump/load"
+
+	case "migrate":
+		method, path = http.MethodPost, "/api/v1/dump/migrate"
+		body = []byte(`{"dry_run":` + strconv.FormatBool(*dryRun) + `}`)
+
+	case "caches":
+		path = "/api/v1/dump/caches"
+
+	case "snapshots":
+		path = "/api/v1/dump/snapshots"
+
+	case "export", "restore":
+		if flags.NArg() < 2 {
+			flags.Usage()
+			return 2
+		}
+
+		if flags.Arg(0) == "export" {
+			path = "/api/v1/dump/caches/" + flags.Arg(1)
+			break
+		}
+
+		method, path = http.MethodPost, "/api/v1/dump/restore"
+		body = []byte(`{"generation":` + strconv.Quote(flags.Arg(1)) + `}`)
+
+	default:
+		flags.Usage()
+		return 2
+	}
+
+	req, err := http.NewRequest(method, *baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := db.SignDumpRequest(req, body, config.DataDumpToken); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	client := &http.Client{Timeout: time.Minute}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(stdout, resp.Body); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	fmt.Fprintln(stdout)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		fmt.Fprintln(stderr, resp.Status)
+		return 1
+	}
+
+	return 0
+}
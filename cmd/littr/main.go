@@ -2,7 +2,19 @@
 
 package main
 
+import "os"
+
 func main() {
+	// Run the data migrations subcommand instead of the server (see migrate.go).
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrateCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	// Call the data-intervention API of the running server (see dump.go).
+	if len(os.Args) > 1 && os.Args[1] == "dump" {
+		os.Exit(runDumpCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	c := newClient()
 	c.Run()
 
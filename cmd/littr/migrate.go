@@ -0,0 +1,147 @@
+//go:build !wasm
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/db"
+)
+
+const migrateUsage = `usage: littr migrate [-dry-run] <command>
+
+commands:
+  list                list the registered migrations and their state in the ledger
+  run                 run the pending migrations
+  rollback [version]  revert the migration (the latest applied one by default), and remove it from the ledger
+
+With the in-memory storage backend, run the command while the server is stopped, as the changed data are dumped to the snapshot files.
+`
+
+// runMigrateCommand is the "littr migrate" subcommand to manage the data migrations. It returns the process' exit code.
+func runMigrateCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, migrateUsage)
+	}
+
+	dryRun := flags.Bool("dry-run", false, "report the changes only, do not write anything")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	switch flags.Arg(0) {
+	case "list", "run", "rollback":
+	default:
+		flags.Usage()
+		return 2
+	}
+
+	keeper := db.NewDatabase()
+
+	defer func() {
+		if err := keeper.Close(); err != nil {
+			fmt.Fprintln(stderr, err)
+		}
+	}()
+
+	if flags.Arg(0) == "list" {
+		return listMigrations(keeper, stdout, stderr)
+	}
+
+	// Load the data to migrate.
+	if _, err := keeper.LoadAll(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	var (
+		report string
+		err    error
+	)
+
+	switch flags.Arg(0) {
+	case "run":
+		report, err = keeper.Migrate(*dryRun)
+
+	case "rollback":
+		var version int
+
+		if flags.NArg() > 1 {
+			if version, err = strconv.Atoi(flags.Arg(1)); err != nil {
+				fmt.Fprintf(stderr, "invalid migration version: %s\n", flags.Arg(1))
+				return 2
+			}
+		}
+
+		report, err = keeper.RollbackMigration(version, *dryRun)
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	fmt.Fprintln(stdout, report)
+
+	if *dryRun {
+		return 0
+	}
+
+	// Persist the migrated data (a no-op for the persistent backends).
+	dumpReport, err := keeper.DumpAll()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	fmt.Fprintln(stdout, dumpReport)
+
+	return 0
+}
+
+func listMigrations(keeper db.DatabaseKeeper, stdout, stderr io.Writer) int {
+	statuses, err := keeper.ListMigrations()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT\tDESCRIPTION")
+
+	for _, status := range statuses {
+		var (
+			state     = "pending"
+			appliedAt = "-"
+		)
+
+		if status.Record != nil {
+			appliedAt = status.Record.AppliedAt.Format(time.RFC3339)
+
+			if !status.Pending {
+				state = "applied"
+			}
+		}
+
+		if status.Repeatable {
+			state = "repeatable"
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt, status.Description)
+	}
+
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	return 0
+}
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	be "go.vxn.dev/littr/pkg/backend"
+	"go.vxn.dev/littr/pkg/backend/audit"
 	"go.vxn.dev/littr/pkg/backend/common"
 	"go.vxn.dev/littr/pkg/backend/db"
 	"go.vxn.dev/littr/pkg/backend/live"
@@ -113,6 +114,13 @@ func (s *server) init() {
 			s.l.Msg(report).Log()
 		}
 
+		// Import the snapshots into the SQL database on the very first boot with the SQLite repositories.
+		if report, err := be.ImportSnapshots(s.db); err != nil {
+			s.l.Error(err).Log()
+		} else if report != "" {
+			s.l.Msg(report).Log()
+		}
+
 		// Unlock the read access.
 		s.db.ReadUnlock()
 
@@ -127,6 +135,14 @@ func (s *server) init() {
 		} else {
 			s.l.Msg(migrationsReport).Log()
 		}
+
+		// Open the audit log, the server runs without it if it cannot be opened.
+		auditLog, err := audit.Open(audit.DefaultPath, audit.DefaultMaxSize, config.AuditLogRetention)
+		if err != nil {
+			s.l.Error(err).Log()
+		} else {
+			audit.SetDefault(auditLog)
+		}
 	})
 }
 
@@ -149,6 +165,20 @@ func (s *server) handleSignalsShutdown() {
 		// Create a shutdown logger.
 		l := common.NewLogger(nil, "shutdown")
 
+		// Release the storage as the very last step, when no more requests are served.
+		defer func() {
+			if err := s.db.Close(); err != nil {
+				l.Error(err).Log()
+			}
+		}()
+
+		// Close the audit log after the last request has been served too.
+		defer func() {
+			if err := audit.Close(); err != nil {
+				l.Error(err).Log()
+			}
+		}()
+
 		// Log and broadcast the message that the server is to shutdown.
 		l.Msg("trap signal: " + sig.String() + ", stopping the HTTP server gracefully...").Log()
 
@@ -0,0 +1,308 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/models"
+
+	chi "github.com/go-chi/chi/v5"
+)
+
+const (
+	loggerWorkerName string = "adminController"
+
+	pollIDParam string = "pollID"
+	postIDParam string = "postID"
+	userIDParam string = "userID"
+)
+
+type AdminController struct {
+	adminService models.AdminServiceInterface
+}
+
+func NewAdminController(adminService models.AdminServiceInterface) *AdminController {
+	if adminService == nil {
+		return nil
+	}
+
+	return &AdminController{
+		adminService: adminService,
+	}
+}
+
+// UpdateActivation is the admin handler that (de)activates an user.
+//
+//	@Summary		Activate or deactivate an user
+//	@Description		This function call sets the user's activation state. The deactivated user is logged out everywhere, and cannot log in until activated again. Requires the `user:manage` permission (the admin role).
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		admin.AdminActivationRequest		true	"The new activation state."
+//	@Param			userID	path		string					true	"ID of the user to (de)activate"
+//	@Success		200		{object}	common.APIResponse{data=models.Stub}	"The activation state has been updated."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid data received."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}	"User unauthorized."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"The caller is not permitted to manage users."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}	"Such user does not exist in the system."
+//	@Failure		409		{object}	common.APIResponse{data=models.Stub}	"The caller cannot deactivate their own account."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"There is an internal processing problem present."
+//	@Router			/admin/users/{userID}/activation [patch]
+func (c *AdminController) UpdateActivation(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, loggerWorkerName)
+
+	// Fetch the userID/nickname from the URI.
+	userID := chi.URLParam(r, userIDParam)
+	if userID == "" {
+		l.Msg(common.ERR_USERID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	var dtoIn AdminActivationRequest
+
+	// Decode the incoming data.
+	if err := common.UnmarshalRequestData(r, &dtoIn); err != nil {
+		l.Msg(common.ERR_INPUT_DATA_FAIL).Status(http.StatusBadRequest).Error(err).Log().Payload(nil).Write(w)
+		return
+	}
+
+	if err := c.adminService.SetUserActive(r.Context(), userID, dtoIn.Active); err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	l.Msg("ok, the user's activation state updated").Status(http.StatusOK).Log().Payload(nil).Write(w)
+}
+
+// UpdateRoles is the admin handler that assigns the roles to an user.
+//
+//	@Summary		Assign the roles
+//	@Description		This function call replaces the user's roles (`admin`, `moderator`) with the roles given, an empty list revokes all the roles. The change applies immediately. Requires the `role:assign` permission (the admin role).
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		admin.AdminRolesRequest			true	"The roles to assign."
+//	@Param			userID	path		string					true	"ID of the user to assign the roles to"
+//	@Success		200		{object}	common.APIResponse{data=admin.UpdateRoles.responseData}	"The roles have been assigned."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid data received, or an unknown role."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}	"User unauthorized."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"The caller is not permitted to assign roles."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}	"Such user does not exist in the system."
+//	@Failure		409		{object}	common.APIResponse{data=models.Stub}	"The caller cannot revoke their own admin role."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"There is an internal processing problem present."
+//	@Router			/admin/users/{userID}/roles [put]
+func (c *AdminController) UpdateRoles(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, loggerWorkerName)
+
+	type responseData struct {
+		Tags []string `json:"tags" example:"moderator"`
+	}
+
+	// Fetch the userID/nickname from the URI.
+	userID := chi.URLParam(r, userIDParam)
+	if userID == "" {
+		l.Msg(common.ERR_USERID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	var dtoIn AdminRolesRequest
+
+	// Decode the incoming data.
+	if err := common.UnmarshalRequestData(r, &dtoIn); err != nil {
+		l.Msg(common.ERR_INPUT_DATA_FAIL).Status(http.StatusBadRequest).Error(err).Log().Payload(nil).Write(w)
+		return
+	}
+
+	user, err := c.adminService.SetUserRoles(r.Context(), userID, dtoIn.Roles)
+	if err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	pl := &responseData{
+		Tags: user.Tags,
+	}
+
+	l.Msg("ok, the user's roles updated").Status(http.StatusOK).Log().Payload(pl).Write(w)
+}
+
+// DeleteUser is the admin handler that deletes an user.
+//
+//	@Summary		Delete an user
+//	@Description		This function call deletes the user together with all their posts, polls and sessions. Requires the `user:manage` permission (the admin role).
+//	@Tags			admin
+//	@Produce		json
+//	@Param			userID	path		string					true	"ID of the user to delete"
+//	@Success		200		{object}	common.APIResponse{data=models.Stub}	"The user has been deleted."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid data received."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}	"User unauthorized."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"The caller is not permitted to manage users."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}	"Such user does not exist in the system."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"There is an internal processing problem present."
+//	@Router			/admin/users/{userID} [delete]
+func (c *AdminController) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, loggerWorkerName)
+
+	// Fetch the userID/nickname from the URI.
+	userID := chi.URLParam(r, userIDParam)
+	if userID == "" {
+		l.Msg(common.ERR_USERID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	if err := c.adminService.DeleteUser(r.Context(), userID); err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	l.Msg("ok, the user deleted").Status(http.StatusOK).Log().Payload(nil).Write(w)
+}
+
+// DeletePost is the admin handler that deletes any post.
+//
+//	@Summary		Delete a post
+//	@Description		This function call deletes the post regardless of its author. Requires the `post:delete` permission (the admin, or moderator role).
+//	@Tags			admin
+//	@Produce		json
+//	@Param			postID	path		string					true	"ID of the post to delete"
+//	@Success		200		{object}	common.APIResponse{data=models.Stub}	"The post has been deleted."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid data received."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}	"User unauthorized."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"The caller is not permitted to delete posts."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}	"Such post does not exist."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"There is an internal processing problem present."
+//	@Router			/admin/posts/{postID} [delete]
+func (c *AdminController) DeletePost(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, loggerWorkerName)
+
+	// Fetch the postID from the URI.
+	postID := chi.URLParam(r, postIDParam)
+	if postID == "" {
+		l.Msg(common.ERR_POSTID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	if err := c.adminService.DeletePost(r.Context(), postID); err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	l.Msg("ok, the post deleted").Status(http.StatusOK).Log().Payload(nil).Write(w)
+}
+
+// DeletePoll is the admin handler that deletes any poll.
+//
+//	@Summary		Delete a poll
+//	@Description		This function call deletes the poll regardless of its author. Requires the `poll:delete` permission (the admin, or moderator role).
+//	@Tags			admin
+//	@Produce		json
+//	@Param			pollID	path		string					true	"ID of the poll to delete"
+//	@Success		200		{object}	common.APIResponse{data=models.Stub}	"The poll has been deleted."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid data received."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}	"User unauthorized."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"The caller is not permitted to delete polls."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}	"Such poll does not exist."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"There is an internal processing problem present."
+//	@Router			/admin/polls/{pollID} [delete]
+func (c *AdminController) DeletePoll(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, loggerWorkerName)
+
+	// Fetch the pollID from the URI.
+	pollID := chi.URLParam(r, pollIDParam)
+	if pollID == "" {
+		l.Msg(common.ERR_POLLID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	if err := c.adminService.DeletePoll(r.Context(), pollID); err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	l.Msg("ok, the poll deleted").Status(http.StatusOK).Log().Payload(nil).Write(w)
+}
+
+// GetAuditEvents is the admin handler that lists the audit log's events.
+//
+//	@Summary		List the audit events
+//	@Description		This function call returns the audit log's events matching the filters given, the newest ones first. The action filter ending with a dot matches a whole group of actions (e.g. `auth.`). Requires the `audit:read` permission (the admin role).
+//	@Tags			admin
+//	@Produce		json
+//	@Param			actor	query		string					false	"Nickname of the actor"
+//	@Param			target	query		string					false	"ID of the entity affected"
+//	@Param			action	query		string					false	"Action, or the group of actions (e.g. auth.)"
+//	@Param			ip	query		string					false	"IP address of the actor"
+//	@Param			since	query		string					false	"The oldest timestamp (RFC 3339)"
+//	@Param			until	query		string					false	"The newest timestamp (RFC 3339)"
+//	@Param			limit	query		int					false	"Maximum count of the events (1000 at most)"
+//	@Success		200		{object}	common.APIResponse{data=admin.GetAuditEvents.responseData}	"The audit events."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid filters received."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}	"User unauthorized."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"The caller is not permitted to read the audit log."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"There is an internal processing problem present."
+//	@Router			/admin/audit [get]
+func (c *AdminController) GetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, loggerWorkerName)
+
+	type responseData struct {
+		Events []models.AuditEvent `json:"events"`
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		l.Msg(common.ERR_AUDIT_FILTER_INVALID).Status(http.StatusBadRequest).Error(err).Log().Payload(nil).Write(w)
+		return
+	}
+
+	events, err := c.adminService.FindAuditEvents(r.Context(), filter)
+	if err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	pl := &responseData{
+		Events: events,
+	}
+
+	l.Msg("ok, dumping the audit events").Status(http.StatusOK).Log().Payload(pl).Write(w)
+}
+
+// parseAuditFilter reads the audit log's filter from the request's query.
+func parseAuditFilter(r *http.Request) (*models.AuditFilter, error) {
+	query := r.URL.Query()
+
+	filter := &models.AuditFilter{
+		Actor:     query.Get("actor"),
+		Target:    query.Get("target"),
+		Action:    query.Get("action"),
+		IPAddress: query.Get("ip"),
+	}
+
+	var err error
+
+	if since := query.Get("since"); since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return nil, err
+		}
+	}
+
+	if until := query.Get("until"); until != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return nil, err
+		}
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			return nil, err
+		}
+
+		if filter.Limit < 1 {
+			return nil, strconv.ErrRange
+		}
+	}
+
+	return filter, nil
+}
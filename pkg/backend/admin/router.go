@@ -0,0 +1,28 @@
+// Administration routes and controllers logic package for the backend.
+package admin
+
+import (
+	"go.vxn.dev/littr/pkg/backend/roles"
+	"go.vxn.dev/littr/pkg/models"
+
+	chi "github.com/go-chi/chi/v5"
+)
+
+// NewAdminRouter returns the router of the administration routes, each of them guarded by the permission required.
+func NewAdminRouter(adminController *AdminController, userRepository models.UserRepositoryInterface) chi.Router {
+	r := chi.NewRouter()
+
+	// User management routes handlers.
+	r.With(roles.RequirePermission(userRepository, roles.PermUserManage)).Patch("/users/{userID}/activation", adminController.UpdateActivation)
+	r.With(roles.RequirePermission(userRepository, roles.PermUserManage)).Delete("/users/{userID}", adminController.DeleteUser)
+	r.With(roles.RequirePermission(userRepository, roles.PermRoleAssign)).Put("/users/{userID}/roles", adminController.UpdateRoles)
+
+	// Content moderation routes handlers.
+	r.With(roles.RequirePermission(userRepository, roles.PermPostDelete)).Delete("/posts/{postID}", adminController.DeletePost)
+	r.With(roles.RequirePermission(userRepository, roles.PermPollDelete)).Delete("/polls/{pollID}", adminController.DeletePoll)
+
+	// Audit log routes handlers.
+	r.With(roles.RequirePermission(userRepository, roles.PermAuditRead)).Get("/audit", adminController.GetAuditEvents)
+
+	return r
+}
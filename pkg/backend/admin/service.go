@@ -0,0 +1,235 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.vxn.dev/littr/pkg/backend/audit"
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/backend/roles"
+	"go.vxn.dev/littr/pkg/backend/users"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+// models.AdminServiceInterface implementation
+//
+
+// The caller's permissions are checked by the router (see roles.RequirePermission).
+type AdminService struct {
+	transactor      db.Transactor
+	pollRepository  models.PollRepositoryInterface
+	postRepository  models.PostRepositoryInterface
+	tokenRepository models.TokenRepositoryInterface
+	userRepository  models.UserRepositoryInterface
+}
+
+func NewAdminService(
+	transactor db.Transactor,
+	pollRepository models.PollRepositoryInterface,
+	postRepository models.PostRepositoryInterface,
+	tokenRepository models.TokenRepositoryInterface,
+	userRepository models.UserRepositoryInterface,
+) models.AdminServiceInterface {
+	if transactor == nil || pollRepository == nil || postRepository == nil || tokenRepository == nil || userRepository == nil {
+		return nil
+	}
+
+	return &AdminService{
+		transactor:      transactor,
+		pollRepository:  pollRepository,
+		postRepository:  postRepository,
+		tokenRepository: tokenRepository,
+		userRepository:  userRepository,
+	}
+}
+
+func (s *AdminService) SetUserActive(ctx context.Context, userID string, active bool) error {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if userID == "" {
+		return fmt.Errorf(common.ERR_USERID_BLANK)
+	}
+
+	if userID == callerID && !active {
+		return fmt.Errorf(common.ERR_ADMIN_SELF_DEACTIVATE)
+	}
+
+	if err := s.userRepository.Update(userID, func(user *models.User) error {
+		user.Active = active
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, models.AuditEvent{
+		Action:  audit.ActionAdminActivation,
+		Target:  userID,
+		Details: map[string]string{"active": strconv.FormatBool(active)},
+	})
+
+	if active {
+		return nil
+	}
+
+	// The deactivated user is logged out everywhere, the login is refused then.
+	tokens, err := s.tokenRepository.GetByNickname(userID)
+	if err != nil {
+		return err
+	}
+
+	for key := range *tokens {
+		if err := s.tokenRepository.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *AdminService) SetUserRoles(ctx context.Context, userID string, newRoles []string) (*models.User, error) {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if userID == "" {
+		return nil, fmt.Errorf(common.ERR_USERID_BLANK)
+	}
+
+	for _, role := range newRoles {
+		if !roles.IsValid(role) {
+			return nil, fmt.Errorf(common.ERR_ROLE_UNKNOWN)
+		}
+	}
+
+	// An admin cannot lock themselves out of the administration.
+	if userID == callerID && !slices.Contains(newRoles, string(roles.RoleAdmin)) {
+		return nil, fmt.Errorf(common.ERR_ADMIN_SELF_DEMOTE)
+	}
+
+	var updated models.User
+
+	if err := s.userRepository.Update(userID, func(user *models.User) error {
+		// Keep the tags not being roles.
+		tags := []string{}
+		for _, tag := range user.Tags {
+			if !roles.IsValid(tag) {
+				tags = append(tags, tag)
+			}
+		}
+
+		for _, role := range newRoles {
+			if !slices.Contains(tags, role) {
+				tags = append(tags, role)
+			}
+		}
+
+		user.Tags = tags
+
+		updated = *user
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, models.AuditEvent{
+		Action:  audit.ActionAdminRoles,
+		Target:  userID,
+		Details: map[string]string{"roles": strings.Join(newRoles, ",")},
+	})
+
+	return &updated, nil
+}
+
+func (s *AdminService) DeleteUser(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf(common.ERR_USERID_BLANK)
+	}
+
+	if _, err := s.userRepository.GetByID(userID); err != nil {
+		return fmt.Errorf(common.ERR_USER_NOT_FOUND)
+	}
+
+	if err := users.PurgeUser(s.transactor, userID); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, models.AuditEvent{
+		Action: audit.ActionAdminUserDelete,
+		Target: userID,
+	})
+
+	return nil
+}
+
+func (s *AdminService) DeletePost(ctx context.Context, postID string) error {
+	if postID == "" {
+		return fmt.Errorf(common.ERR_POSTID_BLANK)
+	}
+
+	// Fetch the post to verify it exists at all.
+	post, err := s.postRepository.GetByID(postID)
+	if err != nil {
+		return fmt.Errorf(common.ERR_POST_NOT_FOUND)
+	}
+
+	if err := s.postRepository.Delete(postID); err != nil {
+		return err
+	}
+
+	// Drop the post from the users' bookmarks.
+	if err := users.PurgeBookmarks(s.userRepository, postID); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, models.AuditEvent{
+		Action:  audit.ActionAdminPostDelete,
+		Target:  postID,
+		Details: map[string]string{"author": post.Nickname},
+	})
+
+	return nil
+}
+
+func (s *AdminService) DeletePoll(ctx context.Context, pollID string) error {
+	if pollID == "" {
+		return fmt.Errorf(common.ERR_POLLID_BLANK)
+	}
+
+	// Fetch the poll to verify it exists at all.
+	poll, err := s.pollRepository.GetByID(pollID)
+	if err != nil {
+		return fmt.Errorf(common.ERR_POLL_NOT_FOUND)
+	}
+
+	if err := s.pollRepository.Delete(pollID); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, models.AuditEvent{
+		Action:  audit.ActionAdminPollDelete,
+		Target:  pollID,
+		Details: map[string]string{"author": poll.Author},
+	})
+
+	return nil
+}
+
+// maxAuditEvents is the limit of the events returned by one query.
+const maxAuditEvents = 1000
+
+func (s *AdminService) FindAuditEvents(ctx context.Context, filter *models.AuditFilter) ([]models.AuditEvent, error) {
+	if filter == nil {
+		filter = &models.AuditFilter{}
+	}
+
+	if filter.Limit <= 0 || filter.Limit > maxAuditEvents {
+		filter.Limit = maxAuditEvents
+	}
+
+	return audit.Query(filter)
+}
@@ -0,0 +1,11 @@
+package admin
+
+type AdminActivationRequest struct {
+	// Active is the new state of the account, the deactivated user is logged out, and cannot log in.
+	Active bool `json:"active" example:"false"`
+}
+
+type AdminRolesRequest struct {
+	// Roles replace the user's current roles, the other user's tags are kept.
+	Roles []string `json:"roles" example:"moderator"`
+}
@@ -0,0 +1,165 @@
+// Personal API tokens package for the backend.
+package apitokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Personal API tokens
+//  The long-lived tokens for bots and scripts, sent in the Authorization: Bearer header. Each token is granted a set of scopes, the scope
+//  required by the request is derived from the API route and the HTTP method (see RequiredScope).
+//
+
+const (
+	// TokenPrefix makes the tokens easy to recognize (e.g. by the secret scanners).
+	TokenPrefix = "littr_"
+
+	// MaxTTL is the longest validity of a token.
+	MaxTTL = time.Hour * 24 * 365
+
+	// MaxNameLength is the maximum length of the token's name.
+	MaxNameLength = 40
+)
+
+const (
+	ScopePostsRead  = "posts:read"
+	ScopePostsWrite = "posts:write"
+	ScopePollsRead  = "polls:read"
+	ScopePollsWrite = "polls:write"
+	ScopeUsersRead  = "users:read"
+	ScopeUsersWrite = "users:write"
+	ScopeStatsRead  = "stats:read"
+)
+
+// Scopes lists all the scopes a token can be granted.
+var Scopes = []string{
+	ScopePostsRead,
+	ScopePostsWrite,
+	ScopePollsRead,
+	ScopePollsWrite,
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeStatsRead,
+}
+
+// restrictedUserRoutes are the account's security routes, those require the browser session (the cookies), so a leaked token cannot be
+// used to take over the account.
+var restrictedUserRoutes = []string{
+	"2fa",
+	"passphrase",
+	"sessions",
+	"tokens",
+}
+
+// IsValidScope reports whether such scope is a known one.
+func IsValidScope(scope string) bool {
+	return slices.Contains(Scopes, scope)
+}
+
+// NewAPIToken generates a new token for such user. The plain token is returned to be shown to the user just once, only its hash is kept.
+func NewAPIToken(nickname, name string, scopes []string, ttl time.Duration) (string, *models.APIToken, error) {
+	raw := make([]byte, 32)
+
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+
+	plain := TokenPrefix + hex.EncodeToString(raw)
+	now := time.Now()
+
+	token := &models.APIToken{
+		Hash:      Hash(plain),
+		Nickname:  nickname,
+		Name:      name,
+		Scopes:    scopes,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	return plain, token, nil
+}
+
+// Hash returns the token's fingerprint used as its ID in the database.
+func Hash(plain string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(plain)))
+}
+
+// FromRequest returns the token sent in the Authorization: Bearer header, or a blank string.
+func FromRequest(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return ""
+	}
+
+	return strings.TrimSpace(header[7:])
+}
+
+// RequiredScope returns the scope the request has to be authorized by. False is returned for the routes not accessible using the tokens at all.
+func RequiredScope(r *http.Request) (string, bool) {
+	// Trim the /api/v1 prefix: e.g. /api/v1/users/alice/posts => [users alice posts].
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1"), "/"), "/")
+
+	write := r.Method != http.MethodGet && r.Method != http.MethodHead
+
+	switch parts[0] {
+	case "posts":
+		if write {
+			return ScopePostsWrite, true
+		}
+		return ScopePostsRead, true
+
+	case "polls":
+		if write {
+			return ScopePollsWrite, true
+		}
+		return ScopePollsRead, true
+
+	case "users":
+		if len(parts) > 2 && slices.Contains(restrictedUserRoutes, parts[2]) {
+			return "", false
+		}
+
+		// The account's deletion is restricted too.
+		if len(parts) == 2 && r.Method == http.MethodDelete {
+			return "", false
+		}
+
+		if write {
+			return ScopeUsersWrite, true
+		}
+		return ScopeUsersRead, true
+
+	case "stats":
+		if write {
+			return "", false
+		}
+		return ScopeStatsRead, true
+
+	case "search":
+		if write {
+			return "", false
+		}
+
+		// The search of a single kind requires its read scope, the search of all kinds falls under the posts one.
+		switch r.URL.Query().Get("type") {
+		case "users":
+			return ScopeUsersRead, true
+		case "polls":
+			return ScopePollsRead, true
+		}
+		return ScopePostsRead, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,77 @@
+package apitokens
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPITokens_NewAPIToken(t *testing.T) {
+	plain, token, err := NewAPIToken("alice", "bot", []string{ScopePostsRead}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(plain, TokenPrefix) {
+		t.Errorf("unexpected token format: %s", plain)
+	}
+
+	// Only the hash is kept.
+	if token.Hash != Hash(plain) || strings.Contains(token.Hash, plain) {
+		t.Error("token hash mismatch")
+	}
+
+	if token.IsExpired() || !token.HasScope(ScopePostsRead) || token.HasScope(ScopePostsWrite) {
+		t.Errorf("unexpected token properties: %+v", token)
+	}
+}
+
+func TestAPITokens_FromRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/posts", nil)
+
+	if FromRequest(r) != "" {
+		t.Error("token found in a request without the header")
+	}
+
+	r.Header.Set("Authorization", "Basic YWxpY2U6c2VjcmV0")
+	if FromRequest(r) != "" {
+		t.Error("token found in the basic auth header")
+	}
+
+	r.Header.Set("Authorization", "bearer littr_abc")
+	if FromRequest(r) != "littr_abc" {
+		t.Error("bearer token not found")
+	}
+}
+
+func TestAPITokens_RequiredScope(t *testing.T) {
+	cases := []struct {
+		method string
+		path   string
+		scope  string
+		ok     bool
+	}{
+		{http.MethodGet, "/api/v1/posts", ScopePostsRead, true},
+		{http.MethodPost, "/api/v1/posts", ScopePostsWrite, true},
+		{http.MethodPatch, "/api/v1/polls/123", ScopePollsWrite, true},
+		{http.MethodGet, "/api/v1/users/alice/posts", ScopeUsersRead, true},
+		{http.MethodPatch, "/api/v1/users/alice/options", ScopeUsersWrite, true},
+		{http.MethodGet, "/api/v1/stats", ScopeStatsRead, true},
+		{http.MethodGet, "/api/v1/search?q=praha", ScopePostsRead, true},
+		{http.MethodGet, "/api/v1/search?q=alice&type=users", ScopeUsersRead, true},
+		{http.MethodPost, "/api/v1/users/alice/tokens", "", false},
+		{http.MethodDelete, "/api/v1/users/alice/sessions", "", false},
+		{http.MethodPatch, "/api/v1/users/alice/passphrase", "", false},
+		{http.MethodDelete, "/api/v1/users/alice", "", false},
+		{http.MethodDelete, "/api/v1/admin/posts/123", "", false},
+	}
+
+	for _, c := range cases {
+		scope, ok := RequiredScope(httptest.NewRequest(c.method, c.path, nil))
+		if scope != c.scope || ok != c.ok {
+			t.Errorf("%s %s: got (%s, %t), expected (%s, %t)", c.method, c.path, scope, ok, c.scope, c.ok)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package apitokens
+
+import (
+	"errors"
+	"fmt"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+// The implementation of pkg/models.APITokenRepositoryInterface.
+type APITokenRepository struct {
+	cache *db.TypedCache[models.APIToken]
+}
+
+func NewAPITokenRepository(cache db.Cacher) models.APITokenRepositoryInterface {
+	if cache == nil {
+		return nil
+	}
+
+	return &APITokenRepository{
+		cache: db.NewTypedCache(cache, models.APIToken{}),
+	}
+}
+
+func (r *APITokenRepository) GetAll() (*map[string]models.APIToken, error) {
+	tokens := make(map[string]models.APIToken, r.cache.Len())
+
+	// Loop over the cache items directly, no copy of the whole cache is made.
+	if err := r.cache.Iterate(func(token models.APIToken) bool {
+		tokens[token.Hash] = token
+		return true
+	}); err != nil {
+		return nil, fmt.Errorf("API token's data corrupted: %w", err)
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf(common.ERR_NO_ITEMS_FOUND)
+	}
+
+	return &tokens, nil
+}
+
+func (r *APITokenRepository) GetByID(tokenID string) (*models.APIToken, error) {
+	token, err := r.cache.Get(tokenID)
+	if errors.Is(err, db.ErrItemNotFound) {
+		return nil, fmt.Errorf(common.ERR_API_TOKEN_NOT_FOUND)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not assert type *models.APIToken")
+	}
+
+	return &token, nil
+}
+
+// GetByNickname fetches all API tokens of such user.
+func (r *APITokenRepository) GetByNickname(nickname string) (*map[string]models.APIToken, error) {
+	tokens := make(map[string]models.APIToken)
+
+	if err := r.cache.Iterate(func(token models.APIToken) bool {
+		if token.Nickname == nickname {
+			tokens[token.Hash] = token
+		}
+		return true
+	}); err != nil {
+		return nil, fmt.Errorf("API token's data corrupted: %w", err)
+	}
+
+	return &tokens, nil
+}
+
+func (r *APITokenRepository) Save(token *models.APIToken) error {
+	// Store the token using its key in the cache.
+	if err := r.cache.Put(*token); err != nil {
+		return fmt.Errorf("an error occurred while saving an API token")
+	}
+
+	return nil
+}
+
+func (r *APITokenRepository) Update(tokenID string, fn func(token *models.APIToken) error) error {
+	err := r.cache.Update(tokenID, fn)
+	if errors.Is(err, db.ErrItemNotFound) {
+		return fmt.Errorf(common.ERR_API_TOKEN_NOT_FOUND)
+	}
+
+	return err
+}
+
+func (r *APITokenRepository) Delete(tokenID string) error {
+	if err := r.cache.Delete(tokenID); err != nil {
+		return fmt.Errorf("API token data could not be purged from the database")
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package apitokens
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+// The implementation of pkg/models.APITokenRepositoryInterface on the SQLite database (see pkg/backend/db/sqlite.go for the schema).
+type SQLiteAPITokenRepository struct {
+	db db.SQLHandle
+}
+
+func NewSQLiteAPITokenRepository(handle db.SQLHandle) *SQLiteAPITokenRepository {
+	if handle == nil {
+		return nil
+	}
+
+	return &SQLiteAPITokenRepository{
+		db: handle,
+	}
+}
+
+func (r *SQLiteAPITokenRepository) GetAll() (*map[string]models.APIToken, error) {
+	tokens, err := r.query("SELECT data FROM api_tokens")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*tokens) == 0 {
+		return nil, fmt.Errorf(common.ERR_NO_ITEMS_FOUND)
+	}
+
+	return tokens, nil
+}
+
+// GetByNickname fetches all API tokens of such user using the nickname index.
+func (r *SQLiteAPITokenRepository) GetByNickname(nickname string) (*map[string]models.APIToken, error) {
+	return r.query("SELECT data FROM api_tokens WHERE nickname = ?", nickname)
+}
+
+func (r *SQLiteAPITokenRepository) GetByID(tokenID string) (*models.APIToken, error) {
+	if tokenID == "" {
+		return nil, fmt.Errorf("tokenID is blank")
+	}
+
+	var raw []byte
+
+	err := r.db.QueryRow("SELECT data FROM api_tokens WHERE hash = ?", tokenID).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(common.ERR_API_TOKEN_NOT_FOUND)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var token models.APIToken
+
+	if err := json.Unmarshal(raw, &token); err != nil {
+		return nil, fmt.Errorf("could not assert type *models.APIToken")
+	}
+
+	return &token, nil
+}
+
+func (r *SQLiteAPITokenRepository) Save(token *models.APIToken) error {
+	raw, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(`INSERT INTO api_tokens (hash, nickname, expires_at, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (hash) DO UPDATE SET nickname = excluded.nickname, expires_at = excluded.expires_at, data = excluded.data`,
+		token.Hash, token.Nickname, token.ExpiresAt.UnixNano(), raw); err != nil {
+		return fmt.Errorf("an error occurred while saving an API token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteAPITokenRepository) Update(tokenID string, fn func(token *models.APIToken) error) error {
+	return db.RetryOnConflict(func() error {
+		token, err := r.GetByID(tokenID)
+		if err != nil {
+			return err
+		}
+
+		version := token.Version
+
+		if err := fn(token); err != nil {
+			return err
+		}
+
+		token.Version = version + 1
+
+		raw, err := json.Marshal(token)
+		if err != nil {
+			return err
+		}
+
+		res, err := r.db.Exec("UPDATE api_tokens SET data = ? WHERE hash = ? AND "+db.SQLVersionCondition, raw, tokenID, version)
+		if err != nil {
+			return fmt.Errorf("an error occurred while saving an API token: %w", err)
+		}
+
+		return db.CheckSwapped(res)
+	})
+}
+
+func (r *SQLiteAPITokenRepository) Delete(tokenID string) error {
+	if _, err := r.db.Exec("DELETE FROM api_tokens WHERE hash = ?", tokenID); err != nil {
+		return fmt.Errorf("API token data could not be purged from the database")
+	}
+
+	return nil
+}
+
+// query runs the query selecting the data column and decodes the rows into a map of API tokens.
+func (r *SQLiteAPITokenRepository) query(query string, args ...interface{}) (*map[string]models.APIToken, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make(map[string]models.APIToken)
+
+	for rows.Next() {
+		var raw []byte
+
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+
+		var token models.APIToken
+
+		if err := json.Unmarshal(raw, &token); err != nil {
+			return nil, fmt.Errorf("API token's data corrupted")
+		}
+
+		tokens[token.Hash] = token
+	}
+
+	return &tokens, rows.Err()
+}
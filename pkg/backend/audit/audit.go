@@ -0,0 +1,122 @@
+// Audit log package for the backend.
+package audit
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Audit log
+//  The security-relevant and moderation actions are recorded as typed events (see models.AuditEvent) to the append-only log. The log is opened
+//  on the server's start (see cmd/littr/server.go), the events are dropped silently until then (e.g. in tests).
+//
+
+const (
+	// DefaultPath is the audit log's file, the rotated files are suffixed with their generation (audit.jsonl.1 being the newest one).
+	DefaultPath = "/opt/data/audit.jsonl"
+
+	// DefaultMaxSize is the size the audit log is rotated after.
+	DefaultMaxSize int64 = 10 << 20
+
+	loggerWorkerName = "audit"
+)
+
+// The audit actions.
+const (
+	ActionLogin       = "auth.login"
+	ActionLoginFailed = "auth.login_failed"
+	ActionLockout     = "auth.lockout"
+	ActionLogout      = "auth.logout"
+	ActionOIDCLink    = "auth.oidc_link"
+
+	ActionUserCreate        = "user.create"
+	ActionUserActivate      = "user.activate"
+	ActionUserDelete        = "user.delete"
+	ActionUserListsUpdate   = "user.lists_update"
+	ActionPassphraseChange  = "user.passphrase_change"
+	ActionPassphraseRequest = "user.passphrase_request"
+	ActionPassphraseReset   = "user.passphrase_reset"
+	ActionTwoFactorEnable   = "user.2fa_enable"
+	ActionTwoFactorDisable  = "user.2fa_disable"
+	ActionSessionRevoke     = "user.session_revoke"
+	ActionAPITokenCreate    = "user.api_token_create"
+	ActionAPITokenDelete    = "user.api_token_delete"
+
+	ActionPostDelete = "post.delete"
+	ActionPollDelete = "poll.delete"
+
+	ActionAdminActivation = "admin.user_activation"
+	ActionAdminRoles      = "admin.user_roles"
+	ActionAdminUserDelete = "admin.user_delete"
+	ActionAdminPostDelete = "admin.post_delete"
+	ActionAdminPollDelete = "admin.poll_delete"
+
+	ActionDataDump    = "data.dump"
+	ActionDataLoad    = "data.load"
+	ActionDataMigrate = "data.migrate"
+	ActionDataExport  = "data.export"
+	ActionDataRestore = "data.restore"
+)
+
+// defaultLog is the log the events are recorded to.
+var defaultLog atomic.Pointer[Log]
+
+// SetDefault sets the log the events are to be recorded to, nil stops the recording.
+func SetDefault(log *Log) {
+	defaultLog.Store(log)
+}
+
+// Close stops the recording, and closes the default log.
+func Close() error {
+	log := defaultLog.Swap(nil)
+	if log == nil {
+		return nil
+	}
+
+	return log.Close()
+}
+
+// Record appends the event to the audit log. The actor and the IP address are taken from the request's context unless set in the event.
+func Record(ctx context.Context, event models.AuditEvent) {
+	log := defaultLog.Load()
+	if log == nil {
+		return
+	}
+
+	if event.Actor == "" {
+		event.Actor = common.GetCallerID(ctx)
+	}
+
+	if event.IPAddress == "" {
+		event.IPAddress = common.GetIPAddress(ctx)
+	}
+
+	// The failed record must not fail the action itself, it is logged at least.
+	if err := log.Append(&event); err != nil {
+		common.NewLogger(nil, loggerWorkerName).Msg("could not record the audit event " + event.Action).Status(http.StatusInternalServerError).Error(err).Log()
+	}
+}
+
+// Query returns the events of the default log matching the filter, the newest ones first.
+func Query(filter *models.AuditFilter) ([]models.AuditEvent, error) {
+	log := defaultLog.Load()
+	if log == nil {
+		return []models.AuditEvent{}, nil
+	}
+
+	return log.Query(filter)
+}
+
+// Middleware stores the client's IP address in the request's context for the events recorded by the services.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), common.ContextIPAddressKeyName, common.ClientIPAddress(r))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,257 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+const (
+	// maxLineSize is the sanity limit of the event's line read back.
+	maxLineSize = 1 << 20
+)
+
+var errLogClosed = errors.New("audit log is not open")
+
+// Log is the append-only audit log: one JSON-encoded event per line. The file is rotated when grown over the size limit, the oldest files over
+// the retention count are removed.
+type Log struct {
+	path      string
+	maxSize   int64
+	retention int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+
+	// seq makes the IDs of the events recorded within the same nanosecond unique.
+	seq uint64
+}
+
+// Open opens (or creates) the audit log at such path.
+func Open(path string, maxSize int64, retention int) (*Log, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	if retention < 1 {
+		retention = 1
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, err
+	}
+
+	log := &Log{
+		path:      path,
+		maxSize:   maxSize,
+		retention: retention,
+	}
+
+	if err := log.open(); err != nil {
+		return nil, err
+	}
+
+	return log, nil
+}
+
+func (l *Log) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	l.file = file
+	l.size = info.Size()
+
+	return nil
+}
+
+// Append writes the event to the log, the ID and the timestamp are set if blank.
+func (l *Log) Append(event *models.AuditEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return errLogClosed
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	if event.ID == "" {
+		l.seq++
+		event.ID = strconv.FormatInt(event.Timestamp.UnixNano(), 10) + "-" + strconv.FormatUint(l.seq, 10)
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	line = append(line, '\n')
+
+	if l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+
+	return err
+}
+
+// rotate shifts the rotated files by one generation (dropping the oldest one), and starts a new file.
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+
+	l.file = nil
+
+	if err := os.Remove(l.generation(l.retention)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for gen := l.retention - 1; gen >= 0; gen-- {
+		if err := os.Rename(l.generation(gen), l.generation(gen+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return l.open()
+}
+
+// generation returns the path of the file of such generation, 0 being the current one.
+func (l *Log) generation(gen int) string {
+	if gen == 0 {
+		return l.path
+	}
+
+	return fmt.Sprintf("%s.%d", l.path, gen)
+}
+
+// Close closes the log, no events can be appended then.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+
+	err := l.file.Close()
+	l.file = nil
+
+	return err
+}
+
+// Query returns the events matching the filter, the newest ones first. The files are read from the newest one until the limit is reached.
+func (l *Log) Query(filter *models.AuditFilter) ([]models.AuditEvent, error) {
+	if filter == nil {
+		filter = &models.AuditFilter{}
+	}
+
+	// Keep the files from being rotated while read.
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	events := []models.AuditEvent{}
+
+	for gen := 0; gen <= l.retention; gen++ {
+		matched, err := readMatching(l.generation(gen), filter)
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		for i := len(matched) - 1; i >= 0; i-- {
+			events = append(events, matched[i])
+
+			if filter.Limit > 0 && len(events) >= filter.Limit {
+				return events, nil
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// readMatching returns the events of the file matching the filter in the order written. The malformed lines (e.g. torn by a crash) are skipped.
+func readMatching(path string, filter *models.AuditFilter) ([]models.AuditEvent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []models.AuditEvent
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+
+	for scanner.Scan() {
+		var event models.AuditEvent
+
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+
+		if matches(&event, filter) {
+			events = append(events, event)
+		}
+	}
+
+	return events, scanner.Err()
+}
+
+// matches reports whether the event meets all the filter's criteria.
+func matches(event *models.AuditEvent, filter *models.AuditFilter) bool {
+	switch {
+	case filter.Actor != "" && event.Actor != filter.Actor:
+		return false
+
+	case filter.Target != "" && event.Target != filter.Target:
+		return false
+
+	case filter.IPAddress != "" && event.IPAddress != filter.IPAddress:
+		return false
+
+	case !filter.Since.IsZero() && event.Timestamp.Before(filter.Since):
+		return false
+
+	case !filter.Until.IsZero() && event.Timestamp.After(filter.Until):
+		return false
+	}
+
+	if filter.Action == "" {
+		return true
+	}
+
+	if strings.HasSuffix(filter.Action, ".") {
+		return strings.HasPrefix(event.Action, filter.Action)
+	}
+
+	return event.Action == filter.Action
+}
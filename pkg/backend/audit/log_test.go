@@ -0,0 +1,182 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+func newTestLog(t *testing.T, maxSize int64, retention int) (*Log, string) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	log, err := Open(path, maxSize, retention)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { log.Close() })
+
+	return log, path
+}
+
+func TestLog_QueryFilters(t *testing.T) {
+	log, _ := newTestLog(t, 0, 1)
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	events := []models.AuditEvent{
+		{Action: ActionLoginFailed, Actor: "alice", Target: "alice", IPAddress: "10.0.0.1", Timestamp: base},
+		{Action: ActionLogin, Actor: "alice", Target: "alice", IPAddress: "10.0.0.1", Timestamp: base.Add(time.Minute)},
+		{Action: ActionAdminPostDelete, Actor: "bob", Target: "123", IPAddress: "10.0.0.2", Timestamp: base.Add(2 * time.Minute)},
+		{Action: ActionLockout, Actor: "system", Target: "cody", IPAddress: "10.0.0.3", Timestamp: base.Add(3 * time.Minute)},
+	}
+
+	for i := range events {
+		if err := log.Append(&events[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter *models.AuditFilter
+		want   []string
+	}{
+		{"all newest first", nil, []string{ActionLockout, ActionAdminPostDelete, ActionLogin, ActionLoginFailed}},
+		{"actor", &models.AuditFilter{Actor: "alice"}, []string{ActionLogin, ActionLoginFailed}},
+		{"target", &models.AuditFilter{Target: "123"}, []string{ActionAdminPostDelete}},
+		{"exact action", &models.AuditFilter{Action: ActionLogin}, []string{ActionLogin}},
+		{"action group", &models.AuditFilter{Action: "auth."}, []string{ActionLockout, ActionLogin, ActionLoginFailed}},
+		{"ip address", &models.AuditFilter{IPAddress: "10.0.0.2"}, []string{ActionAdminPostDelete}},
+		{"time range", &models.AuditFilter{Since: base.Add(time.Minute), Until: base.Add(2 * time.Minute)}, []string{ActionAdminPostDelete, ActionLogin}},
+		{"limit", &models.AuditFilter{Limit: 1}, []string{ActionLockout}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := log.Query(tc.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d events, want %d", len(got), len(tc.want))
+			}
+
+			for i, event := range got {
+				if event.Action != tc.want[i] {
+					t.Errorf("event %d: got %s, want %s", i, event.Action, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLog_Rotation(t *testing.T) {
+	// Room for a few events per file only.
+	log, path := newTestLog(t, 512, 2)
+
+	for i := 0; i < 50; i++ {
+		if err := log.Append(&models.AuditEvent{Action: ActionLogin, Actor: "alice", Target: strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, gen := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(gen)
+		if err != nil {
+			t.Fatalf("missing file: %v", err)
+		}
+
+		if info.Size() > 512 {
+			t.Errorf("%s has grown over the limit: %d", gen, info.Size())
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("the file over the retention has been kept")
+	}
+
+	events, err := log.Query(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The newest events are kept across the files, and returned in order.
+	if len(events) == 0 || events[0].Target != "49" {
+		t.Fatalf("unexpected newest event: %+v", events)
+	}
+
+	for i := 1; i < len(events); i++ {
+		prev, _ := strconv.Atoi(events[i-1].Target)
+		curr, _ := strconv.Atoi(events[i].Target)
+
+		if curr != prev-1 {
+			t.Fatalf("events out of order: %s after %s", events[i].Target, events[i-1].Target)
+		}
+	}
+}
+
+func TestLog_SkipsTornLine(t *testing.T) {
+	log, path := newTestLog(t, 0, 1)
+
+	if err := log.Append(&models.AuditEvent{Action: ActionLogin, Actor: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash in the middle of a write.
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file.WriteString(`{"id":"1","action":"auth.lo`)
+	file.Close()
+
+	events, err := log.Query(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 1 || events[0].Actor != "alice" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+}
+
+func TestRecord_FillsFromContext(t *testing.T) {
+	log, _ := newTestLog(t, 0, 1)
+
+	SetDefault(log)
+	t.Cleanup(func() { SetDefault(nil) })
+
+	ctx := context.WithValue(context.Background(), common.ContextUserKeyName, "bob")
+	ctx = context.WithValue(ctx, common.ContextIPAddressKeyName, "10.0.0.2")
+
+	Record(ctx, models.AuditEvent{Action: ActionPostDelete, Target: "123"})
+
+	// The explicit actor is kept.
+	Record(ctx, models.AuditEvent{Action: ActionLockout, Actor: "system", Target: "cody"})
+
+	events, err := Query(&models.AuditFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+
+	if events[1].Actor != "bob" || events[1].IPAddress != "10.0.0.2" || events[1].ID == "" || events[1].Timestamp.IsZero() {
+		t.Errorf("the event has not been filled in: %+v", events[1])
+	}
+
+	if events[0].Actor != "system" {
+		t.Errorf("the actor has been overwritten: %s", events[0].Actor)
+	}
+}
@@ -7,6 +7,13 @@ type AuthUser struct {
 	// PassphrasePlain is the plain-text format of the passphrase.
 	PassphrasePlain string `json:"passphrase_plain"  example:"s3creTpauWussw0rt"`
 
+	// UserAgent and IPAddress describe the client to be shown in the user's sessions list, they are filled in by the controller.
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+
+	// TOTPCode is the second factor of the users with the two-factor authentication enabled: the TOTP code, or one of the recovery codes.
+	TOTPCode string `json:"totp_code,omitempty" example:"123456"`
+
 	// PassphraseHex is a hexadecimal representation of a passphrase (a SHA-512 checksum).
 	// Use 'echo $PASS | sha512sum' for example to get the hex format.
 	PassphraseHex string `json:"passphrase_hex" example:"fb43b35a752b0e8045e2dd1b1e292983b9cbf4672a51e30caaa3f9b06c5a3b74d5096bc8092c9e90a2e047c1eab29eceb50c09d6c51e6995c1674beb3b06535e" swaggerignore:"true"`
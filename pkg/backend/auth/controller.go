@@ -2,11 +2,16 @@ package auth
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"go.vxn.dev/littr/pkg/backend/common"
 	"go.vxn.dev/littr/pkg/models"
+
+	"github.com/go-chi/chi/v5"
 )
 
 type authController struct {
@@ -26,6 +31,9 @@ func NewAuthController(authService models.AuthServiceInterface) *authController
 const (
 	accessTokenName  string = "access-token"
 	refreshTokenName string = "refresh-token"
+	oidcStateName    string = "oidc-state"
+
+	providerParam string = "provider"
 
 	logLabel string = "authController"
 )
@@ -34,15 +42,19 @@ const (
 //
 //	@Summary		Auth an user
 //	@Description		This function call acts as a procedure to authenticate an user using their credentials (nickname and hashed passphrase). On success, the pair of HTTP cookies are sent with the API response (`refresh-token` and `access-token`).
+//	@Description
+//	@Description		After a few failed attempts, the next attempt has to wait for an exponentially growing delay. After ten failed attempts, the account is locked out for a while, and the user is notified by e-mail.
+//	@Description
+//	@Description		When the user has the two-factor authentication enabled, the first attempt without the `totp_code` field is responded with `totp_required` set to true. The credentials are then to be sent again together with the TOTP code (or one of the recovery codes).
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
 //	@Param			request		body		auth.AuthUser			true			"User's credentials to authenticate."
 //	@Success		200		{object}	common.APIResponse{data=auth.Auth.responseData}		"Authentication process successful, HTTP cookies sent in response."
 //	@Failure		400		{object}	common.APIResponse{data=auth.Logout.responseData}	"Invalid input data."
-//	@Failure		401		{object}	common.APIResponse{data=auth.Logout.responseData}	"User not authenticated, wrong passphrase used, or such account does not exist at all."
+//	@Failure		401		{object}	common.APIResponse{data=auth.Auth.responseData}	"User not authenticated, wrong passphrase or TOTP code used, the TOTP code required, or such account does not exist at all."
 //	@Failure		404		{object}	common.APIResponse{data=auth.Logout.responseData}	"User not found."
-//	@Failure		429		{object}	common.APIResponse{data=models.Stub}			"Too many requests, try again later."
+//	@Failure		429		{object}	common.APIResponse{data=models.Stub}			"Too many requests, or too many failed login attempts of such account (the account is locked out for a while), try again later."
 //	@Failure		500		{object}	common.APIResponse{data=auth.Logout.responseData}	"Internal server problem while processing the request."
 //	@Router			/auth [post]
 func (c *authController) Auth(w http.ResponseWriter, r *http.Request) {
@@ -50,8 +62,9 @@ func (c *authController) Auth(w http.ResponseWriter, r *http.Request) {
 
 	// Response body structure.
 	type responseData struct {
-		AuthGranted bool         `json:"auth_granted"`
-		User        *models.User `json:"user"`
+		AuthGranted  bool         `json:"auth_granted"`
+		TOTPRequired bool         `json:"totp_required"`
+		User         *models.User `json:"user"`
 	}
 
 	// Prepare the response payload.
@@ -68,36 +81,23 @@ func (c *authController) Auth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Describe the client for the sessions list.
+	user.UserAgent = r.UserAgent()
+	user.IPAddress = common.ClientIPAddress(r)
+
 	// Try to authenticate given user.
 	grantedUser, tokens, err := c.authService.Auth(r.Context(), &user)
 	if err != nil {
+		// The passphrase is valid, the second factor is to be sent with the next attempt.
+		pl.TOTPRequired = err.Error() == common.ERR_TOTP_REQUIRED
+
 		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(pl).Write(w)
 		return
 	}
 
 	pl.AuthGranted = true
 
-	// Compose the access HTTP cookie and set it.
-	http.SetCookie(w, &http.Cookie{
-		Name:     accessTokenName,
-		Value:    tokens[0],
-		Expires:  time.Now().Add(time.Minute * 15),
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   true,
-		SameSite: http.SameSiteDefaultMode,
-	})
-
-	// Compose the refresh HTTP cookie and set it.
-	http.SetCookie(w, &http.Cookie{
-		Name:     refreshTokenName,
-		Value:    tokens[1],
-		Expires:  time.Now().Add(common.TokenTTL),
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   true,
-		SameSite: http.SameSiteDefaultMode,
-	})
+	setSessionCookies(w, tokens)
 
 	// Flush the sensitive data of such user in the response.
 	patchedUser := (*common.FlushUserData(&map[string]models.User{grantedUser.Nickname: *grantedUser}, grantedUser.Nickname))[grantedUser.Nickname]
@@ -166,3 +166,138 @@ func (c *authController) Logout(w http.ResponseWriter, r *http.Request) {
 
 	l.Msg(msgSessionTerminated).Status(http.StatusOK).Log().Payload(pl).Write(w)
 }
+
+// OIDCLogin starts the single sign-on using such identity provider.
+//
+//	@Summary		Sign in using an identity provider
+//	@Description		This function call starts the OpenID Connect authorization code flow (with PKCE): the login state is bound to the browser using the `oidc-state` HTTP cookie, and the user is redirected to the identity provider.
+//	@Tags			auth
+//	@Produce		json
+//	@Param			provider	path		string					true	"Identity provider's name (see APP_OIDC_PROVIDERS)."
+//	@Success		302		{string}	string					"Redirected to the identity provider."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}	"Unknown identity provider."
+//	@Failure		429		{object}	common.APIResponse{data=models.Stub}	"Too many requests, try again later."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"Internal server problem while processing the request (e.g. the provider is not reachable)."
+//	@Router			/auth/oidc/{provider} [get]
+func (c *authController) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, logLabel)
+
+	provider := chi.URLParam(r, providerParam)
+
+	authURL, state, err := c.authService.OIDCBegin(r.Context(), provider)
+	if err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// The state cookie binds the login to this very browser, it has to be sent with the provider's redirect back (SameSite=Lax).
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateName,
+		Value:    state,
+		Expires:  time.Now().Add(time.Minute * 10),
+		Path:     "/api/v1/auth/oidc/" + provider,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	l.Msg("redirecting to the identity provider").Status(http.StatusFound).Log()
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallback finishes the single sign-on: the user returns from the identity provider.
+//
+//	@Summary		Finish the sign-in using an identity provider
+//	@Description		This function call is the identity provider's redirect target. The authorization code is exchanged for the ID token, the identity is mapped to the linked account (or the account is linked by the verified e-mail address, or a new account is created, if enabled for the provider). On success, the pair of HTTP cookies are sent (`refresh-token` and `access-token`), and the user is redirected to the flow.
+//	@Tags			auth
+//	@Produce		json
+//	@Param			provider	path		string					true	"Identity provider's name (see APP_OIDC_PROVIDERS)."
+//	@Param			code		query		string					true	"Authorization code issued by the provider."
+//	@Param			state		query		string					true	"Login state, it has to match the `oidc-state` HTTP cookie."
+//	@Success		302		{string}	string					"Authentication process successful, HTTP cookies sent, redirected to the flow."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid or expired login state."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}	"The sign-in at the identity provider failed, or such account has not been activated yet."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"No account is linked to such identity."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}	"Unknown identity provider."
+//	@Failure		429		{object}	common.APIResponse{data=models.Stub}	"Too many requests, try again later."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"Internal server problem while processing the request."
+//	@Router			/auth/oidc/{provider}/callback [get]
+func (c *authController) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, logLabel)
+
+	provider := chi.URLParam(r, providerParam)
+	query := r.URL.Query()
+
+	// The state cookie is single-use.
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateName,
+		Value:    "",
+		Expires:  time.Now().Add(time.Second * -300),
+		MaxAge:   -1,
+		Path:     "/api/v1/auth/oidc/" + provider,
+		HttpOnly: true,
+		Secure:   true,
+	})
+
+	// The user has denied the access, or the provider has failed.
+	if query.Get("error") != "" {
+		l.Msg(common.ERR_OIDC_LOGIN_FAILED).Status(http.StatusUnauthorized).Error(errors.New(query.Get("error"))).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// The login has to be finished in the browser it has been started in.
+	cookie, err := r.Cookie(oidcStateName)
+	if err != nil || cookie.Value == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(query.Get("state"))) != 1 {
+		l.Msg(common.ERR_OIDC_STATE_INVALID).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	grantedUser, tokens, err := c.authService.OIDCComplete(r.Context(), provider, query.Get("state"), query.Get("code"), r.UserAgent(), common.ClientIPAddress(r))
+	if err != nil {
+		status := common.DecideStatusFromError(err)
+
+		// The provider-related failures are wrapped.
+		if strings.HasPrefix(err.Error(), common.ERR_OIDC_LOGIN_FAILED) || errors.Is(err, errNotActivated) {
+			status = http.StatusUnauthorized
+		}
+
+		l.Msg(err.Error()).Status(status).Log().Payload(nil).Write(w)
+		return
+	}
+
+	setSessionCookies(w, tokens)
+
+	l.Msg("ok, auth granted to " + grantedUser.Nickname + ", sending cookies").Status(http.StatusFound).Log()
+
+	http.Redirect(w, r, "/flow", http.StatusFound)
+}
+
+//
+//  Helper functions
+//
+
+// setSessionCookies sets the access and the refresh HTTP cookies of the new session.
+func setSessionCookies(w http.ResponseWriter, tokens []string) {
+	// Compose the access HTTP cookie and set it.
+	http.SetCookie(w, &http.Cookie{
+		Name:     accessTokenName,
+		Value:    tokens[0],
+		Expires:  time.Now().Add(time.Minute * 15),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteDefaultMode,
+	})
+
+	// Compose the refresh HTTP cookie and set it.
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshTokenName,
+		Value:    tokens[1],
+		Expires:  time.Now().Add(common.TokenTTL),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteDefaultMode,
+	})
+}
@@ -3,24 +3,13 @@ package auth
 import (
 	"time"
 
-	"go.vxn.dev/littr/pkg/backend/db"
 	"go.vxn.dev/littr/pkg/models"
 )
 
-func noteUsersActivity(callerID string, cache db.Cacher) bool {
-	// check if caller exists
-	callerUser, found := cache.Load(callerID)
-	if !found {
-		return false
-	}
-
-	caller, ok := callerUser.(models.User)
-	if !ok {
-		return false
-	}
-
-	// update user's activity timestamp
-	caller.LastActiveTime = time.Now()
-
-	return cache.Store(callerID, caller)
+func noteUsersActivity(callerID string, userRepository models.UserRepositoryInterface) bool {
+	// Update the user's activity timestamp only, so the concurrent changes of the user are kept (an unknown caller fails the update).
+	return userRepository.Update(callerID, func(caller *models.User) error {
+		caller.LastActiveTime = time.Now()
+		return nil
+	}) == nil
 }
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/audit"
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/mail"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Brute-force protection
+//  The failed logins are counted per account (the counters are kept in the user's record). After a few failures, the next attempt has to wait
+//  for an exponentially growing delay, after more failures the account is locked out for a while and the user is notified by e-mail.
+//
+
+const (
+	// backoffThreshold is the count of the failed logins the delay starts after.
+	backoffThreshold = 3
+
+	// backoffBase is the delay after the backoffThreshold failures, it doubles with every other failure.
+	backoffBase = time.Second
+
+	// lockoutThreshold is the count of the failed logins the account is locked out after.
+	lockoutThreshold = 10
+
+	// lockoutDuration is the first lockout's duration, it doubles with every other failure (up to maxLockoutDuration).
+	lockoutDuration    = time.Minute * 15
+	maxLockoutDuration = time.Hour * 24
+
+	// failureWindow is the time the failed logins are forgotten after.
+	failureWindow = time.Hour * 24
+)
+
+// loginDelay returns the delay of the next attempt after such count of failed logins.
+func loginDelay(failures int) time.Duration {
+	switch {
+	case failures < backoffThreshold:
+		return 0
+
+	case failures < lockoutThreshold:
+		return backoffBase << (failures - backoffThreshold)
+
+	// Keep the shift small, the duration is capped anyway.
+	case failures-lockoutThreshold > 8:
+		return maxLockoutDuration
+	}
+
+	return min(lockoutDuration<<(failures-lockoutThreshold), maxLockoutDuration)
+}
+
+// loginBlockedUntil returns the time the user can try to log-in again.
+func loginBlockedUntil(user *models.User) time.Time {
+	return user.LastFailedLoginTime.Add(loginDelay(user.FailedLogins))
+}
+
+// countLoginAttempt counts the attempt as a failed one in advance (it is forgiven on success), so the parallel attempts cannot get around the
+// limit. An error is returned if the user has to wait.
+func (s *AuthService) countLoginAttempt(nickname string) error {
+	return s.userRepository.Update(nickname, func(user *models.User) error {
+		now := time.Now()
+
+		// Forget the old failures.
+		if now.Sub(user.LastFailedLoginTime) > failureWindow {
+			user.FailedLogins = 0
+		}
+
+		if now.Before(loginBlockedUntil(user)) {
+			return fmt.Errorf(common.ERR_AUTH_LOCKED)
+		}
+
+		user.FailedLogins++
+		user.LastFailedLoginTime = now
+
+		return nil
+	})
+}
+
+// forgiveLoginAttempt takes back the attempt counted, as it has not been a failed one.
+func (s *AuthService) forgiveLoginAttempt(nickname string) error {
+	return s.userRepository.Update(nickname, func(user *models.User) error {
+		if user.FailedLogins > 0 {
+			user.FailedLogins--
+		}
+
+		return nil
+	})
+}
+
+// noteFailedLogin locks the account out when the failed logins count has reached the threshold: the entry is logged, and the user is notified.
+func (s *AuthService) noteFailedLogin(ctx context.Context, nickname, ipAddress string) {
+	user, err := s.userRepository.GetByID(nickname)
+	if err != nil || user.FailedLogins < lockoutThreshold {
+		return
+	}
+
+	lockedUntil := loginBlockedUntil(user)
+
+	l := common.NewLogger(nil, "authLockout")
+
+	l.Msg(fmt.Sprintf("account %s locked out until %s after %d failed logins (the last one from %s)", nickname, lockedUntil.Format(time.RFC3339), user.FailedLogins, ipAddress)).Status(http.StatusTooManyRequests).Log()
+
+	audit.Record(ctx, models.AuditEvent{
+		Action:    audit.ActionLockout,
+		Actor:     common.DefaultCallerID,
+		Target:    nickname,
+		IPAddress: ipAddress,
+		Details: map[string]string{
+			"failed_logins": strconv.Itoa(user.FailedLogins),
+			"locked_until":  lockedUntil.Format(time.RFC3339),
+		},
+	})
+
+	// Nowhere to send the notification to.
+	if user.Email == "" || s.mailService == nil {
+		return
+	}
+
+	msg, err := s.mailService.ComposeMail(mail.MessagePayload{
+		Nickname:    user.Nickname,
+		Email:       user.Email,
+		Type:        "user_lockout",
+		IPAddress:   ipAddress,
+		LockedUntil: lockedUntil,
+	})
+	if err != nil || msg == nil {
+		l.Msg(common.ERR_MAIL_COMPOSITION_FAIL).Status(http.StatusInternalServerError).Error(err).Log()
+		return
+	}
+
+	if err := s.mailService.SendMail(msg); err != nil {
+		l.Msg("could not send the lockout notification").Status(http.StatusInternalServerError).Error(err).Log()
+	}
+}
+
+// recordFailedLogin records the failed login to the audit log, the actor being the user claimed.
+func recordFailedLogin(ctx context.Context, nickname, ipAddress, reason string) {
+	audit.Record(ctx, models.AuditEvent{
+		Action:    audit.ActionLoginFailed,
+		Actor:     nickname,
+		Target:    nickname,
+		IPAddress: ipAddress,
+		Details:   map[string]string{"reason": reason},
+	})
+}
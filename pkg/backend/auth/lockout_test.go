@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/audit"
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/backend/passphrase"
+	"go.vxn.dev/littr/pkg/backend/tokens"
+	"go.vxn.dev/littr/pkg/backend/users"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+func TestAuth_LoginDelay(t *testing.T) {
+	cases := map[int]time.Duration{
+		0:   0,
+		2:   0,
+		3:   time.Second,
+		5:   time.Second * 4,
+		9:   time.Second * 64,
+		10:  time.Minute * 15,
+		11:  time.Minute * 30,
+		20:  time.Hour * 24,
+		500: time.Hour * 24,
+	}
+
+	for failures, delay := range cases {
+		if got := loginDelay(failures); got != delay {
+			t.Errorf("%d failures: expected %s, got %s", failures, delay, got)
+		}
+	}
+}
+
+func TestAuth_Lockout(t *testing.T) {
+	t.Setenv("APP_PEPPER", "test-secret")
+
+	auditLog, err := audit.Open(filepath.Join(t.TempDir(), "audit.jsonl"), 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	audit.SetDefault(auditLog)
+	t.Cleanup(func() { audit.Close() })
+
+	userRepository := users.NewUserRepository(db.NewSimpleCache("UserCache"))
+	service := NewAuthService(&common.MockMailService{}, nil, tokens.NewTokenRepository(db.NewSimpleCache("TokenCache")), userRepository)
+
+	hash, err := passphrase.Hash("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := userRepository.Save(&models.User{Nickname: "alice", Email: "alice@example.com", PassphraseHex: hash, Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	attempt := func(plain string) error {
+		_, _, err := service.Auth(context.Background(), &AuthUser{Nickname: "alice", PassphrasePlain: plain, IPAddress: "192.0.2.1"})
+		return err
+	}
+
+	// The failures before the backoff threshold do not delay the next attempt.
+	for i := 0; i < backoffThreshold; i++ {
+		if err := attempt("wrong"); err != errAuthFailed {
+			t.Fatalf("attempt %d: %v", i, err)
+		}
+	}
+
+	// Even the right passphrase has to wait now.
+	if err := attempt("s3cret"); err == nil || err.Error() != common.ERR_AUTH_LOCKED {
+		t.Fatalf("attempt not delayed: %v", err)
+	}
+
+	// Pretend the delay has passed: the successful login resets the counter.
+	if err := userRepository.Update("alice", func(user *models.User) error {
+		user.LastFailedLoginTime = time.Now().Add(-time.Minute)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := attempt("s3cret"); err != nil {
+		t.Fatal(err)
+	}
+
+	if user, _ := userRepository.GetByID("alice"); user.FailedLogins != 0 {
+		t.Errorf("failed logins not reset: %d", user.FailedLogins)
+	}
+
+	// Reach the lockout.
+	if err := userRepository.Update("alice", func(user *models.User) error {
+		user.FailedLogins = lockoutThreshold - 1
+		user.LastFailedLoginTime = time.Now().Add(-time.Hour)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := attempt("wrong"); err != errAuthFailed {
+		t.Fatal(err)
+	}
+
+	user, _ := userRepository.GetByID("alice")
+	if until := loginBlockedUntil(user); time.Until(until) < lockoutDuration-time.Minute {
+		t.Errorf("account not locked out: %s", until)
+	}
+
+	if err := attempt("s3cret"); err == nil || err.Error() != common.ERR_AUTH_LOCKED {
+		t.Errorf("locked account logged in: %v", err)
+	}
+
+	// The lockout has been recorded to the audit log.
+	events, err := audit.Query(&models.AuditFilter{Action: audit.ActionLockout, Target: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 1 || events[0].IPAddress != "192.0.2.1" {
+		t.Errorf("lockout not audited: %+v", events)
+	}
+
+	// The old failures are forgotten.
+	if err := userRepository.Update("alice", func(user *models.User) error {
+		user.LastFailedLoginTime = time.Now().Add(-failureWindow - time.Minute)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := attempt("s3cret"); err != nil {
+		t.Errorf("old failures not forgotten: %v", err)
+	}
+}
+
+func TestAuth_LockoutNotActivated(t *testing.T) {
+	t.Setenv("APP_PEPPER", "test-secret")
+
+	userRepository := users.NewUserRepository(db.NewSimpleCache("UserCache"))
+	service := NewAuthService(&common.MockMailService{}, nil, tokens.NewTokenRepository(db.NewSimpleCache("TokenCache")), userRepository)
+
+	hash, err := passphrase.Hash("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := userRepository.Save(&models.User{Nickname: "bob", PassphraseHex: hash}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The retries with the right passphrase before the activation are not the failed logins.
+	for i := 0; i < lockoutThreshold+1; i++ {
+		if _, _, err := service.Auth(context.Background(), &AuthUser{Nickname: "bob", PassphrasePlain: "s3cret"}); err != errNotActivated {
+			t.Fatalf("attempt %d: %v", i, err)
+		}
+	}
+
+	if user, _ := userRepository.GetByID("bob"); user.FailedLogins != 0 {
+		t.Errorf("right passphrase counted as failed: %d", user.FailedLogins)
+	}
+}
@@ -6,10 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"go.vxn.dev/littr/pkg/backend/apitokens"
 	"go.vxn.dev/littr/pkg/backend/common"
-	"go.vxn.dev/littr/pkg/backend/db"
 	"go.vxn.dev/littr/pkg/backend/tokens"
 	"go.vxn.dev/littr/pkg/config"
 	"go.vxn.dev/littr/pkg/helpers"
@@ -20,12 +21,17 @@ import (
 
 var (
 	ErrAccessTokenGenerationFailed = errors.New("the access toked could not be generated")
+	ErrAPITokenExpired             = errors.New("such API token has expired")
+	ErrAPITokenInvalid             = errors.New("invalid API token received")
+	ErrAPITokenScope               = errors.New("such API token has not been granted the scope for this route")
 	ErrCacheLoadFailed             = errors.New("could not load such token from database")
 	ErrCacheDeleteFailed           = errors.New("could not delete such token from database")
 	ErrNoServerSecret              = errors.New("server secret (APP_PEPPER) not set")
 	ErrTokenReferenceNotFound      = errors.New("reference to such refresh token could not be found")
 	ErrTokenInvalidated            = errors.New("such refresh token has been invalidated, redo the auth process")
 	ErrTokenInvalid                = errors.New("invalid token received")
+	ErrTokenReused                 = errors.New("such refresh token has been used already, the session has been revoked")
+	ErrTokenRotationFailed         = errors.New("the refresh token could not be rotated")
 	ErrUserNotFound                = errors.New("referenced user could not be found")
 )
 
@@ -36,7 +42,6 @@ var PathExceptions = []string{
 	"/api/v1",
 	"/api/v1/auth",
 	"/api/v1/auth/logout",
-	"/api/v1/dump",
 	"/api/v1/live",
 	"/api/v1/health",
 	"/api/v1/users/activation",
@@ -44,6 +49,12 @@ var PathExceptions = []string{
 	"/api/v1/users/passphrase/reset",
 }
 
+// PathPrefixExceptions are the URL path prefixes to be skipped by the authentication middleware.
+var PathPrefixExceptions = []string{
+	// The single sign-on routes (the login and the provider's callback).
+	"/api/v1/auth/oidc/",
+}
+
 type responseData struct {
 	AuthGranted bool                   `json:"auth_granted"`
 	Users       map[string]models.User `json:"users"`
@@ -53,14 +64,14 @@ var payload *responseData
 
 const loggerWorkerName string = "authMiddleware"
 
-// The very authentication middleware entrypoint.
-func AuthMiddleware(d db.DatabaseKeeper) func(http.Handler) http.Handler {
+// The very authentication middleware entrypoint. The repositories are used to look up the API tokens, refresh tokens, and their users.
+func AuthMiddleware(apiTokenRepository models.APITokenRepositoryInterface, tokenRepository models.TokenRepositoryInterface, userRepository models.UserRepositoryInterface) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// ctx := r.Context()
 
 			// Skip those HTTP routes.
-			if helpers.Contains(PathExceptions, r.URL.Path) ||
+			if helpers.Contains(PathExceptions, r.URL.Path) || hasPathPrefixException(r.URL.Path) || isSignedDumpRequest(r) ||
 				(r.URL.Path == "/api/v1/users" && r.Method == http.MethodPost) {
 				next.ServeHTTP(w, r)
 				return
@@ -74,6 +85,22 @@ func AuthMiddleware(d db.DatabaseKeeper) func(http.Handler) http.Handler {
 				AuthGranted: false,
 			}
 
+			// The personal API tokens are sent in the Authorization header instead of the HTTP cookies.
+			if plain := apitokens.FromRequest(r); plain != "" {
+				nickname, ok := authenticateAPIToken(l, plain, r, w, apiTokenRepository, userRepository)
+				if !ok {
+					return
+				}
+
+				// Set the HTTP context with the token owner's nickname. No session's ID is set, there is no refresh token involved.
+				ctx := context.WithValue(r.Context(), common.ContextUserKeyName, nickname)
+				r = r.WithContext(ctx)
+
+				// Continue with the HTTP request's propragation.
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Fetch the server's secret.
 			secret := config.ServerSecret
 			if secret == "" {
@@ -113,23 +140,27 @@ func AuthMiddleware(d db.DatabaseKeeper) func(http.Handler) http.Handler {
 				userClaims = tokens.ParseAccessToken(accessCookie.Value, secret)
 			}
 
-			caches := d.Database()
-
 			// Access cookie is expired (not present), or userClaims can be decoded but the token is invalid (expired).
 			// Generate a new access token.
 			if err != nil || (userClaims != nil && userClaims.Valid() != nil) {
 				// Fetch the request token's database record
-				refToken, err := fetchRefreshTokenRecord(refreshCookie, &w, caches["TokenCache"])
+				refToken, err := fetchRefreshTokenRecord(refreshCookie, &w, tokenRepository)
 				if refToken == nil && err != nil {
 					// Token has been invalidated due to its non-existence or invalidity.
 					l.Error(err).Status(http.StatusUnauthorized).Log().Payload(payload).Write(w)
 					return
 				}
 
-				userI, ok := caches["UserCache"].Load(refToken.Nickname)
-				if !ok {
+				// A rotated refresh token has been presented again = revoke the whole token family.
+				if errors.Is(err, ErrTokenReused) || tokens.IsReused(refToken) {
+					revokeReusedToken(l, refToken, &w, tokenRepository)
+					return
+				}
+
+				user, err := userRepository.GetByID(refToken.Nickname)
+				if err != nil {
 					// Delete such token record form the Token database.
-					if ok := caches["TokenCache"].Delete(refToken.Hash); !ok {
+					if err := tokenRepository.Delete(refToken.Hash); err != nil {
 						l.Error(ErrCacheDeleteFailed).Status(http.StatusInternalServerError).Log().Write(w)
 						return
 					}
@@ -140,12 +171,6 @@ func AuthMiddleware(d db.DatabaseKeeper) func(http.Handler) http.Handler {
 					return
 				}
 
-				user, ok := userI.(models.User)
-				if !ok {
-					l.Error(ErrCacheLoadFailed).Status(http.StatusInternalServerError).Log().Write(w)
-					return
-				}
-
 				// Prepare new access token claims.
 				userClaims := tokens.UserClaims{
 					Nickname: refToken.Nickname,
@@ -180,17 +205,45 @@ func AuthMiddleware(d db.DatabaseKeeper) func(http.Handler) http.Handler {
 				// Set the access HTTP cookie to response headers.
 				http.SetCookie(w, accessCookie)
 
+				// Rotate the refresh token. The rotated token within the grace period (a concurrent request of the same client) is not rotated again.
+				if !refToken.IsRotated() {
+					refreshToken, newToken, err := tokens.RotateToken(refToken, tokenRepository, r.UserAgent(), common.ClientIPAddress(r))
+					switch {
+					case errors.Is(err, tokens.ErrTokenRotated):
+						// Rotated by a concurrent request meanwhile, its response carries the new refresh token.
+
+					case err != nil:
+						l.Msg(ErrTokenRotationFailed.Error()).Error(err).Status(http.StatusInternalServerError).Log().Payload(payload).Write(w)
+						return
+
+					default:
+						// Compose the new refresh HTTP cookie and set it.
+						http.SetCookie(w, &http.Cookie{
+							Name:     refreshTokenName,
+							Value:    refreshToken,
+							Expires:  newToken.CreatedAt.Add(newToken.TTL),
+							Path:     "/",
+							HttpOnly: true,
+							Secure:   true,
+							SameSite: http.SameSiteDefaultMode,
+						})
+
+						refToken = newToken
+					}
+				}
+
 				// Add the auth-granted user to the response payload.
 				payload.Users = make(map[string]models.User)
-				payload.Users[refToken.Nickname] = user
+				payload.Users[refToken.Nickname] = *user
 
 				l.Msg(MsgAccessTokenGenerated).Status(http.StatusOK).Log()
 
-				// Set the HTTP context value for such user's nickname.
+				// Set the HTTP context value for such user's nickname, and the session's ID.
 				ctx := context.WithValue(r.Context(), common.ContextUserKeyName, refToken.Nickname)
+				ctx = context.WithValue(ctx, common.ContextTokenKeyName, refToken.Hash)
 
 				// Register user's activity = refresh the LastActiveTime datetime field.
-				noteUsersActivity(refToken.Nickname, caches["UserCache"])
+				noteUsersActivity(refToken.Nickname, userRepository)
 				r = r.WithContext(ctx)
 
 				// Continue with the HTTP request's propragation.
@@ -203,18 +256,25 @@ func AuthMiddleware(d db.DatabaseKeeper) func(http.Handler) http.Handler {
 			//
 
 			// Fetch the request token's database record
-			refToken, err := fetchRefreshTokenRecord(refreshCookie, &w, caches["TokenCache"])
+			refToken, err := fetchRefreshTokenRecord(refreshCookie, &w, tokenRepository)
 			if refToken == nil && err != nil {
 				// Token has been invalidated due to its non-existence or invalidity.
 				l.Error(err).Status(http.StatusUnauthorized).Log().Payload(payload).Write(w)
 				return
 			}
 
-			// Set the HTTP context with the auth-granted user's nickname.
+			// A rotated refresh token has been presented again = revoke the whole token family.
+			if errors.Is(err, ErrTokenReused) || tokens.IsReused(refToken) {
+				revokeReusedToken(l, refToken, &w, tokenRepository)
+				return
+			}
+
+			// Set the HTTP context with the auth-granted user's nickname, and the session's ID.
 			ctx := context.WithValue(r.Context(), common.ContextUserKeyName, refToken.Nickname)
+			ctx = context.WithValue(ctx, common.ContextTokenKeyName, refToken.Hash)
 
 			// Register the user's activity = refresh the LastTimeActive datetime field.
-			noteUsersActivity(refToken.Nickname, caches["UserCache"])
+			noteUsersActivity(refToken.Nickname, userRepository)
 			r = r.WithContext(ctx)
 
 			// Continue with the HTTP request's propragation.
@@ -227,6 +287,23 @@ func AuthMiddleware(d db.DatabaseKeeper) func(http.Handler) http.Handler {
 //  Helper functions
 //
 
+// hasPathPrefixException reports whether such URL path starts with one of the PathPrefixExceptions.
+func hasPathPrefixException(path string) bool {
+	for _, prefix := range PathPrefixExceptions {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSignedDumpRequest reports whether such request is a data-intervention one signed using the shared secret. The signature is verified by the
+// dump router (see db.RequireDumpAuth), the unsigned requests have to be authenticated as usual.
+func isSignedDumpRequest(r *http.Request) bool {
+	return (r.URL.Path == "/api/v1/dump" || strings.HasPrefix(r.URL.Path, "/api/v1/dump/")) && r.Header.Get(common.HDR_DUMP_SIGNATURE) != ""
+}
+
 // invalidateRefreshToken is a helper function to invalidate the refresh HTTP cookie (token). The function sends the invalidated HTTP cookie in the response headers.
 func invalidateRefreshToken(l common.Logger, w *http.ResponseWriter) bool {
 	// Refresh token is invalid = not found in the Token database => user unauthenticated, invalidate the refresh token.
@@ -250,24 +327,81 @@ func invalidateRefreshToken(l common.Logger, w *http.ResponseWriter) bool {
 	return true
 }
 
-// fetchRefreshTokenRecord is a helper function to check the refresh token is valid and exists in the database.
-func fetchRefreshTokenRecord(refreshCookie *http.Cookie, w *http.ResponseWriter, cache db.Cacher) (*models.Token, error) {
+// revokeReusedToken is a helper function to handle the reuse of a rotated refresh token: the token (and any other token of its family) could
+// have been stolen, so the whole family is revoked, and the security event is logged.
+func revokeReusedToken(l common.Logger, token *models.Token, w *http.ResponseWriter, tokenRepository models.TokenRepositoryInterface) {
+	if err := tokens.RevokeFamily(token, tokenRepository); err != nil {
+		l.Error(ErrCacheDeleteFailed, err).Status(http.StatusInternalServerError).Log().Payload(payload).Write(*w)
+		return
+	}
+
+	invalidateRefreshToken(nil, w)
+
+	l.SetPrefix("security").Msg(fmt.Sprintf("refresh token reuse detected, revoked the token family %s of user %s", token.Family(), token.Nickname)).Status(http.StatusUnauthorized).Log()
+	l.RemovePrefix().Msg(ErrTokenReused.Error()).Payload(payload).Write(*w)
+}
+
+// authenticateAPIToken is a helper function to check the personal API token exists, is valid, and is granted the scope required by the request.
+// Returns the token owner's nickname, the error response is written otherwise.
+func authenticateAPIToken(l common.Logger, plain string, r *http.Request, w http.ResponseWriter, apiTokenRepository models.APITokenRepositoryInterface, userRepository models.UserRepositoryInterface) (string, bool) {
+	if apiTokenRepository == nil || userRepository == nil {
+		l.Error(ErrAPITokenInvalid).Status(http.StatusUnauthorized).Log().Payload(payload).Write(w)
+		return "", false
+	}
+
+	token, err := apiTokenRepository.GetByID(apitokens.Hash(plain))
+	if err != nil {
+		l.Error(ErrAPITokenInvalid).Status(http.StatusUnauthorized).Log().Payload(payload).Write(w)
+		return "", false
+	}
+
+	if token.IsExpired() {
+		l.Error(ErrAPITokenExpired).Status(http.StatusUnauthorized).Log().Payload(payload).Write(w)
+		return "", false
+	}
+
+	scope, ok := apitokens.RequiredScope(r)
+	if !ok || !token.HasScope(scope) {
+		l.Error(ErrAPITokenScope).Status(http.StatusForbidden).Log().Payload(payload).Write(w)
+		return "", false
+	}
+
+	// The tokens of the deactivated (or deleted) users are refused.
+	user, err := userRepository.GetByID(token.Nickname)
+	if err != nil || !user.Active {
+		l.Error(ErrUserNotFound).Status(http.StatusUnauthorized).Log().Payload(payload).Write(w)
+		return "", false
+	}
+
+	// Note the token's usage, once a minute at most, so a busy bot does not write to the database on every request.
+	if time.Since(token.LastUsedAt) > time.Minute {
+		_ = apiTokenRepository.Update(token.Hash, func(token *models.APIToken) error {
+			token.LastUsedAt = time.Now()
+			return nil
+		})
+	}
+
+	return token.Nickname, true
+}
+
+// fetchRefreshTokenRecord is a helper function to check the refresh token is valid and exists in the database. The live token of the family is
+// returned together with ErrTokenReused when the token has been rotated and pruned already.
+func fetchRefreshTokenRecord(refreshCookie *http.Cookie, w *http.ResponseWriter, tokenRepository models.TokenRepositoryInterface) (*models.Token, error) {
 	// Get the refresh token's fingerprint.
 	refreshSum := sha256.New()
 	refreshSum.Write([]byte(refreshCookie.Value))
 	refreshTokenSum := fmt.Sprintf("%x", refreshSum.Sum(nil))
 
-	tokenI, ok := cache.Load(refreshTokenSum)
-	if !ok {
-		invalidateRefreshToken(nil, w)
-		return nil, ErrTokenReferenceNotFound
-	}
+	token, err := tokenRepository.GetByID(refreshTokenSum)
+	if err != nil {
+		// The record of a rotated token is pruned after the grace period, its reuse is recognized by the family's live token.
+		if family := tokens.FindRotated(refreshCookie.Value, refreshTokenSum, tokenRepository); family != nil {
+			return family, ErrTokenReused
+		}
 
-	token, ok := tokenI.(models.Token)
-	if !ok {
 		invalidateRefreshToken(nil, w)
-		return nil, ErrCacheLoadFailed
+		return nil, ErrTokenReferenceNotFound
 	}
 
-	return &token, nil
+	return token, nil
 }
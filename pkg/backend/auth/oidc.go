@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/audit"
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/oidc"
+	"go.vxn.dev/littr/pkg/backend/tokens"
+	"go.vxn.dev/littr/pkg/config"
+	"go.vxn.dev/littr/pkg/helpers"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Single sign-on
+//  The provider's user is mapped to the account linked to its subject, then to the account of the same verified e-mail address (if enabled
+//  for the provider), or a new account is created (if enabled for the provider).
+//
+
+// oidcProvider binds the provider's settings to its client.
+type oidcProvider struct {
+	config config.OIDCProvider
+	client *oidc.Provider
+}
+
+func newOIDCProviders(providers map[string]config.OIDCProvider) map[string]*oidcProvider {
+	clients := make(map[string]*oidcProvider)
+
+	for name, provider := range providers {
+		clients[name] = &oidcProvider{
+			config: provider,
+			client: oidc.NewProvider(oidc.Config{
+				Issuer:       provider.Issuer,
+				ClientID:     provider.ClientID,
+				ClientSecret: provider.ClientSecret,
+				RedirectURL:  provider.RedirectURL,
+			}, nil),
+		}
+	}
+
+	return clients
+}
+
+func (s *AuthService) OIDCBegin(ctx context.Context, providerName string) (string, string, error) {
+	provider, found := s.oidcProviders[providerName]
+	if !found {
+		return "", "", fmt.Errorf(common.ERR_OIDC_PROVIDER_UNKNOWN)
+	}
+
+	return provider.client.Begin()
+}
+
+func (s *AuthService) OIDCComplete(ctx context.Context, providerName, state, code, userAgent, ipAddress string) (*models.User, []string, error) {
+	provider, found := s.oidcProviders[providerName]
+	if !found {
+		return nil, nil, fmt.Errorf(common.ERR_OIDC_PROVIDER_UNKNOWN)
+	}
+
+	identity, err := provider.client.Complete(state, code)
+	if errors.Is(err, oidc.ErrStateInvalid) {
+		return nil, nil, fmt.Errorf(common.ERR_OIDC_STATE_INVALID)
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", common.ERR_OIDC_LOGIN_FAILED, err)
+	}
+
+	dbUser, err := s.mapIdentity(ctx, provider.config, identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check if the user has been activated yet.
+	if !dbUser.Active {
+		return nil, nil, errNotActivated
+	}
+
+	if err := s.userRepository.Update(dbUser.Nickname, func(user *models.User) error {
+		user.LastLoginTime = time.Now()
+
+		*dbUser = *user
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	//
+	//  OK, user authorized, now generete tokens
+	//
+
+	tokens, err := tokens.NewToken(dbUser, s.tokenRepository, userAgent, ipAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	audit.Record(ctx, models.AuditEvent{
+		Action:    audit.ActionLogin,
+		Actor:     dbUser.Nickname,
+		Target:    dbUser.Nickname,
+		IPAddress: ipAddress,
+		Details:   map[string]string{"method": "oidc", "provider": providerName},
+	})
+
+	return dbUser, tokens, nil
+}
+
+//
+//  Helper functions
+//
+
+// mapIdentity returns the account of the provider's user: the linked one, the one linked now by the e-mail address, or a new one.
+func (s *AuthService) mapIdentity(ctx context.Context, provider config.OIDCProvider, identity *oidc.Identity) (*models.User, error) {
+	users, err := s.userRepository.GetAll()
+	if err != nil && err.Error() != common.ERR_NO_ITEMS_FOUND {
+		return nil, err
+	}
+
+	// No users in the database yet.
+	if users == nil {
+		users = &map[string]models.User{}
+	}
+
+	email := strings.ToLower(identity.Email)
+
+	var emailUser *models.User
+
+	for _, user := range *users {
+		if user.ExternalIDs[provider.Name] == identity.Subject {
+			return &user, nil
+		}
+
+		if email != "" && strings.ToLower(user.Email) == email {
+			emailUser = user.Copy()
+		}
+	}
+
+	// The unverified addresses are not trusted, anyone could claim them at the provider.
+	if emailUser != nil && provider.LinkByEmail && identity.EmailVerified {
+		if err := s.userRepository.Update(emailUser.Nickname, func(user *models.User) error {
+			// The account is linked to another user of the provider already.
+			if user.ExternalIDs[provider.Name] != "" {
+				return fmt.Errorf(common.ERR_OIDC_USER_NOT_LINKED)
+			}
+
+			if user.ExternalIDs == nil {
+				user.ExternalIDs = make(map[string]string)
+			}
+
+			user.ExternalIDs[provider.Name] = identity.Subject
+
+			*emailUser = *user
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+
+		audit.Record(ctx, models.AuditEvent{
+			Action:  audit.ActionOIDCLink,
+			Actor:   emailUser.Nickname,
+			Target:  emailUser.Nickname,
+			Details: map[string]string{"provider": provider.Name},
+		})
+
+		return emailUser, nil
+	}
+
+	if !provider.AutoProvision || !config.IsRegistrationEnabled {
+		return nil, fmt.Errorf(common.ERR_OIDC_USER_NOT_LINKED)
+	}
+
+	// The address belongs to another account not linked.
+	if emailUser != nil {
+		return nil, fmt.Errorf(common.ERR_EMAIL_ALREADY_USED)
+	}
+
+	return s.provisionUser(ctx, provider, identity, users)
+}
+
+// provisionUser creates a new account for the provider's user. The account has got no passphrase, so the user signs in using the provider
+// only (until the passphrase is reset).
+func (s *AuthService) provisionUser(ctx context.Context, provider config.OIDCProvider, identity *oidc.Identity, users *map[string]models.User) (*models.User, error) {
+	user := new(models.User)
+
+	user.Nickname = provisionNickname(identity, users)
+	user.ExternalIDs = map[string]string{provider.Name: identity.Subject}
+
+	// Keep the verified address only, so it can be used to reset the passphrase.
+	if _, err := netmail.ParseAddress(identity.Email); err == nil && identity.EmailVerified {
+		user.Email = strings.ToLower(identity.Email)
+	}
+
+	user.FullName = identity.Name
+
+	user.FlowList = make(models.UserGenericMap)
+	user.FlowList[user.Nickname] = true
+	user.FlowList["system"] = true
+
+	// Set the defaults and a timestamp.
+	user.RegisteredTime = time.Now()
+	user.LastActiveTime = time.Now()
+	user.About = "newbie"
+
+	// Set the default avatar.
+	user.AvatarURL = func() string {
+		if os.Getenv("APP_URL_MAIN") != "" {
+			return "https://" + os.Getenv("APP_URL_MAIN") + "/web/apple-touch-icon.png"
+		}
+
+		return "https://www.littr.eu/web/apple-touch-icon.png"
+	}()
+
+	// The provider has verified the user already, so the account is active right away.
+	user.Options = map[string]bool{
+		"active":        true,
+		"gdpr":          true,
+		"private":       false,
+		"uiDarkMode":    true,
+		"liveMode":      true,
+		"localTimeMode": true,
+	}
+
+	// Deprecated option setting method.
+	user.GDPR = true
+	user.Active = true
+
+	if err := s.userRepository.Save(user); err != nil {
+		return nil, fmt.Errorf(common.ERR_USER_SAVE_FAIL)
+	}
+
+	audit.Record(ctx, models.AuditEvent{
+		Action:  audit.ActionUserCreate,
+		Actor:   user.Nickname,
+		Target:  user.Nickname,
+		Details: map[string]string{"provider": provider.Name},
+	})
+
+	return user, nil
+}
+
+// provisionNickname derives a free nickname (3-12 alphanumeric characters) from the preferred username or the e-mail address.
+func provisionNickname(identity *oidc.Identity, users *map[string]models.User) string {
+	base := identity.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	base = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, base)
+
+	if len(base) < 3 {
+		base = "user"
+	}
+
+	if len(base) > 12 {
+		base = base[:12]
+	}
+
+	taken := func(nickname string) bool {
+		// The system posts' author is not among the users, but reserved too.
+		if strings.EqualFold(nickname, common.DefaultCallerID) || helpers.Contains(config.UserDeletionList, strings.ToLower(nickname)) {
+			return true
+		}
+
+		for key := range *users {
+			if strings.EqualFold(key, nickname) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if !taken(base) {
+		return base
+	}
+
+	// Append a number to the (shortened) base.
+	if len(base) > 8 {
+		base = base[:8]
+	}
+
+	for i := 2; ; i++ {
+		if nickname := base + strconv.Itoa(i); !taken(nickname) {
+			return nickname
+		}
+	}
+}
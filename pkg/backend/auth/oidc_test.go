@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/backend/oidc"
+	"go.vxn.dev/littr/pkg/backend/oidc/oidctest"
+	"go.vxn.dev/littr/pkg/backend/tokens"
+	"go.vxn.dev/littr/pkg/backend/users"
+	"go.vxn.dev/littr/pkg/config"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+const testRedirectURL = "https://littr.example.com/api/v1/auth/oidc/corp/callback"
+
+func newTestOIDCService(t *testing.T, idp *oidctest.Server, linkByEmail, autoProvision bool) (*AuthService, models.UserRepositoryInterface) {
+	t.Setenv("APP_PEPPER", "test-secret")
+
+	idpConfig := idp.Config(testRedirectURL)
+	userRepository := users.NewUserRepository(db.NewSimpleCache("UserCache"))
+
+	service := NewAuthService(&common.MockMailService{}, map[string]config.OIDCProvider{
+		"corp": {
+			Name:          "corp",
+			Issuer:        idpConfig.Issuer,
+			ClientID:      idpConfig.ClientID,
+			ClientSecret:  idpConfig.ClientSecret,
+			RedirectURL:   idpConfig.RedirectURL,
+			LinkByEmail:   linkByEmail,
+			AutoProvision: autoProvision,
+		},
+	}, tokens.NewTokenRepository(db.NewSimpleCache("TokenCache")), userRepository)
+
+	return service.(*AuthService), userRepository
+}
+
+// login runs the whole flow at the stub provider.
+func login(t *testing.T, service *AuthService, idp *oidctest.Server) (*models.User, []string, error) {
+	authURL, _, err := service.OIDCBegin(context.Background(), "corp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, state, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return service.OIDCComplete(context.Background(), "corp", state, code, "agent", "192.0.2.1")
+}
+
+func TestAuth_OIDCProvision(t *testing.T) {
+	idp, err := oidctest.NewServer("littr", "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idp.Close()
+
+	service, userRepository := newTestOIDCService(t, idp, true, true)
+
+	// The nickname is taken already.
+	if err := userRepository.Save(&models.User{Nickname: "alice", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	idp.SetUser(oidctest.User{Subject: "1234", Email: "Alice@Example.com", EmailVerified: true, PreferredUsername: "alice.w"})
+
+	user, tokens, err := login(t, service, idp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Nickname != "alicew" || user.Email != "alice@example.com" || !user.Active || user.ExternalIDs["corp"] != "1234" || len(tokens) != 2 {
+		t.Errorf("unexpected provisioned user: %+v", user)
+	}
+
+	// The next login maps to the same account.
+	again, _, err := login(t, service, idp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again.Nickname != user.Nickname {
+		t.Errorf("another account used: %s", again.Nickname)
+	}
+
+	if all, _ := userRepository.GetAll(); len(*all) != 2 {
+		t.Errorf("unexpected count of users: %d", len(*all))
+	}
+}
+
+func TestAuth_OIDCProvisionReserved(t *testing.T) {
+	users := map[string]models.User{}
+
+	// The reserved nicknames, and the system posts' author, are never provisioned.
+	for username, expected := range map[string]string{
+		"system": "system2",
+		"SYSTEM": "SYSTEM2",
+		"admin":  "admin2",
+		"bob":    "bob",
+	} {
+		if nickname := provisionNickname(&oidc.Identity{PreferredUsername: username}, &users); nickname != expected {
+			t.Errorf("%s: expected %s, got %s", username, expected, nickname)
+		}
+	}
+}
+
+func TestAuth_OIDCLinkByEmail(t *testing.T) {
+	idp, err := oidctest.NewServer("littr", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idp.Close()
+
+	service, userRepository := newTestOIDCService(t, idp, true, false)
+
+	if err := userRepository.Save(&models.User{Nickname: "bob", Email: "bob@example.com", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The unverified address is not linked.
+	idp.SetUser(oidctest.User{Subject: "5678", Email: "bob@example.com", EmailVerified: false})
+
+	if _, _, err := login(t, service, idp); err == nil || err.Error() != common.ERR_OIDC_USER_NOT_LINKED {
+		t.Errorf("unverified address linked: %v", err)
+	}
+
+	idp.SetUser(oidctest.User{Subject: "5678", Email: "bob@example.com", EmailVerified: true})
+
+	user, _, err := login(t, service, idp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Nickname != "bob" {
+		t.Errorf("unexpected user: %s", user.Nickname)
+	}
+
+	if dbUser, _ := userRepository.GetByID("bob"); dbUser.ExternalIDs["corp"] != "5678" {
+		t.Errorf("account not linked: %v", dbUser.ExternalIDs)
+	}
+
+	// Another subject of the same address cannot take over the linked account.
+	idp.SetUser(oidctest.User{Subject: "9999", Email: "bob@example.com", EmailVerified: true})
+
+	if _, _, err := login(t, service, idp); err == nil || err.Error() != common.ERR_OIDC_USER_NOT_LINKED {
+		t.Errorf("linked account taken over: %v", err)
+	}
+}
+
+func TestAuth_OIDCController(t *testing.T) {
+	idp, err := oidctest.NewServer("littr", "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idp.Close()
+
+	service, _ := newTestOIDCService(t, idp, false, true)
+	router := NewAuthRouter(NewAuthController(service))
+
+	idp.SetUser(oidctest.User{Subject: "1234", PreferredUsername: "carol"})
+
+	// Unknown provider.
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oidc/unknown", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unexpected status: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oidc/corp", nil))
+
+	if w.Code != http.StatusFound {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	stateCookie := w.Result().Cookies()[0]
+
+	code, state, err := idp.Authorize(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callback := "/oidc/corp/callback?" + url.Values{"code": {code}, "state": {state}}.Encode()
+
+	// The state cookie is missing (another browser).
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, callback, nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("login finished without the state cookie: %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, callback, nil)
+	req.AddCookie(stateCookie)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/flow" {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+
+	names := map[string]bool{}
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Value != "" {
+			names[cookie.Name] = true
+		}
+	}
+
+	if !names[accessTokenName] || !names[refreshTokenName] {
+		t.Errorf("session cookies not set: %v", names)
+	}
+}
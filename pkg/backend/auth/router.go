@@ -10,5 +10,8 @@ func NewAuthRouter(authController *authController) chi.Router {
 	r.Post("/", authController.Auth)
 	r.Post("/logout", authController.Logout)
 
+	r.Get("/oidc/{provider}", authController.OIDCLogin)
+	r.Get("/oidc/{provider}/callback", authController.OIDCCallback)
+
 	return r
 }
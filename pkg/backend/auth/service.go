@@ -3,25 +3,31 @@ package auth
 import (
 	"context"
 	"crypto/sha256"
-	"crypto/sha512"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"time"
 
+	"go.vxn.dev/littr/pkg/backend/audit"
 	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/passphrase"
 	"go.vxn.dev/littr/pkg/backend/tokens"
+	"go.vxn.dev/littr/pkg/backend/totp"
+	"go.vxn.dev/littr/pkg/config"
 	"go.vxn.dev/littr/pkg/models"
 )
 
-var appSecret = os.Getenv("APP_PEPPER")
-
 type AuthService struct {
+	mailService     models.MailServiceInterface
+	oidcProviders   map[string]*oidcProvider
 	tokenRepository models.TokenRepositoryInterface
 	userRepository  models.UserRepositoryInterface
 }
 
 func NewAuthService(
+	mailService models.MailServiceInterface,
+	oidcProviders map[string]config.OIDCProvider,
 	tokenRepository models.TokenRepositoryInterface,
 	userRepository models.UserRepositoryInterface,
 ) models.AuthServiceInterface {
@@ -30,6 +36,8 @@ func NewAuthService(
 	}
 
 	return &AuthService{
+		mailService:     mailService,
+		oidcProviders:   newOIDCProviders(oidcProviders),
 		tokenRepository: tokenRepository,
 		userRepository:  userRepository,
 	}
@@ -44,26 +52,82 @@ func (s *AuthService) Auth(ctx context.Context, authUserI interface{}) (*models.
 	// Fetch one user from cache according to the login credentials.
 	dbUser, err := s.userRepository.GetByID(authUser.Nickname)
 	if err != nil {
+		recordFailedLogin(ctx, authUser.Nickname, authUser.IPAddress, "unknown user")
+		return nil, nil, err
+	}
+
+	// Count the attempt, or refuse it when the user has to wait after the failed ones.
+	if err := s.countLoginAttempt(dbUser.Nickname); err != nil {
+		recordFailedLogin(ctx, dbUser.Nickname, authUser.IPAddress, err.Error())
 		return nil, nil, err
 	}
 
-	passHash := sha512.Sum512([]byte(authUser.PassphrasePlain + appSecret))
-	passHashHex := fmt.Sprintf("%x", passHash)
+	// Check the passphrase against the stored hash (of any supported format).
+	ok, rehash, err := passphrase.Verify(authUser.PassphrasePlain, dbUser.PassphraseHex)
+	if errors.Is(err, passphrase.ErrNoServerSecret) {
+		return nil, nil, err
+	}
+
+	if err != nil || !ok {
+		recordFailedLogin(ctx, dbUser.Nickname, authUser.IPAddress, "invalid passphrase")
+		s.noteFailedLogin(ctx, dbUser.Nickname, authUser.IPAddress)
 
-	// Check the passhashes.
-	if dbUser.PassphraseHex != passHashHex {
 		// Return auth fail.
 		return nil, nil, errAuthFailed
 	}
 
 	// Check if the user has been activated yet.
 	if !dbUser.Active {
+		// The passphrase has been right, so this is not a failed attempt (the retries must not lock the user out).
+		if err := s.forgiveLoginAttempt(dbUser.Nickname); err != nil {
+			return nil, nil, err
+		}
+
 		return nil, nil, errNotActivated
 	}
 
-	dbUser.LastLoginTime = time.Now()
+	// The second factor is asked for after the passphrase has been verified only.
+	if dbUser.TOTPEnabled && authUser.TOTPCode == "" {
+		// The passphrase has been right, so this is not a failed attempt.
+		if err := s.forgiveLoginAttempt(dbUser.Nickname); err != nil {
+			return nil, nil, err
+		}
+
+		return nil, nil, fmt.Errorf(common.ERR_TOTP_REQUIRED)
+	}
+
+	var passHash string
+
+	// Upgrade the legacy (or outdated) hash, now the plain passphrase is known to be valid.
+	if rehash {
+		if passHash, err = passphrase.Hash(authUser.PassphrasePlain); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// The second factor is checked within the update, so the TOTP code (or the recovery code) is consumed by one login only.
+	if err := s.userRepository.Update(dbUser.Nickname, func(user *models.User) error {
+		if user.TOTPEnabled {
+			if err := totp.Authenticate(user, authUser.TOTPCode); err != nil {
+				return err
+			}
+		}
+
+		if passHash != "" {
+			user.PassphraseHex = passHash
+		}
+
+		user.FailedLogins = 0
+		user.LastLoginTime = time.Now()
+
+		*dbUser = *user
+		return nil
+	}); err != nil {
+		if err.Error() == common.ERR_TOTP_INVALID {
+			recordFailedLogin(ctx, dbUser.Nickname, authUser.IPAddress, "invalid second factor")
+			s.noteFailedLogin(ctx, dbUser.Nickname, authUser.IPAddress)
+		}
 
-	if err := s.userRepository.Save(dbUser); err != nil {
 		return nil, nil, err
 	}
 
@@ -71,11 +135,19 @@ func (s *AuthService) Auth(ctx context.Context, authUserI interface{}) (*models.
 	//  OK, user authorized, now generete tokens
 	//
 
-	tokens, err := tokens.NewToken(dbUser, s.tokenRepository)
+	tokens, err := tokens.NewToken(dbUser, s.tokenRepository, authUser.UserAgent, authUser.IPAddress)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	audit.Record(ctx, models.AuditEvent{
+		Action:    audit.ActionLogin,
+		Actor:     dbUser.Nickname,
+		Target:    dbUser.Nickname,
+		IPAddress: authUser.IPAddress,
+		Details:   map[string]string{"method": "passphrase"},
+	})
+
 	// User authorized.
 	return dbUser, tokens, nil
 }
@@ -114,11 +186,16 @@ func (s *AuthService) Logout(ctx context.Context) error {
 		return fmt.Errorf(common.ERR_INVALID_REF_TOKEN)
 	}
 
-	// Delete such token not to be prune to hijack anymore.
-	err = s.tokenRepository.Delete(token.Hash)
-	if err != nil {
+	// Delete such token (and the tokens rotated before it) not to be prune to hijack anymore.
+	if err := tokens.RevokeFamily(token, s.tokenRepository); err != nil {
 		return err
 	}
 
+	audit.Record(ctx, models.AuditEvent{
+		Action: audit.ActionLogout,
+		Actor:  token.Nickname,
+		Target: token.Nickname,
+	})
+
 	return nil
 }
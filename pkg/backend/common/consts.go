@@ -15,20 +15,61 @@ const (
 const (
 	HDR_PAGE_NO      = "X-Page-No"
 	HDR_HIDE_REPLIES = "X-Hide-Replies"
-	HDR_DUMP_TOKEN   = "X-Dump-Token"
 	DHR_API_TOKEN    = "X-API-Token"
+
+	// The signed data-intervention requests (see pkg/backend/db/dump_auth.go).
+	HDR_DUMP_NONCE     = "X-Dump-Nonce"
+	HDR_DUMP_SIGNATURE = "X-Dump-Signature"
+	HDR_DUMP_TIMESTAMP = "X-Dump-Timestamp"
 )
 
 // (Non-)Error messages.
 const (
 	// Auth-related error messages
 	ERR_AUTH_ACC_TOKEN_FAIL = "could not generate new access token"
+	ERR_AUTH_LOCKED         = "too many failed login attempts, try again later"
 	ERR_AUTH_REF_TOKEN_FAIL = "could not generate new refresh token"
 	ERR_BLANK_REF_TOKEN     = "no refresh token provided with request"
 	ERR_INVALID_REF_TOKEN   = "invalid refresh token"
 	ERR_TOKEN_SAVE_FAIL     = "could not save new token to database"
 	ERR_TOKEN_NOT_FOUND     = "requested token was not found"
 
+	// Admin-related error messages
+	ERR_PERMISSION_DENIED     = "you do not have the permission to do that"
+	ERR_ROLE_UNKNOWN          = "unknown role"
+	ERR_ADMIN_SELF_DEMOTE     = "you cannot revoke your own admin role"
+	ERR_ADMIN_SELF_DEACTIVATE = "you cannot deactivate your own account"
+	ERR_AUDIT_FILTER_INVALID  = "invalid audit filter, use the RFC 3339 timestamps and a positive limit"
+
+	// API token-related error messages
+	ERR_API_TOKEN_ID_BLANK      = "tokenID cannot be blank"
+	ERR_API_TOKEN_NAME_INVALID  = "API token name has to be 1-40 characters long"
+	ERR_API_TOKEN_NOT_FOUND     = "requested API token was not found"
+	ERR_API_TOKEN_SCOPES_BLANK  = "API token has to be granted one scope at least"
+	ERR_API_TOKEN_SCOPE_UNKNOWN = "unknown API token scope"
+	ERR_API_TOKEN_TTL_INVALID   = "API token has to expire in 1-365 days"
+	ERR_USER_API_TOKEN_FOREIGN  = "you can manage yours API tokens only"
+
+	// Single sign-on-related error messages
+	ERR_OIDC_LOGIN_FAILED     = "could not sign in with the identity provider"
+	ERR_OIDC_PROVIDER_UNKNOWN = "unknown identity provider"
+	ERR_OIDC_STATE_INVALID    = "invalid or expired sign-in state, try again"
+	ERR_OIDC_USER_NOT_LINKED  = "no account is linked to such identity"
+
+	// Session-related error messages
+	ERR_SESSION_ID_BLANK     = "sessionID cannot be blank"
+	ERR_SESSION_NOT_FOUND    = "requested session was not found"
+	ERR_USER_SESSION_FOREIGN = "you can manage yours sessions only"
+
+	// Two-factor-related error messages
+	ERR_TOTP_REQUIRED        = "two-factor authentication code required"
+	ERR_TOTP_INVALID         = "invalid two-factor authentication code"
+	ERR_TOTP_CODE_BLANK      = "two-factor authentication code is blank"
+	ERR_TOTP_ALREADY_ENABLED = "two-factor authentication is already enabled"
+	ERR_TOTP_NOT_ENABLED     = "two-factor authentication is not enabled"
+	ERR_TOTP_NOT_SETUP       = "two-factor authentication setup has not been started"
+	ERR_USER_TOTP_FOREIGN    = "you can manage yours two-factor authentication only"
+
 	// Generic error messages
 	ERR_CALLER_BLANK        = "callerID cannot be empty"
 	ERR_CALLER_FAIL         = "could not get caller's name"
@@ -41,6 +82,8 @@ const (
 	ERR_API_TOKEN_BLANK     = "blank API token sent"
 	ERR_API_TOKEN_INVALID   = "invalid API token sent"
 	ERR_NO_SERVER_SECRET    = "missing the server's secret (APP_PEPPER)"
+	ERR_SNAPSHOT_ID_BLANK   = "snapshot generation ID is required"
+	ERR_NO_ITEMS_FOUND      = "no items found"
 
 	// Image-processing-related error messages
 	ERR_IMG_DECODE_FAIL      = "image: could not decode to byte stream"
@@ -78,6 +121,27 @@ const (
 	ERR_POSTID_BLANK        = "postID param is required"
 	ERR_HASHTAG_BLANK       = "hashtag param is required"
 
+	// Reaction-related error messages
+	ERR_REACTION_KIND_UNKNOWN = "unknown reaction kind"
+
+	// Post editing-related error messages
+	ERR_POST_EDIT_EXPIRED = "the post cannot be edited anymore"
+	ERR_POST_EDIT_LIMIT   = "the post has been edited too many times"
+	ERR_POST_EDIT_TYPE    = "only the text posts can be edited"
+
+	// Thread-related error messages
+	ERR_THREAD_DEPTH_INVALID = "thread depth has to be a number between 0 and 20"
+	ERR_POST_HIDDEN          = "you are not allowed to see the post"
+
+	// Bookmark-related error messages
+	ERR_BOOKMARK_LIMIT    = "the bookmarks limit has been reached"
+	ERR_BOOKMARKS_FOREIGN = "you can list your bookmarks only"
+
+	// Search-related error messages
+	ERR_SEARCH_QUERY_BLANK    = "search query has to contain a word at least"
+	ERR_SEARCH_QUERY_TOO_LONG = "search query is too long"
+	ERR_SEARCH_TYPE_UNKNOWN   = "unknown search type"
+
 	// Push-related (non-)error messages
 	MSG_WEBPUSH_GW_RESPONSE         = "push goroutine: webpush gateway:"
 	ERR_DEVICE_NOT_FOUND            = "devices not found in the database"
@@ -1,13 +1,19 @@
 package common
 
-import "context"
+import (
+	"context"
+	"net"
+	"net/http"
+)
 
 // Used in context as a custom type.
 type UserNickname string
 
 const (
-	ContextUserKeyName UserNickname = "nickname"
-	DefaultCallerID    string       = "system"
+	ContextUserKeyName      UserNickname = "nickname"
+	ContextTokenKeyName     UserNickname = "tokenID"
+	ContextIPAddressKeyName UserNickname = "ipAddress"
+	DefaultCallerID         string       = "system"
 )
 
 func GetCallerID(ctx context.Context) string {
@@ -18,3 +24,29 @@ func GetCallerID(ctx context.Context) string {
 
 	return callerID
 }
+
+// GetTokenID returns the ID (hash) of the refresh token the request has been authenticated with.
+func GetTokenID(ctx context.Context) string {
+	tokenID, _ := ctx.Value(ContextTokenKeyName).(string)
+	return tokenID
+}
+
+// GetIPAddress returns the client's IP address stored in the context (see audit.Middleware).
+func GetIPAddress(ctx context.Context) string {
+	ipAddress, _ := ctx.Value(ContextIPAddressKeyName).(string)
+	return ipAddress
+}
+
+// ClientIPAddress returns the client's IP address as set by the reverse proxy, or the remote address of the connection.
+func ClientIPAddress(r *http.Request) string {
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -1,9 +1,22 @@
 package common
 
 import (
+	"time"
+
 	"go.vxn.dev/littr/pkg/models"
 )
 
+// FlushUserSecrets clears the user's credentials, the second factor and the login lockout state, which are never exported.
+func FlushUserSecrets(user *models.User) {
+	user.Passphrase = ""
+	user.PassphraseHex = ""
+	user.TOTPSecret = ""
+	user.TOTPLastStep = 0
+	user.RecoveryCodes = nil
+	user.FailedLogins = 0
+	user.LastFailedLoginTime = time.Time{}
+}
+
 // Helper function to flush sensitive user data in the export for response.
 func FlushUserData(users *map[string]models.User, callerID string) *map[string]models.User {
 	if users == nil || callerID == "" {
@@ -12,14 +25,16 @@ func FlushUserData(users *map[string]models.User, callerID string) *map[string]m
 
 	// Flush unwanted properties.
 	for key, user := range *users {
-		user.Passphrase = ""
-		user.PassphraseHex = ""
+		FlushUserSecrets(&user)
 
 		// These are kept for callerID.
 		if user.Nickname != callerID {
 			user.Email = ""
+			user.TOTPEnabled = false
+			user.ExternalIDs = nil
 			user.FlowList = nil
 			user.ShadeList = nil
+			user.Bookmarks = nil
 
 			// Flush user's options, keep the private state only.
 			options := map[string]bool{}
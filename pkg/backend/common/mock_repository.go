@@ -11,6 +11,55 @@ var (
 	MockUUID         = "550e8400-e29b-41d4-a716-446655440000"
 )
 
+//
+//  APITokenRepositoryInterface dummy implementation
+//
+
+type MockAPITokenRepository struct{}
+
+func (m *MockAPITokenRepository) GetAll() (*map[string]models.APIToken, error) {
+	return &map[string]models.APIToken{
+		"a1b2c3d4": {
+			Hash:      "a1b2c3d4",
+			Nickname:  MockUserNickname,
+			Name:      "bot",
+			Scopes:    []string{"posts:read"},
+			CreatedAt: time.Now(),
+			ExpiresAt: time.Now().Add(24 * time.Hour),
+		},
+	}, nil
+}
+
+func (m *MockAPITokenRepository) GetByID(tokenID string) (*models.APIToken, error) {
+	return &models.APIToken{
+		Hash:      "a1b2c3d4",
+		Nickname:  MockUserNickname,
+		Name:      "bot",
+		Scopes:    []string{"posts:read"},
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+	}, nil
+}
+
+func (m *MockAPITokenRepository) GetByNickname(nickname string) (*map[string]models.APIToken, error) {
+	return m.GetAll()
+}
+
+func (m *MockAPITokenRepository) Save(token *models.APIToken) error {
+	return nil
+}
+
+func (m *MockAPITokenRepository) Update(tokenID string, fn func(token *models.APIToken) error) error {
+	return nil
+}
+
+func (m *MockAPITokenRepository) Delete(tokenID string) error {
+	return nil
+}
+
+// Implementation verification for compiler.
+var _ models.APITokenRepositoryInterface = (*MockAPITokenRepository)(nil)
+
 //
 //  PollRepositoryInterface dummy implementation
 //
@@ -33,6 +82,10 @@ func (m *MockPollRepository) Save(poll *models.Poll) error {
 	return nil
 }
 
+func (m *MockPollRepository) Update(pollID string, fn func(poll *models.Poll) error) error {
+	return nil
+}
+
 func (m *MockPollRepository) Delete(pollID string) error {
 	return nil
 }
@@ -62,6 +115,10 @@ func (m *MockPostRepository) Save(post *models.Post) error {
 	return nil
 }
 
+func (m *MockPostRepository) Update(postID string, fn func(post *models.Post) error) error {
+	return nil
+}
+
 func (m *MockPostRepository) Delete(postID string) error {
 	return nil
 }
@@ -146,10 +203,25 @@ func (m *MockTokenRepository) GetByID(tokenID string) (*models.Token, error) {
 	}, nil
 }
 
+func (m *MockTokenRepository) GetByNickname(nickname string) (*map[string]models.Token, error) {
+	return &map[string]models.Token{
+		"ff00lerT5": {
+			Hash:      "ff00lertT5",
+			Nickname:  MockUserNickname,
+			CreatedAt: time.Now().Add(-5 * 7 * 24 * time.Hour),
+			TTL:       500,
+		},
+	}, nil
+}
+
 func (m *MockTokenRepository) Save(token *models.Token) error {
 	return nil
 }
 
+func (m *MockTokenRepository) Update(tokenID string, fn func(token *models.Token) error) error {
+	return nil
+}
+
 func (m *MockTokenRepository) Delete(tokenID string) error {
 	return nil
 }
@@ -179,6 +251,10 @@ func (m *MockUserRepository) Save(user *models.User) error {
 	return nil
 }
 
+func (m *MockUserRepository) Update(userID string, fn func(user *models.User) error) error {
+	return nil
+}
+
 func (m *MockUserRepository) Delete(userID string) error {
 	return nil
 }
@@ -25,15 +25,51 @@ var DecideStatusFromError = func(err error) int {
 		err.Error() == ERR_NICKNAME_TOO_LONG_SHORT ||
 		err.Error() == ERR_WRONG_EMAIL_FORMAT ||
 		err.Error() == ERR_INPUT_DATA_FAIL ||
+		err.Error() == ERR_TOTP_CODE_BLANK ||
+		err.Error() == ERR_SESSION_ID_BLANK ||
+		err.Error() == ERR_ROLE_UNKNOWN ||
+		err.Error() == ERR_REACTION_KIND_UNKNOWN ||
+		err.Error() == ERR_POSTID_BLANK ||
+		err.Error() == ERR_POST_BLANK ||
+		err.Error() == ERR_POST_EDIT_TYPE ||
+		err.Error() == ERR_THREAD_DEPTH_INVALID ||
+		err.Error() == ERR_SEARCH_QUERY_BLANK ||
+		err.Error() == ERR_SEARCH_QUERY_TOO_LONG ||
+		err.Error() == ERR_SEARCH_TYPE_UNKNOWN ||
+		err.Error() == ERR_API_TOKEN_ID_BLANK ||
+		err.Error() == ERR_API_TOKEN_NAME_INVALID ||
+		err.Error() == ERR_API_TOKEN_SCOPES_BLANK ||
+		err.Error() == ERR_API_TOKEN_SCOPE_UNKNOWN ||
+		err.Error() == ERR_API_TOKEN_TTL_INVALID ||
+		err.Error() == ERR_OIDC_STATE_INVALID ||
 		err.Error() == ERR_IMG_UNKNOWN_TYPE {
 		return http.StatusBadRequest
 	}
 
+	// HTTP 401 conditions.
+	if err.Error() == ERR_TOTP_REQUIRED ||
+		err.Error() == ERR_TOTP_INVALID ||
+		err.Error() == ERR_OIDC_LOGIN_FAILED {
+		return http.StatusUnauthorized
+	}
+
 	// HTTP 403 conditions.
 	if err.Error() == ERR_POLL_SELF_VOTE ||
+		err.Error() == ERR_POST_SELF_RATE ||
+		err.Error() == ERR_POST_UPDATE_FOREIGN ||
+		err.Error() == ERR_POST_EDIT_EXPIRED ||
+		err.Error() == ERR_POST_EDIT_LIMIT ||
+		err.Error() == ERR_POST_HIDDEN ||
 		err.Error() == ERR_USER_SHADED ||
 		err.Error() == ERR_USER_DELETE_FOREIGN ||
 		err.Error() == ERR_USER_PASSPHRASE_FOREIGN ||
+		err.Error() == ERR_USER_TOTP_FOREIGN ||
+		err.Error() == ERR_USER_SESSION_FOREIGN ||
+		err.Error() == ERR_BOOKMARK_LIMIT ||
+		err.Error() == ERR_BOOKMARKS_FOREIGN ||
+		err.Error() == ERR_USER_API_TOKEN_FOREIGN ||
+		err.Error() == ERR_OIDC_USER_NOT_LINKED ||
+		err.Error() == ERR_PERMISSION_DENIED ||
 		err.Error() == ERR_REGISTRATION_DISABLED ||
 		err.Error() == ERR_POLL_EXISTING_VOTE ||
 		err.Error() == ERR_POLL_INVALID_VOTE_COUNT {
@@ -42,17 +78,31 @@ var DecideStatusFromError = func(err error) int {
 
 	// HTTP 404 conditions.
 	if err.Error() == ERR_POLL_NOT_FOUND ||
+		err.Error() == ERR_POST_NOT_FOUND ||
 		err.Error() == ERR_NO_EMAIL_MATCH ||
+		err.Error() == ERR_SESSION_NOT_FOUND ||
+		err.Error() == ERR_API_TOKEN_NOT_FOUND ||
+		err.Error() == ERR_OIDC_PROVIDER_UNKNOWN ||
 		err.Error() == ERR_USER_NOT_FOUND {
 		return http.StatusNotFound
 	}
 
 	// HTTP 409 condition
 	if err.Error() == ERR_EMAIL_ALREADY_USED ||
-		err.Error() == ERR_PASSPHRASE_CURRENT_WRONG {
+		err.Error() == ERR_PASSPHRASE_CURRENT_WRONG ||
+		err.Error() == ERR_TOTP_ALREADY_ENABLED ||
+		err.Error() == ERR_TOTP_NOT_ENABLED ||
+		err.Error() == ERR_TOTP_NOT_SETUP ||
+		err.Error() == ERR_ADMIN_SELF_DEMOTE ||
+		err.Error() == ERR_ADMIN_SELF_DEACTIVATE {
 		return http.StatusConflict
 	}
 
+	// HTTP 429 conditions.
+	if err.Error() == ERR_AUTH_LOCKED {
+		return http.StatusTooManyRequests
+	}
+
 	// HTTP 500 as default.
 	return http.StatusInternalServerError
 }
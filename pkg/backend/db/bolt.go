@@ -0,0 +1,192 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  BoltCache
+//  Persistent implementation of the Cacher interface backed by the embedded bbolt key-value store. Every cache lives in its own bucket of the shared database file.
+//  Store and Delete are committed (and fsynced) immediately, so there is nothing to be dumped or loaded, and the dataset does not have to fit in memory.
+//
+
+// OpenBoltDatabase opens (or creates) the bbolt database file at the given path.
+func OpenBoltDatabase(path string) (*bolt.DB, error) {
+	return bolt.Open(path, 0o660, &bolt.Options{Timeout: 5 * time.Second})
+}
+
+type BoltCache struct {
+	// Name of the cache = name of the bucket.
+	Name string
+
+	db *bolt.DB
+
+	// decode restores the stored JSON value to the cache's item type.
+	decode func(raw []byte) (interface{}, error)
+}
+
+// NewBoltCache prepares the bucket for a cache of such name. As long as the function is generic, the item type T is used to decode the stored values,
+// so the values loaded are of the very same type as in the in-memory caches.
+func NewBoltCache[T models.Item](db *bolt.DB, name string, _ T) *BoltCache {
+	if db == nil || name == "" {
+		return nil
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(name))
+		return err
+	}); err != nil {
+		return nil
+	}
+
+	return &BoltCache{
+		Name: name,
+		db:   db,
+		decode: func(raw []byte) (interface{}, error) {
+			var item T
+
+			if err := json.Unmarshal(raw, &item); err != nil {
+				return nil, err
+			}
+
+			return item, nil
+		},
+	}
+}
+
+func (c *BoltCache) Load(key string) (interface{}, bool) {
+	var rawV interface{}
+
+	err := c.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket([]byte(c.Name)).Get([]byte(key))
+		if raw == nil {
+			return nil
+		}
+
+		var err error
+		rawV, err = c.decode(raw)
+		return err
+	})
+	if err != nil || rawV == nil {
+		return nil, false
+	}
+
+	return rawV, true
+}
+
+func (c *BoltCache) Store(key string, rawV interface{}) bool {
+	raw, err := json.Marshal(rawV)
+	if err != nil {
+		return false
+	}
+
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(c.Name)).Put([]byte(key), raw)
+	})
+
+	return err == nil
+}
+
+func (c *BoltCache) CompareAndSwap(key string, version int64, rawV interface{}) bool {
+	raw, err := json.Marshal(rawV)
+	if err != nil {
+		return false
+	}
+
+	// The bolt write transactions are serialized, so the check and the write are atomic.
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(c.Name))
+
+		stored := bucket.Get([]byte(key))
+		if stored == nil {
+			return ErrVersionConflict
+		}
+
+		current, err := c.decode(stored)
+		if err != nil {
+			return err
+		}
+
+		if itemVersion(current) != version {
+			return ErrVersionConflict
+		}
+
+		return bucket.Put([]byte(key), raw)
+	})
+
+	return err == nil
+}
+
+func (c *BoltCache) Delete(key string) bool {
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(c.Name)).Delete([]byte(key))
+	})
+
+	return err == nil
+}
+
+func (c *BoltCache) Range() (*GenericMap, int64) {
+	var counter int64
+	genericMap := GenericMap{}
+
+	_ = c.Stream(func(key string, rawV interface{}) bool {
+		genericMap[key] = rawV
+		counter++
+		return true
+	})
+
+	return &genericMap, counter
+}
+
+// Stream walks the bucket with a cursor and passes the decoded items one by one to the given function, without materializing the whole cache.
+// The walk stops when the function returns false. Items that cannot be decoded are skipped.
+func (c *BoltCache) Stream(fn func(key string, rawV interface{}) bool) error {
+	return c.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket([]byte(c.Name)).Cursor()
+
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			rawV, err := c.decode(v)
+			if err != nil {
+				continue
+			}
+
+			if !fn(string(k), rawV) {
+				break
+			}
+		}
+
+		return nil
+	})
+}
+
+// Iterate implements the Iterator interface using the cursor walk (see Stream).
+func (c *BoltCache) Iterate(fn func(key string, rawV interface{}) bool) {
+	_ = c.Stream(fn)
+}
+
+// Count returns the number of keys stored in the bucket.
+func (c *BoltCache) Count() int64 {
+	var count int64
+
+	_ = c.db.View(func(tx *bolt.Tx) error {
+		count = int64(tx.Bucket([]byte(c.Name)).Stats().KeyN)
+		return nil
+	})
+
+	return count
+}
+
+func (c *BoltCache) GetName() string {
+	return c.Name
+}
+
+func (c *BoltCache) Dump() *GenericMap {
+	mp, _ := c.Range()
+
+	return mp
+}
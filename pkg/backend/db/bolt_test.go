@@ -0,0 +1,63 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+func TestBoltCache_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "littr.db")
+
+	boltDB, err := OpenBoltDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := NewBoltCache(boltDB, "UserCache", models.User{})
+	if cache == nil {
+		t.Fatal("nil BoltCache")
+	}
+
+	for _, nick := range []string{"alice", "bob", "cody"} {
+		if !cache.Store(nick, models.User{Nickname: nick, About: "hi, " + nick}) {
+			t.Fatalf("could not store user %s", nick)
+		}
+	}
+
+	if !cache.Delete("bob") {
+		t.Fatal("could not delete user bob")
+	}
+
+	// Reopen the database file as a restarted server would do.
+	if err := boltDB.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	boltDB, err = OpenBoltDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer boltDB.Close()
+
+	cache = NewBoltCache(boltDB, "UserCache", models.User{})
+
+	rawUser, found := cache.Load("alice")
+	if !found {
+		t.Fatal("user alice not found after reopen")
+	}
+
+	// The repositories assert the very same type as stored in the in-memory caches.
+	if user, ok := rawUser.(models.User); !ok || user.About != "hi, alice" {
+		t.Errorf("user alice loaded corrupted: %+v", rawUser)
+	}
+
+	if _, found := cache.Load("bob"); found {
+		t.Error("deleted user bob found after reopen")
+	}
+
+	if users, count := cache.Range(); count != 2 || len(*users) != 2 || cache.Count() != 2 {
+		t.Errorf("expected 2 users, got %d", count)
+	}
+}
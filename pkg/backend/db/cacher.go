@@ -83,6 +83,18 @@ func (c *DefaultCache) Range() (*GenericMap, int64) {
 	return &genericMap, counter
 }
 
+// Iterate loops over the sync.Map directly, no copy is made.
+func (c *DefaultCache) Iterate(fn func(key string, rawV interface{}) bool) {
+	c.syncMap.Range(func(rawK, rawV interface{}) bool {
+		key, ok := rawK.(string)
+		if !ok {
+			return true
+		}
+
+		return fn(key, rawV)
+	})
+}
+
 func (c *DefaultCache) GetName() string {
 	return c.Name
 }
@@ -159,6 +171,20 @@ func (c *SimpleCache) Store(key string, rawV interface{}) bool {
 	return true
 }
 
+func (c *SimpleCache) CompareAndSwap(key string, version int64, rawV interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current, found := c.mp[key]
+	if !found || itemVersion(current) != version {
+		return false
+	}
+
+	c.mp[key] = rawV
+
+	return true
+}
+
 func (c *SimpleCache) Delete(key string) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -183,6 +209,26 @@ func (c *SimpleCache) Range() (*GenericMap, int64) {
 	return &genericMap, counter
 }
 
+// Iterate loops over the map under the read lock, no copy is made. The function must not write to the cache.
+func (c *SimpleCache) Iterate(fn func(key string, rawV interface{}) bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for key, rawV := range c.mp {
+		if !fn(key, rawV) {
+			return
+		}
+	}
+}
+
+// Count returns the number of items in the map.
+func (c *SimpleCache) Count() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return int64(len(c.mp))
+}
+
 func (c *SimpleCache) GetName() string {
 	return c.Name
 }
@@ -1,19 +1,29 @@
 package db
 
 import (
+	"bytes"
+	"errors"
 	"net/http"
 
+	"go.vxn.dev/littr/pkg/backend/audit"
 	"go.vxn.dev/littr/pkg/backend/common"
-	"go.vxn.dev/littr/pkg/config"
+	"go.vxn.dev/littr/pkg/backend/roles"
+	"go.vxn.dev/littr/pkg/models"
+
+	chi "github.com/go-chi/chi/v5"
 )
 
 type dumpController struct {
 	db DatabaseKeeper
+
+	// reindex is called after the running data have been replaced (e.g. to rebuild the search index), may be nil.
+	reindex func()
 }
 
-func NewDumpController(db DatabaseKeeper) *dumpController {
+func NewDumpController(db DatabaseKeeper, reindex func()) *dumpController {
 	return &dumpController{
-		db: db,
+		db:      db,
+		reindex: reindex,
 	}
 }
 
@@ -23,32 +33,300 @@ func NewDumpController(db DatabaseKeeper) *dumpController {
 //	@Description		This function call is used primarily by the healthcheck function inside the Docker compose stack to periodically dump running data into the JSON files.
 //	@Tags			dump
 //	@Produce		json
-//	@Param			X-Dump-Token	header		string	true	"A special app's dump token."
+//	@Param			X-Dump-Timestamp	header		string	false	"Unix timestamp of the signed request (see RequireDumpAuth)."
+//	@Param			X-Dump-Nonce		header		string	false	"Random one-time value of the signed request."
+//	@Param			X-Dump-Signature	header		string	false	"HMAC-SHA256 signature of the request, the admin's session is required if not set."
 //	@Success		200				{object}	common.APIResponse{data=models.Stub}	"The dumping process was successful."
-//	@Failure		400				{object}	common.APIResponse{data=models.Stub}	"Invalid input data (e.g. a blank token)."
-//	@Failure		403				{object}	common.APIResponse{data=models.Stub}	"User unauthorized (e.g. invalid token)."
+//	@Failure		400				{object}	common.APIResponse{data=models.Stub}	"Invalid input data (e.g. malformed signature headers)."
+//	@Failure		403				{object}	common.APIResponse{data=models.Stub}	"Invalid, expired or replayed signature, or the caller lacks the data:manage permission."
 //	@Failure		429				{object}	common.APIResponse{data=models.Stub}	"Too many requests, try again later."
 //	@Router			/dump [get]
 func (c *dumpController) DumpAll(w http.ResponseWriter, r *http.Request) {
 	l := common.NewLogger(r, "dumpController")
 
-	// check the incoming API token
-	token := r.Header.Get(common.HDR_DUMP_TOKEN)
-	if token == "" {
-		l.Msg(common.ERR_API_TOKEN_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+	report, err := c.db.DumpAll()
+	if err != nil {
+		l.Error(err).Status(http.StatusInternalServerError).Log().Write(w)
 		return
 	}
 
-	// validate the incoming token
-	if token != config.DataDumpToken {
-		l.Msg(common.ERR_API_TOKEN_INVALID).Status(http.StatusForbidden).Log().Payload(nil).Write(w)
+	recordDataEvent(r, models.AuditEvent{
+		Action: audit.ActionDataDump,
+	})
+
+	l.Msg(report).Status(http.StatusOK).Log().Write(w)
+}
+
+// recordDataEvent records the data intervention, noting whether the request has been signed, or authorized by the caller's permission (see
+// RequireDumpAuth). The signed requests are recorded as made by the system.
+func recordDataEvent(r *http.Request, event models.AuditEvent) {
+	authorizedBy := string(roles.PermDataManage)
+	if r.Header.Get(common.HDR_DUMP_SIGNATURE) != "" {
+		authorizedBy = "signature"
+	}
+
+	if event.Details == nil {
+		event.Details = make(map[string]string)
+	}
+
+	event.Details["authorized_by"] = authorizedBy
+
+	audit.Record(r.Context(), event)
+}
+
+// ListSnapshots is the handler function to list the snapshot generations available for restore.
+//
+//	@Summary		List snapshot generations
+//	@Description		This function call lists the snapshot generations written by the previous dumps (the newest first) including the checksums of their files.
+//	@Tags			dump
+//	@Produce		json
+//	@Param			X-Dump-Timestamp	header		string	false	"Unix timestamp of the signed request (see RequireDumpAuth)."
+//	@Param			X-Dump-Nonce		header		string	false	"Random one-time value of the signed request."
+//	@Param			X-Dump-Signature	header		string	false	"HMAC-SHA256 signature of the request, the admin's session is required if not set."
+//	@Success		200				{object}	common.APIResponse{data=db.ListSnapshots.responseData}	"The snapshot generations are listed."
+//	@Failure		400				{object}	common.APIResponse{data=models.Stub}	"Invalid input data (e.g. malformed signature headers)."
+//	@Failure		403				{object}	common.APIResponse{data=models.Stub}	"Invalid, expired or replayed signature, or the caller lacks the data:manage permission."
+//	@Failure		500				{object}	common.APIResponse{data=models.Stub}	"The manifest could not be read."
+//	@Router			/dump/snapshots [get]
+func (c *dumpController) ListSnapshots(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, "dumpController")
+
+	type responseData struct {
+		Generations []SnapshotGeneration `json:"generations"`
+	}
+
+	gens, err := c.db.ListSnapshots()
+	if err != nil {
+		l.Error(err).Status(http.StatusInternalServerError).Log().Payload(nil).Write(w)
+		return
+	}
+
+	l.Msg("ok, listing snapshot generations").Status(http.StatusOK).Log().Payload(&responseData{Generations: gens}).Write(w)
+}
+
+// RestoreSnapshot is the handler function to replace the running data with a snapshot generation.
+//
+//	@Summary		Restore a snapshot generation
+//	@Description		This function call replaces the running data with the snapshot generation specified. All the generation's files are verified against the manifest checksums first. The restored state is dumped right away as a new generation.
+//	@Tags			dump
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Dump-Timestamp	header		string	false	"Unix timestamp of the signed request (see RequireDumpAuth)."
+//	@Param			X-Dump-Nonce		header		string	false	"Random one-time value of the signed request."
+//	@Param			X-Dump-Signature	header		string	false	"HMAC-SHA256 signature of the request, the admin's session is required if not set."
+//	@Param			request			body		db.SnapshotRestoreRequest	true	"The generation to restore."
+//	@Success		200				{object}	common.APIResponse{data=models.Stub}	"The snapshot generation has been restored."
+//	@Failure		400				{object}	common.APIResponse{data=models.Stub}	"Invalid input data (e.g. malformed signature headers, or a blank generation ID)."
+//	@Failure		403				{object}	common.APIResponse{data=models.Stub}	"Invalid, expired or replayed signature, or the caller lacks the data:manage permission."
+//	@Failure		404				{object}	common.APIResponse{data=models.Stub}	"No such snapshot generation."
+//	@Failure		500				{object}	common.APIResponse{data=models.Stub}	"The generation could not be verified, or restored."
+//	@Router			/dump/restore [post]
+func (c *dumpController) RestoreSnapshot(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, "dumpController")
+
+	var dtoIn SnapshotRestoreRequest
+
+	if err := common.UnmarshalRequestData(r, &dtoIn); err != nil {
+		l.Msg(common.ERR_INPUT_DATA_FAIL).Status(http.StatusBadRequest).Error(err).Log().Payload(nil).Write(w)
+		return
+	}
+
+	if dtoIn.Generation == "" {
+		l.Msg(common.ERR_SNAPSHOT_ID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	report, err := c.db.RestoreSnapshot(dtoIn.Generation)
+	if errors.Is(err, errSnapshotNotFound) {
+		l.Error(err).Status(http.StatusNotFound).Log().Payload(nil).Write(w)
 		return
 	}
 
-	report, err := c.db.DumpAll()
 	if err != nil {
-		l.Error(err).Status(http.StatusInternalServerError).Log().Write(w)
-	} else {
-		l.Msg(report).Status(http.StatusOK).Log().Write(w)
+		l.Msg(report).Error(err).Status(http.StatusInternalServerError).Log().Payload(nil).Write(w)
+		return
+	}
+
+	c.reindexData()
+
+	recordDataEvent(r, models.AuditEvent{
+		Action: audit.ActionDataRestore,
+		Target: dtoIn.Generation,
+	})
+
+	l.Msg(report).Status(http.StatusOK).Log().Payload(nil).Write(w)
+}
+
+// reindexData calls the reindex hook if set.
+func (c *dumpController) reindexData() {
+	if c.reindex != nil {
+		c.reindex()
+	}
+}
+
+type SnapshotRestoreRequest struct {
+	// Generation is the ID of the snapshot generation to restore.
+	Generation string `json:"generation" example:"20240101T000000.000Z"`
+}
+
+// LoadAll is the handler function to load the snapshot files into the running caches.
+//
+//	@Summary		Load the data snapshots
+//	@Description		This function call loads the latest snapshot files into the running caches (the items of the same keys are overwritten), and replays the journals. It is skipped with the persistent storage backends.
+//	@Tags			dump
+//	@Produce		json
+//	@Param			X-Dump-Timestamp	header		string	false	"Unix timestamp of the signed request (see RequireDumpAuth)."
+//	@Param			X-Dump-Nonce		header		string	false	"Random one-time value of the signed request."
+//	@Param			X-Dump-Signature	header		string	false	"HMAC-SHA256 signature of the request, the admin's session is required if not set."
+//	@Success		200				{object}	common.APIResponse{data=models.Stub}	"The snapshots have been loaded."
+//	@Failure		400				{object}	common.APIResponse{data=models.Stub}	"Invalid input data (e.g. malformed signature headers)."
+//	@Failure		403				{object}	common.APIResponse{data=models.Stub}	"Invalid, expired or replayed signature, or the caller lacks the data:manage permission."
+//	@Failure		500				{object}	common.APIResponse{data=models.Stub}	"The snapshots could not be loaded."
+//	@Router			/dump/load [post]
+func (c *dumpController) LoadAll(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, "dumpController")
+
+	report, err := c.db.LoadAll()
+	if err != nil {
+		l.Msg(report).Error(err).Status(http.StatusInternalServerError).Log().Payload(nil).Write(w)
+		return
 	}
+
+	c.reindexData()
+
+	recordDataEvent(r, models.AuditEvent{
+		Action: audit.ActionDataLoad,
+	})
+
+	l.Msg(report).Status(http.StatusOK).Log().Payload(nil).Write(w)
+}
+
+// Migrate is the handler function to run the pending data migrations.
+//
+//	@Summary		Run the data migrations
+//	@Description		This function call runs the pending data migrations (and the repeatable ones), and records them in the migration ledger. The changes are reported only when `dry_run` is set.
+//	@Tags			dump
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Dump-Timestamp	header		string	false	"Unix timestamp of the signed request (see RequireDumpAuth)."
+//	@Param			X-Dump-Nonce		header		string	false	"Random one-time value of the signed request."
+//	@Param			X-Dump-Signature	header		string	false	"HMAC-SHA256 signature of the request, the admin's session is required if not set."
+//	@Param			request			body		db.MigrateRequest	false	"The migration options."
+//	@Success		200				{object}	common.APIResponse{data=models.Stub}	"The migrations have been run."
+//	@Failure		400				{object}	common.APIResponse{data=models.Stub}	"Invalid input data (e.g. malformed signature headers)."
+//	@Failure		403				{object}	common.APIResponse{data=models.Stub}	"Invalid, expired or replayed signature, or the caller lacks the data:manage permission."
+//	@Failure		500				{object}	common.APIResponse{data=models.Stub}	"A migration has failed."
+//	@Router			/dump/migrate [post]
+func (c *dumpController) Migrate(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, "dumpController")
+
+	var dtoIn MigrateRequest
+
+	// The body is optional.
+	if r.ContentLength != 0 {
+		if err := common.UnmarshalRequestData(r, &dtoIn); err != nil {
+			l.Msg(common.ERR_INPUT_DATA_FAIL).Status(http.StatusBadRequest).Error(err).Log().Payload(nil).Write(w)
+			return
+		}
+	}
+
+	report, err := c.db.Migrate(dtoIn.DryRun)
+	if err != nil {
+		l.Msg(report).Error(err).Status(http.StatusInternalServerError).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// The dry runs change nothing.
+	if !dtoIn.DryRun {
+		c.reindexData()
+
+		recordDataEvent(r, models.AuditEvent{
+			Action: audit.ActionDataMigrate,
+		})
+	}
+
+	l.Msg(report).Status(http.StatusOK).Log().Payload(nil).Write(w)
+}
+
+// CacheSizes is the handler function to report the item counts of the caches.
+//
+//	@Summary		Report the cache sizes
+//	@Description		This function call reports the count of the items of every cache (or of its SQL table with the SQLite repositories).
+//	@Tags			dump
+//	@Produce		json
+//	@Param			X-Dump-Timestamp	header		string	false	"Unix timestamp of the signed request (see RequireDumpAuth)."
+//	@Param			X-Dump-Nonce		header		string	false	"Random one-time value of the signed request."
+//	@Param			X-Dump-Signature	header		string	false	"HMAC-SHA256 signature of the request, the admin's session is required if not set."
+//	@Success		200				{object}	common.APIResponse{data=db.CacheSizes.responseData}	"The cache sizes are reported."
+//	@Failure		400				{object}	common.APIResponse{data=models.Stub}	"Invalid input data (e.g. malformed signature headers)."
+//	@Failure		403				{object}	common.APIResponse{data=models.Stub}	"Invalid, expired or replayed signature, or the caller lacks the data:manage permission."
+//	@Failure		500				{object}	common.APIResponse{data=models.Stub}	"The sizes could not be counted."
+//	@Router			/dump/caches [get]
+func (c *dumpController) CacheSizes(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, "dumpController")
+
+	type responseData struct {
+		Sizes map[string]int64 `json:"sizes"`
+	}
+
+	sizes, err := c.db.CacheSizes()
+	if err != nil {
+		l.Error(err).Status(http.StatusInternalServerError).Log().Payload(nil).Write(w)
+		return
+	}
+
+	l.Msg("ok, reporting cache sizes").Status(http.StatusOK).Log().Payload(&responseData{Sizes: sizes}).Write(w)
+}
+
+// ExportCache is the handler function to export the items of a single cache.
+//
+//	@Summary		Export a cache
+//	@Description		This function call streams the items of such cache as a JSON object (the items keyed by their IDs). The users' secrets (the passphrase hashes, the TOTP secrets, the recovery codes, the lockout state) and e-mail addresses are left out of the export.
+//	@Tags			dump
+//	@Produce		json
+//	@Param			X-Dump-Timestamp	header		string	false	"Unix timestamp of the signed request (see RequireDumpAuth)."
+//	@Param			X-Dump-Nonce		header		string	false	"Random one-time value of the signed request."
+//	@Param			X-Dump-Signature	header		string	false	"HMAC-SHA256 signature of the request, the admin's session is required if not set."
+//	@Param			cacheName		path		string	true	"Name of the cache (e.g. UserCache)."
+//	@Success		200				{object}	map[string]interface{}			"The cache's items."
+//	@Failure		400				{object}	common.APIResponse{data=models.Stub}	"Invalid input data (e.g. malformed signature headers)."
+//	@Failure		403				{object}	common.APIResponse{data=models.Stub}	"Invalid, expired or replayed signature, or the caller lacks the data:manage permission."
+//	@Failure		404				{object}	common.APIResponse{data=models.Stub}	"No such cache."
+//	@Failure		500				{object}	common.APIResponse{data=models.Stub}	"The cache could not be exported."
+//	@Router			/dump/caches/{cacheName} [get]
+func (c *dumpController) ExportCache(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, "dumpController")
+
+	name := chi.URLParam(r, "cacheName")
+
+	// Export into the buffer first, so the error can still be responded with.
+	var buf bytes.Buffer
+
+	err := c.db.ExportCache(name, &buf)
+	if errors.Is(err, errCacheNotFound) {
+		l.Error(err).Status(http.StatusNotFound).Log().Payload(nil).Write(w)
+		return
+	}
+
+	if err != nil {
+		l.Error(err).Status(http.StatusInternalServerError).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// The export contains the sensitive data, so it is recorded.
+	recordDataEvent(r, models.AuditEvent{
+		Action: audit.ActionDataExport,
+		Target: name,
+	})
+
+	l.Msg("ok, exporting cache " + name).Status(http.StatusOK).Log()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = buf.WriteTo(w)
+}
+
+type MigrateRequest struct {
+	// DryRun reports the changes only, nothing is written.
+	DryRun bool `json:"dry_run" example:"false"`
 }
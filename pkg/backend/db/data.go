@@ -6,28 +6,73 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
+	"path"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"go.vxn.dev/littr/pkg/backend/common"
 	"go.vxn.dev/littr/pkg/config"
 	"go.vxn.dev/littr/pkg/models"
 )
 
+// Storage backend names (see config.DataStorageBackend).
 const (
-	pollsFile    = "/opt/data/polls.json"
-	postsFile    = "/opt/data/posts.json"
-	requestsFile = "/opt/data/requests.json"
-	tokensFile   = "/opt/data/tokens.json"
-	usersFile    = "/opt/data/users.json"
+	storageBackendMemory = "memory"
+	storageBackendBolt   = "bolt"
 )
 
+// Repository backend names (see config.DataRepositoryBackend).
+const (
+	repositoryBackendCache  = "cache"
+	repositoryBackendSQLite = "sqlite"
+)
+
+const (
+	apiTokensFile = "/opt/data/api_tokens.json"
+	pollsFile     = "/opt/data/polls.json"
+	postsFile     = "/opt/data/posts.json"
+	requestsFile  = "/opt/data/requests.json"
+	tokensFile    = "/opt/data/tokens.json"
+	usersFile     = "/opt/data/users.json"
+
+	// boltDatabaseFile is the database file of the "bolt" storage backend.
+	boltDatabaseFile = "/opt/data/littr.db"
+
+	// sqliteDatabaseFile is the database file of the "sqlite" repository backend.
+	sqliteDatabaseFile = "/opt/data/littr.sqlite"
+
+	// migrationLedgerFile is the record of the migrations applied (see migration_ledger.go).
+	migrationLedgerFile = "/opt/data/migrations.json"
+
+	// journalDir is the directory to hold the write-ahead journals of the caches.
+	journalDir = "/opt/data"
+)
+
+// journalPath returns the path to the journal of the cache of such name.
+func journalPath(cacheName string) string {
+	return fmt.Sprintf("%s/%s.journal", journalDir, cacheName)
+}
+
 func (d *defaultDatabaseKeeper) LoadAll() (string, error) {
 	db := d.Database()
 
+	// The repositories do not use the caches at all, the snapshots are imported into the SQL database instead (see ImportSnapshots).
+	if d.sqlDB != nil {
+		return "loaded: skipped, the repositories are backed by SQLite", nil
+	}
+
+	// The persistent storage is loaded already, only import the snapshots into empty caches (the very first boot with such backend).
+	if d.persistent {
+		return importSnapshots(db), nil
+	}
+
 	polls := makeLoadReport("polls", wrapLoadOutput(
 		loadOne(db["PollCache"], pollsFile, models.Poll{})))
 
@@ -43,20 +88,49 @@ func (d *defaultDatabaseKeeper) LoadAll() (string, error) {
 	users := makeLoadReport("users", wrapLoadOutput(
 		loadOne(db["UserCache"], usersFile, models.User{})))
 
+	apiTokens := makeLoadReport("API tokens", wrapLoadOutput(
+		loadOne(db["APITokenCache"], apiTokensFile, models.APIToken{})))
+
+	// Replay the journals on top of the loaded snapshots.
+	journals := []string{
+		loadJournal("polls", db["PollCache"], models.Poll{}),
+		loadJournal("posts", db["FlowCache"], models.Post{}),
+		loadJournal("requests", db["RequestCache"], models.Request{}),
+		loadJournal("tokens", db["TokenCache"], models.Token{}),
+		loadJournal("users", db["UserCache"], models.User{}),
+		loadJournal("API tokens", db["APITokenCache"], models.APIToken{}),
+	}
+
 	defer runtime.GC()
 
-	return fmt.Sprintf("loaded: %s, %s, %s, %s, %s", polls, posts, reqs, tokens, users), nil
+	report := fmt.Sprintf("loaded: %s, %s, %s, %s, %s, %s", polls, posts, reqs, tokens, users, apiTokens)
+
+	if journals[0] != "" {
+		report += fmt.Sprintf("; journal replayed: %s", strings.Join(journals, ", "))
+	}
+
+	return report, nil
 }
 
 func (d *defaultDatabaseKeeper) DumpAll() (string, error) {
 	db := d.Database()
 
+	// Every write is committed to the disk immediately = there is nothing to dump.
+	if d.sqlDB != nil {
+		return "dump: skipped, the repositories are backed by SQLite", nil
+	}
+
+	if d.persistent {
+		return "dump: skipped, the storage backend is persistent", nil
+	}
+
 	caches := []Cacher{
 		db["PollCache"],
 		db["FlowCache"],
 		db["RequestCache"],
 		db["TokenCache"],
 		db["UserCache"],
+		db["APITokenCache"],
 	}
 
 	paths := []string{
@@ -65,15 +139,191 @@ func (d *defaultDatabaseKeeper) DumpAll() (string, error) {
 		requestsFile,
 		tokensFile,
 		usersFile,
+		apiTokensFile,
+	}
+
+	snapshotMu.Lock()
+	defer snapshotMu.Unlock()
+
+	// Start new journal segments, so the records made during the dump are kept until the next one.
+	rotated := rotateJournals(caches)
+
+	gen := SnapshotGeneration{
+		CreatedAt: time.Now(),
+		Files:     make(map[string]SnapshotFile),
 	}
+	gen.ID = newSnapshotID(gen.CreatedAt)
 
-	report := runDumpEngine(caches, paths)
+	report, reports := runDumpEngine(caches, paths, gen.ID)
+
+	// Drop the journal segments already contained in the written snapshots.
+	commitJournals(caches, rotated, reports)
+
+	for _, rep := range reports {
+		if rep != nil && rep.Error == nil && rep.File != nil {
+			gen.Files[rep.CacheName] = *rep.File
+		}
+	}
+
+	// Record the new generation, and prune the oldest ones.
+	if len(gen.Files) > 0 {
+		if err := commitSnapshotGeneration(snapshotDir, gen, config.DataSnapshotRetention); err != nil {
+			report += fmt.Sprintf("[manifest] write failed: %s, ", err.Error())
+		} else {
+			report += fmt.Sprintf("[manifest] generation: %s", gen.ID)
+		}
+	}
 
 	defer runtime.GC()
 
 	return fmt.Sprintf("dump: %s", report), nil
 }
 
+// ListSnapshots returns the snapshot generations recorded in the manifest, the newest first.
+func (d *defaultDatabaseKeeper) ListSnapshots() ([]SnapshotGeneration, error) {
+	manifest, err := readSnapshotManifest(snapshotDir)
+	if err != nil {
+		return nil, err
+	}
+
+	gens := manifest.Generations
+
+	sort.Slice(gens, func(i, j int) bool {
+		return gens[i].ID > gens[j].ID
+	})
+
+	return gens, nil
+}
+
+// RestoreSnapshot replaces the caches' contents with the snapshot generation of such ID. Caches missing in the generation are left untouched.
+// The restored state is dumped right away as a new generation.
+func (d *defaultDatabaseKeeper) RestoreSnapshot(id string) (string, error) {
+	if d.sqlDB != nil {
+		return "", errors.New("restore: not supported, the repositories are backed by SQLite")
+	}
+
+	db := d.Database()
+
+	snapshotMu.Lock()
+
+	gen, err := findSnapshotGeneration(snapshotDir, id)
+	if err != nil {
+		snapshotMu.Unlock()
+		return "", err
+	}
+
+	// Verify all the generation's files first, so the caches are not restored partially because of a corrupted file.
+	verify := []error{
+		verifySnapshot(gen, db["PollCache"], models.Poll{}),
+		verifySnapshot(gen, db["FlowCache"], models.Post{}),
+		verifySnapshot(gen, db["RequestCache"], models.Request{}),
+		verifySnapshot(gen, db["TokenCache"], models.Token{}),
+		verifySnapshot(gen, db["UserCache"], models.User{}),
+		verifySnapshot(gen, db["APITokenCache"], models.APIToken{}),
+	}
+
+	if err := errors.Join(verify...); err != nil {
+		snapshotMu.Unlock()
+		return "", err
+	}
+
+	reports := []string{
+		makeRestoreReport("polls", gen, db["PollCache"], models.Poll{}),
+		makeRestoreReport("posts", gen, db["FlowCache"], models.Post{}),
+		makeRestoreReport("requests", gen, db["RequestCache"], models.Request{}),
+		makeRestoreReport("tokens", gen, db["TokenCache"], models.Token{}),
+		makeRestoreReport("users", gen, db["UserCache"], models.User{}),
+		makeRestoreReport("API tokens", gen, db["APITokenCache"], models.APIToken{}),
+	}
+
+	snapshotMu.Unlock()
+
+	report := fmt.Sprintf("restored %s: %s", gen.ID, strings.Join(reports, ", "))
+
+	// The persistent caches do not need to be dumped.
+	if d.persistent {
+		return report, nil
+	}
+
+	dump, err := d.DumpAll()
+	if err != nil {
+		return report, err
+	}
+
+	return fmt.Sprintf("%s; %s", report, dump), nil
+}
+
+// importSnapshots loads the JSON snapshots into the persistent caches, which are empty. Non-empty caches are skipped.
+func importSnapshots(db map[string]Cacher) string {
+	type snapshot struct {
+		name  string
+		cache string
+		load  func(cache Cacher) load
+	}
+
+	snapshots := []snapshot{
+		{"polls", "PollCache", func(c Cacher) load { return wrapLoadOutput(loadOne(c, pollsFile, models.Poll{})) }},
+		{"posts", "FlowCache", func(c Cacher) load { return wrapLoadOutput(loadOne(c, postsFile, models.Post{})) }},
+		{"requests", "RequestCache", func(c Cacher) load { return wrapLoadOutput(loadOne(c, requestsFile, models.Request{})) }},
+		{"tokens", "TokenCache", func(c Cacher) load { return wrapLoadOutput(loadOne(c, tokensFile, models.Token{})) }},
+		{"users", "UserCache", func(c Cacher) load { return wrapLoadOutput(loadOne(c, usersFile, models.User{})) }},
+		{"API tokens", "APITokenCache", func(c Cacher) load { return wrapLoadOutput(loadOne(c, apiTokensFile, models.APIToken{})) }},
+	}
+
+	var reports []string
+
+	for _, snap := range snapshots {
+		cache, ok := db[snap.cache]
+		if !ok {
+			continue
+		}
+
+		if bc, ok := cache.(*BoltCache); ok && bc.Count() > 0 {
+			reports = append(reports, fmt.Sprintf("%d %s (persistent)", bc.Count(), snap.name))
+			continue
+		}
+
+		reports = append(reports, makeLoadReport(snap.name, snap.load(cache)))
+	}
+
+	return fmt.Sprintf("loaded: %s", strings.Join(reports, ", "))
+}
+
+//
+//  helper functions --- restore stack
+//
+
+// verifySnapshot checks the cache's file of such generation (if present at all) can be restored.
+func verifySnapshot[T models.Item](gen *SnapshotGeneration, cache Cacher, model T) error {
+	if cache == nil {
+		return errCacheNotListed
+	}
+
+	if _, ok := gen.Files[cache.GetName()]; !ok {
+		return nil
+	}
+
+	_, err := readSnapshotGeneration(snapshotDir, *gen, cache.GetName(), model)
+	if err != nil {
+		return fmt.Errorf("%s: %w", cache.GetName(), err)
+	}
+
+	return nil
+}
+
+func makeRestoreReport[T models.Item](name string, gen *SnapshotGeneration, cache Cacher, model T) string {
+	if _, ok := gen.Files[cache.GetName()]; !ok {
+		return fmt.Sprintf("%s skipped", name)
+	}
+
+	count, err := restoreOne(cache, snapshotDir, *gen, model)
+	if err != nil {
+		return fmt.Sprintf("%d %s but err: %s", count, name, err.Error())
+	}
+
+	return fmt.Sprintf("%d %s", count, name)
+}
+
 //
 //  helper functions --- loadOne stack
 //
@@ -140,47 +390,42 @@ func loadOne[T models.Item](cache Cacher, filepath string, _ T) (int64, int64, e
 		}
 
 	default:
-		rawData, err := os.ReadFile(filepath)
-		if err != nil {
-			l.Error(err).Status(http.StatusInternalServerError).Log()
-			return count, total, err
-		}
-
-		if string(rawData) == "" {
-			l.Msg("empty data on input").Status(http.StatusBadRequest).Log()
-			return count, total, errors.New("empty data on input")
-		}
+		// Stream the items into the cache one by one, the snapshot format is detected automatically.
+		store := func(key string, val T) error {
+			if key == "" {
+				return nil
+			}
 
-		matrix := &struct {
-			Items map[string]T `json:"items"`
-		}{}
+			if saved := setOne(cache, key, val); !saved {
+				return fmt.Errorf("cannot load item from file '%s' (key: %s)", filepath, key)
+			}
 
-		err = json.Unmarshal(rawData, matrix)
-		if err != nil {
-			l.Error(err).Status(http.StatusInternalServerError).Log()
-			return count, total, err
+			count++
+			return nil
 		}
 
-		total = int64(len(matrix.Items))
+		var err error
 
-		for key, val := range matrix.Items {
-			if key == "" {
-				continue
+		if total, err = decodeSnapshotFile(filepath, *new(T), store); err != nil {
+			l.Msg("could not load the snapshot: " + filepath).Error(err).Status(http.StatusInternalServerError).Log()
+
+			// Fall back to the newest generation passing the checksum verification.
+			gen, genErr := newestValidSnapshot(snapshotDir, cache.GetName())
+			if genErr != nil {
+				l.Msg("no valid snapshot generation found: " + cache.GetName()).Error(genErr).Status(http.StatusInternalServerError).Log()
+				return count, total, err
 			}
 
-			if saved := setOne(cache, key, val); !saved {
-				msg := fmt.Sprintf("cannot load item from file '%s' (key: %s)", filepath, key)
-				l.Msg(msg).Status(http.StatusInternalServerError).Log()
-				return count, total, fmt.Errorf("%s", msg)
+			count = 0
+
+			if total, err = streamSnapshotGeneration(snapshotDir, *gen, cache.GetName(), *new(T), store); err != nil {
+				l.Msg("could not load the snapshot generation: " + gen.ID).Error(err).Status(http.StatusInternalServerError).Log()
+				return count, total, err
 			}
 
-			count++
+			l.Msg(fmt.Sprintf("loaded the snapshot generation %s instead: %s", gen.ID, cache.GetName())).Status(http.StatusOK).Log()
 		}
 
-		matrix = &struct {
-			Items map[string]T `json:"items"`
-		}{}
-
 		// metrics.UpdateCountMetric(cache.GetName(), count, true)
 
 	}
@@ -188,6 +433,79 @@ func loadOne[T models.Item](cache Cacher, filepath string, _ T) (int64, int64, e
 	return count, total, nil
 }
 
+//
+//  helper functions --- journal stack
+//
+
+// loadJournal replays the cache's journal (if the cache is journaled at all) and attaches the journal for the following writes. Returns a short report.
+func loadJournal[T models.Item](name string, cache Cacher, model T) string {
+	l := common.NewLogger(nil, "journal load")
+
+	jc, ok := cache.(*JournalCache)
+	if !ok {
+		return ""
+	}
+
+	count, err := replayJournal(jc, model)
+	if err != nil {
+		l.Msg("journal replay failed: " + cache.GetName()).Error(err).Status(http.StatusInternalServerError).Log()
+	}
+
+	if err := jc.Attach(); err != nil {
+		l.Msg("journal could not be opened, writes are not journaled: " + cache.GetName()).Error(err).Status(http.StatusInternalServerError).Log()
+		return fmt.Sprintf("%d %s (detached)", count, name)
+	}
+
+	return fmt.Sprintf("%d %s", count, name)
+}
+
+// rotateJournals starts new journal segments for all journaled caches. Returns the names of caches rotated successfully.
+func rotateJournals(caches []Cacher) map[string]bool {
+	l := common.NewLogger(nil, "journal rotate")
+
+	rotated := make(map[string]bool)
+
+	for _, cache := range caches {
+		jc, ok := cache.(*JournalCache)
+		if !ok {
+			continue
+		}
+
+		if err := jc.Rotate(); err != nil {
+			l.Msg("journal rotation failed: " + cache.GetName()).Error(err).Status(http.StatusInternalServerError).Log()
+			continue
+		}
+
+		rotated[cache.GetName()] = true
+	}
+
+	return rotated
+}
+
+// commitJournals truncates the rotated journal segments of the caches dumped successfully.
+func commitJournals(caches []Cacher, rotated map[string]bool, reports []*dumpReport) {
+	l := common.NewLogger(nil, "journal commit")
+
+	dumped := make(map[string]bool)
+
+	for _, report := range reports {
+		if report != nil && report.Error == nil {
+			dumped[report.CacheName] = true
+		}
+	}
+
+	for _, cache := range caches {
+		jc, ok := cache.(*JournalCache)
+		if !ok || !rotated[cache.GetName()] || !dumped[cache.GetName()] {
+			continue
+		}
+
+		if err := jc.Commit(); err != nil {
+			l.Msg("journal commit failed: " + cache.GetName()).Error(err).Status(http.StatusInternalServerError).Log()
+		}
+	}
+}
+
 //
 //  helper functions --- dumpOne
 //
@@ -196,6 +514,9 @@ type dumpReport struct {
 	CacheName string
 	Total     int64
 	Error     error
+
+	// File is the manifest record of the generation file written.
+	File *SnapshotFile
 }
 
 /*func prepareDumpReport(cacheName string, rep *dumpReport) string {
@@ -297,9 +618,9 @@ func dumpOne[T models.Item](cache Cacher, filepath string, model T) *dumpReport
 	}
 }*/
 
-func runDumpEngine(caches []Cacher, filePaths []string) string {
+func runDumpEngine(caches []Cacher, filePaths []string, generationID string) (string, []*dumpReport) {
 	if len(caches) != len(filePaths) || len(caches) == 0 {
-		return "input error: check the length of input arrays"
+		return "input error: check the length of input arrays", nil
 	}
 
 	chans := make([]chan interface{}, 0)
@@ -311,13 +632,16 @@ func runDumpEngine(caches []Cacher, filePaths []string) string {
 		chans = append(chans, ch)
 
 		wg.Add(1)
-		go dumpOneRaw(cache, filePaths[j], ch, &wg)
+		go dumpOneRaw(cache, filePaths[j], generationID, ch, &wg)
 	}
 
 	reports := FanInChannels(nil, chans...)
 	wg.Wait()
 
-	var commonReport string
+	var (
+		commonReport string
+		dumpReports  []*dumpReport
+	)
 
 	for raw := range reports {
 		report, ok := raw.(*dumpReport)
@@ -326,6 +650,8 @@ func runDumpEngine(caches []Cacher, filePaths []string) string {
 			continue
 		}
 
+		dumpReports = append(dumpReports, report)
+
 		if report.Error != nil {
 			commonReport += fmt.Sprintf("[%s] dump failed: %d (%s), ", report.CacheName, report.Total, report.Error.Error())
 			continue
@@ -334,10 +660,11 @@ func runDumpEngine(caches []Cacher, filePaths []string) string {
 		commonReport += fmt.Sprintf("[%s] dumped: %d, ", report.CacheName, report.Total)
 	}
 
-	return commonReport
+	return commonReport, dumpReports
 }
 
-func dumpOneRaw(cache Cacher, filepath string, ch chan interface{}, wg *sync.WaitGroup) {
+// dumpOneRaw writes the cache's file of such generation, and then the current snapshot file. Both writes are atomic.
+func dumpOneRaw(cache Cacher, filepath, generationID string, ch chan interface{}, wg *sync.WaitGroup) {
 	mp := cache.Dump()
 	total := len(*mp)
 
@@ -357,22 +684,69 @@ func dumpOneRaw(cache Cacher, filepath string, ch chan interface{}, wg *sync.Wai
 		}
 	}()
 
-	data := struct {
-		Items *GenericMap `json:"items"`
-	}{
-		Items: mp,
+	var (
+		ext   = snapshotExtJSON
+		write func(w io.Writer) error
+	)
+
+	switch config.DataDumpFormat {
+	case dumpFormatJSONLGzip:
+		// Iterate over a shallow copy, the cache is being written to while the (slower) stream is encoded.
+		items, count := cache.Range()
+
+		report.Total = count
+		ext = snapshotExtJSONLGzip
+		write = func(w io.Writer) error {
+			return encodeSnapshotStream(w, items)
+		}
+
+	default:
+		data := struct {
+			Items *GenericMap `json:"items"`
+		}{
+			Items: mp,
+		}
+
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			report.Error = err
+			return
+		}
+
+		write = func(w io.Writer) error {
+			_, err := w.Write(jsonData)
+			return err
+		}
 	}
 
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		report.Error = err
-		return
+	// Write the generation file first. A failure is reported, but the current file is written anyway.
+	file, genErr := writeSnapshotFile(snapshotDir, snapshotFileName(cache.GetName(), generationID, ext), report.Total, write)
+	if genErr == nil {
+		report.File = &file
+
+		// Copy the generation file instead of encoding the data once again.
+		write = func(w io.Writer) error {
+			return copyFile(w, path.Join(snapshotDir, file.Name))
+		}
 	}
 
 	// Write dumped data to the file.
-	err = os.WriteFile(filepath, jsonData, 0o660)
-	if err != nil {
+	if err := writeFileAtomicStream(filepath, 0o660, write); err != nil {
 		report.Error = err
 		return
 	}
+
+	report.Error = genErr
+}
+
+// copyFile streams the contents of the file at such path to the writer.
+func copyFile(w io.Writer, src string) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(w, file)
+	return err
 }
@@ -1,51 +1,33 @@
 package db
 
 import (
+	"net/http"
 	"reflect"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/models"
 )
 
-// getAll function does a fetch-all operation on the given cache instance. After the data retrieval, all items are to be asserted their corresponding types and to be loaded into a map[string]T map, where T is a generic type. This map and number of processed items are returned.
-func getAll[T any](cache Cacher, _ T) (*map[string]T, int64) {
+// getAll function does a fetch-all operation on the given cache instance. All items are asserted their corresponding type T and loaded into a map[string]T map
+// keyed by the cache keys. The items of other type are kept in the cache, but reported to the log, so the loss does not stay unnoticed. This map and the number
+// of processed (valid) items are returned.
+func getAll[T models.Item](cache Cacher, model T) (*map[string]T, int64) {
 	// An initialization check.
 	if cache == nil {
 		return nil, 0
 	}
 
-	// Fetch all items as the map[string]interface{} map.
-	itemsInterface, count := cache.Range()
-
-	// Prepare the data KV map.
 	items := make(map[string]T)
 
-	var control int64
-
-	// Loop over all keyed interfaces and assert type T to every one of them, compose an output map. Make a control counting.
-	for key, rawItem := range *itemsInterface {
-		control++
-
-		// Assert the specific type.
-		item, ok := rawItem.(T)
-		if !ok {
-			// Continue rather than exiting, compare the count values afterwards.
-			//return items, count
-			continue
-		}
+	err := NewTypedCache(cache, model).iterate(func(key string, item T) bool {
 		items[key] = item
+		return true
+	})
+	if err != nil {
+		common.NewLogger(nil, "getAll").Msg("items skipped").Error(err).Status(http.StatusInternalServerError).Log()
 	}
 
-	// Write the count to the associated metric.
-	//metrics.UpdateCountMetric(cache.GetName(), count, true)
-
-	/*defer func() {
-		items = map[string]T{}
-	}()*/
-
-	// Return the lower count, because it reflects the actual valid items count.
-	if control < count {
-		return &items, control
-	}
-
-	return &items, count
+	return &items, int64(len(items))
 }
 
 // getOne fetches just one very item from the given cache instance. As long as the function is generic, the type is asserted automatically, so the type passing is required. Returns the requested item and the its retrieval result as boolean.
@@ -0,0 +1,167 @@
+package db
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/roles"
+	"go.vxn.dev/littr/pkg/config"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Data-intervention requests authentication
+//  The dump routes are accessible to the admins (the data:manage permission), or to the requests signed using the shared secret (API_TOKEN)
+//  like the healthcheck's one (see "littr dump"). The signature is the HMAC-SHA256 of the method, the URI, the timestamp, the nonce, and the
+//  body's checksum. The secret itself is never sent, the timestamp has to be fresh, and each nonce is accepted once.
+//
+
+const (
+	// dumpSignatureTTL is the maximum age (and the clock skew) of the signed request.
+	dumpSignatureTTL = time.Minute * 5
+
+	// maxDumpRequestBody is the size limit of the signed request's body.
+	maxDumpRequestBody = 1 << 20
+
+	loggerDumpAuthName = "dumpAuth"
+)
+
+var (
+	errDumpSignatureDisabled  = errors.New("the signed requests are disabled, no secret set")
+	errDumpSignatureExpired   = errors.New("the request's timestamp is too old, or in the future")
+	errDumpSignatureInvalid   = errors.New("invalid request signature")
+	errDumpSignatureMalformed = errors.New("malformed request signature headers")
+	errDumpSignatureReplayed  = errors.New("such request has been used already")
+)
+
+// dumpNonces holds the nonces of the accepted requests until their timestamps expire.
+var dumpNonces = struct {
+	sync.Mutex
+	seen map[string]time.Time
+}{
+	seen: make(map[string]time.Time),
+}
+
+// SignDumpRequest sets the signature headers of the request. The body has to be the very same as sent with the request.
+func SignDumpRequest(r *http.Request, body []byte, secret string) error {
+	raw := make([]byte, 16)
+
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+
+	nonce := hex.EncodeToString(raw)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	r.Header.Set(common.HDR_DUMP_TIMESTAMP, timestamp)
+	r.Header.Set(common.HDR_DUMP_NONCE, nonce)
+	r.Header.Set(common.HDR_DUMP_SIGNATURE, dumpSignature(secret, r.Method, r.URL.RequestURI(), timestamp, nonce, body))
+
+	return nil
+}
+
+// RequireDumpAuth returns the middleware passing the signed requests, and the requests of the callers holding the data:manage permission.
+func RequireDumpAuth(userRepository models.UserRepositoryInterface) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		guard := roles.RequirePermission(userRepository, roles.PermDataManage)(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The unsigned requests have passed the authentication middleware, so the caller is known.
+			if r.Header.Get(common.HDR_DUMP_SIGNATURE) == "" {
+				guard.ServeHTTP(w, r)
+				return
+			}
+
+			if err := verifyDumpRequest(r, config.DataDumpToken); err != nil {
+				l := common.NewLogger(r, loggerDumpAuthName)
+
+				status := http.StatusForbidden
+				if errors.Is(err, errDumpSignatureMalformed) {
+					status = http.StatusBadRequest
+				}
+
+				l.Msg(err.Error()).Status(status).Log().Payload(nil).Write(w)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// verifyDumpRequest checks the request's signature headers. The body is read, and replaced so it can be read again by the handler.
+func verifyDumpRequest(r *http.Request, secret string) error {
+	if secret == "" {
+		return errDumpSignatureDisabled
+	}
+
+	timestamp := r.Header.Get(common.HDR_DUMP_TIMESTAMP)
+	nonce := r.Header.Get(common.HDR_DUMP_NONCE)
+	signature := r.Header.Get(common.HDR_DUMP_SIGNATURE)
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(nonce) < 16 || len(nonce) > 128 {
+		return errDumpSignatureMalformed
+	}
+
+	issuedAt := time.Unix(unix, 0)
+	if time.Since(issuedAt) > dumpSignatureTTL || time.Until(issuedAt) > dumpSignatureTTL {
+		return errDumpSignatureExpired
+	}
+
+	var body []byte
+
+	if r.Body != nil {
+		if body, err = io.ReadAll(io.LimitReader(r.Body, maxDumpRequestBody)); err != nil {
+			return err
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	expected := dumpSignature(secret, r.Method, r.URL.RequestURI(), timestamp, nonce, body)
+
+	// Constant-time comparison, so the signature cannot be guessed byte by byte.
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errDumpSignatureInvalid
+	}
+
+	// Remember the nonce of the valid request only, so the store cannot be flooded.
+	dumpNonces.Lock()
+	defer dumpNonces.Unlock()
+
+	for seen, expiresAt := range dumpNonces.seen {
+		if time.Now().After(expiresAt) {
+			delete(dumpNonces.seen, seen)
+		}
+	}
+
+	if _, found := dumpNonces.seen[nonce]; found {
+		return errDumpSignatureReplayed
+	}
+
+	dumpNonces.seen[nonce] = issuedAt.Add(dumpSignatureTTL * 2)
+
+	return nil
+}
+
+// dumpSignature returns the hex-encoded HMAC-SHA256 of the request's canonical form.
+func dumpSignature(secret, method, uri, timestamp, nonce string, body []byte) string {
+	bodySum := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%x", method, uri, timestamp, nonce, bodySum)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
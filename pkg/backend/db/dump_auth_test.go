@@ -0,0 +1,197 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/audit"
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/config"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+// adminRepository returns the admin for any nickname.
+type adminRepository struct {
+	common.MockUserRepository
+}
+
+func (m *adminRepository) GetByID(userID string) (*models.User, error) {
+	return &models.User{Nickname: userID, Tags: []string{"admin"}}, nil
+}
+
+func newSignedRequest(t *testing.T, method, target string, body []byte, secret string) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+
+	if err := SignDumpRequest(req, body, secret); err != nil {
+		t.Fatal(err)
+	}
+
+	return req
+}
+
+func TestDumpAuth_Signature(t *testing.T) {
+	const secret = "s3cret"
+
+	body := []byte(`{"generation":"20240101T000000.000Z"}`)
+
+	req := newSignedRequest(t, http.MethodPost, "/api/v1/dump/restore", body, secret)
+	if err := verifyDumpRequest(req, secret); err != nil {
+		t.Fatal(err)
+	}
+
+	// The handler can read the body again.
+	if req.ContentLength != int64(len(body)) {
+		t.Errorf("unexpected content length: %d", req.ContentLength)
+	}
+
+	// The very same request cannot be replayed.
+	replayed := httptest.NewRequest(http.MethodPost, "/api/v1/dump/restore", bytes.NewReader(body))
+	replayed.Header = req.Header.Clone()
+
+	if err := verifyDumpRequest(replayed, secret); err != errDumpSignatureReplayed {
+		t.Errorf("request replayed: %v", err)
+	}
+
+	// Another secret.
+	if err := verifyDumpRequest(newSignedRequest(t, http.MethodGet, "/api/v1/dump", nil, "wrong"), secret); err != errDumpSignatureInvalid {
+		t.Errorf("foreign signature accepted: %v", err)
+	}
+
+	// Tampered body.
+	tampered := newSignedRequest(t, http.MethodPost, "/api/v1/dump/restore", body, secret)
+	tampered.Body = http.NoBody
+
+	if err := verifyDumpRequest(tampered, secret); err != errDumpSignatureInvalid {
+		t.Errorf("tampered body accepted: %v", err)
+	}
+
+	// Another route.
+	moved := newSignedRequest(t, http.MethodGet, "/api/v1/dump/snapshots", nil, secret)
+	moved.URL.Path = "/api/v1/dump/caches/UserCache"
+
+	if err := verifyDumpRequest(moved, secret); err != errDumpSignatureInvalid {
+		t.Errorf("request moved to another route accepted: %v", err)
+	}
+
+	// Stale timestamp, re-signed with the same nonce.
+	stale := newSignedRequest(t, http.MethodGet, "/api/v1/dump", nil, secret)
+	timestamp := strconv.FormatInt(time.Now().Add(-dumpSignatureTTL-time.Minute).Unix(), 10)
+	stale.Header.Set(common.HDR_DUMP_TIMESTAMP, timestamp)
+	stale.Header.Set(common.HDR_DUMP_SIGNATURE, dumpSignature(secret, http.MethodGet, "/api/v1/dump", timestamp, stale.Header.Get(common.HDR_DUMP_NONCE), nil))
+
+	if err := verifyDumpRequest(stale, secret); err != errDumpSignatureExpired {
+		t.Errorf("stale request accepted: %v", err)
+	}
+
+	// No secret set.
+	if err := verifyDumpRequest(newSignedRequest(t, http.MethodGet, "/api/v1/dump", nil, ""), ""); err != errDumpSignatureDisabled {
+		t.Errorf("signature accepted without the secret: %v", err)
+	}
+}
+
+func TestDumpAuth_Middleware(t *testing.T) {
+	secret := config.DataDumpToken
+	config.DataDumpToken = "s3cret"
+
+	t.Cleanup(func() {
+		config.DataDumpToken = secret
+	})
+
+	handler := RequireDumpAuth(&adminRepository{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Neither signed, nor authenticated.
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/dump", nil))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unexpected status: %d", w.Code)
+	}
+
+	// Malformed signature headers.
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/dump", nil)
+	req.Header.Set(common.HDR_DUMP_SIGNATURE, "abc")
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unexpected status: %d", w.Code)
+	}
+
+	// Signed request.
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newSignedRequest(t, http.MethodGet, "/api/v1/dump", nil, "s3cret"))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("signed request refused: %d", w.Code)
+	}
+
+	// The admin's session.
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/dump", nil)
+	req = req.WithContext(context.WithValue(req.Context(), common.ContextUserKeyName, "alice"))
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("admin refused: %d", w.Code)
+	}
+}
+
+// dumpingKeeper reports a successful dump without writing any files.
+type dumpingKeeper struct {
+	DatabaseKeeper
+}
+
+func (k *dumpingKeeper) DumpAll() (string, error) {
+	return "dumped", nil
+}
+
+func TestDumpController_DumpAudited(t *testing.T) {
+	auditLog, err := audit.Open(filepath.Join(t.TempDir(), "audit.jsonl"), 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	audit.SetDefault(auditLog)
+	t.Cleanup(func() { audit.Close() })
+
+	controller := NewDumpController(&dumpingKeeper{}, nil)
+
+	// The signed request, and the admin's session.
+	signed := newSignedRequest(t, http.MethodGet, "/api/v1/dump", nil, "s3cret")
+
+	session := httptest.NewRequest(http.MethodGet, "/api/v1/dump", nil)
+	session = session.WithContext(context.WithValue(session.Context(), common.ContextUserKeyName, "alice"))
+
+	for _, req := range []*http.Request{signed, session} {
+		w := httptest.NewRecorder()
+		controller.DumpAll(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status: %d", w.Code)
+		}
+	}
+
+	events, err := audit.Query(&models.AuditFilter{Action: audit.ActionDataDump})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authorizedBy := make(map[string]string)
+	for _, event := range events {
+		authorizedBy[event.Actor] = event.Details["authorized_by"]
+	}
+
+	if len(events) != 2 || authorizedBy["system"] != "signature" || authorizedBy["alice"] != "data:manage" {
+		t.Errorf("unexpected audit events: %+v", events)
+	}
+}
@@ -0,0 +1,372 @@
+package db
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  PostIndex
+//  The in-memory secondary indexes of the posts: author -> post IDs, parent post -> reply IDs, hashtag -> post IDs, and the time-ordered list
+//  of all post IDs. The indexes are maintained by IndexedPostCache on every Store/Delete, so the flow pages are composed without the full scans.
+//
+
+// hashtagRegexp matches the hashtags in the post's content (#phrase => phrase).
+var hashtagRegexp = regexp.MustCompile(`#([\p{L}\p{N}_]+)`)
+
+// NormalizeHashtag returns the hashtag in its indexed form: lowercased, without the leading # sign.
+func NormalizeHashtag(hashtag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(hashtag), "#"))
+}
+
+// ParseHashtags returns the unique normalized hashtags found in the content.
+func ParseHashtags(content string) []string {
+	var (
+		hashtags []string
+		seen     = make(map[string]bool)
+	)
+
+	for _, match := range hashtagRegexp.FindAllStringSubmatch(content, -1) {
+		hashtag := NormalizeHashtag(match[1])
+
+		if seen[hashtag] {
+			continue
+		}
+
+		seen[hashtag] = true
+		hashtags = append(hashtags, hashtag)
+	}
+
+	return hashtags
+}
+
+// timelineEntry is one record of the time-ordered post list.
+type timelineEntry struct {
+	id        string
+	timestamp time.Time
+}
+
+// before orders the entries by the timestamp, and by the ID for the posts of the very same timestamp.
+func (e timelineEntry) before(other timelineEntry) bool {
+	if e.timestamp.Equal(other.timestamp) {
+		return e.id < other.id
+	}
+
+	return e.timestamp.Before(other.timestamp)
+}
+
+type PostIndex struct {
+	mu sync.RWMutex
+
+	// cache is the indexed cache, used to fetch the posts found.
+	cache Cacher
+
+	byAuthor  map[string]map[string]struct{}
+	byReplyTo map[string]map[string]struct{}
+	byHashtag map[string]map[string]struct{}
+
+	// timeline holds all post IDs ordered from the oldest to the newest one, so the new posts are mostly appended.
+	timeline []timelineEntry
+}
+
+func newPostIndex(cache Cacher) *PostIndex {
+	return &PostIndex{
+		cache:     cache,
+		byAuthor:  make(map[string]map[string]struct{}),
+		byReplyTo: make(map[string]map[string]struct{}),
+		byHashtag: make(map[string]map[string]struct{}),
+	}
+}
+
+// add indexes the post. The caller has to hold the write lock.
+func (x *PostIndex) add(post models.Post) {
+	addToSet(x.byAuthor, post.Nickname, post.ID)
+
+	if post.ReplyToID != "" {
+		addToSet(x.byReplyTo, post.ReplyToID, post.ID)
+	}
+
+	for _, hashtag := range ParseHashtags(post.Content) {
+		addToSet(x.byHashtag, hashtag, post.ID)
+	}
+
+	entry := timelineEntry{id: post.ID, timestamp: post.Timestamp}
+
+	pos := sort.Search(len(x.timeline), func(i int) bool {
+		return !x.timeline[i].before(entry)
+	})
+
+	x.timeline = append(x.timeline, timelineEntry{})
+	copy(x.timeline[pos+1:], x.timeline[pos:])
+	x.timeline[pos] = entry
+}
+
+// remove drops the post from all indexes. The caller has to hold the write lock.
+func (x *PostIndex) remove(post models.Post) {
+	removeFromSet(x.byAuthor, post.Nickname, post.ID)
+
+	if post.ReplyToID != "" {
+		removeFromSet(x.byReplyTo, post.ReplyToID, post.ID)
+	}
+
+	for _, hashtag := range ParseHashtags(post.Content) {
+		removeFromSet(x.byHashtag, hashtag, post.ID)
+	}
+
+	entry := timelineEntry{id: post.ID, timestamp: post.Timestamp}
+
+	pos := sort.Search(len(x.timeline), func(i int) bool {
+		return !x.timeline[i].before(entry)
+	})
+
+	if pos < len(x.timeline) && x.timeline[pos].id == post.ID {
+		x.timeline = append(x.timeline[:pos], x.timeline[pos+1:]...)
+	}
+}
+
+func addToSet(index map[string]map[string]struct{}, key, id string) {
+	set, found := index[key]
+	if !found {
+		set = make(map[string]struct{})
+		index[key] = set
+	}
+
+	set[id] = struct{}{}
+}
+
+func removeFromSet(index map[string]map[string]struct{}, key, id string) {
+	set, found := index[key]
+	if !found {
+		return
+	}
+
+	delete(set, id)
+
+	if len(set) == 0 {
+		delete(index, key)
+	}
+}
+
+func setToSlice(set map[string]struct{}) []string {
+	ids := make([]string, 0, len(set))
+
+	for id := range set {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// Get fetches the post of such ID from the indexed cache.
+func (x *PostIndex) Get(postID string) (models.Post, bool) {
+	rawPost, found := x.cache.Load(postID)
+	if !found {
+		return models.Post{}, false
+	}
+
+	post, ok := rawPost.(models.Post)
+	return post, ok
+}
+
+// ByAuthor returns the IDs of the posts of such author.
+func (x *PostIndex) ByAuthor(nickname string) []string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return setToSlice(x.byAuthor[nickname])
+}
+
+// Replies returns the IDs of the direct replies to such post.
+func (x *PostIndex) Replies(postID string) []string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return setToSlice(x.byReplyTo[postID])
+}
+
+// ReplyCount returns the number of the direct replies to such post.
+func (x *PostIndex) ReplyCount(postID string) int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return len(x.byReplyTo[postID])
+}
+
+// ByHashtag returns the IDs of the posts tagged with such hashtag (see NormalizeHashtag).
+func (x *PostIndex) ByHashtag(hashtag string) []string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return setToSlice(x.byHashtag[NormalizeHashtag(hashtag)])
+}
+
+// Timeline passes the post IDs from the newest to the oldest one to the given function until it returns false.
+// The index is read-locked meanwhile, so the function must not write to the indexed cache.
+func (x *PostIndex) Timeline(fn func(postID string) bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	for i := len(x.timeline) - 1; i >= 0; i-- {
+		if !fn(x.timeline[i].id) {
+			return
+		}
+	}
+}
+
+// Len returns the number of the posts indexed.
+func (x *PostIndex) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return len(x.timeline)
+}
+
+//
+//  IndexedPostCache
+//  Cacher interface implementation wrapping the posts' cache (FlowCache). Every Store/Delete updates the PostIndex together with the wrapped cache.
+//  The wrapper goes under the JournalCache (if any), so the snapshot load and the journal replay are indexed as well.
+//
+
+// PostIndexer is implemented by the caches maintaining the post indexes, and by the wrappers of such caches.
+type PostIndexer interface {
+	PostIndex() *PostIndex
+}
+
+type IndexedPostCache struct {
+	cache Cacher
+	index *PostIndex
+
+	// Serializes the writes, so the index always reflects the wrapped cache's contents.
+	mu sync.Mutex
+}
+
+func NewIndexedPostCache(cache Cacher) *IndexedPostCache {
+	if cache == nil {
+		return nil
+	}
+
+	c := &IndexedPostCache{
+		cache: cache,
+		index: newPostIndex(cache),
+	}
+
+	// Index the items already present (the persistent caches).
+	rawItems, _ := cache.Range()
+
+	for _, rawItem := range *rawItems {
+		if post, ok := rawItem.(models.Post); ok {
+			c.index.add(post)
+		}
+	}
+
+	return c
+}
+
+func (c *IndexedPostCache) Load(key string) (interface{}, bool) {
+	return c.cache.Load(key)
+}
+
+func (c *IndexedPostCache) Store(key string, rawV interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rawOld, found := c.cache.Load(key)
+
+	return c.store(key, rawOld, found, rawV)
+}
+
+func (c *IndexedPostCache) CompareAndSwap(key string, version int64, rawV interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rawOld, found := c.cache.Load(key)
+	if !found || itemVersion(rawOld) != version {
+		return false
+	}
+
+	return c.store(key, rawOld, found, rawV)
+}
+
+// store writes the item to the wrapped cache and reindexes it. The caller has to hold the mutex.
+func (c *IndexedPostCache) store(key string, rawOld interface{}, found bool, rawV interface{}) bool {
+	if !c.cache.Store(key, rawV) {
+		return false
+	}
+
+	c.index.mu.Lock()
+	defer c.index.mu.Unlock()
+
+	if old, ok := rawOld.(models.Post); found && ok {
+		c.index.remove(old)
+	}
+
+	if post, ok := rawV.(models.Post); ok {
+		c.index.add(post)
+	}
+
+	return true
+}
+
+func (c *IndexedPostCache) Delete(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rawOld, found := c.cache.Load(key)
+
+	if !c.cache.Delete(key) {
+		return false
+	}
+
+	if old, ok := rawOld.(models.Post); found && ok {
+		c.index.mu.Lock()
+		c.index.remove(old)
+		c.index.mu.Unlock()
+	}
+
+	return true
+}
+
+func (c *IndexedPostCache) Range() (*GenericMap, int64) {
+	return c.cache.Range()
+}
+
+func (c *IndexedPostCache) Iterate(fn func(key string, rawV interface{}) bool) {
+	if it, ok := c.cache.(Iterator); ok {
+		it.Iterate(fn)
+		return
+	}
+
+	rawItems, _ := c.cache.Range()
+
+	for key, rawV := range *rawItems {
+		if !fn(key, rawV) {
+			return
+		}
+	}
+}
+
+func (c *IndexedPostCache) Count() int64 {
+	if counter, ok := c.cache.(Counter); ok {
+		return counter.Count()
+	}
+
+	_, count := c.cache.Range()
+	return count
+}
+
+func (c *IndexedPostCache) GetName() string {
+	return c.cache.GetName()
+}
+
+func (c *IndexedPostCache) Dump() *GenericMap {
+	return c.cache.Dump()
+}
+
+func (c *IndexedPostCache) PostIndex() *PostIndex {
+	return c.index
+}
@@ -0,0 +1,184 @@
+package db
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Inspection
+//  The read-only procedures for the data-intervention API (see controllers.go): the item counts, and the export of a single cache (or its
+//  SQL table when the repositories are backed by SQLite).
+//
+
+var errCacheNotFound = errors.New("no such cache")
+
+// sqliteTable holds the table of the cache's items, and its primary key column.
+type sqliteTable struct {
+	name string
+	key  string
+}
+
+// cacheTables maps the caches to the SQL tables holding the very same items.
+var cacheTables = map[string]sqliteTable{
+	"APITokenCache": {name: "api_tokens", key: "hash"},
+	"FlowCache":     {name: "posts", key: "id"},
+	"PollCache":     {name: "polls", key: "id"},
+	"RequestCache":  {name: "requests", key: "id"},
+	"TokenCache":    {name: "tokens", key: "hash"},
+	"UserCache":     {name: "users", key: "nickname"},
+}
+
+// CacheSizes returns the count of the items of every cache.
+func (d *defaultDatabaseKeeper) CacheSizes() (map[string]int64, error) {
+	sizes := make(map[string]int64)
+
+	if d.sqlDB != nil {
+		for name, table := range cacheTables {
+			var count int64
+
+			// The table name comes from the fixed list above, not from the user input.
+			if err := d.sqlDB.QueryRow("SELECT COUNT(*) FROM " + table.name).Scan(&count); err != nil {
+				return nil, err
+			}
+
+			sizes[name] = count
+		}
+
+		return sizes, nil
+	}
+
+	for name, cache := range d.Database() {
+		_, count := cache.Range()
+		sizes[name] = count
+	}
+
+	return sizes, nil
+}
+
+// ExportCache writes the items of the cache of such name to the writer as a JSON object (the items keyed by their IDs, sorted).
+func (d *defaultDatabaseKeeper) ExportCache(name string, w io.Writer) error {
+	if _, found := cacheTables[name]; !found {
+		return errCacheNotFound
+	}
+
+	items := make(map[string]json.RawMessage)
+
+	if d.sqlDB != nil {
+		table := cacheTables[name]
+
+		rows, err := d.sqlDB.Query("SELECT " + table.key + ", data FROM " + table.name)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				key  string
+				data []byte
+			)
+
+			if err := rows.Scan(&key, &data); err != nil {
+				return err
+			}
+
+			items[key] = data
+		}
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	} else {
+		cache, found := d.Database()[name]
+		if !found {
+			return errCacheNotFound
+		}
+
+		for key, item := range *cache.Dump() {
+			data, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+
+			items[key] = data
+		}
+	}
+
+	// The users' secrets do not leave the server, not even to the data managers.
+	if name == "UserCache" {
+		for key, data := range items {
+			scrubbed, err := scrubUser(data)
+			if err != nil {
+				return err
+			}
+
+			items[key] = scrubbed
+		}
+	}
+
+	return writeJSONObject(w, items)
+}
+
+// scrubUser drops the credentials, the second factor, the lockout state and the e-mail address from the exported user record.
+func scrubUser(data json.RawMessage) (json.RawMessage, error) {
+	var user models.User
+
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, err
+	}
+
+	common.FlushUserSecrets(&user)
+	user.Email = ""
+
+	return json.Marshal(user)
+}
+
+// writeJSONObject streams the items as one JSON object with the keys sorted, so the exports are comparable.
+func writeJSONObject(w io.Writer, items map[string]json.RawMessage) error {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	bw := bufio.NewWriter(w)
+
+	if _, err := bw.WriteString("{"); err != nil {
+		return err
+	}
+
+	for i, key := range keys {
+		if i > 0 {
+			if _, err := bw.WriteString(","); err != nil {
+				return err
+			}
+		}
+
+		rawKey, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+
+		if _, err := bw.Write(append(rawKey, ':')); err != nil {
+			return err
+		}
+
+		if _, err := bw.Write(items[key]); err != nil {
+			return err
+		}
+	}
+
+	if _, err := bw.WriteString("}"); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+func TestInspect_ExportCache(t *testing.T) {
+	var mu sync.RWMutex
+
+	users := NewSimpleCache("UserCache")
+	users.Store("bob", models.User{Nickname: "bob"})
+	users.Store("alice", models.User{
+		Nickname:      "alice",
+		PassphraseHex: "a1b2c3passphrasehash",
+		Email:         "alice@example.com",
+		TOTPSecret:    "JBSWY3DPEHPK3PXP",
+		TOTPLastStep:  12345,
+		RecoveryCodes: []string{"recoverycodehash"},
+		FailedLogins:  3,
+		FlowList:      map[string]bool{"alice": true},
+	})
+
+	keeper := &defaultDatabaseKeeper{
+		mu:     &mu,
+		caches: []Cacher{users, NewSimpleCache("PollCache")},
+	}
+
+	sizes, err := keeper.CacheSizes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sizes["UserCache"] != 2 || sizes["PollCache"] != 0 {
+		t.Errorf("unexpected sizes: %v", sizes)
+	}
+
+	var buf bytes.Buffer
+
+	if err := keeper.ExportCache("UserCache", &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var exported map[string]models.User
+
+	if err := json.Unmarshal(buf.Bytes(), &exported); err != nil {
+		t.Fatalf("invalid export: %v (%s)", err, buf.String())
+	}
+
+	if len(exported) != 2 || exported["alice"].Nickname != "alice" {
+		t.Errorf("unexpected export: %v", exported)
+	}
+
+	// The secrets are scrubbed, the rest is kept.
+	for _, secret := range []string{"a1b2c3passphrasehash", "alice@example.com", "JBSWY3DPEHPK3PXP", "12345", "recoverycodehash", "failed_logins"} {
+		if bytes.Contains(buf.Bytes(), []byte(secret)) {
+			t.Errorf("secret %q exported: %s", secret, buf.String())
+		}
+	}
+
+	if !exported["alice"].FlowList["alice"] {
+		t.Errorf("flow list not exported: %v", exported["alice"])
+	}
+
+	// The keys are sorted.
+	if !bytes.HasPrefix(buf.Bytes(), []byte(`{"alice":`)) {
+		t.Errorf("unsorted export: %s", buf.String())
+	}
+
+	if err := keeper.ExportCache("NoSuchCache", &buf); err != errCacheNotFound {
+		t.Errorf("unknown cache exported: %v", err)
+	}
+}
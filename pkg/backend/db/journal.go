@@ -0,0 +1,510 @@
+package db
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Journal
+//  An append-only write-ahead log of Store/Delete operations of a single cache. The journal is replayed on top of the last snapshot (see LoadAll)
+//  and rotated away after each successful snapshot write (see DumpAll). Every record is framed as [length uint32][crc32 uint32][JSON payload],
+//  so a torn tail left by a crash in the middle of a write can be detected and cut off on the next replay.
+//
+
+const (
+	journalOpStore  = "store"
+	journalOpDelete = "delete"
+
+	// journalFrameHeaderSize is the length of the record's header (payload length + payload checksum).
+	journalFrameHeaderSize = 8
+
+	// journalMaxRecordSize is a sanity limit to detect garbage in the record's header.
+	journalMaxRecordSize = 64 << 20
+
+	// journalOldSuffix is appended to the journal's path to name the rotated segment waiting for the snapshot to be written.
+	journalOldSuffix = ".old"
+)
+
+var (
+	errJournalClosed      = errors.New("journal is not open")
+	errJournalTornRecord  = errors.New("journal record is incomplete")
+	errJournalBadChecksum = errors.New("journal record checksum mismatch")
+)
+
+type journalRecord struct {
+	// Op is the operation recorded: store, or delete.
+	Op string `json:"op"`
+
+	// Key of the item affected.
+	Key string `json:"key"`
+
+	// Value is the JSON-encoded item (stored items only).
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type Journal struct {
+	// Path to the current journal segment.
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewJournal(path string) *Journal {
+	if path == "" {
+		return nil
+	}
+
+	return &Journal{
+		path: path,
+	}
+}
+
+// Open opens the current journal segment for appending. Records are written straight to the file (unbuffered), so they survive the process being killed.
+func (j *Journal) Open() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file != nil {
+		return nil
+	}
+
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o660)
+	if err != nil {
+		return err
+	}
+
+	j.file = file
+	return nil
+}
+
+// Close closes the current journal segment. Further appends fail until the journal is opened again.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+
+	err := j.file.Close()
+	j.file = nil
+
+	return err
+}
+
+// IsOpen tells whether the journal accepts new records.
+func (j *Journal) IsOpen() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.file != nil
+}
+
+// Append writes a single framed record to the current journal segment.
+func (j *Journal) Append(op, key string, value interface{}) error {
+	record := journalRecord{
+		Op:  op,
+		Key: key,
+	}
+
+	if op == journalOpStore {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		record.Value = raw
+	}
+
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	// Compose the whole frame first to write it using a single syscall.
+	frame := make([]byte, journalFrameHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(payload))
+	copy(frame[journalFrameHeaderSize:], payload)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return errJournalClosed
+	}
+
+	_, err = j.file.Write(frame)
+	return err
+}
+
+// Rotate moves the current segment aside (path.old) and starts a new empty one. If the rotated segment of the previous dump is still present
+// (the dump failed), the current segment is appended to it instead, so no record is ever dropped before its snapshot is written.
+func (j *Journal) Rotate() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	wasOpen := j.file != nil
+
+	if wasOpen {
+		if err := j.file.Close(); err != nil {
+			return err
+		}
+		j.file = nil
+	}
+
+	oldPath := j.path + journalOldSuffix
+
+	if _, err := os.Stat(oldPath); errors.Is(err, os.ErrNotExist) {
+		if err := os.Rename(j.path, oldPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	} else {
+		if err := appendFile(oldPath, j.path); err != nil {
+			return err
+		}
+
+		if err := os.Truncate(j.path, 0); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	if !wasOpen {
+		return nil
+	}
+
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o660)
+	if err != nil {
+		return err
+	}
+
+	j.file = file
+	return nil
+}
+
+// Commit removes the rotated segment as its records are already contained in the last written snapshot.
+func (j *Journal) Commit() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := os.Remove(j.path + journalOldSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Replay reads the rotated and the current segment (in this order) and passes every valid record to the apply function.
+// A torn tail is cut off the segment file, so that new records are not appended behind garbage. Returns the number of records applied.
+func (j *Journal) Replay(apply func(record journalRecord) error) (int64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var count int64
+
+	for _, path := range []string{j.path + journalOldSuffix, j.path} {
+		n, err := replaySegment(path, apply)
+		count += n
+
+		if err != nil {
+			return count, err
+		}
+	}
+
+	return count, nil
+}
+
+// replaySegment reads one segment file record by record. The file is truncated to the last valid record's end when a torn or corrupted record is found.
+func replaySegment(path string, apply func(record journalRecord) error) (int64, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0o660)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+	defer file.Close()
+
+	var (
+		count  int64
+		offset int64
+		reader = bufio.NewReader(file)
+	)
+
+	for {
+		record, size, err := readRecord(reader)
+		if err == io.EOF {
+			return count, nil
+		}
+
+		if errors.Is(err, errJournalTornRecord) || errors.Is(err, errJournalBadChecksum) {
+			// Cut the garbage off, the record has never been completely written.
+			if err := file.Truncate(offset); err != nil {
+				return count, err
+			}
+
+			return count, nil
+		}
+
+		if err != nil {
+			return count, err
+		}
+
+		if err := apply(record); err != nil {
+			return count, fmt.Errorf("%s: key %s: %w", path, record.Key, err)
+		}
+
+		offset += size
+		count++
+	}
+}
+
+// readRecord decodes a single framed record. Returns the record and the number of bytes consumed.
+func readRecord(reader io.Reader) (journalRecord, int64, error) {
+	var (
+		header [journalFrameHeaderSize]byte
+		record journalRecord
+	)
+
+	n, err := io.ReadFull(reader, header[:])
+	if err == io.EOF {
+		return record, 0, io.EOF
+	}
+
+	if err != nil || n != journalFrameHeaderSize {
+		return record, 0, errJournalTornRecord
+	}
+
+	size := binary.BigEndian.Uint32(header[0:4])
+	sum := binary.BigEndian.Uint32(header[4:8])
+
+	if size == 0 || size > journalMaxRecordSize {
+		return record, 0, errJournalBadChecksum
+	}
+
+	payload := make([]byte, size)
+
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return record, 0, errJournalTornRecord
+	}
+
+	if crc32.ChecksumIEEE(payload) != sum {
+		return record, 0, errJournalBadChecksum
+	}
+
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return record, 0, errJournalBadChecksum
+	}
+
+	return record, int64(journalFrameHeaderSize + size), nil
+}
+
+// appendFile copies the contents of the src file to the end of the dst file.
+func appendFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o660)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+//
+//  JournalCache
+//  Cacher interface implementation wrapping another Cacher. Every Store/Delete is recorded in the journal before it is applied to the wrapped cache.
+//  The journal stays detached until the cache is loaded (see LoadAll), so the snapshot load itself is not journaled.
+//
+
+type JournalCache struct {
+	cache   Cacher
+	journal *Journal
+
+	// Serializes the journal append together with the wrapped cache's write, so the journal order always equals the cache order.
+	mu sync.Mutex
+}
+
+func NewJournalCache(cache Cacher, journal *Journal) *JournalCache {
+	if cache == nil || journal == nil {
+		return nil
+	}
+
+	return &JournalCache{
+		cache:   cache,
+		journal: journal,
+	}
+}
+
+func (c *JournalCache) Load(key string) (interface{}, bool) {
+	return c.cache.Load(key)
+}
+
+func (c *JournalCache) Store(key string, rawV interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.journal.IsOpen() {
+		// The write is not durable = refuse it.
+		if err := c.journal.Append(journalOpStore, key, rawV); err != nil {
+			return false
+		}
+	}
+
+	return c.cache.Store(key, rawV)
+}
+
+func (c *JournalCache) CompareAndSwap(key string, version int64, rawV interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// All writes to the wrapped cache go through the mutex, so the check cannot be raced.
+	current, found := c.cache.Load(key)
+	if !found || itemVersion(current) != version {
+		return false
+	}
+
+	if c.journal.IsOpen() {
+		if err := c.journal.Append(journalOpStore, key, rawV); err != nil {
+			return false
+		}
+	}
+
+	return c.cache.Store(key, rawV)
+}
+
+func (c *JournalCache) Delete(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.journal.IsOpen() {
+		if err := c.journal.Append(journalOpDelete, key, nil); err != nil {
+			return false
+		}
+	}
+
+	return c.cache.Delete(key)
+}
+
+func (c *JournalCache) Range() (*GenericMap, int64) {
+	return c.cache.Range()
+}
+
+func (c *JournalCache) Iterate(fn func(key string, rawV interface{}) bool) {
+	if it, ok := c.cache.(Iterator); ok {
+		it.Iterate(fn)
+		return
+	}
+
+	rawItems, _ := c.cache.Range()
+
+	for key, rawV := range *rawItems {
+		if !fn(key, rawV) {
+			return
+		}
+	}
+}
+
+func (c *JournalCache) Count() int64 {
+	if counter, ok := c.cache.(Counter); ok {
+		return counter.Count()
+	}
+
+	_, count := c.cache.Range()
+	return count
+}
+
+func (c *JournalCache) GetName() string {
+	return c.cache.GetName()
+}
+
+func (c *JournalCache) Dump() *GenericMap {
+	return c.cache.Dump()
+}
+
+// PostIndex returns the post indexes of the wrapped cache, nil if the wrapped cache maintains none.
+func (c *JournalCache) PostIndex() *PostIndex {
+	if indexer, ok := c.cache.(PostIndexer); ok {
+		return indexer.PostIndex()
+	}
+
+	return nil
+}
+
+// Attach opens the journal, so the following writes are recorded.
+func (c *JournalCache) Attach() error {
+	return c.journal.Open()
+}
+
+// Detach closes the journal, the following writes are applied to the wrapped cache only.
+func (c *JournalCache) Detach() error {
+	return c.journal.Close()
+}
+
+// Rotate starts a new journal segment. No write can interleave the rotation, so every write lands either in the rotated segment, or in the new one.
+func (c *JournalCache) Rotate() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.journal.Rotate()
+}
+
+// Commit drops the rotated journal segment after the snapshot has been written successfully.
+func (c *JournalCache) Commit() error {
+	return c.journal.Commit()
+}
+
+// replayJournal applies all the journal records on top of the wrapped cache. As long as the function is generic, the stored values are decoded to type T.
+func replayJournal[T models.Item](c *JournalCache, _ T) (int64, error) {
+	if c == nil {
+		return 0, nil
+	}
+
+	return c.journal.Replay(func(record journalRecord) error {
+		switch record.Op {
+		case journalOpStore:
+			var item T
+
+			if err := json.Unmarshal(record.Value, &item); err != nil {
+				return err
+			}
+
+			if !c.cache.Store(record.Key, item) {
+				return fmt.Errorf("could not store the item")
+			}
+
+		case journalOpDelete:
+			c.cache.Delete(record.Key)
+
+		default:
+			return fmt.Errorf("unknown journal operation: %s", record.Op)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+const (
+	envJournalCrashPath = "LITTR_TEST_JOURNAL_CRASH_PATH"
+
+	journalCrashAcks = 300
+)
+
+func newTestJournalCache(t *testing.T, path string) *JournalCache {
+	cache := NewJournalCache(NewSimpleCache("FlowCache"), NewJournal(path))
+	if cache == nil {
+		t.Fatal("nil JournalCache")
+	}
+
+	return cache
+}
+
+// TestJournal_CrashWriter is not a test on its own, it is the child process of TestJournal_CrashRecovery writing into the journal until killed.
+func TestJournal_CrashWriter(t *testing.T) {
+	path := os.Getenv(envJournalCrashPath)
+	if path == "" {
+		t.Skip("helper process only")
+	}
+
+	cache := NewJournalCache(NewSimpleCache("FlowCache"), NewJournal(path))
+	if err := cache.Attach(); err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+
+	for i := 0; ; i++ {
+		key := strconv.Itoa(i)
+
+		if !cache.Store(key, models.Post{ID: key, Nickname: "crasher", Content: "post number " + key}) {
+			fmt.Println("error: store failed")
+			os.Exit(1)
+		}
+
+		// Delete every tenth post to get some delete records in the journal.
+		if i%10 == 9 && !cache.Delete(key) {
+			fmt.Println("error: delete failed")
+			os.Exit(1)
+		}
+
+		// Acknowledge the write only after it has returned.
+		fmt.Println(key)
+	}
+}
+
+func TestJournal_CrashRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "FlowCache.journal")
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestJournal_CrashWriter$")
+	cmd.Env = append(os.Environ(), envJournalCrashPath+"="+path)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read acknowledged writes, then kill the writer in the middle of its work.
+	acked := -1
+	scanner := bufio.NewScanner(stdout)
+
+	for scanner.Scan() {
+		n, err := strconv.Atoi(scanner.Text())
+		if err != nil {
+			t.Fatalf("writer failed: %s", scanner.Text())
+		}
+
+		acked = n
+		if acked >= journalCrashAcks {
+			break
+		}
+	}
+
+	if err := cmd.Process.Kill(); err != nil {
+		t.Fatal(err)
+	}
+	_ = cmd.Wait()
+
+	if acked < journalCrashAcks {
+		t.Fatalf("writer exited too early, acked: %d", acked)
+	}
+
+	// Simulate the torn write on top of whatever the writer managed to write: a record header promising more than present.
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o660)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write([]byte{0, 0, 1, 0, 0xde, 0xad, 0xbe, 0xef, '{', '"', 'o', 'p'}); err != nil {
+		t.Fatal(err)
+	}
+	_ = file.Close()
+
+	// Replay the journal into a fresh cache as the next boot would do.
+	cache := newTestJournalCache(t, path)
+
+	count, err := replayJournal(cache, models.Post{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count < int64(acked) {
+		t.Errorf("too few records replayed: %d (acked: %d)", count, acked)
+	}
+
+	for i := 0; i <= acked; i++ {
+		key := strconv.Itoa(i)
+		raw, found := cache.Load(key)
+
+		// Deleted posts must stay deleted.
+		if i%10 == 9 {
+			if found {
+				t.Errorf("deleted post %s has been replayed", key)
+			}
+			continue
+		}
+
+		if !found {
+			t.Errorf("acked post %s is missing", key)
+			continue
+		}
+
+		post, ok := raw.(models.Post)
+		if !ok || post.Content != "post number "+key {
+			t.Errorf("post %s replayed corrupted: %+v", key, raw)
+		}
+	}
+
+	// The torn tail has to be gone, so that new records are readable after the next crash.
+	if err := cache.Attach(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !cache.Store("after-crash", models.Post{ID: "after-crash"}) {
+		t.Fatal("store after replay failed")
+	}
+	_ = cache.Detach()
+
+	again := newTestJournalCache(t, path)
+
+	if _, err := replayJournal(again, models.Post{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, found := again.Load("after-crash"); !found {
+		t.Error("record appended after the replay is lost")
+	}
+}
+
+func TestJournal_RotateCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "PollCache.journal")
+
+	cache := newTestJournalCache(t, path)
+	if err := cache.Attach(); err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Store("before", models.Poll{ID: "before", Timestamp: time.Now()})
+
+	// The dump starts.
+	if err := cache.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Store("during", models.Poll{ID: "during"})
+
+	// The dump fails = the next rotation has to keep the old segment.
+	cache.Store("failed", models.Poll{ID: "failed"})
+	if err := cache.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	replayed := newTestJournalCache(t, path)
+	if count, err := replayJournal(replayed, models.Poll{}); err != nil || count != 3 {
+		t.Fatalf("expected 3 records replayed, got %d (err: %v)", count, err)
+	}
+
+	// The dump succeeds.
+	if err := cache.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Store("after", models.Poll{ID: "after"})
+	_ = cache.Detach()
+
+	replayed = newTestJournalCache(t, path)
+	if count, err := replayJournal(replayed, models.Poll{}); err != nil || count != 1 {
+		t.Fatalf("expected 1 record replayed, got %d (err: %v)", count, err)
+	}
+
+	if _, found := replayed.Load("after"); !found {
+		t.Error("record written after the commit is lost")
+	}
+}
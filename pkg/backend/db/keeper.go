@@ -1,7 +1,16 @@
 package db
 
 import (
+	"database/sql"
+	"io"
+	"net/http"
 	"sync"
+
+	bolt "go.etcd.io/bbolt"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/config"
+	"go.vxn.dev/littr/pkg/models"
 )
 
 type DatabaseKeeper interface {
@@ -12,11 +21,25 @@ type DatabaseKeeper interface {
 	ReleaseLock()
 
 	RunMigrations() (report string, err error)
+	Migrate(dryRun bool) (report string, err error)
+	RollbackMigration(version int, dryRun bool) (report string, err error)
+	ListMigrations() ([]MigrationStatus, error)
 
 	DumpAll() (report string, err error)
 	LoadAll() (report string, err error)
 
+	ListSnapshots() ([]SnapshotGeneration, error)
+	RestoreSnapshot(id string) (report string, err error)
+
+	CacheSizes() (map[string]int64, error)
+	ExportCache(name string, w io.Writer) error
+
 	Database() map[string]Cacher
+	SQLDatabase() *sql.DB
+
+	Begin() (*Tx, error)
+
+	Close() error
 }
 
 type defaultDatabaseKeeper struct {
@@ -26,6 +49,15 @@ type defaultDatabaseKeeper struct {
 	readonly bool
 
 	caches []Cacher
+
+	// persistent is true when the caches are backed by an on-disk storage, so dumping and loading are not needed.
+	persistent bool
+
+	// boltDB is the embedded key-value store of the "bolt" storage backend (nil otherwise).
+	boltDB *bolt.DB
+
+	// sqlDB is the embedded SQL database of the "sqlite" repository backend (nil otherwise).
+	sqlDB *sql.DB
 }
 
 func NewDatabase() *defaultDatabaseKeeper {
@@ -35,6 +67,7 @@ func NewDatabase() *defaultDatabaseKeeper {
 	)
 
 	names := []string{
+		"APITokenCache",
 		"FlowCache",
 		"PollCache",
 		"RequestCache",
@@ -42,8 +75,35 @@ func NewDatabase() *defaultDatabaseKeeper {
 		"UserCache",
 	}
 
+	// The repositories are backed by the SQL database, the caches are kept (empty) for the cache-level procedures only.
+	if config.DataRepositoryBackend == repositoryBackendSQLite {
+		if keeper := newSQLiteDatabase(&mu, names); keeper != nil {
+			return keeper
+		}
+	}
+
+	// Use the embedded on-disk storage if configured so.
+	if config.DataStorageBackend == storageBackendBolt {
+		if keeper := newBoltDatabase(&mu); keeper != nil {
+			return keeper
+		}
+	}
+
 	for _, name := range names {
-		caches = append(caches, NewSimpleCache(name))
+		var cache Cacher = NewSimpleCache(name)
+
+		// The posts' cache maintains the secondary indexes for the flow pages.
+		if name == "FlowCache" {
+			cache = NewIndexedPostCache(cache)
+		}
+
+		// Wrap the cache with the write-ahead journal, feature-flagged.
+		if config.IsDataJournalEnabled {
+			caches = append(caches, NewJournalCache(cache, NewJournal(journalPath(name))))
+			continue
+		}
+
+		caches = append(caches, cache)
 	}
 
 	return &defaultDatabaseKeeper{
@@ -52,6 +112,69 @@ func NewDatabase() *defaultDatabaseKeeper {
 	}
 }
 
+// newBoltDatabase opens the bbolt database file and prepares a bucket-backed cache for every item type. Returns nil if the file cannot be opened.
+func newBoltDatabase(mu *sync.RWMutex) *defaultDatabaseKeeper {
+	l := common.NewLogger(nil, "database")
+
+	boltDB, err := OpenBoltDatabase(boltDatabaseFile)
+	if err != nil {
+		l.Msg("could not open the bolt database, falling back to the in-memory caches").Error(err).Status(http.StatusInternalServerError).Log()
+		return nil
+	}
+
+	boltCaches := []*BoltCache{
+		NewBoltCache(boltDB, "APITokenCache", models.APIToken{}),
+		NewBoltCache(boltDB, "FlowCache", models.Post{}),
+		NewBoltCache(boltDB, "PollCache", models.Poll{}),
+		NewBoltCache(boltDB, "RequestCache", models.Request{}),
+		NewBoltCache(boltDB, "TokenCache", models.Token{}),
+		NewBoltCache(boltDB, "UserCache", models.User{}),
+	}
+
+	var caches []Cacher
+
+	for _, cache := range boltCaches {
+		if cache == nil {
+			l.Msg("could not prepare the bolt buckets, falling back to the in-memory caches").Status(http.StatusInternalServerError).Log()
+			_ = boltDB.Close()
+			return nil
+		}
+
+		caches = append(caches, cache)
+	}
+
+	return &defaultDatabaseKeeper{
+		mu:         mu,
+		caches:     caches,
+		persistent: true,
+		boltDB:     boltDB,
+	}
+}
+
+// newSQLiteDatabase opens the SQLite database file. Returns nil if the file cannot be opened.
+func newSQLiteDatabase(mu *sync.RWMutex, names []string) *defaultDatabaseKeeper {
+	l := common.NewLogger(nil, "database")
+
+	sqlDB, err := OpenSQLiteDatabase(sqliteDatabaseFile)
+	if err != nil {
+		l.Msg("could not open the SQLite database, falling back to the caches").Error(err).Status(http.StatusInternalServerError).Log()
+		return nil
+	}
+
+	var caches []Cacher
+
+	for _, name := range names {
+		caches = append(caches, NewSimpleCache(name))
+	}
+
+	return &defaultDatabaseKeeper{
+		mu:         mu,
+		caches:     caches,
+		persistent: true,
+		sqlDB:      sqlDB,
+	}
+}
+
 func (d *defaultDatabaseKeeper) ReadLock() {
 	d.mu.RLock()
 }
@@ -86,3 +209,29 @@ func (d *defaultDatabaseKeeper) Database() map[string]Cacher {
 
 	return m
 }
+
+// SQLDatabase returns the SQL database handle of the "sqlite" repository backend, or nil when the repositories are backed by the caches.
+func (d *defaultDatabaseKeeper) SQLDatabase() *sql.DB {
+	return d.sqlDB
+}
+
+// Close releases the underlying storage: the SQLite database file, the bolt database file, or the caches' journals.
+func (d *defaultDatabaseKeeper) Close() error {
+	if d.sqlDB != nil {
+		return d.sqlDB.Close()
+	}
+
+	if d.boltDB != nil {
+		return d.boltDB.Close()
+	}
+
+	for _, cache := range d.caches {
+		if jc, ok := cache.(*JournalCache); ok {
+			if err := jc.Detach(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -1,12 +1,14 @@
 package db
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
-	"fmt"
 	"net/http"
 	"os"
 	"reflect"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -21,177 +23,308 @@ import (
 // migrationFunc declares the unified type for any migration function.
 type migrationFunc func(common.Logger, []interface{}, []Cacher) bool
 
-// migrationProp is a struct to hold the migration function reference, and array of interfaces (mainly pointers) of various length.
-type migrationProp struct {
+// sqlMigrationFunc is the migration function for the SQLite repository backend, the count of the rows changed is returned.
+type sqlMigrationFunc func(tx SQLHandle) (int64, error)
+
+// Migration is a registered migration procedure. The migrations are run in the ascending order of their versions, each one just once
+// unless repeatable. The applied migrations are recorded in the migration ledger (see migration_ledger.go).
+type Migration struct {
+	// Version is the migration's unique ID.
+	Version int `json:"version"`
+
 	// Migration's name.
-	N string
+	Name string `json:"name"`
+
+	// Description is a short summary of the data changed.
+	Description string `json:"description"`
+
+	// Caches are the names of the caches to be modified. Their items are passed to the migration's functions in the same order.
+	Caches []string `json:"caches"`
+
+	// Repeatable migrations (the cleanups) are run on every boot.
+	Repeatable bool `json:"repeatable"`
 
-	// Migration's function handle.
-	F migrationFunc
+	// Fingerprint returns the state of the migration's inputs other than the data (e.g. the config). The migration is run again when it changes.
+	Fingerprint func() string `json:"-"`
 
-	// Migration's resources to process.
-	R []interface{}
+	// Up is the migration's function handle.
+	Up migrationFunc `json:"-"`
 
-	// Caches to be modified.
-	C []Cacher
+	// Down reverts the migration, nil if the migration is irreversible.
+	Down migrationFunc `json:"-"`
+
+	// SQL is the migration's counterpart run with the SQLite repository backend, nil if the migration concerns the caches only.
+	SQL sqlMigrationFunc `json:"-"`
 }
 
-var errCacheNotListed = errors.New("such cache could not be found")
+var (
+	errCacheNotListed        = errors.New("such cache could not be found")
+	errMigrationNotFound     = errors.New("no such migration version")
+	errMigrationNotApplied   = errors.New("such migration has not been applied")
+	errMigrationRepeatable   = errors.New("repeatable migrations cannot be rolled back")
+	errMigrationsUnsupported = errors.New("migrations are not supported by the SQLite repository backend")
+)
+
+// migrations is the registry of the migration procedures. New migrations are appended with the next version, the versions must never be reused.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Name:        "migrateExpiredRequests",
+		Description: "delete the expired requests",
+		Caches:      []string{"RequestCache"},
+		Repeatable:  true,
+		Up:          migrateExpiredRequests,
+		SQL:         sqlDeleteExpiredRequests,
+	},
+	{
+		Version:     2,
+		Name:        "migrateExpiredTokens",
+		Description: "delete the expired tokens",
+		Caches:      []string{"TokenCache"},
+		Repeatable:  true,
+		Up:          migrateExpiredTokens,
+		SQL:         sqlDeleteExpiredTokens,
+	},
+	{
+		Version:     3,
+		Name:        "migrateDeleteBlankDevices",
+		Description: "drop the blank devices of users",
+		Caches:      []string{"UserCache"},
+		Up:          migrateDeleteBlankDevices,
+	},
+	{
+		Version:     4,
+		Name:        "migrateEmptyDeviceTags",
+		Description: "fill the empty device tags",
+		Caches:      []string{"UserCache"},
+		Up:          migrateEmptyDeviceTags,
+	},
+	{
+		Version:     5,
+		Name:        "migrateAvatarURL",
+		Description: "reassign the users' avatars (RUN_AVATAR_MIGRATION)",
+		Caches:      []string{"UserCache"},
+		Fingerprint: func() string { return os.Getenv("RUN_AVATAR_MIGRATION") },
+		Up:          migrateAvatarURL,
+	},
+	{
+		Version:     6,
+		Name:        "migrateFlowPurge",
+		Description: "delete the posts of nonexistent users and the system posts of deleted items",
+		Caches:      []string{"PollCache", "FlowCache", "UserCache"},
+		Up:          migrateFlowPurge,
+	},
+	{
+		Version:     7,
+		Name:        "migrateUserDeletion",
+		Description: "delete the users of the deletion list and their posts",
+		Caches:      []string{"FlowCache", "UserCache"},
+		Fingerprint: func() string { return listFingerprint(config.UserDeletionList) },
+		Up:          migrateUserDeletion,
+	},
+	{
+		Version:     8,
+		Name:        "migrateUserRegisteredTime",
+		Description: "fix the blank registration time of users",
+		Caches:      []string{"UserCache"},
+		Up:          migrateUserRegisteredTime,
+	},
+	{
+		Version:     9,
+		Name:        "migrateUserShadeList",
+		Description: "unfollow the shaded users",
+		Caches:      []string{"UserCache"},
+		Up:          migrateUserShadeList,
+	},
+	{
+		Version:     10,
+		Name:        "migrateUserUnshade",
+		Description: "unshade the users of the unshade list",
+		Caches:      []string{"UserCache"},
+		Fingerprint: func() string { return listFingerprint(config.UsersToUnshade) },
+		Up:          migrateUserUnshade,
+	},
+	{
+		Version:     11,
+		Name:        "migrateBlankAboutText",
+		Description: "set the blank about texts",
+		Caches:      []string{"UserCache"},
+		Up:          migrateBlankAboutText,
+	},
+	{
+		Version:     12,
+		Name:        "migrateSystemFlowOn",
+		Description: "add the system account to everyone's flow",
+		Caches:      []string{"UserCache"},
+		Up:          migrateSystemFlowOn,
+	},
+	{
+		Version:     13,
+		Name:        "migrateUserActiveState",
+		Description: "activate the users registered before Oct 28, 2024",
+		Caches:      []string{"UserCache", "RequestCache"},
+		Up:          migrateUserActiveState,
+	},
+	{
+		Version:     14,
+		Name:        "migrateUserOptions",
+		Description: "fill the users' options map",
+		Caches:      []string{"UserCache"},
+		Up:          migrateUserOptions,
+	},
+	{
+		Version:     15,
+		Name:        "migratePostData",
+		Description: "truncate the posts' data",
+		Caches:      []string{"FlowCache"},
+		Up:          migratePostData,
+	},
+	{
+		Version:     16,
+		Name:        "migrateExpiredAPITokens",
+		Description: "delete the expired API tokens",
+		Caches:      []string{"APITokenCache"},
+		Repeatable:  true,
+		Up:          migrateExpiredAPITokens,
+		SQL:         sqlDeleteExpiredAPITokens,
+	},
+	{
+		Version:     17,
+		Name:        "migratePostReactions",
+		Description: "move the posts' star counts to the reaction counts",
+		Caches:      []string{"FlowCache"},
+		Up:          migratePostReactions,
+		Down:        revertPostReactions,
+	},
+}
 
-// RunMigrations is a wrapper function for the migration registration and execution.
+// listFingerprint returns the checksum of the list's items regardless of their order.
+func listFingerprint(list []string) string {
+	items := append([]string(nil), list...)
+	sort.Strings(items)
+
+	sum := sha256.Sum256([]byte(strings.Join(items, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// RunMigrations runs the pending migrations and records them in the migration ledger.
 func (d *defaultDatabaseKeeper) RunMigrations() (string, error) {
+	return d.Migrate(false)
+}
+
+// Migrate runs the pending migrations. The dry run only reports the writes the migrations would make, nothing is changed, nor recorded.
+func (d *defaultDatabaseKeeper) Migrate(dryRun bool) (string, error) {
 	l := common.NewLogger(nil, "migrations")
 
-	var caches = d.Database()
-
-	pollCache, ok := caches["PollCache"]
-	if !ok {
-		return "", errCacheNotListed
-	}
-	postCache, ok := caches["FlowCache"]
-	if !ok {
-		return "", errCacheNotListed
-	}
-	requestCache, ok := caches["RequestCache"]
-	if !ok {
-		return "", errCacheNotListed
-	}
-	tokenCache, ok := caches["TokenCache"]
-	if !ok {
-		return "", errCacheNotListed
-	}
-	userCache, ok := caches["UserCache"]
-	if !ok {
-		return "", errCacheNotListed
-	}
-
-	// Fetch all the data for the migration procedures.
-	polls, _ := getAll(pollCache, models.Poll{})
-	posts, _ := getAll(postCache, models.Post{})
-	reqs, _ := getAll(requestCache, models.Request{})
-	tokens, _ := getAll(tokenCache, models.Token{})
-	users, _ := getAll(userCache, models.User{})
-
-	// This is/was just to check whether there are any data entering the migration starter.
-	//l.Msg(fmt.Sprintf("counts: posts, %d, reqs: %d, subs: %d, tokens: %d, users: %d", postCount, reqCount, subCount, tokenCount, userCount)).Status(http.StatusOK).Log()
-
-	// Define the migration procedures order.
-	var migrationsOrderedList = []migrationProp{
-		{
-			N: "migrateExpiredReuests",
-			F: migrateExpiredRequests,
-			R: []interface{}{reqs},
-			C: []Cacher{requestCache},
-		},
-		{
-			N: "migrateExpiredTokens",
-			F: migrateExpiredTokens,
-			R: []interface{}{tokens},
-			C: []Cacher{tokenCache},
-		},
-		{
-			N: "migrateDeleteBlankDevices",
-			F: migrateDeleteBlankDevices,
-			R: []interface{}{users},
-			C: []Cacher{userCache},
-		},
-		{
-			N: "migrateEmptyDeviceTags",
-			F: migrateEmptyDeviceTags,
-			R: []interface{}{users},
-			C: []Cacher{userCache},
-		},
-		{
-			N: "migrateAvatarURL",
-			F: migrateAvatarURL,
-			R: []interface{}{users},
-			C: []Cacher{userCache},
-		},
-		{
-			N: "migrateFlowPurge",
-			F: migrateFlowPurge,
-			R: []interface{}{polls, posts, users},
-			C: []Cacher{pollCache, postCache, userCache},
-		},
-		{
-			N: "migrateUserDeletion",
-			F: migrateUserDeletion,
-			R: []interface{}{posts, users},
-			C: []Cacher{postCache, userCache},
-		},
-		{
-			N: "migrateUserRegisteredTime",
-			F: migrateUserRegisteredTime,
-			R: []interface{}{users},
-			C: []Cacher{userCache},
-		},
-		{
-			N: "migrateUserShadeList",
-			F: migrateUserShadeList,
-			R: []interface{}{users},
-			C: []Cacher{userCache},
-		},
-		{
-			N: "migrateUserUnshade",
-			F: migrateUserUnshade,
-			R: []interface{}{users},
-			C: []Cacher{userCache},
-		},
-		{
-			N: "migrateBlankAboutText",
-			F: migrateBlankAboutText,
-			R: []interface{}{users},
-			C: []Cacher{userCache},
-		},
-		{
-			N: "migrateSystemFlowOn",
-			F: migrateSystemFlowOn,
-			R: []interface{}{users},
-			C: []Cacher{userCache},
-		},
-		{
-			N: "migrateUserActiveState",
-			F: migrateUserActiveState,
-			R: []interface{}{users, reqs},
-			C: []Cacher{userCache, requestCache},
-		},
-		{
-			N: "migrateUserOptions",
-			F: migrateUserOptions,
-			R: []interface{}{users},
-			C: []Cacher{userCache},
-		},
-		/*{
-			N: "migratePolls",
-			F: migratePolls,
-			R: []interface{}{&users, &polls},
-		},*/
-		{
-			N: "migratePostData",
-			F: migratePostData,
-			R: []interface{}{posts},
-			C: []Cacher{postCache},
-		},
-	}
-
-	// Declare the migrations report variable.
-	var report string
-
-	// Execute the migration procedures.
-	for _, mig := range migrationsOrderedList {
-		report += fmt.Sprintf("[%s]: %t, ", mig.N, mig.F(l.SetPrefix(mig.N), mig.R, mig.C))
-	}
-
-	//report += fmt.Sprintf("; aftermath: posts: %d, requests: %d, subscriptions: %d, tokens: %d, users: %d", len(posts), len(reqs), len(subs), len(tokens), len(users))
+	ledger, err := readMigrationLedger(migrationLedgerFile)
+	if err != nil {
+		return "", err
+	}
+
+	// The caches are kept empty with the SQLite repositories, so only the migrations having the SQL counterpart are run (the cleanups).
+	if d.sqlDB != nil {
+		report, applied, err := runSQLMigrations(l, migrations, ledger, d.sqlDB, dryRun)
+		if err != nil || dryRun || len(applied) == 0 {
+			return report, err
+		}
+
+		ledger.record(applied...)
+
+		return report, writeMigrationLedger(migrationLedgerFile, ledger)
+	}
+
+	report, applied, err := runMigrations(l, migrations, ledger, d.Database(), dryRun)
+	if err != nil || dryRun || len(applied) == 0 {
+		return report, err
+	}
+
+	// Make sure the migrated data are on the disk before the migrations are recorded as applied.
+	if !d.durable() {
+		if _, err := d.DumpAll(); err != nil {
+			return report, err
+		}
+	}
+
+	ledger.record(applied...)
+
+	if err := writeMigrationLedger(migrationLedgerFile, ledger); err != nil {
+		return report, err
+	}
 
 	// Run the GC to tidy up.
 	runtime.GC()
 
-	// Remove the prefix for the further Logger instance usage.
-	l.RemovePrefix()
 	return report, nil
 }
 
+// RollbackMigration reverts the applied migration of such version (the latest applied one for the zero version), and removes it from the ledger,
+// so it is run again on the next boot. The data of the irreversible migrations are left as they are.
+func (d *defaultDatabaseKeeper) RollbackMigration(version int, dryRun bool) (string, error) {
+	if d.sqlDB != nil {
+		return "", errMigrationsUnsupported
+	}
+
+	l := common.NewLogger(nil, "migrations")
+
+	ledger, err := readMigrationLedger(migrationLedgerFile)
+	if err != nil {
+		return "", err
+	}
+
+	report, err := rollbackMigration(l, migrations, ledger, d.Database(), version, dryRun)
+	if err != nil || dryRun {
+		return report, err
+	}
+
+	if !d.durable() {
+		if _, err := d.DumpAll(); err != nil {
+			return report, err
+		}
+	}
+
+	if err := writeMigrationLedger(migrationLedgerFile, ledger); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// ListMigrations returns all registered migrations together with their state in the ledger.
+func (d *defaultDatabaseKeeper) ListMigrations() ([]MigrationStatus, error) {
+	ledger, err := readMigrationLedger(migrationLedgerFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return listMigrations(migrations, ledger), nil
+}
+
+// durable reports whether every cache write is on the disk immediately: the persistent storage backend, or the journaled caches.
+func (d *defaultDatabaseKeeper) durable() bool {
+	if d.persistent {
+		return true
+	}
+
+	for _, cache := range d.caches {
+		if jc, ok := cache.(*JournalCache); !ok || !jc.journal.IsOpen() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isDryRun reports whether the writes to the caches are only staged (see txCache), so the migration must not make any changes beyond the caches.
+func isDryRun(caches []Cacher) bool {
+	for _, cache := range caches {
+		if _, ok := cache.(*txCache); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
 // migrateExpiredRequests procedure loops over requests and removes those expired already.
 func migrateExpiredRequests(l common.Logger, rawElems []interface{}, caches []Cacher) bool {
 	var reqs *map[string]models.Request
@@ -272,6 +405,67 @@ func migrateExpiredTokens(l common.Logger, rawElems []interface{}, caches []Cach
 	return true
 }
 
+// migrateExpiredAPITokens procedure deletes the API tokens past their expiration time.
+func migrateExpiredAPITokens(l common.Logger, rawElems []interface{}, caches []Cacher) bool {
+	var tokens *map[string]models.APIToken
+
+	// Assert pointers from the interface array.
+	for _, raw := range rawElems {
+		elem, ok := raw.(*map[string]models.APIToken)
+		if ok {
+			tokens = elem
+			continue
+		}
+	}
+
+	// Exit if the tokens pointer is nil.
+	if tokens == nil {
+		l.Msg("API tokens are nil").Status(http.StatusInternalServerError).Log()
+		return false
+	}
+
+	for hash, token := range *tokens {
+		if !token.IsExpired() {
+			continue
+		}
+
+		if deleted := deleteOne(caches[0], hash); !deleted {
+			l.Msg("could not delete API token: " + hash).Status(http.StatusInternalServerError).Log()
+			return false
+		}
+
+		// Delete from the tokens map locally within the migrations.
+		delete(*tokens, hash)
+	}
+
+	return true
+}
+
+// sqlDeleteExpiredRequests is the SQLite counterpart of migrateExpiredRequests.
+func sqlDeleteExpiredRequests(tx SQLHandle) (int64, error) {
+	return sqlDeleteRows(tx, "DELETE FROM requests WHERE created_at < ?", time.Now().Add(-24*time.Hour).UnixNano())
+}
+
+// sqlDeleteExpiredTokens is the SQLite counterpart of migrateExpiredTokens.
+func sqlDeleteExpiredTokens(tx SQLHandle) (int64, error) {
+	return sqlDeleteRows(tx, "DELETE FROM tokens WHERE created_at < ?", time.Now().Add(-common.TokenTTL).UnixNano())
+}
+
+// sqlDeleteExpiredAPITokens is the SQLite counterpart of migrateExpiredAPITokens.
+func sqlDeleteExpiredAPITokens(tx SQLHandle) (int64, error) {
+	return sqlDeleteRows(tx, "DELETE FROM api_tokens WHERE expires_at < ?", time.Now().UnixNano())
+}
+
+// sqlDeleteRows runs the DELETE statement, the count of the rows deleted is returned.
+func sqlDeleteRows(tx SQLHandle, query string, args ...any) (int64, error) {
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
 // migrateDeleteBlankDevices procedure ensures blank devices are omitted from the user's device list in SubscriptionCache.
 func migrateDeleteBlankDevices(l common.Logger, rawElems []interface{}, caches []Cacher) bool {
 	var users *map[string]models.User
@@ -523,7 +717,7 @@ func migrateFlowPurge(l common.Logger, rawElems []interface{}, caches []Cacher)
 			}
 
 			// Delete associated image and its thumbnail.
-			if post.Figure != "" {
+			if post.Figure != "" && !isDryRun(caches) {
 				err := os.Remove("/opt/pix/thumb_" + post.Figure)
 				if err != nil {
 					l.Msg(common.ERR_POST_DELETE_THUMB).Status(http.StatusInternalServerError).Error(err).Log()
@@ -596,13 +790,13 @@ func migrateUserDeletion(l common.Logger, rawElems []interface{}, caches []Cache
 	for key, post := range *posts {
 		if helpers.Contains(*bank, post.Nickname) {
 			// Delete the post from the Flow database.
-			if deleted := deleteOne(caches[1], key); !deleted {
+			if deleted := deleteOne(caches[0], key); !deleted {
 				l.Msg("cannot delete a post: " + key).Status(http.StatusInternalServerError).Log()
 				return false
 			}
 
 			// Delete associated image and its thumbnail.
-			if post.Figure != "" {
+			if post.Figure != "" && !isDryRun(caches) {
 				err := os.Remove("/opt/pix/thumb_" + post.Figure)
 				if err != nil {
 					l.Msg(common.ERR_POST_DELETE_THUMB).Status(http.StatusInternalServerError).Error(err).Log()
@@ -905,7 +1099,7 @@ func migrateUserActiveState(l common.Logger, rawElems []interface{}, caches []Ca
 				(*users)[req.Nickname].Options["active"]) {
 
 			// Delete the misdeleted request.
-			if deleted := deleteOne(caches[0], key); !deleted {
+			if deleted := deleteOne(caches[1], key); !deleted {
 				l.Msg("cannot delete the request: " + key).Status(http.StatusInternalServerError).Log()
 				return false
 			}
@@ -938,7 +1132,7 @@ func migrateUserActiveState(l common.Logger, rawElems []interface{}, caches []Ca
 			user.Options["active"] = true
 
 			// Update the user in the User database.
-			if saved := setOne(caches[1], key, user); !saved {
+			if saved := setOne(caches[0], key, user); !saved {
 				l.Msg("cannot save an user: " + key).Status(http.StatusInternalServerError).Log()
 				return false
 			}
@@ -1113,3 +1307,57 @@ func migratePostData(l common.Logger, rawElems []interface{}, caches []Cacher) b
 
 	return true
 }
+
+// migratePostReactions procedure moves the anonymous star counts of the posts to the per-kind reaction counts (see models.Post.ReactionCounts).
+func migratePostReactions(l common.Logger, rawElems []interface{}, caches []Cacher) bool {
+	posts, ok := rawElems[0].(*map[string]models.Post)
+	if !ok || posts == nil {
+		l.Msg("posts are nil").Status(http.StatusInternalServerError).Log()
+		return false
+	}
+
+	for key, post := range *posts {
+		// Migrated already, or nothing to migrate.
+		if post.ReactionCounts != nil || post.ReactionCount == 0 {
+			continue
+		}
+
+		post.ReactionCounts = map[string]int64{models.ReactionStar: post.ReactionCount}
+
+		if saved := setOne(caches[0], key, post); !saved {
+			l.Msg("cannot migrate the post's reactions").Status(http.StatusInternalServerError).Log()
+			return false
+		}
+
+		(*posts)[key] = post
+	}
+
+	return true
+}
+
+// revertPostReactions procedure drops the posts' reactions, the total counts are kept as the star counts.
+func revertPostReactions(l common.Logger, rawElems []interface{}, caches []Cacher) bool {
+	posts, ok := rawElems[0].(*map[string]models.Post)
+	if !ok || posts == nil {
+		l.Msg("posts are nil").Status(http.StatusInternalServerError).Log()
+		return false
+	}
+
+	for key, post := range *posts {
+		if post.ReactionCounts == nil && post.Reactions == nil {
+			continue
+		}
+
+		post.Reactions = nil
+		post.ReactionCounts = nil
+
+		if saved := setOne(caches[0], key, post); !saved {
+			l.Msg("cannot revert the post's reactions").Status(http.StatusInternalServerError).Log()
+			return false
+		}
+
+		(*posts)[key] = post
+	}
+
+	return true
+}
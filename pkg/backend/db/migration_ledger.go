@@ -0,0 +1,513 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Migration ledger
+//  The record of the applied migrations persisted alongside the data (/opt/data/migrations.json). The migrations recorded are skipped on boot,
+//  unless repeatable, or their fingerprint has changed. The dry run stages the migrations' writes in a txCache layer per migration, so the writes
+//  can be reported without touching the caches.
+//
+
+type MigrationRecord struct {
+	// Version of the migration applied.
+	Version int `json:"version"`
+
+	// Name of the migration applied.
+	Name string `json:"name"`
+
+	// AppliedAt is the time of the last successful run.
+	AppliedAt time.Time `json:"applied_at"`
+
+	// Fingerprint is the migration's fingerprint at the time of the run (see Migration.Fingerprint).
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// MigrationStatus is the registered migration together with its ledger record (nil if not applied yet).
+type MigrationStatus struct {
+	Migration
+
+	Record *MigrationRecord `json:"record,omitempty"`
+
+	// Pending is true when the migration is to be run on the next boot.
+	Pending bool `json:"pending"`
+}
+
+type migrationLedger struct {
+	// Applied migrations ordered by their version.
+	Applied []MigrationRecord `json:"applied"`
+}
+
+// readMigrationLedger reads the ledger file, a missing file is an empty ledger.
+func readMigrationLedger(path string) (*migrationLedger, error) {
+	ledger := &migrationLedger{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ledger, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, ledger); err != nil {
+		return nil, fmt.Errorf("migration ledger corrupted: %w", err)
+	}
+
+	return ledger, nil
+}
+
+func writeMigrationLedger(path string, ledger *migrationLedger) error {
+	data, err := json.MarshalIndent(ledger, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, data, 0600)
+}
+
+// find returns the record of such version, nil if not applied.
+func (lg *migrationLedger) find(version int) *MigrationRecord {
+	for i := range lg.Applied {
+		if lg.Applied[i].Version == version {
+			return &lg.Applied[i]
+		}
+	}
+
+	return nil
+}
+
+// record adds (or replaces) the records, and keeps them ordered by the version.
+func (lg *migrationLedger) record(records ...MigrationRecord) {
+	for _, rec := range records {
+		if existing := lg.find(rec.Version); existing != nil {
+			*existing = rec
+			continue
+		}
+
+		lg.Applied = append(lg.Applied, rec)
+	}
+
+	sort.Slice(lg.Applied, func(i, j int) bool {
+		return lg.Applied[i].Version < lg.Applied[j].Version
+	})
+}
+
+func (lg *migrationLedger) remove(version int) {
+	for i := range lg.Applied {
+		if lg.Applied[i].Version == version {
+			lg.Applied = append(lg.Applied[:i], lg.Applied[i+1:]...)
+			return
+		}
+	}
+}
+
+func (m Migration) fingerprint() string {
+	if m.Fingerprint == nil {
+		return ""
+	}
+
+	return m.Fingerprint()
+}
+
+// isPending reports whether the migration is to be run according to the ledger.
+func (m Migration) isPending(ledger *migrationLedger) bool {
+	if m.Repeatable {
+		return true
+	}
+
+	rec := ledger.find(m.Version)
+
+	return rec == nil || rec.Fingerprint != m.fingerprint()
+}
+
+func listMigrations(list []Migration, ledger *migrationLedger) []MigrationStatus {
+	var statuses []MigrationStatus
+
+	for _, mig := range list {
+		status := MigrationStatus{
+			Migration: mig,
+			Pending:   mig.isPending(ledger),
+		}
+
+		if rec := ledger.find(mig.Version); rec != nil {
+			recCopy := *rec
+			status.Record = &recCopy
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// migrationData loads the caches' items for the migrations lazily, so only the caches of the pending migrations are read. The items are shared
+// by the migrations, which keep them in sync with their writes.
+type migrationData struct {
+	caches map[string]Cacher
+	items  map[string]interface{}
+
+	// clone makes the items independent of the cached ones (the dry run), as the migrations modify the items' maps and slices in place.
+	clone bool
+}
+
+func (md *migrationData) load(name string) (interface{}, error) {
+	if items, found := md.items[name]; found {
+		return items, nil
+	}
+
+	cache, found := md.caches[name]
+	if !found || cache == nil {
+		return nil, fmt.Errorf("%s: %w", name, errCacheNotListed)
+	}
+
+	var (
+		items interface{}
+		err   error
+	)
+
+	switch name {
+	case "APITokenCache":
+		items, err = loadMigrationItems(cache, models.APIToken{}, md.clone)
+	case "FlowCache":
+		items, err = loadMigrationItems(cache, models.Post{}, md.clone)
+	case "PollCache":
+		items, err = loadMigrationItems(cache, models.Poll{}, md.clone)
+	case "RequestCache":
+		items, err = loadMigrationItems(cache, models.Request{}, md.clone)
+	case "TokenCache":
+		items, err = loadMigrationItems(cache, models.Token{}, md.clone)
+	case "UserCache":
+		items, err = loadMigrationItems(cache, models.User{}, md.clone)
+	default:
+		return nil, fmt.Errorf("%s: %w", name, errCacheNotListed)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	md.items[name] = items
+	return items, nil
+}
+
+func loadMigrationItems[T models.Item](cache Cacher, model T, clone bool) (*map[string]T, error) {
+	items, _ := getAll(cache, model)
+	if !clone {
+		return items, nil
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+
+	cloned := make(map[string]T)
+
+	if err := json.Unmarshal(data, &cloned); err != nil {
+		return nil, err
+	}
+
+	return &cloned, nil
+}
+
+// migrationView holds the caches the migrations write to: the actual caches, or the txCache layers of the dry run.
+type migrationView map[string]Cacher
+
+// layer wraps the caches of such names with a new txCache layer, and returns the layers.
+func (v migrationView) layer(names []string) []*txCache {
+	var layers []*txCache
+
+	for _, name := range names {
+		if v[name] == nil {
+			continue
+		}
+
+		tc := newTxCache(v[name])
+		v[name] = tc
+		layers = append(layers, tc)
+	}
+
+	return layers
+}
+
+func (v migrationView) resolve(names []string) ([]Cacher, error) {
+	var caches []Cacher
+
+	for _, name := range names {
+		cache, found := v[name]
+		if !found || cache == nil {
+			return nil, fmt.Errorf("%s: %w", name, errCacheNotListed)
+		}
+
+		caches = append(caches, cache)
+	}
+
+	return caches, nil
+}
+
+// prepareMigration returns the items and the caches of such names to be passed to the migration's function.
+func prepareMigration(view migrationView, data *migrationData, names []string) ([]interface{}, []Cacher, error) {
+	caches, err := view.resolve(names)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rawElems []interface{}
+
+	for _, name := range names {
+		items, err := data.load(name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rawElems = append(rawElems, items)
+	}
+
+	return rawElems, caches, nil
+}
+
+// stagedReport summarizes the writes staged in the layers.
+func stagedReport(layers []*txCache) string {
+	var parts []string
+
+	for _, layer := range layers {
+		var stored, deleted int
+
+		layer.mu.RLock()
+		for _, write := range layer.writes {
+			if write.deleted {
+				deleted++
+				continue
+			}
+
+			stored++
+		}
+		layer.mu.RUnlock()
+
+		parts = append(parts, fmt.Sprintf("%s: %d stored, %d deleted", layer.GetName(), stored, deleted))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// runMigrations runs the pending migrations of the list on the caches. The records of the migrations applied successfully are returned to be saved
+// in the ledger, the failed ones are run again on the next boot.
+func runMigrations(l common.Logger, list []Migration, ledger *migrationLedger, caches map[string]Cacher, dryRun bool) (string, []MigrationRecord, error) {
+	var (
+		applied []MigrationRecord
+		reports []string
+		skipped int
+	)
+
+	view := make(migrationView)
+	for name, cache := range caches {
+		view[name] = cache
+	}
+
+	data := &migrationData{
+		caches: caches,
+		items:  make(map[string]interface{}),
+		clone:  dryRun,
+	}
+
+	// Remove the prefix for the further Logger instance usage.
+	defer l.RemovePrefix()
+
+	for _, mig := range list {
+		if !mig.isPending(ledger) {
+			skipped++
+			continue
+		}
+
+		var layers []*txCache
+		if dryRun {
+			layers = view.layer(mig.Caches)
+		}
+
+		rawElems, migCaches, err := prepareMigration(view, data, mig.Caches)
+		if err != nil {
+			return "", nil, err
+		}
+
+		ok := mig.Up(l.SetPrefix(mig.Name), rawElems, migCaches)
+
+		switch {
+		case dryRun:
+			reports = append(reports, fmt.Sprintf("[%s]: %t (%s)", mig.Name, ok, stagedReport(layers)))
+		default:
+			reports = append(reports, fmt.Sprintf("[%s]: %t", mig.Name, ok))
+		}
+
+		if ok {
+			applied = append(applied, MigrationRecord{
+				Version:     mig.Version,
+				Name:        mig.Name,
+				AppliedAt:   time.Now(),
+				Fingerprint: mig.fingerprint(),
+			})
+		}
+	}
+
+	prefix := "migrations"
+	if dryRun {
+		prefix = "migrations (dry run)"
+	}
+
+	return fmt.Sprintf("%s: %s; skipped (applied already): %d", prefix, strings.Join(reports, ", "), skipped), applied, nil
+}
+
+// runSQLMigrations runs the pending migrations of the list having the SQL counterpart, each one in its own SQL transaction. The dry run rolls
+// the transactions back. The records of the migrations applied successfully are returned to be saved in the ledger.
+func runSQLMigrations(l common.Logger, list []Migration, ledger *migrationLedger, sqlDB *sql.DB, dryRun bool) (string, []MigrationRecord, error) {
+	var (
+		applied []MigrationRecord
+		reports []string
+		skipped int
+	)
+
+	for _, mig := range list {
+		if mig.SQL == nil || !mig.isPending(ledger) {
+			skipped++
+			continue
+		}
+
+		tx, err := sqlDB.Begin()
+		if err != nil {
+			return "", nil, err
+		}
+
+		affected, err := mig.SQL(tx)
+
+		if err != nil || dryRun {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+
+		if err != nil {
+			l.SetPrefix(mig.Name).Msg("SQL migration failed").Error(err).Status(http.StatusInternalServerError).Log()
+			reports = append(reports, fmt.Sprintf("[%s]: false", mig.Name))
+			continue
+		}
+
+		reports = append(reports, fmt.Sprintf("[%s]: true (%d rows)", mig.Name, affected))
+
+		applied = append(applied, MigrationRecord{
+			Version:     mig.Version,
+			Name:        mig.Name,
+			AppliedAt:   time.Now(),
+			Fingerprint: mig.fingerprint(),
+		})
+	}
+
+	l.RemovePrefix()
+
+	prefix := "migrations (SQLite)"
+	if dryRun {
+		prefix = "migrations (SQLite, dry run)"
+	}
+
+	return fmt.Sprintf("%s: %s; skipped (applied already, or caches only): %d", prefix, strings.Join(reports, ", "), skipped), applied, nil
+}
+
+// rollbackMigration runs the migration's Down function (if any), and removes the migration from the ledger. The zero version stands for
+// the latest migration applied.
+func rollbackMigration(l common.Logger, list []Migration, ledger *migrationLedger, caches map[string]Cacher, version int, dryRun bool) (string, error) {
+	if version == 0 {
+		for i := len(ledger.Applied) - 1; i >= 0; i-- {
+			if mig := findMigration(list, ledger.Applied[i].Version); mig != nil && !mig.Repeatable {
+				version = mig.Version
+				break
+			}
+		}
+	}
+
+	mig := findMigration(list, version)
+	if mig == nil {
+		return "", fmt.Errorf("%d: %w", version, errMigrationNotFound)
+	}
+
+	if mig.Repeatable {
+		return "", fmt.Errorf("%s: %w", mig.Name, errMigrationRepeatable)
+	}
+
+	if ledger.find(mig.Version) == nil {
+		return "", fmt.Errorf("%s: %w", mig.Name, errMigrationNotApplied)
+	}
+
+	report := fmt.Sprintf("rollback [%s]: ", mig.Name)
+
+	if dryRun {
+		report = fmt.Sprintf("rollback (dry run) [%s]: ", mig.Name)
+	}
+
+	if mig.Down != nil {
+		view := make(migrationView)
+		for name, cache := range caches {
+			view[name] = cache
+		}
+
+		var layers []*txCache
+		if dryRun {
+			layers = view.layer(mig.Caches)
+		}
+
+		data := &migrationData{
+			caches: caches,
+			items:  make(map[string]interface{}),
+			clone:  dryRun,
+		}
+
+		rawElems, migCaches, err := prepareMigration(view, data, mig.Caches)
+		if err != nil {
+			return "", err
+		}
+
+		defer l.RemovePrefix()
+
+		if !mig.Down(l.SetPrefix(mig.Name), rawElems, migCaches) {
+			return "", fmt.Errorf("%s: the rollback failed", mig.Name)
+		}
+
+		if dryRun {
+			report += fmt.Sprintf("reverted (%s), ", stagedReport(layers))
+		} else {
+			report += "reverted, "
+		}
+	} else {
+		report += "irreversible, the data are left as they are, "
+	}
+
+	if dryRun {
+		return report + "kept in the ledger", nil
+	}
+
+	ledger.remove(mig.Version)
+
+	return report + "removed from the ledger", nil
+}
+
+func findMigration(list []Migration, version int) *Migration {
+	for i := range list {
+		if list[i].Version == version {
+			return &list[i]
+		}
+	}
+
+	return nil
+}
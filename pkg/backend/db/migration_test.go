@@ -1 +1,241 @@
 package db
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+func TestMigrations_Registry(t *testing.T) {
+	versions := make(map[int]bool)
+
+	for i, mig := range migrations {
+		if mig.Up == nil || mig.Name == "" || len(mig.Caches) == 0 {
+			t.Errorf("migration %d is incomplete", mig.Version)
+		}
+
+		if versions[mig.Version] || (i > 0 && mig.Version <= migrations[i-1].Version) {
+			t.Errorf("migration %d: the versions must be unique and ascending", mig.Version)
+		}
+
+		versions[mig.Version] = true
+	}
+}
+
+func newTestMigrations(runs *int, fingerprint *string) []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Name:        "migrateAboutText",
+			Caches:      []string{"UserCache"},
+			Fingerprint: func() string { return *fingerprint },
+			Up: func(l common.Logger, rawElems []interface{}, caches []Cacher) bool {
+				*runs++
+
+				users := rawElems[0].(*map[string]models.User)
+
+				for key, user := range *users {
+					user.About = "migrated"
+
+					if !setOne(caches[0], key, user) {
+						return false
+					}
+
+					(*users)[key] = user
+				}
+
+				return true
+			},
+		},
+	}
+}
+
+func TestMigrations_Ledger(t *testing.T) {
+	l := common.NewLogger(nil, "migrations")
+	path := filepath.Join(t.TempDir(), "migrations.json")
+
+	userCache := NewSimpleCache("UserCache")
+	userCache.Store("alice", models.User{Nickname: "alice"})
+
+	caches := map[string]Cacher{"UserCache": userCache}
+
+	var (
+		runs        int
+		fingerprint = "a"
+		list        = newTestMigrations(&runs, &fingerprint)
+	)
+
+	ledger, err := readMigrationLedger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The dry run reports the writes, but changes nothing.
+	report, applied, err := runMigrations(l, list, ledger, caches, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(applied) != 1 || !strings.Contains(report, "UserCache: 1 stored, 0 deleted") {
+		t.Errorf("unexpected dry run report: %s", report)
+	}
+
+	if rawUser, _ := userCache.Load("alice"); rawUser.(models.User).About != "" {
+		t.Error("the dry run changed the cache")
+	}
+
+	// The real run.
+	if _, applied, err = runMigrations(l, list, ledger, caches, false); err != nil {
+		t.Fatal(err)
+	}
+
+	ledger.record(applied...)
+
+	if err := writeMigrationLedger(path, ledger); err != nil {
+		t.Fatal(err)
+	}
+
+	if rawUser, _ := userCache.Load("alice"); rawUser.(models.User).About != "migrated" {
+		t.Error("the migration has not been applied")
+	}
+
+	// The applied migration is skipped on the next boot.
+	if ledger, err = readMigrationLedger(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err = runMigrations(l, list, ledger, caches, false); err != nil || runs != 2 {
+		t.Errorf("the applied migration has been run again: %d runs, %v", runs, err)
+	}
+
+	// The changed fingerprint makes the migration pending again.
+	fingerprint = "b"
+
+	if statuses := listMigrations(list, ledger); !statuses[0].Pending || statuses[0].Record == nil {
+		t.Error("the migration of the changed fingerprint is not pending")
+	}
+
+	// The irreversible migration's rollback only removes the ledger record.
+	if _, err := rollbackMigration(l, list, ledger, caches, 0, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if ledger.find(1) != nil {
+		t.Error("the rolled back migration is still in the ledger")
+	}
+
+	if _, err := rollbackMigration(l, list, ledger, caches, 1, false); err == nil {
+		t.Error("the rollback of the migration not applied succeeded")
+	}
+}
+
+func TestMigrations_PostReactions(t *testing.T) {
+	l := common.NewLogger(nil, "migrations")
+
+	cache := NewSimpleCache("FlowCache")
+	posts := map[string]models.Post{
+		"1": {ID: "1", ReactionCount: 3},
+		"2": {ID: "2"},
+	}
+
+	for key, post := range posts {
+		cache.Store(key, post)
+	}
+
+	if !migratePostReactions(l, []interface{}{&posts}, []Cacher{cache}) {
+		t.Fatal("migration failed")
+	}
+
+	raw, _ := cache.Load("1")
+	if post := raw.(models.Post); post.ReactionCounts[models.ReactionStar] != 3 || post.ReactionCount != 3 {
+		t.Errorf("stars not migrated: %+v", post)
+	}
+
+	raw, _ = cache.Load("2")
+	if post := raw.(models.Post); post.ReactionCounts != nil {
+		t.Errorf("post without stars migrated: %+v", post)
+	}
+
+	if !revertPostReactions(l, []interface{}{&posts}, []Cacher{cache}) {
+		t.Fatal("rollback failed")
+	}
+
+	raw, _ = cache.Load("1")
+	if post := raw.(models.Post); post.ReactionCounts != nil || post.ReactionCount != 3 {
+		t.Errorf("reactions not reverted: %+v", post)
+	}
+}
+
+func TestMigrations_SQLiteCleanups(t *testing.T) {
+	l := common.NewLogger(nil, "migrations")
+
+	sqlDB, err := OpenSQLiteDatabase(filepath.Join(t.TempDir(), "littr.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	now := time.Now()
+
+	// One expired and one valid item of each kind.
+	rows := []struct {
+		query string
+		key   string
+		at    time.Time
+	}{
+		{"INSERT INTO requests (id, nickname, created_at, data) VALUES (?, 'alice', ?, '{}')", "expired", now.Add(-48 * time.Hour)},
+		{"INSERT INTO requests (id, nickname, created_at, data) VALUES (?, 'alice', ?, '{}')", "valid", now},
+		{"INSERT INTO tokens (hash, nickname, created_at, data) VALUES (?, 'alice', ?, '{}')", "expired", now.Add(-common.TokenTTL - time.Hour)},
+		{"INSERT INTO tokens (hash, nickname, created_at, data) VALUES (?, 'alice', ?, '{}')", "valid", now},
+		{"INSERT INTO api_tokens (hash, nickname, expires_at, data) VALUES (?, 'alice', ?, '{}')", "expired", now.Add(-time.Hour)},
+		{"INSERT INTO api_tokens (hash, nickname, expires_at, data) VALUES (?, 'alice', ?, '{}')", "valid", now.Add(time.Hour)},
+	}
+
+	for _, row := range rows {
+		if _, err := sqlDB.Exec(row.query, row.key, row.at.UnixNano()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count := func() (total int) {
+		for _, table := range []string{"requests", "tokens", "api_tokens"} {
+			var n int
+			if err := sqlDB.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+				t.Fatal(err)
+			}
+			total += n
+		}
+		return total
+	}
+
+	ledger := &migrationLedger{}
+
+	// The dry run deletes nothing.
+	if _, _, err := runSQLMigrations(l, migrations, ledger, sqlDB, true); err != nil || count() != 6 {
+		t.Fatalf("the dry run changed the database: %d rows left, %v", count(), err)
+	}
+
+	report, applied, err := runSQLMigrations(l, migrations, ledger, sqlDB, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count() != 3 {
+		t.Errorf("expired items left: %d rows, report: %s", count(), report)
+	}
+
+	// Only the cleanups are run, and recorded.
+	var versions []int
+	for _, record := range applied {
+		versions = append(versions, record.Version)
+	}
+
+	if !reflect.DeepEqual(versions, []int{1, 2, 16}) {
+		t.Errorf("unexpected migrations applied: %v", versions)
+	}
+}
@@ -9,9 +9,12 @@ import (
 //
 
 type Repositories struct {
-	PollRepository models.PollRepositoryInterface
-	PostRepository models.PostRepositoryInterface
-	UserRepository models.UserRepositoryInterface
+	APITokenRepository models.APITokenRepositoryInterface
+	PollRepository     models.PollRepositoryInterface
+	PostRepository     models.PostRepositoryInterface
+	RequestRepository  models.RequestRepositoryInterface
+	TokenRepository    models.TokenRepositoryInterface
+	UserRepository     models.UserRepositoryInterface
 }
 
 var Storage *Repositories
@@ -2,13 +2,24 @@
 package db
 
 import (
+	"go.vxn.dev/littr/pkg/models"
+
 	chi "github.com/go-chi/chi/v5"
 )
 
-func NewDumpRouter(controller *dumpController) chi.Router {
+// NewDumpRouter returns the router of the data-intervention routes, all of them guarded by RequireDumpAuth.
+func NewDumpRouter(controller *dumpController, userRepository models.UserRepositoryInterface) chi.Router {
 	r := chi.NewRouter()
 
+	r.Use(RequireDumpAuth(userRepository))
+
 	r.Get("/", controller.DumpAll)
+	r.Get("/caches", controller.CacheSizes)
+	r.Get("/caches/{cacheName}", controller.ExportCache)
+	r.Post("/load", controller.LoadAll)
+	r.Post("/migrate", controller.Migrate)
+	r.Get("/snapshots", controller.ListSnapshots)
+	r.Post("/restore", controller.RestoreSnapshot)
 
 	return r
 }
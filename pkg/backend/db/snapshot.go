@@ -0,0 +1,379 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Snapshots
+//  Every DumpAll writes a new generation of the snapshot files into the snapshot directory, and updates the current snapshot files (/opt/data/*.json).
+//  All files are written atomically (temp file, fsync, rename). The manifest holds the checksum of every generation file, so LoadAll can fall back
+//  to the newest valid generation when the current file is corrupted. Only the newest config.DataSnapshotRetention generations are kept.
+//
+
+const (
+	// snapshotDir is the directory to hold the snapshot generations and their manifest.
+	snapshotDir = "/opt/data/snapshots"
+
+	// snapshotManifestName is the name of the manifest file in the snapshot directory.
+	snapshotManifestName = "manifest.json"
+
+	// snapshotIDLayout is used to name the generations, sortable as a string.
+	snapshotIDLayout = "20060102T150405.000Z"
+)
+
+var (
+	errSnapshotNotFound    = errors.New("no such snapshot generation")
+	errSnapshotBadChecksum = errors.New("snapshot file checksum mismatch")
+)
+
+// snapshotMu serializes the generation writes and restores, as DumpAll can be called by the timer, the API and the shutdown procedure at once.
+var snapshotMu sync.Mutex
+
+type SnapshotGeneration struct {
+	// ID is the generation's timestamp formatted using snapshotIDLayout.
+	ID string `json:"id"`
+
+	// CreatedAt is the time of the dump.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Files is the map of the generation's files keyed by the cache name.
+	Files map[string]SnapshotFile `json:"files"`
+}
+
+type SnapshotFile struct {
+	// Name of the file in the snapshot directory.
+	Name string `json:"name"`
+
+	// SHA256 is the hex-encoded checksum of the file's contents.
+	SHA256 string `json:"sha256"`
+
+	// Size of the file in bytes.
+	Size int64 `json:"size"`
+
+	// Items is the number of cache items dumped.
+	Items int64 `json:"items"`
+}
+
+type snapshotManifest struct {
+	// Generations ordered from the oldest to the newest one.
+	Generations []SnapshotGeneration `json:"generations"`
+}
+
+// newSnapshotID returns a new generation ID of the given time.
+func newSnapshotID(t time.Time) string {
+	return t.UTC().Format(snapshotIDLayout)
+}
+
+// snapshotFileName returns the name of the cache's file in such generation. The extension reflects the file's format.
+func snapshotFileName(cacheName, id, ext string) string {
+	return fmt.Sprintf("%s.%s%s", cacheName, id, ext)
+}
+
+// writeFileAtomic writes the data to a temporary file in the target's directory, syncs it to the disk and renames it over the target,
+// so the target file is always either the old, or the new complete version.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return writeFileAtomicStream(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// writeFileAtomicStream is the streaming variant of writeFileAtomic, the contents are written by the given function.
+func writeFileAtomicStream(path string, perm os.FileMode, write func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	// Remove the temporary file on any failure, it is renamed away on success.
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if err := write(tmp); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// Persist the rename itself.
+	return syncDir(dir)
+}
+
+// syncDir flushes the directory entries to the disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
+// checksumFile returns the hex-encoded SHA256 sum of the file's contents without reading the file into memory.
+func checksumFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// countingWriter counts the bytes written through.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+// writeSnapshotFile writes one cache's generation file of such name, the contents are written by the given function. Returns the file's manifest record.
+func writeSnapshotFile(dir, name string, items int64, write func(w io.Writer) error) (SnapshotFile, error) {
+	file := SnapshotFile{
+		Name:  name,
+		Items: items,
+	}
+
+	if err := os.MkdirAll(dir, 0o770); err != nil {
+		return file, err
+	}
+
+	hash := sha256.New()
+
+	err := writeFileAtomicStream(filepath.Join(dir, file.Name), 0o660, func(w io.Writer) error {
+		cw := &countingWriter{w: io.MultiWriter(w, hash)}
+
+		if err := write(cw); err != nil {
+			return err
+		}
+
+		file.Size = cw.n
+		return nil
+	})
+	if err != nil {
+		return file, err
+	}
+
+	file.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return file, nil
+}
+
+// readSnapshotManifest reads the manifest of the snapshot directory. A missing manifest is an empty one.
+func readSnapshotManifest(dir string) (*snapshotManifest, error) {
+	manifest := &snapshotManifest{}
+
+	raw, err := os.ReadFile(filepath.Join(dir, snapshotManifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// commitSnapshotGeneration records the generation in the manifest and prunes the generations over the retention limit (the files included).
+func commitSnapshotGeneration(dir string, gen SnapshotGeneration, retention int) error {
+	manifest, err := readSnapshotManifest(dir)
+	if err != nil {
+		return err
+	}
+
+	manifest.Generations = append(manifest.Generations, gen)
+
+	sort.Slice(manifest.Generations, func(i, j int) bool {
+		return manifest.Generations[i].ID < manifest.Generations[j].ID
+	})
+
+	var pruned []SnapshotGeneration
+
+	if retention > 0 && len(manifest.Generations) > retention {
+		pruned = manifest.Generations[:len(manifest.Generations)-retention]
+		manifest.Generations = manifest.Generations[len(manifest.Generations)-retention:]
+	}
+
+	raw, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	// Write the manifest first, so it never points to a deleted file.
+	if err := writeFileAtomic(filepath.Join(dir, snapshotManifestName), raw, 0o660); err != nil {
+		return err
+	}
+
+	for _, old := range pruned {
+		for _, file := range old.Files {
+			if err := os.Remove(filepath.Join(dir, file.Name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// verifySnapshotGeneration checks the cache's file of such generation against the manifest checksum.
+func verifySnapshotGeneration(dir string, gen SnapshotGeneration, cacheName string) (string, error) {
+	file, ok := gen.Files[cacheName]
+	if !ok {
+		return "", errSnapshotNotFound
+	}
+
+	path := filepath.Join(dir, file.Name)
+
+	sum, err := checksumFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	if sum != file.SHA256 {
+		return "", errSnapshotBadChecksum
+	}
+
+	return path, nil
+}
+
+// streamSnapshotGeneration verifies the cache's file of such generation, and streams its items to the given function.
+func streamSnapshotGeneration[T models.Item](dir string, gen SnapshotGeneration, cacheName string, model T, fn func(key string, item T) error) (int64, error) {
+	path, err := verifySnapshotGeneration(dir, gen, cacheName)
+	if err != nil {
+		return 0, err
+	}
+
+	return decodeSnapshotFile(path, model, fn)
+}
+
+// readSnapshotGeneration reads and verifies the cache's file of such generation.
+func readSnapshotGeneration[T models.Item](dir string, gen SnapshotGeneration, cacheName string, model T) (map[string]T, error) {
+	items := make(map[string]T)
+
+	_, err := streamSnapshotGeneration(dir, gen, cacheName, model, func(key string, item T) error {
+		items[key] = item
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// newestValidSnapshot walks the generations from the newest one and returns the first one with the cache's file passing the checksum verification.
+func newestValidSnapshot(dir, cacheName string) (*SnapshotGeneration, error) {
+	manifest, err := readSnapshotManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(manifest.Generations) - 1; i >= 0; i-- {
+		gen := manifest.Generations[i]
+
+		if _, err := verifySnapshotGeneration(dir, gen, cacheName); err != nil {
+			continue
+		}
+
+		return &gen, nil
+	}
+
+	return nil, errSnapshotNotFound
+}
+
+// findSnapshotGeneration returns the generation of such ID from the manifest.
+func findSnapshotGeneration(dir, id string) (*SnapshotGeneration, error) {
+	manifest, err := readSnapshotManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, gen := range manifest.Generations {
+		if gen.ID == id {
+			return &gen, nil
+		}
+	}
+
+	return nil, errSnapshotNotFound
+}
+
+// restoreOne replaces the cache's contents with the cache's file of such generation. The file is verified before the cache is touched.
+func restoreOne[T models.Item](cache Cacher, dir string, gen SnapshotGeneration, model T) (int64, error) {
+	items, err := readSnapshotGeneration(dir, gen, cache.GetName(), model)
+	if err != nil {
+		return 0, err
+	}
+
+	current, _ := cache.Range()
+
+	for key := range *current {
+		if _, ok := items[key]; ok {
+			continue
+		}
+
+		if !cache.Delete(key) {
+			return 0, fmt.Errorf("could not delete item %s from %s", key, cache.GetName())
+		}
+	}
+
+	var count int64
+
+	for key, item := range items {
+		if !cache.Store(key, item) {
+			return count, fmt.Errorf("could not store item %s to %s", key, cache.GetName())
+		}
+
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+func writeTestGeneration(t *testing.T, dir string, at time.Time, content string) SnapshotGeneration {
+	items := map[string]models.Post{"1": {ID: "1", Content: content}}
+
+	data, err := json.Marshal(struct {
+		Items map[string]models.Post `json:"items"`
+	}{items})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gen := SnapshotGeneration{
+		ID:        newSnapshotID(at),
+		CreatedAt: at,
+		Files:     make(map[string]SnapshotFile),
+	}
+
+	file, err := writeSnapshotFile(dir, snapshotFileName("FlowCache", gen.ID, snapshotExtJSON), 1, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gen.Files["FlowCache"] = file
+
+	if err := commitSnapshotGeneration(dir, gen, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	return gen
+}
+
+func TestSnapshot_RetentionAndFallback(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	var gens []SnapshotGeneration
+
+	for i := 0; i < 5; i++ {
+		gens = append(gens, writeTestGeneration(t, dir, now.Add(time.Duration(i)*time.Second), "generation "+strconv.Itoa(i)))
+	}
+
+	manifest, err := readSnapshotManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(manifest.Generations) != 3 || manifest.Generations[0].ID != gens[2].ID {
+		t.Fatalf("expected the 3 newest generations kept, got %+v", manifest.Generations)
+	}
+
+	// The pruned generation's file has to be gone.
+	if _, err := os.Stat(filepath.Join(dir, gens[0].Files["FlowCache"].Name)); !os.IsNotExist(err) {
+		t.Errorf("pruned generation file still present: %v", err)
+	}
+
+	// Corrupt the newest generation, the previous one has to be loaded instead.
+	if err := os.WriteFile(filepath.Join(dir, gens[4].Files["FlowCache"].Name), []byte(`{"items":{"1":{"id":"1","conte`), 0o660); err != nil {
+		t.Fatal(err)
+	}
+
+	gen, err := newestValidSnapshot(dir, "FlowCache")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := readSnapshotGeneration(dir, *gen, "FlowCache", models.Post{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gen.ID != gens[3].ID || items["1"].Content != "generation 3" {
+		t.Errorf("expected generation %s, got %s (%+v)", gens[3].ID, gen.ID, items)
+	}
+}
+
+func TestSnapshot_WriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "posts.json")
+
+	for _, data := range []string{"first", "second"} {
+		if err := writeFileAtomic(path, []byte(data), 0o660); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil || string(raw) != "second" {
+		t.Errorf("unexpected contents: %q (err: %v)", raw, err)
+	}
+
+	// No temporary files may be left behind.
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("expected a single file, got %d", len(entries))
+	}
+}
+
+func TestSnapshot_StreamFormats(t *testing.T) {
+	items := GenericMap{
+		"1": models.Post{ID: "1", Content: "first"},
+		"2": models.Post{ID: "2", Content: "second"},
+	}
+
+	legacy, err := json.Marshal(struct {
+		Items *GenericMap `json:"items"`
+	}{&items})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stream bytes.Buffer
+
+	if err := encodeSnapshotStream(&stream, &items); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both formats have to be detected and decoded to the very same items.
+	for name, raw := range map[string][]byte{"legacy": legacy, "stream": stream.Bytes()} {
+		decoded := make(map[string]models.Post)
+
+		total, err := decodeSnapshotStream(bytes.NewReader(raw), models.Post{}, func(key string, item models.Post) error {
+			decoded[key] = item
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+
+		if total != 2 || decoded["2"].Content != "second" {
+			t.Errorf("%s: unexpected items decoded: %+v", name, decoded)
+		}
+	}
+
+	// A truncated stream must not pass silently.
+	truncated := stream.Bytes()[:stream.Len()-4]
+
+	if _, err := decodeSnapshotStream(bytes.NewReader(truncated), models.Post{}, func(string, models.Post) error { return nil }); err == nil {
+		t.Error("truncated stream decoded without an error")
+	}
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	// Pure-Go SQLite driver registered as "sqlite".
+	_ "modernc.org/sqlite"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  SQLite
+//  The embedded SQL database to back the repositories (see the SQLite*Repository types in the posts, polls, requests, tokens and users packages).
+//  Every item is stored as a JSON document together with the columns needed for the indexed lookups (nickname, timestamp, reply_to_id).
+//
+
+// sqliteSchema is applied on every open, so the statements have to be idempotent.
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS posts (
+		id          TEXT PRIMARY KEY,
+		nickname    TEXT NOT NULL,
+		timestamp   INTEGER NOT NULL,
+		reply_to_id TEXT NOT NULL DEFAULT '',
+		data        BLOB NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS posts_nickname_timestamp ON posts (nickname, timestamp)`,
+	`CREATE INDEX IF NOT EXISTS posts_timestamp ON posts (timestamp)`,
+	`CREATE INDEX IF NOT EXISTS posts_reply_to_id ON posts (reply_to_id)`,
+
+	// The hashtag index has been read by nothing, the flow pages filter the hashtags themselves.
+	`DROP TABLE IF EXISTS post_hashtags`,
+
+	`CREATE TABLE IF NOT EXISTS polls (
+		id        TEXT PRIMARY KEY,
+		author    TEXT NOT NULL,
+		timestamp INTEGER NOT NULL,
+		data      BLOB NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS polls_author_timestamp ON polls (author, timestamp)`,
+
+	`CREATE TABLE IF NOT EXISTS requests (
+		id         TEXT PRIMARY KEY,
+		nickname   TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		data       BLOB NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS requests_nickname ON requests (nickname)`,
+
+	`CREATE TABLE IF NOT EXISTS tokens (
+		hash       TEXT PRIMARY KEY,
+		nickname   TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		data       BLOB NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS tokens_nickname ON tokens (nickname)`,
+
+	`CREATE TABLE IF NOT EXISTS api_tokens (
+		hash       TEXT PRIMARY KEY,
+		nickname   TEXT NOT NULL,
+		expires_at INTEGER NOT NULL,
+		data       BLOB NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS api_tokens_nickname ON api_tokens (nickname)`,
+
+	`CREATE TABLE IF NOT EXISTS users (
+		nickname TEXT PRIMARY KEY,
+		email    TEXT NOT NULL DEFAULT '',
+		data     BLOB NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS users_email ON users (email)`,
+}
+
+// OpenSQLiteDatabase opens (or creates) the SQLite database file at the given path and applies the schema.
+func OpenSQLiteDatabase(path string) (*sql.DB, error) {
+	// WAL lets the readers run concurrently with the writer, the busy timeout makes the concurrent writers wait instead of failing.
+	dsn := fmt.Sprintf("file:%s?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_pragma=synchronous(NORMAL)", path)
+
+	sqlDB, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stmt := range sqliteSchema {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			_ = sqlDB.Close()
+			return nil, err
+		}
+	}
+
+	return sqlDB, nil
+}
+
+// ImportSnapshots is the one-shot importer of the JSON snapshots (/opt/data/*.json) into the SQLite database. The items are saved using the given
+// repositories, so the secondary columns and tables are filled in the very same way as on any regular write. Non-empty tables are skipped.
+func ImportSnapshots(sqlDB *sql.DB, repos *Repositories) (string, error) {
+	if sqlDB == nil || repos == nil {
+		return "", errors.New("import: no SQLite database to import to")
+	}
+
+	reports := []string{
+		importSnapshot(sqlDB, "polls", pollsFile, models.Poll{}, func(item models.Poll) error { return repos.PollRepository.Save(&item) }),
+		importSnapshot(sqlDB, "posts", postsFile, models.Post{}, func(item models.Post) error { return repos.PostRepository.Save(&item) }),
+		importSnapshot(sqlDB, "requests", requestsFile, models.Request{}, func(item models.Request) error { return repos.RequestRepository.Save(&item) }),
+		importSnapshot(sqlDB, "tokens", tokensFile, models.Token{}, func(item models.Token) error { return repos.TokenRepository.Save(&item) }),
+		importSnapshot(sqlDB, "users", usersFile, models.User{}, func(item models.User) error { return repos.UserRepository.Save(&item) }),
+		importSnapshot(sqlDB, "api_tokens", apiTokensFile, models.APIToken{}, func(item models.APIToken) error { return repos.APITokenRepository.Save(&item) }),
+	}
+
+	return fmt.Sprintf("imported: %s", strings.Join(reports, ", ")), nil
+}
+
+// importSnapshot loads one snapshot file into the table of such name using the save function. Returns a short report.
+func importSnapshot[T models.Item](sqlDB *sql.DB, table, filepath string, _ T, save func(item T) error) string {
+	l := common.NewLogger(nil, "data import")
+
+	var count int64
+
+	// The table name comes from the fixed list above, not from the user input.
+	if err := sqlDB.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+		return makeLoadReport(table, load{err: err})
+	}
+
+	if count > 0 {
+		return fmt.Sprintf("%d %s (persistent)", count, table)
+	}
+
+	items, err := readSnapshot(filepath, *new(T))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Sprintf("0 %s (no snapshot)", table)
+		}
+
+		l.Msg("could not read the snapshot: " + filepath).Error(err).Status(http.StatusInternalServerError).Log()
+		return makeLoadReport(table, load{err: err})
+	}
+
+	ld := load{total: int64(len(items))}
+
+	for key, item := range items {
+		if key == "" {
+			continue
+		}
+
+		if err := save(item); err != nil {
+			l.Msg(fmt.Sprintf("cannot import item from file '%s' (key: %s)", filepath, key)).Error(err).Status(http.StatusInternalServerError).Log()
+			ld.err = err
+			break
+		}
+
+		ld.count++
+	}
+
+	return makeLoadReport(table, ld)
+}
+
+// readSnapshot decodes the snapshot file of any supported format (see decodeSnapshotStream).
+func readSnapshot[T models.Item](filepath string, model T) (map[string]T, error) {
+	items := make(map[string]T)
+
+	if _, err := decodeSnapshotFile(filepath, model, func(key string, item T) error {
+		items[key] = item
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
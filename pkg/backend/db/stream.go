@@ -0,0 +1,147 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Streaming snapshot format
+//  The gzip-compressed JSON Lines (DATA_DUMP_FORMAT=JSONL_GZ), one {"key":...,"value":...} record per line. The records are encoded and decoded
+//  one by one, so neither the dump, nor the load has to hold the whole encoded snapshot in memory. The format of any snapshot file is detected
+//  on load by its leading bytes, so the plain JSON snapshots ({"items":{...}}) are still loadable.
+//
+
+const (
+	// dumpFormatJSONLGzip is the DATA_DUMP_FORMAT value of the streaming format.
+	dumpFormatJSONLGzip = "JSONL_GZ"
+
+	// snapshotExtJSON and snapshotExtJSONLGzip are the snapshot generation files' extensions.
+	snapshotExtJSON      = ".json"
+	snapshotExtJSONLGzip = ".jsonl.gz"
+)
+
+// gzipMagic are the leading bytes of any gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+type snapshotRecord struct {
+	// Key of the item in the cache.
+	Key string `json:"key"`
+
+	// Value is the JSON-encoded item.
+	Value json.RawMessage `json:"value"`
+}
+
+// encodeSnapshotStream writes the items as the gzip-compressed JSON Lines records.
+func encodeSnapshotStream(w io.Writer, items *GenericMap) error {
+	zw := gzip.NewWriter(w)
+	enc := json.NewEncoder(zw)
+
+	for key, item := range *items {
+		value, err := json.Marshal(item)
+		if err != nil {
+			_ = zw.Close()
+			return fmt.Errorf("key %s: %w", key, err)
+		}
+
+		// The encoder terminates every record with a newline.
+		if err := enc.Encode(snapshotRecord{Key: key, Value: value}); err != nil {
+			_ = zw.Close()
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// decodeSnapshotStream reads the snapshot of any supported format and passes the items one by one to the given function.
+// Returns the number of records read (the total), which can be higher than the number of items passed when the function fails.
+func decodeSnapshotStream[T models.Item](r io.Reader, _ T, fn func(key string, item T) error) (int64, error) {
+	br := bufio.NewReader(r)
+
+	head, err := br.Peek(len(gzipMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+
+	if len(head) == 0 {
+		return 0, errors.New("empty data on input")
+	}
+
+	// The legacy format: one JSON object, decoded as a whole.
+	if !bytes.Equal(head, gzipMagic) {
+		matrix := &struct {
+			Items map[string]T `json:"items"`
+		}{}
+
+		if err := json.NewDecoder(br).Decode(matrix); err != nil {
+			return 0, err
+		}
+
+		var total int64
+
+		for key, item := range matrix.Items {
+			total++
+
+			if err := fn(key, item); err != nil {
+				return total, err
+			}
+		}
+
+		return total, nil
+	}
+
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return 0, err
+	}
+	defer zr.Close()
+
+	var (
+		dec   = json.NewDecoder(zr)
+		total int64
+	)
+
+	for {
+		var record snapshotRecord
+
+		if err := dec.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				return total, nil
+			}
+
+			return total, err
+		}
+
+		total++
+
+		var item T
+
+		if err := json.Unmarshal(record.Value, &item); err != nil {
+			return total, fmt.Errorf("key %s: %w", record.Key, err)
+		}
+
+		if err := fn(record.Key, item); err != nil {
+			return total, err
+		}
+	}
+}
+
+// decodeSnapshotFile opens the snapshot file and streams its items to the given function (see decodeSnapshotStream).
+func decodeSnapshotFile[T models.Item](path string, model T, fn func(key string, item T) error) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	return decodeSnapshotStream(file, model, fn)
+}
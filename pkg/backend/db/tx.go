@@ -0,0 +1,429 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+//
+//  Transactions
+//  Tx stages the writes to several caches and applies them as a unit on Commit. The staged writes are visible to the transaction's reads only.
+//  When any write fails during the commit, the writes applied so far are reverted, so the caches end up in the state before the commit.
+//  With the SQLite repository backend, Tx wraps the SQL transaction instead.
+//
+
+var ErrTxDone = errors.New("transaction has already been committed or rolled back")
+
+// Transactor runs the function using the repositories of a new transaction. The transaction is committed when the function returns nil,
+// rolled back otherwise.
+type Transactor interface {
+	RunInTransaction(fn func(repos *Repositories) error) error
+}
+
+// SQLHandle is the common part of *sql.DB and *sql.Tx used by the SQLite repositories, so they can run within a transaction too.
+type SQLHandle interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// WithSQLTx runs the function in a new SQL transaction, or in the transaction given already.
+func WithSQLTx(handle SQLHandle, fn func(tx SQLHandle) error) error {
+	sqlDB, ok := handle.(*sql.DB)
+	if !ok {
+		return fn(handle)
+	}
+
+	tx, err := sqlDB.Begin()
+	if err != nil {
+		return err
+	}
+
+	// Rollback is a no-op after the commit.
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// txCommitMu serializes the commits, so the version checks and the writes of one transaction are not interleaved with another one's.
+var txCommitMu sync.Mutex
+
+type Tx struct {
+	mu   sync.Mutex
+	done bool
+
+	// caches are the staging caches keyed by the cache name.
+	caches map[string]*txCache
+
+	// sqlTx is the SQL transaction of the SQLite repository backend (nil otherwise).
+	sqlTx *sql.Tx
+
+	// onCommit are the functions to be run once the transaction is committed (see OnCommit).
+	onCommit []func()
+}
+
+// Begin starts a new transaction over all caches (or over the SQL database).
+func (d *defaultDatabaseKeeper) Begin() (*Tx, error) {
+	if d.sqlDB != nil {
+		sqlTx, err := d.sqlDB.Begin()
+		if err != nil {
+			return nil, err
+		}
+
+		return &Tx{sqlTx: sqlTx}, nil
+	}
+
+	tx := &Tx{
+		caches: make(map[string]*txCache),
+	}
+
+	for _, cache := range d.caches {
+		tx.caches[cache.GetName()] = newTxCache(cache)
+	}
+
+	return tx, nil
+}
+
+// Database returns the transaction's caches keyed by the cache name (see DatabaseKeeper.Database).
+func (tx *Tx) Database() map[string]Cacher {
+	caches := make(map[string]Cacher)
+
+	for name, cache := range tx.caches {
+		caches[name] = cache
+	}
+
+	return caches
+}
+
+// SQLTx returns the SQL transaction, nil for the cache-backed repositories.
+func (tx *Tx) SQLTx() *sql.Tx {
+	return tx.sqlTx
+}
+
+// OnCommit registers the function to be run after the successful commit (e.g. an update of the search index), it is dropped on the rollback.
+func (tx *Tx) OnCommit(fn func()) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	tx.onCommit = append(tx.onCommit, fn)
+}
+
+// Commit applies all the staged writes. Nothing is applied when a versioned item has been changed since it was read (ErrVersionConflict), the
+// writes applied so far are reverted when an item is changed concurrently during the commit. The OnCommit functions are run afterwards.
+func (tx *Tx) Commit() error {
+	if err := tx.commit(); err != nil {
+		return err
+	}
+
+	tx.mu.Lock()
+	hooks := tx.onCommit
+	tx.onCommit = nil
+	tx.mu.Unlock()
+
+	for _, fn := range hooks {
+		fn()
+	}
+
+	return nil
+}
+
+func (tx *Tx) commit() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return ErrTxDone
+	}
+
+	tx.done = true
+
+	if tx.sqlTx != nil {
+		return tx.sqlTx.Commit()
+	}
+
+	txCommitMu.Lock()
+	defer txCommitMu.Unlock()
+
+	for _, cache := range tx.caches {
+		if err := cache.verify(); err != nil {
+			return err
+		}
+	}
+
+	var applied []*txCache
+
+	for _, cache := range tx.caches {
+		if err := cache.apply(); err != nil {
+			// Revert the caches applied fully, the failed one has reverted itself.
+			for i := len(applied) - 1; i >= 0; i-- {
+				applied[i].revert(len(applied[i].order))
+			}
+
+			return err
+		}
+
+		applied = append(applied, cache)
+	}
+
+	return nil
+}
+
+// Rollback discards all the staged writes.
+func (tx *Tx) Rollback() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return ErrTxDone
+	}
+
+	tx.done = true
+	tx.onCommit = nil
+
+	if tx.sqlTx != nil {
+		return tx.sqlTx.Rollback()
+	}
+
+	return nil
+}
+
+//
+//  txCache
+//  Cacher interface implementation staging the writes to the wrapped cache until the transaction's commit.
+//
+
+// txWrite is a staged write, the deletion if deleted is true.
+type txWrite struct {
+	value   interface{}
+	deleted bool
+
+	// swapped is true when the write is conditional on the stored version (see CompareAndSwap).
+	swapped bool
+	version int64
+}
+
+// txUndo holds the value replaced by an applied write.
+type txUndo struct {
+	value interface{}
+	found bool
+}
+
+type txCache struct {
+	mu   sync.RWMutex
+	base Cacher
+
+	writes map[string]txWrite
+
+	// order holds the keys in the order of the first write, the writes are applied in this order.
+	order []string
+
+	// undo holds the replaced values of the writes applied (by the order index).
+	undo []txUndo
+}
+
+func newTxCache(base Cacher) *txCache {
+	return &txCache{
+		base:   base,
+		writes: make(map[string]txWrite),
+	}
+}
+
+func (c *txCache) stage(key string, write txWrite) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Only the first write of the key can be checked against the stored version, the later writes are based on the staged value.
+	if previous, found := c.writes[key]; found {
+		write.swapped = previous.swapped
+		write.version = previous.version
+	} else {
+		c.order = append(c.order, key)
+	}
+
+	c.writes[key] = write
+}
+
+func (c *txCache) Load(key string) (interface{}, bool) {
+	c.mu.RLock()
+	write, found := c.writes[key]
+	c.mu.RUnlock()
+
+	if !found {
+		return c.base.Load(key)
+	}
+
+	if write.deleted {
+		return nil, false
+	}
+
+	return write.value, true
+}
+
+func (c *txCache) Store(key string, rawV interface{}) bool {
+	c.stage(key, txWrite{value: rawV})
+	return true
+}
+
+func (c *txCache) CompareAndSwap(key string, version int64, rawV interface{}) bool {
+	current, found := c.Load(key)
+	if !found || itemVersion(current) != version {
+		return false
+	}
+
+	c.stage(key, txWrite{value: rawV, swapped: true, version: version})
+	return true
+}
+
+func (c *txCache) Delete(key string) bool {
+	c.stage(key, txWrite{deleted: true})
+	return true
+}
+
+func (c *txCache) Range() (*GenericMap, int64) {
+	rawItems, _ := c.base.Range()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for key, write := range c.writes {
+		if write.deleted {
+			delete(*rawItems, key)
+			continue
+		}
+
+		(*rawItems)[key] = write.value
+	}
+
+	return rawItems, int64(len(*rawItems))
+}
+
+func (c *txCache) GetName() string {
+	return c.base.GetName()
+}
+
+func (c *txCache) Dump() *GenericMap {
+	rawItems, _ := c.Range()
+	return rawItems
+}
+
+// verify checks the conditional writes' versions against the wrapped cache.
+func (c *txCache) verify() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, key := range c.order {
+		write := c.writes[key]
+		if !write.swapped {
+			continue
+		}
+
+		// The stored item has to be still of the version read by the transaction.
+		current, found := c.base.Load(key)
+		if !found || itemVersion(current) != write.version {
+			return fmt.Errorf("%s: %s: %w", c.base.GetName(), key, ErrVersionConflict)
+		}
+	}
+
+	return nil
+}
+
+// apply writes the staged writes to the wrapped cache. Each write replaces the stored item only if it is still of the version verified (or
+// loaded just before), so the concurrent updates landing after the verification are not overwritten. On a failure, the writes applied are
+// reverted and the error is returned.
+func (c *txCache) apply() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.undo = make([]txUndo, 0, len(c.order))
+
+	for i, key := range c.order {
+		write := c.writes[key]
+
+		value, found := c.base.Load(key)
+		c.undo = append(c.undo, txUndo{value: value, found: found})
+
+		// The conditional writes are based on the version verified, the others on the version just loaded.
+		version := itemVersion(value)
+		if write.swapped {
+			version = write.version
+		}
+
+		var ok bool
+
+		switch {
+		case write.deleted:
+			ok = c.base.Delete(key)
+
+		case found || write.swapped:
+			if !c.swap(key, version, write.value) {
+				c.revertLocked(i)
+				return fmt.Errorf("%s: %s: %w", c.base.GetName(), key, ErrVersionConflict)
+			}
+
+			ok = true
+
+		default:
+			ok = c.base.Store(key, write.value)
+		}
+
+		if !ok {
+			c.revertLocked(i)
+			return fmt.Errorf("%s: could not apply the write of item %s", c.base.GetName(), key)
+		}
+	}
+
+	return nil
+}
+
+// swap replaces the stored item only if it is of such version, the caches not implementing Swapper are written without the check.
+func (c *txCache) swap(key string, version int64, rawV interface{}) bool {
+	swapper, ok := c.base.(Swapper)
+	if !ok {
+		return c.base.Store(key, rawV)
+	}
+
+	return swapper.CompareAndSwap(key, version, rawV)
+}
+
+// revert restores the values replaced by the first n writes applied.
+func (c *txCache) revert(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.revertLocked(n)
+}
+
+// revertLocked restores the values replaced by the first n writes applied. The items changed concurrently since the write are kept as they are,
+// not to overwrite the concurrent updates.
+func (c *txCache) revertLocked(n int) {
+	for i := n - 1; i >= 0; i-- {
+		key, undo, write := c.order[i], c.undo[i], c.writes[c.order[i]]
+
+		current, found := c.base.Load(key)
+
+		switch {
+		case write.deleted:
+			// Restore the deleted item, unless stored again meanwhile.
+			if undo.found && !found {
+				c.base.Store(key, undo.value)
+			}
+
+		case !undo.found:
+			// Delete the item added, unless updated meanwhile.
+			if found && itemVersion(current) == itemVersion(write.value) {
+				c.base.Delete(key)
+			}
+
+		default:
+			c.swap(key, itemVersion(write.value), undo.value)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+func newTestTx(caches ...Cacher) *Tx {
+	tx := &Tx{caches: make(map[string]*txCache)}
+
+	for _, cache := range caches {
+		tx.caches[cache.GetName()] = newTxCache(cache)
+	}
+
+	return tx
+}
+
+func TestTx_CommitRollback(t *testing.T) {
+	polls, posts := NewSimpleCache("PollCache"), NewSimpleCache("FlowCache")
+
+	tx := newTestTx(polls, posts)
+	caches := tx.Database()
+
+	caches["PollCache"].Store("1", models.Poll{ID: "1"})
+	caches["FlowCache"].Store("2", models.Post{ID: "2"})
+
+	var hooks []string
+
+	tx.OnCommit(func() {
+		hooks = append(hooks, "commit")
+	})
+
+	// The staged writes are visible to the transaction only.
+	if _, found := caches["FlowCache"].Load("2"); !found {
+		t.Error("staged post not visible to the transaction")
+	}
+
+	if _, found := posts.Load("2"); found {
+		t.Error("staged post visible before the commit")
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, found := polls.Load("1"); !found {
+		t.Error("poll not stored on commit")
+	}
+
+	if _, found := posts.Load("2"); !found {
+		t.Error("post not stored on commit")
+	}
+
+	if len(hooks) != 1 {
+		t.Errorf("unexpected hooks run on commit: %v", hooks)
+	}
+
+	if err := tx.Rollback(); !errors.Is(err, ErrTxDone) {
+		t.Errorf("expected ErrTxDone, got %v", err)
+	}
+
+	// Rollback discards the staged deletion.
+	tx = newTestTx(polls, posts)
+	tx.Database()["FlowCache"].Delete("2")
+
+	tx.OnCommit(func() {
+		hooks = append(hooks, "rollback")
+	})
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(hooks) != 1 {
+		t.Errorf("hook run despite the rollback: %v", hooks)
+	}
+
+	if _, found := posts.Load("2"); !found {
+		t.Error("post deleted despite the rollback")
+	}
+}
+
+func TestTx_VersionConflict(t *testing.T) {
+	polls, posts := NewSimpleCache("PollCache"), NewSimpleCache("FlowCache")
+	posts.Store("1", models.Post{ID: "1"})
+
+	tx := newTestTx(polls, posts)
+	caches := tx.Database()
+
+	caches["PollCache"].Store("1", models.Poll{ID: "1"})
+
+	if !caches["FlowCache"].(Swapper).CompareAndSwap("1", 0, models.Post{ID: "1", Version: 1, Content: "tx"}) {
+		t.Fatal("staged swap failed")
+	}
+
+	// A concurrent write changes the post's version before the commit.
+	posts.Store("1", models.Post{ID: "1", Version: 1, Content: "concurrent"})
+
+	if err := tx.Commit(); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+
+	// Nothing of the transaction is applied.
+	if _, found := polls.Load("1"); found {
+		t.Error("poll stored despite the conflict")
+	}
+
+	if rawPost, _ := posts.Load("1"); rawPost.(models.Post).Content != "concurrent" {
+		t.Error("concurrent write overwritten")
+	}
+}
+
+// racyCache runs the hook on every load of the key, to simulate the concurrent writes landing in the middle of the commit.
+type racyCache struct {
+	*SimpleCache
+
+	key    string
+	loads  int
+	onLoad func(loads int)
+}
+
+func (c *racyCache) Load(key string) (interface{}, bool) {
+	if key == c.key {
+		c.loads++
+		c.onLoad(c.loads)
+	}
+
+	return c.SimpleCache.Load(key)
+}
+
+func TestTx_ConcurrentUpdateDuringCommit(t *testing.T) {
+	posts := &racyCache{SimpleCache: NewSimpleCache("FlowCache"), key: "2"}
+	posts.Store("1", models.Post{ID: "1", Version: 1})
+	posts.Store("2", models.Post{ID: "2", Version: 1})
+
+	// The concurrent updates land after the verification (the loads of the post are: staged, verified, and applied), and after the first post
+	// has been written.
+	posts.onLoad = func(loads int) {
+		if loads != 3 {
+			return
+		}
+
+		posts.CompareAndSwap("1", 2, models.Post{ID: "1", Version: 3, Content: "concurrent"})
+		posts.CompareAndSwap("2", 1, models.Post{ID: "2", Version: 2, Content: "concurrent"})
+	}
+
+	tx := newTestTx(posts)
+	cache := tx.Database()["FlowCache"].(Swapper)
+
+	if !cache.CompareAndSwap("1", 1, models.Post{ID: "1", Version: 2, Content: "tx"}) || !cache.CompareAndSwap("2", 1, models.Post{ID: "2", Version: 2, Content: "tx"}) {
+		t.Fatal("staged swap failed")
+	}
+
+	if err := tx.Commit(); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+
+	// Neither concurrent update is overwritten, by the commit nor by its revert.
+	for _, key := range []string{"1", "2"} {
+		if rawPost, _ := posts.Load(key); rawPost.(models.Post).Content != "concurrent" {
+			t.Errorf("post %s: concurrent write overwritten: %+v", key, rawPost)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  TypedCache
+//  The generic, type-safe wrapper of any Cacher. The items are keyed by their IDs (see models.Item). Unlike the interface{} Cacher API,
+//  the items of other than the cache's type are reported (see TypeMismatchError), never dropped silently.
+//
+
+var ErrItemNotFound = errors.New("item not found")
+
+// TypeMismatchError lists the keys of the items which could not be asserted the cache's item type.
+type TypeMismatchError struct {
+	// Cache is the name of the cache holding the items.
+	Cache string
+
+	// Keys of the mismatched items.
+	Keys []string
+}
+
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("%s: %d item(s) of unexpected type: %s", e.Cache, len(e.Keys), strings.Join(e.Keys, ", "))
+}
+
+// Iterator is implemented by the caches able to loop over their items without copying the whole map first.
+// The function is called under the cache's read lock, so it must not write to the very same cache.
+type Iterator interface {
+	Iterate(fn func(key string, rawV interface{}) bool)
+}
+
+// Counter is implemented by the caches able to count their items without copying them.
+type Counter interface {
+	Count() int64
+}
+
+type TypedCache[T models.Item] struct {
+	cache Cacher
+}
+
+func NewTypedCache[T models.Item](cache Cacher, _ T) *TypedCache[T] {
+	if cache == nil {
+		return nil
+	}
+
+	return &TypedCache[T]{
+		cache: cache,
+	}
+}
+
+// Get fetches the item of such key. Returns ErrItemNotFound, or TypeMismatchError on failure.
+func (c *TypedCache[T]) Get(key string) (T, error) {
+	var item T
+
+	rawItem, found := c.cache.Load(key)
+	if !found {
+		return item, ErrItemNotFound
+	}
+
+	item, ok := rawItem.(T)
+	if !ok {
+		return item, &TypeMismatchError{Cache: c.cache.GetName(), Keys: []string{key}}
+	}
+
+	return item, nil
+}
+
+// Put stores the item under its ID.
+func (c *TypedCache[T]) Put(item T) error {
+	if !c.cache.Store(item.GetID(), item) {
+		return fmt.Errorf("%s: could not store item %s", c.cache.GetName(), item.GetID())
+	}
+
+	return nil
+}
+
+// Update applies the function to the current item of such key, increases the item's version (see models.Versioned) and stores the result,
+// only if the item has not been changed meanwhile. The update is retried with the fresh item on a conflict, the function can be thus called
+// repeatedly. Fails with ErrVersionConflict when the retries are exhausted. Caches not implementing Swapper are written without the check.
+func (c *TypedCache[T]) Update(key string, fn func(item *T) error) error {
+	return RetryOnConflict(func() error {
+		item, err := c.Get(key)
+		if err != nil {
+			return err
+		}
+
+		version := itemVersion(item)
+
+		if err := fn(&item); err != nil {
+			return err
+		}
+
+		if setter, ok := any(&item).(versionSetter); ok {
+			setter.SetVersion(version + 1)
+		}
+
+		swapper, ok := c.cache.(Swapper)
+		if !ok {
+			return c.Put(item)
+		}
+
+		if !swapper.CompareAndSwap(key, version, item) {
+			return ErrVersionConflict
+		}
+
+		return nil
+	})
+}
+
+// Delete deletes the item of such key.
+func (c *TypedCache[T]) Delete(key string) error {
+	if !c.cache.Delete(key) {
+		return fmt.Errorf("%s: could not delete item %s", c.cache.GetName(), key)
+	}
+
+	return nil
+}
+
+// Iterate passes the items one by one to the given function until it returns false. The items of unexpected type are skipped, and reported
+// as TypeMismatchError once the loop is over. No full copy of the cache is made when the cache implements the Iterator interface.
+func (c *TypedCache[T]) Iterate(fn func(item T) bool) error {
+	return c.iterate(func(_ string, item T) bool {
+		return fn(item)
+	})
+}
+
+// iterate is the keyed variant of Iterate for the callers depending on the cache keys (see getAll).
+func (c *TypedCache[T]) iterate(fn func(key string, item T) bool) error {
+	var mismatched []string
+
+	visit := func(key string, rawItem interface{}) bool {
+		item, ok := rawItem.(T)
+		if !ok {
+			mismatched = append(mismatched, key)
+			return true
+		}
+
+		return fn(key, item)
+	}
+
+	if it, ok := c.cache.(Iterator); ok {
+		it.Iterate(visit)
+	} else {
+		rawItems, _ := c.cache.Range()
+
+		for key, rawItem := range *rawItems {
+			if !visit(key, rawItem) {
+				break
+			}
+		}
+	}
+
+	if len(mismatched) > 0 {
+		return &TypeMismatchError{Cache: c.cache.GetName(), Keys: mismatched}
+	}
+
+	return nil
+}
+
+// Len returns the number of items in the cache (including the items of unexpected type).
+func (c *TypedCache[T]) Len() int64 {
+	if counter, ok := c.cache.(Counter); ok {
+		return counter.Count()
+	}
+
+	_, count := c.cache.Range()
+	return count
+}
+
+// GetName returns the wrapped cache's name.
+func (c *TypedCache[T]) GetName() string {
+	return c.cache.GetName()
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+func TestTypedCache_Mismatch(t *testing.T) {
+	cache := NewSimpleCache("FlowCache")
+	posts := NewTypedCache(cache, models.Post{})
+
+	if err := posts.Put(models.Post{ID: "1", Content: "first"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A foreign item stored using the raw Cacher API.
+	cache.Store("2", models.User{Nickname: "2"})
+
+	if _, err := posts.Get("3"); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("expected ErrItemNotFound, got %v", err)
+	}
+
+	var mismatch *TypeMismatchError
+
+	if _, err := posts.Get("2"); !errors.As(err, &mismatch) {
+		t.Errorf("expected TypeMismatchError, got %v", err)
+	}
+
+	var count int
+
+	err := posts.Iterate(func(post models.Post) bool {
+		count++
+		return true
+	})
+	if !errors.As(err, &mismatch) || len(mismatch.Keys) != 1 || mismatch.Keys[0] != "2" {
+		t.Errorf("expected the mismatched key reported, got %v", err)
+	}
+
+	if count != 1 || posts.Len() != 2 {
+		t.Errorf("unexpected counts: iterated %d, len %d", count, posts.Len())
+	}
+}
+
+func TestTypedCache_UpdateNoLostWrites(t *testing.T) {
+	posts := NewTypedCache(NewSimpleCache("FlowCache"), models.Post{})
+
+	if err := posts.Put(models.Post{ID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int64
+	)
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := posts.Update("1", func(post *models.Post) error {
+				post.ReactionCount++
+				return nil
+			})
+			if err != nil && !errors.Is(err, ErrVersionConflict) {
+				t.Error(err)
+				return
+			}
+
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	post, err := posts.Get("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every successful update has to be counted, none overwritten.
+	if post.ReactionCount != succeeded || post.Version != succeeded {
+		t.Errorf("expected %d reactions and version, got %d (version %d)", succeeded, post.ReactionCount, post.Version)
+	}
+
+	// A stale version has to be refused.
+	if posts.cache.(Swapper).CompareAndSwap("1", succeeded-1, models.Post{ID: "1"}) {
+		t.Error("stale write accepted")
+	}
+}
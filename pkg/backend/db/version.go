@@ -0,0 +1,70 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Optimistic concurrency
+//  The items implementing models.Versioned carry a version increased on every update. An update is stored only if the stored item's version
+//  is still the one the update is based on (compare-and-swap), otherwise it is retried with the fresh item. See TypedCache.Update, and the Update
+//  method of the repositories.
+//
+
+// updateRetries is the number of attempts to apply an update before ErrVersionConflict is returned.
+const updateRetries = 5
+
+// SQLVersionCondition is the WHERE clause condition matching the stored JSON document of such version (the items stored before the versioning
+// was introduced are of version 0).
+const SQLVersionCondition = "COALESCE(json_extract(data, '$.version'), 0) = ?"
+
+var ErrVersionConflict = errors.New("the item has been modified concurrently, please try again")
+
+// Swapper is implemented by the caches able to replace an item atomically, only if the stored item is of such version.
+type Swapper interface {
+	CompareAndSwap(key string, version int64, rawV interface{}) bool
+}
+
+// versionSetter is implemented by the pointers to the models.Versioned items.
+type versionSetter interface {
+	SetVersion(version int64)
+}
+
+// itemVersion returns the version of the item, 0 for the items not versioned.
+func itemVersion(rawV interface{}) int64 {
+	if item, ok := rawV.(models.Versioned); ok {
+		return item.GetVersion()
+	}
+
+	return 0
+}
+
+// RetryOnConflict calls the update function again while it fails on ErrVersionConflict, at most updateRetries times.
+func RetryOnConflict(update func() error) error {
+	var err error
+
+	for attempt := 0; attempt < updateRetries; attempt++ {
+		if err = update(); !errors.Is(err, ErrVersionConflict) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// CheckSwapped returns ErrVersionConflict if the conditional UPDATE statement (see SQLVersionCondition) has not changed any row.
+func CheckSwapped(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrVersionConflict
+	}
+
+	return nil
+}
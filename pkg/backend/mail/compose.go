@@ -102,6 +102,18 @@ func (s *mailService) ComposeMail(payloadI interface{}) (*gomail.Msg, error) {
 
 		m.Subject("Your New Passphrase")
 
+	// Account lockout notification.
+	case "user_lockout":
+		tmplPayload = &TemplatePayload{
+			Nickname:    nickname,
+			MainURL:     config.ServerUrl,
+			IPAddress:   payload.IPAddress,
+			LockedUntil: payload.LockedUntil.UTC().Format("2006-01-02 15:04 MST"),
+			TemplateSrc: "/opt/templates/lockout.tmpl",
+		}
+
+		m.Subject("Your Account Has Been Locked")
+
 	default:
 		return nil, ErrUnknownMailType
 	}
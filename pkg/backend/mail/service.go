@@ -2,6 +2,8 @@
 package mail
 
 import (
+	"time"
+
 	"go.vxn.dev/littr/pkg/models"
 )
 
@@ -11,6 +13,10 @@ type MessagePayload struct {
 	UUID       string
 	Passphrase string
 	Nickname   string
+
+	// Lockout notification.
+	IPAddress   string
+	LockedUntil time.Time
 }
 
 type mailService struct{}
@@ -22,6 +22,10 @@ type TemplatePayload struct {
 
 	// Passphrase template.
 	Passphrase string
+
+	// Lockout template.
+	IPAddress   string
+	LockedUntil string
 }
 
 var fileAsString = func(templateName string) string {
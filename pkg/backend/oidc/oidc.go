@@ -0,0 +1,417 @@
+// OpenID Connect relying party package for the backend.
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+//
+//  OpenID Connect
+//  The authorization code flow with PKCE (RFC 7636). The provider's endpoints are discovered from its issuer URL, the ID token is verified
+//  using the provider's JSON Web Key Set (RS256 only).
+//
+
+const (
+	// loginTTL is the time to complete the login at the provider.
+	loginTTL = time.Minute * 10
+
+	// clockSkew is the tolerance of the ID token's timestamps.
+	clockSkew = time.Minute
+)
+
+var (
+	ErrDiscoveryFailed = errors.New("could not fetch the provider's configuration")
+	ErrExchangeFailed  = errors.New("could not exchange the authorization code")
+	ErrIDTokenInvalid  = errors.New("invalid ID token received")
+	ErrKeyNotFound     = errors.New("no such signing key of the provider")
+	ErrStateInvalid    = errors.New("unknown or expired login state")
+)
+
+// Config holds the provider's settings (see config.OIDCProvider).
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// Identity is the provider's user as described by the verified ID token.
+type Identity struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	Name              string
+}
+
+// discovery is the subset of the provider's configuration document used.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// pendingLogin is the state of the login started, kept until the provider redirects the user back.
+type pendingLogin struct {
+	verifier  string
+	nonce     string
+	expiresAt time.Time
+}
+
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu      sync.Mutex
+	doc     *discovery
+	keys    map[string]*rsa.PublicKey
+	pending map[string]pendingLogin
+}
+
+func NewProvider(config Config, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: time.Second * 10}
+	}
+
+	return &Provider{
+		config:  config,
+		client:  client,
+		keys:    make(map[string]*rsa.PublicKey),
+		pending: make(map[string]pendingLogin),
+	}
+}
+
+// Begin starts a new login. Returns the provider's authorization URL to redirect the user to, and the login's state to be bound to the user's
+// browser (e.g. using a cookie).
+func (p *Provider) Begin() (string, string, error) {
+	doc, err := p.discover()
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	verifier, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	nonce, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	p.mu.Lock()
+
+	// Prune the logins never completed.
+	for key, login := range p.pending {
+		if time.Now().After(login.expiresAt) {
+			delete(p.pending, key)
+		}
+	}
+
+	p.pending[state] = pendingLogin{
+		verifier:  verifier,
+		nonce:     nonce,
+		expiresAt: time.Now().Add(loginTTL),
+	}
+
+	p.mu.Unlock()
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {"openid email profile"},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {Challenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return doc.AuthorizationEndpoint + sep + query.Encode(), state, nil
+}
+
+// Complete finishes the login of such state: the authorization code is exchanged for the ID token, which is verified then. Each state can be
+// completed just once.
+func (p *Provider) Complete(state, code string) (*Identity, error) {
+	p.mu.Lock()
+	login, found := p.pending[state]
+	delete(p.pending, state)
+	p.mu.Unlock()
+
+	if !found || state == "" || time.Now().After(login.expiresAt) {
+		return nil, ErrStateInvalid
+	}
+
+	rawIDToken, err := p.exchange(code, login.verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.verify(rawIDToken, login.nonce)
+}
+
+// Challenge returns the PKCE code challenge of such verifier (the S256 method).
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+//
+//  Helper functions
+//
+
+// discover fetches the provider's configuration document, it is cached once fetched successfully.
+func (p *Provider) discover() (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.doc != nil {
+		return p.doc, nil
+	}
+
+	var doc discovery
+
+	if err := p.getJSON(p.config.Issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDiscoveryFailed, err)
+	}
+
+	// The issuer has to match exactly, so the ID tokens of another issuer are not accepted.
+	if doc.Issuer != p.config.Issuer || doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, ErrDiscoveryFailed
+	}
+
+	p.doc = &doc
+	return p.doc, nil
+}
+
+// exchange redeems the authorization code at the token endpoint. Returns the raw ID token.
+func (p *Provider) exchange(code, verifier string) (string, error) {
+	doc, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"code_verifier": {verifier},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	// The confidential clients authenticate using the HTTP basic scheme (client_secret_basic).
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrExchangeFailed, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrExchangeFailed, err)
+	}
+
+	if resp.StatusCode != http.StatusOK || body.IDToken == "" {
+		return "", fmt.Errorf("%w: %s %s", ErrExchangeFailed, body.Error, body.ErrorDescription)
+	}
+
+	return body.IDToken, nil
+}
+
+// idTokenClaims are the ID token's claims checked and mapped to the Identity.
+type idTokenClaims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	ExpiresAt         int64    `json:"exp"`
+	IssuedAt          int64    `json:"iat"`
+	Nonce             string   `json:"nonce"`
+	Email             string   `json:"email"`
+	EmailVerified     bool     `json:"email_verified"`
+	PreferredUsername string   `json:"preferred_username"`
+	Name              string   `json:"name"`
+}
+
+func (c *idTokenClaims) Valid() error {
+	now := time.Now()
+
+	if now.After(time.Unix(c.ExpiresAt, 0).Add(clockSkew)) || now.Add(clockSkew).Before(time.Unix(c.IssuedAt, 0)) {
+		return errors.New("ID token expired, or issued in the future")
+	}
+
+	return nil
+}
+
+// audience is the aud claim, a single string or an array of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+
+	*a = multiple
+	return nil
+}
+
+// verify checks the ID token's signature, issuer, audience, expiration, and nonce.
+func (p *Provider) verify(rawIDToken, nonce string) (*Identity, error) {
+	claims := &idTokenClaims{}
+
+	if _, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		return p.key(kid)
+	}); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIDTokenInvalid, err)
+	}
+
+	if claims.Issuer != p.config.Issuer || !slices.Contains(claims.Audience, p.config.ClientID) || claims.Nonce != nonce || claims.Subject == "" {
+		return nil, ErrIDTokenInvalid
+	}
+
+	return &Identity{
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     claims.EmailVerified,
+		PreferredUsername: claims.PreferredUsername,
+		Name:              claims.Name,
+	}, nil
+}
+
+// key returns the provider's signing key of such ID. The key set is fetched again when the key is not known (the keys have been rotated).
+func (p *Provider) key(kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	key, found := p.keys[kid]
+	p.mu.Unlock()
+
+	if found {
+		return key, nil
+	}
+
+	doc, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+
+	if err := p.getJSON(doc.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	if key, found = keys[kid]; !found {
+		return nil, ErrKeyNotFound
+	}
+
+	return key, nil
+}
+
+func (p *Provider) getJSON(url string, target interface{}) error {
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+// randomString returns a random URL-safe string of 32 bytes of entropy.
+func randomString() (string, error) {
+	raw := make([]byte, 32)
+
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
@@ -0,0 +1,89 @@
+package oidc_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"go.vxn.dev/littr/pkg/backend/oidc"
+	"go.vxn.dev/littr/pkg/backend/oidc/oidctest"
+)
+
+func TestOIDC_Login(t *testing.T) {
+	idp, err := oidctest.NewServer("littr", "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idp.Close()
+
+	idp.SetUser(oidctest.User{Subject: "1234", Email: "alice@example.com", EmailVerified: true})
+
+	provider := oidc.NewProvider(idp.Config("https://littr.example.com/api/v1/auth/oidc/corp/callback"), nil)
+
+	authURL, state, err := provider.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.Query().Get("code_challenge_method") != "S256" || parsed.Query().Get("state") != state {
+		t.Errorf("unexpected authorization URL: %s", authURL)
+	}
+
+	code, returnedState, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if returnedState != state {
+		t.Fatalf("state mismatch: %s", returnedState)
+	}
+
+	identity, err := provider.Complete(state, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if identity.Subject != "1234" || identity.Email != "alice@example.com" || !identity.EmailVerified {
+		t.Errorf("unexpected identity: %+v", identity)
+	}
+
+	// The state cannot be used twice.
+	if _, err := provider.Complete(state, code); !errors.Is(err, oidc.ErrStateInvalid) {
+		t.Errorf("state reused: %v", err)
+	}
+}
+
+func TestOIDC_WrongClient(t *testing.T) {
+	idp, err := oidctest.NewServer("littr", "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idp.Close()
+
+	idp.SetUser(oidctest.User{Subject: "1234"})
+
+	// The client secret does not match.
+	config := idp.Config("https://littr.example.com/callback")
+	config.ClientSecret = "wrong"
+
+	provider := oidc.NewProvider(config, nil)
+
+	authURL, state, err := provider.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, _, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Complete(state, code); !errors.Is(err, oidc.ErrExchangeFailed) {
+		t.Errorf("expected the exchange to fail: %v", err)
+	}
+}
@@ -32,6 +32,7 @@ type FlowOptions struct {
 	UserFlowNick string `json:"user_Flow_nick"`
 	Hashtag      string `json:"hashtag"`
 	HideReplies  bool   `json:"hide_replies"`
+	Bookmarks    bool   `json:"bookmarks"`
 }
 
 // polls subviews' options
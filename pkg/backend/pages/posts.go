@@ -2,17 +2,30 @@ package pages
 
 import (
 	"sort"
-	"strings"
 
+	"go.vxn.dev/littr/pkg/backend/db"
 	"go.vxn.dev/littr/pkg/models"
 )
 
+// PostData returns the post indexes to be paged if maintained by the repository (no full copy of the posts is made then), or all posts
+// otherwise.
+func PostData(postRepository models.PostRepositoryInterface) (interface{}, error) {
+	if indexer, ok := postRepository.(db.PostIndexer); ok {
+		if index := indexer.PostIndex(); index != nil && index.Len() > 0 {
+			return index, nil
+		}
+	}
+
+	return postRepository.GetAll()
+}
+
 func onePagePosts(opts *PageOptions, data ...interface{}) *PagePointers {
 	// BTW those variables are both of type map[string]T
 	var (
 		allPosts *map[string]models.Post
 		allUsers *map[string]models.User
 		posts    []models.Post
+		index    *db.PostIndex
 	)
 
 	defer func() {
@@ -33,6 +46,17 @@ func onePagePosts(opts *PageOptions, data ...interface{}) *PagePointers {
 			allUsers = u
 			continue
 		}
+
+		x, ok := iface.(*db.PostIndex)
+		if ok {
+			index = x
+			continue
+		}
+	}
+
+	// Query the secondary indexes instead of scanning all posts.
+	if index != nil && allUsers != nil {
+		return onePageIndexedPosts(opts, index, allUsers)
 	}
 
 	if allPosts == nil || allUsers == nil || len(*allPosts) == 0 || len(*allUsers) == 0 {
@@ -44,8 +68,6 @@ func onePagePosts(opts *PageOptions, data ...interface{}) *PagePointers {
 	// + include previous posts to a reply
 	// + only include users mentioned
 
-	num := 0
-
 	caller := (*allUsers)[opts.CallerID]
 	opts.Caller = &caller
 
@@ -71,45 +93,90 @@ func onePagePosts(opts *PageOptions, data ...interface{}) *PagePointers {
 
 	// filter out all posts for such callerID
 	for _, post := range *allPosts {
-		// check the caller's flow list, skip on unfollowed, or unknown user
-		if value, found := flowList[post.Nickname]; (!found || !value) && !opts.Flow.UserFlow {
-			continue
+		if matchPost(opts, flowList, allUsers, post) {
+			posts = append(posts, post)
 		}
+	}
 
-		if opts.Flow.Hashtag != "" {
-			if strings.Contains(post.Content, "#"+opts.Flow.Hashtag) {
-				posts = append(posts, post)
-			}
-			continue
-		}
+	// order posts by timestamp DESC
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].Timestamp.After(posts[j].Timestamp)
+	})
 
-		// filter replies out
-		if opts.Flow.HideReplies && post.ReplyToID != "" {
-			continue
+	part := cutPage(opts.PageNo, posts)
+
+	return exportPage(opts, part, allUsers, func(postID string) (models.Post, bool) {
+		post, found := (*allPosts)[postID]
+		return post, found
+	})
+}
+
+// onePageIndexedPosts composes the flow page using the post indexes: only the posts of the author, the replies, or the hashtag requested
+// are fetched, and the plain flow walks the timeline from the newest post until the page is filled.
+func onePageIndexedPosts(opts *PageOptions, index *db.PostIndex, allUsers *map[string]models.User) *PagePointers {
+	if index.Len() == 0 || len(*allUsers) == 0 {
+		return &PagePointers{Posts: nil, Users: nil}
+	}
+
+	caller := (*allUsers)[opts.CallerID]
+	opts.Caller = &caller
+
+	// overload flowList
+	flowList := opts.Caller.FlowList
+	if opts.FlowList != nil {
+		flowList = opts.FlowList
+	}
+
+	// lookup fetches the post with its reply count assigned
+	lookup := func(postID string) (models.Post, bool) {
+		post, found := index.Get(postID)
+		if !found {
+			return post, false
 		}
 
-		// exctract replies to the single post
-		if opts.Flow.SinglePost && opts.Flow.SinglePostID != "" {
-			if post.ReplyToID == opts.Flow.SinglePostID || post.ID == opts.Flow.SinglePostID {
-				posts = append(posts, post)
-			}
-			continue
+		post.ReplyCount = int64(index.ReplyCount(postID))
+		return post, true
+	}
+
+	var (
+		posts []models.Post
+		ids   []string
+	)
+
+	switch {
+	case opts.Flow.Hashtag != "":
+		ids = index.ByHashtag(opts.Flow.Hashtag)
+
+	case opts.Flow.SinglePost && opts.Flow.SinglePostID != "":
+		ids = append(index.Replies(opts.Flow.SinglePostID), opts.Flow.SinglePostID)
+
+	case opts.Flow.UserFlow && opts.Flow.UserFlowNick != "":
+		ids = index.ByAuthor(opts.Flow.UserFlowNick)
+
+	case opts.Flow.Bookmarks:
+		for postID := range opts.Caller.Bookmarks {
+			ids = append(ids, postID)
 		}
 
-		if opts.Flow.UserFlow && opts.Flow.UserFlowNick != "" {
-			if (*allUsers)[opts.Flow.UserFlowNick].Private {
-				if value, _ := opts.Caller.FlowList[opts.Flow.UserFlowNick]; !value && (*allUsers)[opts.Flow.UserFlowNick].Private {
-					continue
-				}
-			}
+	default:
+		// the timeline is ordered already, so stop once the requested page is filled
+		end := (PAGE_SIZE * 2) * (opts.PageNo + 1)
 
-			if post.Nickname == opts.Flow.UserFlowNick {
+		index.Timeline(func(postID string) bool {
+			if post, found := lookup(postID); found && matchPost(opts, flowList, allUsers, post) {
 				posts = append(posts, post)
 			}
-			continue
-		}
 
-		posts = append(posts, post)
+			return len(posts) < end
+		})
+
+		return exportPage(opts, cutPage(opts.PageNo, posts), allUsers, lookup)
+	}
+
+	for _, postID := range ids {
+		if post, found := lookup(postID); found && matchPost(opts, flowList, allUsers, post) {
+			posts = append(posts, post)
+		}
 	}
 
 	// order posts by timestamp DESC
@@ -117,10 +184,71 @@ func onePagePosts(opts *PageOptions, data ...interface{}) *PagePointers {
 		return posts[i].Timestamp.After(posts[j].Timestamp)
 	})
 
-	// cut the PAGE_SIZE*2 number of posts only
-	var part []models.Post
+	return exportPage(opts, cutPage(opts.PageNo, posts), allUsers, lookup)
+}
+
+// matchPost decides whether the post belongs to the flow page requested.
+func matchPost(opts *PageOptions, flowList models.UserGenericMap, allUsers *map[string]models.User, post models.Post) bool {
+	// the bookmarked posts are not limited to the caller's flow list, the private content is left out until followed
+	if opts.Flow.Bookmarks {
+		if !opts.Caller.HasBookmarked(post.ID) || (opts.Flow.HideReplies && post.ReplyToID != "") {
+			return false
+		}
+
+		if value, found := opts.Caller.FlowList[post.Nickname]; (!found || !value) && (*allUsers)[post.Nickname].Private && post.Nickname != opts.CallerID {
+			return false
+		}
+
+		return true
+	}
+
+	// check the caller's flow list, skip on unfollowed, or unknown user
+	if value, found := flowList[post.Nickname]; (!found || !value) && !opts.Flow.UserFlow {
+		return false
+	}
+
+	if opts.Flow.Hashtag != "" {
+		return hasHashtag(post.Content, opts.Flow.Hashtag)
+	}
+
+	// filter replies out
+	if opts.Flow.HideReplies && post.ReplyToID != "" {
+		return false
+	}
+
+	// exctract replies to the single post
+	if opts.Flow.SinglePost && opts.Flow.SinglePostID != "" {
+		return post.ReplyToID == opts.Flow.SinglePostID || post.ID == opts.Flow.SinglePostID
+	}
+
+	if opts.Flow.UserFlow && opts.Flow.UserFlowNick != "" {
+		if (*allUsers)[opts.Flow.UserFlowNick].Private {
+			if value, _ := opts.Caller.FlowList[opts.Flow.UserFlowNick]; !value && (*allUsers)[opts.Flow.UserFlowNick].Private {
+				return false
+			}
+		}
+
+		return post.Nickname == opts.Flow.UserFlowNick
+	}
+
+	return true
+}
+
+// hasHashtag checks the content for such hashtag the very same way the post index does (see db.ParseHashtags).
+func hasHashtag(content, hashtag string) bool {
+	hashtag = db.NormalizeHashtag(hashtag)
 
-	pageNo := opts.PageNo
+	for _, tag := range db.ParseHashtags(content) {
+		if tag == hashtag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cutPage cuts the PAGE_SIZE*2 number of posts only
+func cutPage(pageNo int, posts []models.Post) []models.Post {
 	start := (PAGE_SIZE * 2) * pageNo
 	end := (PAGE_SIZE * 2) * (pageNo + 1)
 
@@ -130,26 +258,25 @@ func onePagePosts(opts *PageOptions, data ...interface{}) *PagePointers {
 
 		if len(posts) <= end {
 			// the very last page
-			part = posts[start:]
-		} else {
-			// the middle page
-			part = posts[start:end]
+			return posts[start:]
 		}
-	} else {
-		// the very single page
-		part = posts
+
+		// the middle page
+		return posts[start:end]
 	}
 
+	// the very single page
+	return posts
+}
+
+// exportPage exports the page's posts together with the posts replied to, and with all users related.
+func exportPage(opts *PageOptions, part []models.Post, allUsers *map[string]models.User, lookup func(postID string) (models.Post, bool)) *PagePointers {
 	// loop through the array and manually include other posts too
 	// watch for users as well
 	pExport := make(map[string]models.Post)
 	uExport := make(map[string]models.User)
 
-	num = 0
 	for _, post := range part {
-		// increase the count of posts
-		num++
-
 		// export one (1) post
 		pExport[post.ID] = post
 		uExport[post.Nickname] = (*allUsers)[post.Nickname]
@@ -157,9 +284,7 @@ func onePagePosts(opts *PageOptions, data ...interface{}) *PagePointers {
 		// we can have multiple keys from a single post -> its interractions
 		repKey := post.ReplyToID
 		if repKey != "" {
-			if prePost, found := (*allPosts)[repKey]; found {
-				num++
-
+			if prePost, found := lookup(repKey); found {
 				// export previous user too
 				nick := prePost.Nickname
 				uExport[nick] = (*allUsers)[nick]
@@ -174,11 +299,6 @@ func onePagePosts(opts *PageOptions, data ...interface{}) *PagePointers {
 				pExport[repKey] = prePost
 			}
 		}
-
-		// this makes sure only N posts are returned, but it cuts off the tail posts
-		/*if num > pageSize {
-			break
-		}*/
 	}
 
 	// ensure the UserFlowNick is always included too
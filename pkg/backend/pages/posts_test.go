@@ -0,0 +1,157 @@
+package pages
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+// benchPostCount is the number of posts the benchmarks are run on.
+const benchPostCount = 100000
+
+// preparePosts fills both the indexed cache and the plain map with the very same posts of ten users, every tenth post is a reply.
+func preparePosts(count int) (*db.IndexedPostCache, *map[string]models.User) {
+	cache := db.NewIndexedPostCache(db.NewSimpleCache("FlowCache"))
+	users := make(map[string]models.User)
+
+	flowList := make(models.UserGenericMap)
+
+	for i := 0; i < 10; i++ {
+		nick := fmt.Sprintf("user%d", i)
+		flowList[nick] = true
+		users[nick] = models.User{Nickname: nick}
+	}
+
+	for nick := range users {
+		user := users[nick]
+		user.FlowList = flowList
+		users[nick] = user
+	}
+
+	start := time.Now().Add(-time.Duration(count) * time.Second)
+
+	for i := 0; i < count; i++ {
+		post := models.Post{
+			ID:        fmt.Sprintf("%08d", i),
+			Nickname:  fmt.Sprintf("user%d", i%10),
+			Content:   fmt.Sprintf("post no. %d #tag%d", i, i%100),
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+		}
+
+		if i%10 == 9 {
+			post.ReplyToID = fmt.Sprintf("%08d", i-9)
+		}
+
+		cache.Store(post.ID, post)
+	}
+
+	return cache, &users
+}
+
+// allPosts copies the posts the way PostRepository.GetAll does.
+func allPosts(cache db.Cacher) *map[string]models.Post {
+	posts := make(map[string]models.Post)
+
+	rawPosts, _ := cache.Range()
+
+	for key, rawPost := range *rawPosts {
+		posts[key] = rawPost.(models.Post)
+	}
+
+	return &posts
+}
+
+func TestOnePagePosts_IndexedEqualsScan(t *testing.T) {
+	cache, users := preparePosts(1000)
+
+	// Every third post is bookmarked by the caller.
+	caller := (*users)["user0"]
+	for i := 0; i < 1000; i += 3 {
+		caller.AddBookmark(fmt.Sprintf("%08d", i), time.Now())
+	}
+	(*users)["user0"] = caller
+
+	flows := map[string]FlowOptions{
+		"plain":       {Plain: true},
+		"hideReplies": {Plain: true, HideReplies: true},
+		"singlePost":  {SinglePost: true, SinglePostID: "00000990"},
+		"userFlow":    {UserFlow: true, UserFlowNick: "user3"},
+		"hashtag":     {Hashtag: "tag42"},
+		"bookmarks":   {Bookmarks: true},
+	}
+
+	for name, flow := range flows {
+		for _, pageNo := range []int{0, 3} {
+			scan := onePagePosts(&PageOptions{CallerID: "user0", PageNo: pageNo, Flow: flow}, allPosts(cache), users)
+			indexed := onePagePosts(&PageOptions{CallerID: "user0", PageNo: pageNo, Flow: flow}, cache.PostIndex(), users)
+
+			if len(*scan.Posts) == 0 || len(*scan.Posts) != len(*indexed.Posts) {
+				t.Fatalf("%s (page %d): scanned %d posts, indexed %d", name, pageNo, len(*scan.Posts), len(*indexed.Posts))
+			}
+
+			for id, post := range *scan.Posts {
+				if got := (*indexed.Posts)[id]; got.ID != post.ID || got.ReplyCount != post.ReplyCount {
+					t.Errorf("%s (page %d): post %s differs: %+v != %+v", name, pageNo, id, got, post)
+				}
+			}
+		}
+	}
+
+	// The index has to follow the deletes.
+	cache.Delete("00000999")
+
+	if replies := cache.PostIndex().Replies("00000990"); len(replies) != 0 {
+		t.Errorf("stale reply index: %v", replies)
+	}
+}
+
+//
+//  Full scan (the posts copied from the cache on every request)
+//
+
+func BenchmarkOnePagePostsScan(b *testing.B) {
+	cache, users := preparePosts(benchPostCount)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = onePagePosts(&PageOptions{CallerID: "user0", Flow: FlowOptions{Plain: true}}, allPosts(cache), users)
+	}
+}
+
+func BenchmarkOnePagePostsScanHashtag(b *testing.B) {
+	cache, users := preparePosts(benchPostCount)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = onePagePosts(&PageOptions{CallerID: "user0", Flow: FlowOptions{Hashtag: "tag42"}}, allPosts(cache), users)
+	}
+}
+
+//
+//  Indexed
+//
+
+func BenchmarkOnePagePostsIndexed(b *testing.B) {
+	cache, users := preparePosts(benchPostCount)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = onePagePosts(&PageOptions{CallerID: "user0", Flow: FlowOptions{Plain: true}}, cache.PostIndex(), users)
+	}
+}
+
+func BenchmarkOnePagePostsIndexedHashtag(b *testing.B) {
+	cache, users := preparePosts(benchPostCount)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = onePagePosts(&PageOptions{CallerID: "user0", Flow: FlowOptions{Hashtag: "tag42"}}, cache.PostIndex(), users)
+	}
+}
@@ -0,0 +1,155 @@
+package passphrase
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+
+	"go.vxn.dev/littr/pkg/config"
+)
+
+//
+//  Passphrase hashes
+//  The hashes are stored in the PHC string format encoding the algorithm, its version and parameters, and the salt together with the hash:
+//  $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash> (base64 without padding). The legacy hashes are the hex-encoded sha512(passphrase + APP_PEPPER)
+//  sums, still accepted by Verify, and reported to be rehashed.
+//
+
+// Argon2id parameters of the new hashes (see RFC 9106, the second recommended option).
+const (
+	argonMemory  uint32 = 64 * 1024
+	argonTime    uint32 = 3
+	argonThreads uint8  = 2
+	argonKeyLen  uint32 = 32
+	argonSaltLen        = 16
+)
+
+// argonPrefix is the PHC identifier of the Argon2id hashes.
+const argonPrefix = "$argon2id$"
+
+// legacyHashLen is the length of the hex-encoded SHA-512 sum.
+const legacyHashLen = sha512.Size * 2
+
+var (
+	ErrBlankPassphrase = errors.New("passphrase is blank")
+	ErrUnknownFormat   = errors.New("unknown passphrase hash format")
+	ErrNoServerSecret  = errors.New("server secret (APP_PEPPER) not set, cannot verify the legacy hash")
+)
+
+type argonParams struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+var defaultParams = argonParams{
+	memory:  argonMemory,
+	time:    argonTime,
+	threads: argonThreads,
+}
+
+// Hash returns the Argon2id hash of the passphrase in the PHC string format.
+func Hash(passphrase string) (string, error) {
+	if passphrase == "" {
+		return "", ErrBlankPassphrase
+	}
+
+	salt := make([]byte, argonSaltLen)
+
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	return encodeArgon(defaultParams, salt, argonKey(passphrase, salt, defaultParams, argonKeyLen)), nil
+}
+
+// Verify checks the passphrase against the stored hash of any supported format. The rehash flag is true when the hash matches, but it is not
+// of the current format and parameters, so the caller should store a new hash (see Hash).
+func Verify(passphrase, stored string) (ok bool, rehash bool, err error) {
+	switch {
+	case strings.HasPrefix(stored, argonPrefix):
+		params, salt, key, err := decodeArgon(stored)
+		if err != nil {
+			return false, false, err
+		}
+
+		computed := argonKey(passphrase, salt, params, uint32(len(key)))
+
+		if subtle.ConstantTimeCompare(computed, key) != 1 {
+			return false, false, nil
+		}
+
+		return true, params != defaultParams || len(key) != int(argonKeyLen), nil
+
+	case len(stored) == legacyHashLen:
+		if config.ServerSecret == "" {
+			return false, false, ErrNoServerSecret
+		}
+
+		sum := sha512.Sum512([]byte(passphrase + config.ServerSecret))
+
+		if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(strings.ToLower(stored))) != 1 {
+			return false, false, nil
+		}
+
+		return true, true, nil
+	}
+
+	return false, false, ErrUnknownFormat
+}
+
+func argonKey(passphrase string, salt []byte, params argonParams, keyLen uint32) []byte {
+	return argon2.IDKey([]byte(passphrase), salt, params.time, params.memory, params.threads, keyLen)
+}
+
+func encodeArgon(params argonParams, salt, key []byte) string {
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argonPrefix,
+		argon2.Version,
+		params.memory,
+		params.time,
+		params.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+func decodeArgon(stored string) (argonParams, []byte, []byte, error) {
+	var (
+		params  argonParams
+		version int
+	)
+
+	// "", "argon2id", "v=19", "m=65536,t=3,p=2", salt, key
+	parts := strings.Split(stored, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrUnknownFormat
+	}
+
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version: %w", ErrUnknownFormat)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 parameters: %w", ErrUnknownFormat)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 salt: %w", ErrUnknownFormat)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, fmt.Errorf("invalid argon2 hash: %w", ErrUnknownFormat)
+	}
+
+	return params, salt, key, nil
+}
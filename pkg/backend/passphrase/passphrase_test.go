@@ -0,0 +1,65 @@
+package passphrase
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.vxn.dev/littr/pkg/config"
+)
+
+func TestPassphrase_HashVerify(t *testing.T) {
+	hash, err := Hash("s3creTpauWussw0rt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=2$") {
+		t.Errorf("unexpected hash format: %s", hash)
+	}
+
+	if other, _ := Hash("s3creTpauWussw0rt"); other == hash {
+		t.Error("the hashes of the same passphrase are not salted")
+	}
+
+	if ok, rehash, err := Verify("s3creTpauWussw0rt", hash); !ok || rehash || err != nil {
+		t.Errorf("valid passphrase: ok %t, rehash %t, err %v", ok, rehash, err)
+	}
+
+	if ok, _, err := Verify("wrong", hash); ok || err != nil {
+		t.Errorf("wrong passphrase: ok %t, err %v", ok, err)
+	}
+
+	// The hash of other parameters is valid, but to be rehashed.
+	params, salt, _, _ := decodeArgon(hash)
+	params.time = 1
+
+	weaker := encodeArgon(params, salt, argonKey("s3creTpauWussw0rt", salt, params, argonKeyLen))
+
+	if ok, rehash, err := Verify("s3creTpauWussw0rt", weaker); !ok || !rehash || err != nil {
+		t.Errorf("weaker hash: ok %t, rehash %t, err %v", ok, rehash, err)
+	}
+
+	if _, _, err := Verify("s3creTpauWussw0rt", "plaintext"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestPassphrase_VerifyLegacy(t *testing.T) {
+	if config.ServerSecret == "" {
+		t.Skip("APP_PEPPER not set")
+	}
+
+	sum := sha512.Sum512([]byte("s3creTpauWussw0rt" + config.ServerSecret))
+	legacy := hex.EncodeToString(sum[:])
+
+	if ok, rehash, err := Verify("s3creTpauWussw0rt", legacy); !ok || !rehash || err != nil {
+		t.Errorf("legacy hash: ok %t, rehash %t, err %v", ok, rehash, err)
+	}
+
+	if ok, _, err := Verify("wrong", legacy); ok || err != nil {
+		t.Errorf("wrong passphrase: ok %t, err %v", ok, err)
+	}
+}
@@ -1,6 +1,7 @@
 package polls
 
 import (
+	"errors"
 	"fmt"
 
 	"go.vxn.dev/littr/pkg/backend/common"
@@ -10,7 +11,7 @@ import (
 
 // The implementation of pkg/models.PollRepositoryInterface.
 type PollRepository struct {
-	cache db.Cacher
+	cache *db.TypedCache[models.Poll]
 }
 
 func NewPollRepository(cache db.Cacher) models.PollRepositoryInterface {
@@ -19,26 +20,23 @@ func NewPollRepository(cache db.Cacher) models.PollRepositoryInterface {
 	}
 
 	return &PollRepository{
-		cache: cache,
+		cache: db.NewTypedCache(cache, models.Poll{}),
 	}
 }
 
 func (r *PollRepository) GetAll() (*map[string]models.Poll, error) {
-	rawPolls, count := r.cache.Range()
-	if count == 0 {
-		return nil, fmt.Errorf("no items found")
+	polls := make(map[string]models.Poll, r.cache.Len())
+
+	// Loop over the cache items directly, no copy of the whole cache is made.
+	if err := r.cache.Iterate(func(poll models.Poll) bool {
+		polls[poll.ID] = poll
+		return true
+	}); err != nil {
+		return nil, fmt.Errorf("poll's data corrupted: %w", err)
 	}
 
-	polls := make(map[string]models.Poll)
-
-	// Assert types to fetched interface map.
-	for key, rawPoll := range *rawPolls {
-		poll, ok := rawPoll.(models.Poll)
-		if !ok {
-			return nil, fmt.Errorf("poll's data corrupted")
-		}
-
-		polls[key] = poll
+	if len(polls) == 0 {
+		return nil, fmt.Errorf(common.ERR_NO_ITEMS_FOUND)
 	}
 
 	return &polls, nil
@@ -46,14 +44,12 @@ func (r *PollRepository) GetAll() (*map[string]models.Poll, error) {
 
 func (r *PollRepository) GetByID(pollID string) (*models.Poll, error) {
 	// Fetch the poll from the cache.
-	rawPoll, found := r.cache.Load(pollID)
-	if !found {
+	poll, err := r.cache.Get(pollID)
+	if errors.Is(err, db.ErrItemNotFound) {
 		return nil, fmt.Errorf(common.ERR_POLL_NOT_FOUND)
 	}
 
-	// Assert the type
-	poll, ok := rawPoll.(models.Poll)
-	if !ok {
+	if err != nil {
 		return nil, fmt.Errorf("poll's data corrupted")
 	}
 
@@ -62,18 +58,26 @@ func (r *PollRepository) GetByID(pollID string) (*models.Poll, error) {
 
 func (r *PollRepository) Save(poll *models.Poll) error {
 	// Store the poll using its key in the cache.
-	saved := r.cache.Store(poll.ID, *poll)
-	if !saved {
+	if err := r.cache.Put(*poll); err != nil {
 		return fmt.Errorf("an error occurred while saving a poll")
 	}
 
 	return nil
 }
 
+// Update applies the function to the stored poll and saves the result, only if the poll has not been changed concurrently (see db.TypedCache.Update).
+func (r *PollRepository) Update(pollID string, fn func(poll *models.Poll) error) error {
+	err := r.cache.Update(pollID, fn)
+	if errors.Is(err, db.ErrItemNotFound) {
+		return fmt.Errorf(common.ERR_POLL_NOT_FOUND)
+	}
+
+	return err
+}
+
 func (r *PollRepository) Delete(pollID string) error {
 	// Simple poll's deleting.
-	deleted := r.cache.Delete(pollID)
-	if !deleted {
+	if err := r.cache.Delete(pollID); err != nil {
 		return fmt.Errorf("poll data could not be purged from the database")
 	}
 
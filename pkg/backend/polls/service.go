@@ -6,7 +6,9 @@ import (
 	"strconv"
 	"time"
 
+	"go.vxn.dev/littr/pkg/backend/audit"
 	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
 	"go.vxn.dev/littr/pkg/backend/live"
 	"go.vxn.dev/littr/pkg/backend/pages"
 	"go.vxn.dev/littr/pkg/helpers"
@@ -19,6 +21,7 @@ import (
 
 type PollService struct {
 	pageService    models.PagingServiceInterface
+	transactor     db.Transactor
 	pollRepository models.PollRepositoryInterface
 	postRepository models.PostRepositoryInterface
 	userRepository models.UserRepositoryInterface
@@ -26,16 +29,18 @@ type PollService struct {
 
 func NewPollService(
 	pageService models.PagingServiceInterface,
+	transactor db.Transactor,
 	pollRepository models.PollRepositoryInterface,
 	postRepository models.PostRepositoryInterface,
 	userRepository models.UserRepositoryInterface,
 ) models.PollServiceInterface {
-	if pageService == nil || pollRepository == nil || postRepository == nil || userRepository == nil {
+	if pageService == nil || transactor == nil || pollRepository == nil || postRepository == nil || userRepository == nil {
 		return nil
 	}
 
 	return &PollService{
 		pageService:    pageService,
+		transactor:     transactor,
 		pollRepository: pollRepository,
 		postRepository: postRepository,
 		userRepository: userRepository,
@@ -83,10 +88,6 @@ func (s *PollService) Create(ctx context.Context, pollI interface{}) error {
 	poll.Timestamp = time.Now()
 	poll.ID = strconv.FormatInt(poll.Timestamp.UnixNano(), 10)
 
-	if err := s.pollRepository.Save(poll); err != nil {
-		return err
-	}
-
 	// Prepare timestamps for a new system post to flow.
 	postStamp := time.Now()
 	postKey := strconv.FormatInt(postStamp.UnixNano(), 10)
@@ -100,8 +101,15 @@ func (s *PollService) Create(ctx context.Context, pollI interface{}) error {
 		Timestamp: postStamp,
 	}
 
-	// Dispatch the new post aboat a new poll to postRepository.
-	if err := s.postRepository.Save(post); err != nil {
+	// Save the poll together with its system post, so neither is left behind without the other.
+	if err := s.transactor.RunInTransaction(func(repos *db.Repositories) error {
+		if err := repos.PollRepository.Save(poll); err != nil {
+			return err
+		}
+
+		// Dispatch the new post aboat a new poll to postRepository.
+		return repos.PostRepository.Save(post)
+	}); err != nil {
 		return err
 	}
 
@@ -127,29 +135,43 @@ func (s *PollService) Update(ctx context.Context, pollI interface{}) error {
 		return err
 	}
 
-	// Check the poll's ownership. The author cannot vote on such poll.
-	if dbPoll.Author == callerID {
-		return fmt.Errorf(common.ERR_POLL_SELF_VOTE)
-	}
-
-	// Has the callerID already voted?
-	if helpers.Contains(dbPoll.Voted, callerID) {
-		return fmt.Errorf(common.ERR_POLL_EXISTING_VOTE)
-	}
-
 	// Verify that only one vote had been passed in; suppress vote count forgery.
 	if (pollReq.OptionOneCount + pollReq.OptionTwoCount + pollReq.OptionThreeCount) != (dbPoll.OptionOne.Counter + dbPoll.OptionTwo.Counter + dbPoll.OptionThree.Counter + 1) {
 		return fmt.Errorf(common.ERR_POLL_INVALID_VOTE_COUNT)
 	}
 
-	// Now, update the poll's data.
-	dbPoll.Voted = append(dbPoll.Voted, callerID)
-	dbPoll.OptionOne.Counter = pollReq.OptionOneCount
-	dbPoll.OptionTwo.Counter = pollReq.OptionTwoCount
-	dbPoll.OptionThree.Counter = pollReq.OptionThreeCount
+	// Find the option voted for, the vote is then applied as an increment, so the concurrent votes are not lost.
+	var option func(poll *models.Poll) *models.PollOption
+
+	switch {
+	case pollReq.OptionOneCount == dbPoll.OptionOne.Counter+1:
+		option = func(poll *models.Poll) *models.PollOption { return &poll.OptionOne }
+	case pollReq.OptionTwoCount == dbPoll.OptionTwo.Counter+1:
+		option = func(poll *models.Poll) *models.PollOption { return &poll.OptionTwo }
+	case pollReq.OptionThreeCount == dbPoll.OptionThree.Counter+1:
+		option = func(poll *models.Poll) *models.PollOption { return &poll.OptionThree }
+	default:
+		return fmt.Errorf(common.ERR_POLL_INVALID_VOTE_COUNT)
+	}
+
+	// Apply the vote on the current poll's data, the update is retried if another vote is saved meanwhile.
+	return s.pollRepository.Update(pollReq.ID, func(poll *models.Poll) error {
+		// Check the poll's ownership. The author cannot vote on such poll.
+		if poll.Author == callerID {
+			return fmt.Errorf(common.ERR_POLL_SELF_VOTE)
+		}
+
+		// Has the callerID already voted?
+		if helpers.Contains(poll.Voted, callerID) {
+			return fmt.Errorf(common.ERR_POLL_EXISTING_VOTE)
+		}
+
+		// Now, update the poll's data. The voted list is reallocated, as its backing array is shared with the stored poll.
+		poll.Voted = append(poll.Voted[:len(poll.Voted):len(poll.Voted)], callerID)
+		option(poll).Counter++
 
-	// Save the changes in repository.
-	return s.pollRepository.Save(dbPoll)
+		return nil
+	})
 }
 
 func (s *PollService) Delete(ctx context.Context, pollID string) error {
@@ -168,7 +190,16 @@ func (s *PollService) Delete(ctx context.Context, pollID string) error {
 	}
 
 	// Try to delete the poll.
-	return s.pollRepository.Delete(pollID)
+	if err := s.pollRepository.Delete(pollID); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, models.AuditEvent{
+		Action: audit.ActionPollDelete,
+		Target: pollID,
+	})
+
+	return nil
 }
 
 func (s *PollService) FindAll(ctx context.Context, pageOpts interface{}) (*map[string]models.Poll, *models.User, error) {
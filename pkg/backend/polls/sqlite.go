@@ -0,0 +1,144 @@
+package polls
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+// The implementation of pkg/models.PollRepositoryInterface on the SQLite database (see pkg/backend/db/sqlite.go for the schema).
+type SQLitePollRepository struct {
+	db db.SQLHandle
+}
+
+func NewSQLitePollRepository(handle db.SQLHandle) *SQLitePollRepository {
+	if handle == nil {
+		return nil
+	}
+
+	return &SQLitePollRepository{
+		db: handle,
+	}
+}
+
+func (r *SQLitePollRepository) GetAll() (*map[string]models.Poll, error) {
+	polls, err := r.query("SELECT data FROM polls")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*polls) == 0 {
+		return nil, fmt.Errorf(common.ERR_NO_ITEMS_FOUND)
+	}
+
+	return polls, nil
+}
+
+func (r *SQLitePollRepository) GetByID(pollID string) (*models.Poll, error) {
+	var raw []byte
+
+	err := r.db.QueryRow("SELECT data FROM polls WHERE id = ?", pollID).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(common.ERR_POLL_NOT_FOUND)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var poll models.Poll
+
+	if err := json.Unmarshal(raw, &poll); err != nil {
+		return nil, fmt.Errorf("poll's data corrupted")
+	}
+
+	return &poll, nil
+}
+
+func (r *SQLitePollRepository) Save(poll *models.Poll) error {
+	raw, err := json.Marshal(poll)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(`INSERT INTO polls (id, author, timestamp, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET author = excluded.author, timestamp = excluded.timestamp, data = excluded.data`,
+		poll.ID, poll.Author, poll.Timestamp.UnixNano(), raw); err != nil {
+		return fmt.Errorf("an error occurred while saving a poll: %w", err)
+	}
+
+	return nil
+}
+
+// Update applies the function to the stored poll and saves the result, only if the poll has not been changed concurrently (see db.SQLVersionCondition).
+func (r *SQLitePollRepository) Update(pollID string, fn func(poll *models.Poll) error) error {
+	return db.RetryOnConflict(func() error {
+		poll, err := r.GetByID(pollID)
+		if err != nil {
+			return err
+		}
+
+		version := poll.Version
+
+		if err := fn(poll); err != nil {
+			return err
+		}
+
+		poll.Version = version + 1
+
+		raw, err := json.Marshal(poll)
+		if err != nil {
+			return err
+		}
+
+		res, err := r.db.Exec("UPDATE polls SET author = ?, timestamp = ?, data = ? WHERE id = ? AND "+db.SQLVersionCondition,
+			poll.Author, poll.Timestamp.UnixNano(), raw, pollID, version)
+		if err != nil {
+			return fmt.Errorf("an error occurred while saving a poll: %w", err)
+		}
+
+		return db.CheckSwapped(res)
+	})
+}
+
+func (r *SQLitePollRepository) Delete(pollID string) error {
+	if _, err := r.db.Exec("DELETE FROM polls WHERE id = ?", pollID); err != nil {
+		return fmt.Errorf("poll data could not be purged from the database")
+	}
+
+	return nil
+}
+
+// query runs the query selecting the data column and decodes the rows into a map of polls.
+func (r *SQLitePollRepository) query(query string, args ...interface{}) (*map[string]models.Poll, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	polls := make(map[string]models.Poll)
+
+	for rows.Next() {
+		var raw []byte
+
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+
+		var poll models.Poll
+
+		if err := json.Unmarshal(raw, &poll); err != nil {
+			return nil, fmt.Errorf("poll's data corrupted")
+		}
+
+		polls[poll.ID] = poll
+	}
+
+	return &polls, rows.Err()
+}
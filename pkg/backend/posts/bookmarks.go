@@ -0,0 +1,54 @@
+package posts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+// AddBookmark saves the post to the caller's bookmarks, bookmarking the post again changes nothing.
+func (s *postService) AddBookmark(ctx context.Context, postID string) error {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if postID == "" {
+		return fmt.Errorf(common.ERR_POSTID_BLANK)
+	}
+
+	// Verify the post exists, and the caller is allowed to see it.
+	if _, err := s.FindVisible(ctx, postID); err != nil {
+		return err
+	}
+
+	return s.userRepository.Update(callerID, func(user *models.User) error {
+		if user.HasBookmarked(postID) {
+			return nil
+		}
+
+		if len(user.Bookmarks) >= models.MaxBookmarks {
+			return fmt.Errorf(common.ERR_BOOKMARK_LIMIT)
+		}
+
+		user.AddBookmark(postID, time.Now())
+		return nil
+	})
+}
+
+// RemoveBookmark drops the post from the caller's bookmarks. The post does not have to exist anymore, so the dangling bookmarks can be
+// removed too.
+func (s *postService) RemoveBookmark(ctx context.Context, postID string) error {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if postID == "" {
+		return fmt.Errorf(common.ERR_POSTID_BLANK)
+	}
+
+	return s.userRepository.Update(callerID, func(user *models.User) error {
+		user.RemoveBookmarks(postID)
+		return nil
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	chi "github.com/go-chi/chi/v5"
 
@@ -156,21 +157,173 @@ func (c *PostController) Create(w http.ResponseWriter, r *http.Request) {
 	l.Msg("ok, adding new post").Status(http.StatusCreated).Log().Payload(post).Write(w)
 }
 
-// UpdateReactions increases the star count for the given post.
+// Edit changes the content of the caller's post.
 //
-//	@Summary		Update post's star count
-//	@Description		This function call is used to increase the post reactions counter value. It add one (1) reaction to the current state of a post.
+//	@Summary		Edit a post
+//	@Description		This function call replaces the content of the caller's text post, the previous content is kept as a revision. The post can be edited within the edit window after its creation only (see `POST_EDIT_WINDOW`, 15 minutes by default).
 //	@Tags			posts
+//	@Accept			json
 //	@Produce		json
-//	@Param			postID	path			string		true						"Post ID to update."
-//	@Success		200		{object}	common.APIResponse{data=posts.UpdateReactions.responseData}	"Counter value has been increased successfully."
+//	@Param			postID	path			string			true						"Post ID to edit."
+//	@Param			request	body			posts.PostEditRequest	true						"The new post's content."
+//	@Success		200		{object}	common.APIResponse{data=posts.Edit.responseData}		"The post has been edited."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}				"Invalid data input (e.g. a blank content, or a poll to edit)."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}				"User unauthorized."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}				"Forbidden action happened (e.g. a foreign post, or the edit window has passed)."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}				"Such post does not exist."
+//	@Failure		429		{object}	common.APIResponse{data=models.Stub}				"Too many requests, try again later."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}				"Internal server problem occurred while processing the request."
+//	@Router			/posts/{postID} [patch]
+func (c *PostController) Edit(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, loggerWorkerName)
+
+	type responseData struct {
+		Posts map[string]models.Post `json:"posts"`
+	}
+
+	if l.CallerID() == "" {
+		l.Msg(common.ERR_CALLER_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// take the param from path
+	postID := chi.URLParam(r, "postID")
+	if postID == "" {
+		l.Msg(common.ERR_POSTID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	var dto PostEditRequest
+
+	if err := common.UnmarshalRequestData(r, &dto); err != nil {
+		l.Msg(common.ERR_INPUT_DATA_FAIL).Status(http.StatusBadRequest).Error(err).Log().Payload(nil).Write(w)
+		return
+	}
+
+	post, err := c.postService.Edit(r.Context(), postID, dto.Content)
+	if err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	pl := &responseData{
+		Posts: map[string]models.Post{postID: *post},
+	}
+
+	l.Msg("ok, the post edited").Status(http.StatusOK).Log().Payload(pl).Write(w)
+}
+
+// GetRevisions lists the post's previous contents.
+//
+//	@Summary		Get the post's revisions
+//	@Description		This function call returns the previous contents of the edited post, the oldest one first. Each revision holds the time its content was published.
+//	@Tags			posts
+//	@Produce		json
+//	@Param			postID	path			string		true						"Post ID to list the revisions of."
+//	@Success		200		{object}	common.APIResponse{data=posts.GetRevisions.responseData}	"The post's revisions."
 //	@Failure		400		{object}	common.APIResponse{data=models.Stub}				"Invalid data input."
-//	@Failure		403		{object}	common.APIResponse{data=models.Stub}				"Forbidden action happened (e.g. caller tried to increase the counter value of a post of theirs)."
 //	@Failure		401		{object}	common.APIResponse{data=models.Stub}				"User unauthorized."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}				"The post's author is private or has shaded the caller."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}				"Such post does not exist."
+//	@Failure		429		{object}	common.APIResponse{data=models.Stub}				"Too many requests, try again later."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}				"Internal server problem occurred while processing the request."
+//	@Router			/posts/{postID}/revisions [get]
+func (c *PostController) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, loggerWorkerName)
+
+	type responseData struct {
+		Revisions []models.PostRevision `json:"revisions"`
+		Edited    bool                  `json:"edited"`
+		EditedAt  time.Time             `json:"edited_at"`
+	}
+
+	postID := chi.URLParam(r, "postID")
+	if postID == "" {
+		l.Msg(common.ERR_POSTID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	post, err := c.postService.FindVisible(r.Context(), postID)
+	if err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	pl := &responseData{
+		Revisions: post.Revisions,
+		Edited:    post.Edited,
+		EditedAt:  post.EditedAt,
+	}
+
+	if pl.Revisions == nil {
+		pl.Revisions = []models.PostRevision{}
+	}
+
+	l.Msg("ok, listing the post's revisions").Status(http.StatusOK).Log().Payload(pl).Write(w)
+}
+
+// UpdateReactions gives the post a star.
+//
+//	@Summary		Star a post (deprecated)
+//	@Description		This function call gives the post a star, the same as `PUT /posts/{postID}/reactions/star` does. Starring the post again changes nothing.
+//	@Tags			posts
+//	@Produce		json
+//	@Param			postID	path			string		true						"Post ID to star."
+//	@Success		200		{object}	common.APIResponse{data=posts.updateReaction.responseData}	"The post has been starred."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}				"Invalid data input."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}				"Forbidden action happened (e.g. caller tried to star a post of theirs, or a post of a private author)."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}				"User unauthorized."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}				"Such post does not exist."
 //	@Failure		429		{object}	common.APIResponse{data=models.Stub}				"Too many requests, try again later."
 //	@Failure		500		{object}	common.APIResponse{data=models.Stub}				"Internal server problem occurred while processing the request."
 //	@Router			/posts/{postID}/star [patch]
+//	@Deprecated
 func (c *PostController) UpdateReactions(w http.ResponseWriter, r *http.Request) {
+	c.updateReaction(w, r, models.ReactionStar, true)
+}
+
+// AddReaction adds the caller's reaction to the post.
+//
+//	@Summary		Add a reaction
+//	@Description		This function call adds the caller's reaction of such kind (`star`, `heart`, `laugh`, `wow`, `sad`) to the post. Adding the same reaction again changes nothing.
+//	@Tags			posts
+//	@Produce		json
+//	@Param			postID	path			string		true						"Post ID to react to."
+//	@Param			kind	path			string		true						"Reaction kind."
+//	@Success		200		{object}	common.APIResponse{data=posts.updateReaction.responseData}	"The reaction has been added."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}				"Invalid data input (e.g. an unknown reaction kind)."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}				"User unauthorized."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}				"The caller cannot react to a post of theirs, or to a post of a private (or shading) author."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}				"Such post does not exist."
+//	@Failure		429		{object}	common.APIResponse{data=models.Stub}				"Too many requests, try again later."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}				"Internal server problem occurred while processing the request."
+//	@Router			/posts/{postID}/reactions/{kind} [put]
+func (c *PostController) AddReaction(w http.ResponseWriter, r *http.Request) {
+	c.updateReaction(w, r, chi.URLParam(r, "kind"), true)
+}
+
+// RemoveReaction takes the caller's reaction to the post back.
+//
+//	@Summary		Remove a reaction
+//	@Description		This function call removes the caller's reaction of such kind from the post. Removing a reaction not given changes nothing.
+//	@Tags			posts
+//	@Produce		json
+//	@Param			postID	path			string		true						"Post ID to take the reaction back from."
+//	@Param			kind	path			string		true						"Reaction kind."
+//	@Success		200		{object}	common.APIResponse{data=posts.updateReaction.responseData}	"The reaction has been removed."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}				"Invalid data input (e.g. an unknown reaction kind)."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}				"User unauthorized."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}				"The caller cannot react to a post of theirs, or to a post of a private (or shading) author."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}				"Such post does not exist."
+//	@Failure		429		{object}	common.APIResponse{data=models.Stub}				"Too many requests, try again later."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}				"Internal server problem occurred while processing the request."
+//	@Router			/posts/{postID}/reactions/{kind} [delete]
+func (c *PostController) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	c.updateReaction(w, r, chi.URLParam(r, "kind"), false)
+}
+
+// updateReaction adds (or removes) the caller's reaction, the updated post is responded with.
+func (c *PostController) updateReaction(w http.ResponseWriter, r *http.Request, kind string, add bool) {
 	l := common.NewLogger(r, loggerWorkerName)
 
 	type responseData struct {
@@ -189,35 +342,156 @@ func (c *PostController) UpdateReactions(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	post, _, err := c.postService.FindByID(r.Context(), postID)
+	var (
+		post *models.Post
+		err  error
+	)
+
+	if add {
+		post, err = c.postService.AddReaction(r.Context(), postID, kind)
+	} else {
+		post, err = c.postService.RemoveReaction(r.Context(), postID, kind)
+	}
+
 	if err != nil {
-		l.Error(err).Status(http.StatusInternalServerError).Log().Write(w)
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
 		return
 	}
 
-	// check if there is a selfrater
-	if post.Nickname == l.CallerID() {
-		l.Msg(common.ERR_POST_SELF_RATE).Status(http.StatusForbidden).Log().Payload(nil).Write(w)
+	pl := &responseData{
+		Posts: map[string]models.Post{postID: *post},
+	}
+
+	l.Msg("ok, the reactions updated").Status(http.StatusOK).Log().Payload(pl).Write(w)
+}
+
+// AddBookmark saves the post to the caller's bookmarks.
+//
+//	@Summary		Bookmark a post
+//	@Description		This function call saves the post to the caller's private bookmarks (see `/users/{userID}/bookmarks`). Bookmarking the post again changes nothing.
+//	@Tags			posts
+//	@Produce		json
+//	@Param			postID	path			string		true						"Post ID to bookmark."
+//	@Success		200		{object}	common.APIResponse{data=posts.updateBookmark.responseData}	"The post has been bookmarked."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}				"Invalid data input."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}				"User unauthorized."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}				"The bookmarks limit has been reached, or the post's author is private (or has shaded the caller)."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}				"Such post does not exist."
+//	@Failure		429		{object}	common.APIResponse{data=models.Stub}				"Too many requests, try again later."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}				"Internal server problem occurred while processing the request."
+//	@Router			/posts/{postID}/bookmark [post]
+func (c *PostController) AddBookmark(w http.ResponseWriter, r *http.Request) {
+	c.updateBookmark(w, r, true)
+}
+
+// RemoveBookmark drops the post from the caller's bookmarks.
+//
+//	@Summary		Remove a bookmark
+//	@Description		This function call drops the post from the caller's bookmarks. The post does not have to exist anymore, removing a bookmark not saved changes nothing.
+//	@Tags			posts
+//	@Produce		json
+//	@Param			postID	path			string		true						"Post ID to remove the bookmark of."
+//	@Success		200		{object}	common.APIResponse{data=posts.updateBookmark.responseData}	"The bookmark has been removed."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}				"Invalid data input."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}				"User unauthorized."
+//	@Failure		429		{object}	common.APIResponse{data=models.Stub}				"Too many requests, try again later."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}				"Internal server problem occurred while processing the request."
+//	@Router			/posts/{postID}/bookmark [delete]
+func (c *PostController) RemoveBookmark(w http.ResponseWriter, r *http.Request) {
+	c.updateBookmark(w, r, false)
+}
+
+// updateBookmark adds (or removes) the caller's bookmark of the post.
+func (c *PostController) updateBookmark(w http.ResponseWriter, r *http.Request, add bool) {
+	l := common.NewLogger(r, loggerWorkerName)
+
+	type responseData struct {
+		PostID     string `json:"post_id"`
+		Bookmarked bool   `json:"bookmarked"`
+	}
+
+	if l.CallerID() == "" {
+		l.Msg(common.ERR_CALLER_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// take the param from path
+	postID := chi.URLParam(r, "postID")
+	if postID == "" {
+		l.Msg(common.ERR_POSTID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
 		return
 	}
 
-	// increment the star count by one (1)
-	post.ReactionCount++
+	var err error
+
+	if add {
+		err = c.postService.AddBookmark(r.Context(), postID)
+	} else {
+		err = c.postService.RemoveBookmark(r.Context(), postID)
+	}
+
+	if err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	pl := &responseData{
+		PostID:     postID,
+		Bookmarked: add,
+	}
+
+	l.Msg("ok, the bookmarks updated").Status(http.StatusOK).Log().Payload(pl).Write(w)
+}
+
+// GetReactions lists the post's reactions.
+//
+//	@Summary		Get the post's reactions
+//	@Description		This function call returns the nicknames of the users reacted to the post per reaction kind, and the reaction counts (the counts include the anonymous stars given before the reactions were recorded per user).
+//	@Tags			posts
+//	@Produce		json
+//	@Param			postID	path			string		true						"Post ID to list the reactions of."
+//	@Success		200		{object}	common.APIResponse{data=posts.GetReactions.responseData}	"The post's reactions."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}				"Invalid data input."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}				"User unauthorized."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}				"The post's author is private or has shaded the caller."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}				"Such post does not exist."
+//	@Failure		429		{object}	common.APIResponse{data=models.Stub}				"Too many requests, try again later."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}				"Internal server problem occurred while processing the request."
+//	@Router			/posts/{postID}/reactions [get]
+func (c *PostController) GetReactions(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, loggerWorkerName)
+
+	type responseData struct {
+		Reactions map[string][]string `json:"reactions"`
+		Counts    map[string]int64    `json:"counts"`
+	}
 
-	if err := c.postService.Update(r.Context(), post); err != nil {
-		l.Msg(common.ERR_POST_SAVE_FAIL).Status(http.StatusInternalServerError).Log().Payload(nil).Write(w)
+	postID := chi.URLParam(r, "postID")
+	if postID == "" {
+		l.Msg(common.ERR_POSTID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
 		return
 	}
 
-	// prepare payload
-	posts := make(map[string]models.Post)
-	posts[postID] = *post
+	post, err := c.postService.FindVisible(r.Context(), postID)
+	if err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
 
 	pl := &responseData{
-		Posts: posts,
+		Reactions: post.Reactions,
+		Counts:    post.ReactionCounts,
 	}
 
-	l.Msg("ok, star count incremented").Status(http.StatusOK).Log().Payload(pl).Write(w)
+	if pl.Reactions == nil {
+		pl.Reactions = map[string][]string{}
+	}
+
+	if pl.Counts == nil {
+		pl.Counts = map[string]int64{}
+	}
+
+	l.Msg("ok, listing the post's reactions").Status(http.StatusOK).Log().Payload(pl).Write(w)
 }
 
 // Delete removes the specified post.
@@ -362,6 +636,67 @@ func (c *PostController) GetByID(w http.ResponseWriter, r *http.Request) {
 	l.Msg("ok, dumping single post and its interactions").Status(http.StatusOK).Log().Payload(pl).Write(w)
 }
 
+// GetThread fetches the conversation around the specified post.
+//
+//	@Summary		Get post's thread
+//	@Description		This function call returns the chain of the posts the specified post replies to (the conversation's root first), and the tree of the replies to the post up to the requested depth. Every post holds the count of its direct replies, the cut off replies are marked with `more`. The content of the private accounts' posts not followed by the caller is flushed.
+//	@Tags			posts
+//	@Produce		json
+//	@Param			postID	path		string		true						"Post ID to fetch the thread of."
+//	@Param			depth	query		integer		false						"Depth of the replies' tree (default is 5, max is 20)."
+//	@Success		200		{object}	common.APIResponse{data=posts.GetThread.responseData}		"Data fetched successfully."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}				"Invalid input data."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}				"User unauthorized."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}				"Such post does not exist."
+//	@Failure		429		{object}	common.APIResponse{data=models.Stub}				"Too many requests, try again later."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}				"Internal server problem occurred while processing the request."
+//	@Router			/posts/{postID}/thread [get]
+func (c *PostController) GetThread(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, loggerWorkerName)
+
+	type responseData struct {
+		Thread models.PostThread      `json:"thread"`
+		Users  map[string]models.User `json:"users"`
+	}
+
+	// skip blank callerID
+	if l.CallerID() == "" {
+		l.Msg(common.ERR_CALLER_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// take the param from path
+	postID := chi.URLParam(r, "postID")
+	if postID == "" {
+		l.Msg(common.ERR_POSTID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	depth := DefaultThreadDepth
+
+	if raw := r.URL.Query().Get("depth"); raw != "" {
+		var err error
+
+		if depth, err = strconv.Atoi(raw); err != nil {
+			l.Msg(common.ERR_THREAD_DEPTH_INVALID).Status(http.StatusBadRequest).Error(err).Log().Payload(nil).Write(w)
+			return
+		}
+	}
+
+	thread, users, err := c.postService.FindThread(r.Context(), postID, depth)
+	if err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	pl := &responseData{
+		Thread: *thread,
+		Users:  *users,
+	}
+
+	l.Msg("ok, dumping the post's thread").Status(http.StatusOK).Log().Payload(pl).Write(w)
+}
+
 // GetByHashtag fetches all posts tagged with the specified hashtag.
 //
 //	@Summary		Get hashtagged post list
@@ -1,15 +1,20 @@
 package posts
 
 import (
+	"errors"
 	"fmt"
 
+	"go.vxn.dev/littr/pkg/backend/common"
 	"go.vxn.dev/littr/pkg/backend/db"
 	"go.vxn.dev/littr/pkg/models"
 )
 
 // The implementation of pkg/models.PostRepositoryInterface.
 type PostRepository struct {
-	cache db.Cacher
+	cache *db.TypedCache[models.Post]
+
+	// index holds the secondary indexes of the cache, nil if the cache maintains none (see db.IndexedPostCache).
+	index *db.PostIndex
 }
 
 func NewPostRepository(cache db.Cacher) *PostRepository {
@@ -17,27 +22,35 @@ func NewPostRepository(cache db.Cacher) *PostRepository {
 		return nil
 	}
 
-	return &PostRepository{
-		cache: cache,
+	repo := &PostRepository{
+		cache: db.NewTypedCache(cache, models.Post{}),
 	}
-}
 
-func (r *PostRepository) GetAll() (*map[string]models.Post, error) {
-	rawPosts, count := r.cache.Range()
-	if count == 0 {
-		return nil, fmt.Errorf("no items found")
+	if indexer, ok := cache.(db.PostIndexer); ok {
+		repo.index = indexer.PostIndex()
 	}
 
-	posts := make(map[string]models.Post)
+	return repo
+}
+
+// PostIndex returns the secondary indexes of the posts, nil if not maintained by the cache.
+func (r *PostRepository) PostIndex() *db.PostIndex {
+	return r.index
+}
 
-	// Assert types to fetched interface map.
-	for key, rawPost := range *rawPosts {
-		post, ok := rawPost.(models.Post)
-		if !ok {
-			return nil, fmt.Errorf("post's data corrupted")
-		}
+func (r *PostRepository) GetAll() (*map[string]models.Post, error) {
+	posts := make(map[string]models.Post, r.cache.Len())
+
+	// Loop over the cache items directly, no copy of the whole cache is made.
+	if err := r.cache.Iterate(func(post models.Post) bool {
+		posts[post.ID] = post
+		return true
+	}); err != nil {
+		return nil, fmt.Errorf("post's data corrupted: %w", err)
+	}
 
-		posts[key] = post
+	if len(posts) == 0 {
+		return nil, fmt.Errorf(common.ERR_NO_ITEMS_FOUND)
 	}
 
 	return &posts, nil
@@ -45,14 +58,12 @@ func (r *PostRepository) GetAll() (*map[string]models.Post, error) {
 
 func (r *PostRepository) GetByID(postID string) (*models.Post, error) {
 	// Fetch the post from the cache.
-	rawPost, found := r.cache.Load(postID)
-	if !found {
+	post, err := r.cache.Get(postID)
+	if errors.Is(err, db.ErrItemNotFound) {
 		return nil, fmt.Errorf("requested post not found")
 	}
 
-	// Assert the type
-	post, ok := rawPost.(models.Post)
-	if !ok {
+	if err != nil {
 		return nil, fmt.Errorf("post's data corrupted")
 	}
 
@@ -61,18 +72,26 @@ func (r *PostRepository) GetByID(postID string) (*models.Post, error) {
 
 func (r *PostRepository) Save(post *models.Post) error {
 	// Store the post using its key in the cache.
-	saved := r.cache.Store(post.ID, *post)
-	if !saved {
+	if err := r.cache.Put(*post); err != nil {
 		return fmt.Errorf("an error occurred while saving a post")
 	}
 
 	return nil
 }
 
+// Update applies the function to the stored post and saves the result, only if the post has not been changed concurrently (see db.TypedCache.Update).
+func (r *PostRepository) Update(postID string, fn func(post *models.Post) error) error {
+	err := r.cache.Update(postID, fn)
+	if errors.Is(err, db.ErrItemNotFound) {
+		return fmt.Errorf("requested post not found")
+	}
+
+	return err
+}
+
 func (r *PostRepository) Delete(postID string) error {
 	// Simple post's deleting.
-	deleted := r.cache.Delete(postID)
-	if !deleted {
+	if err := r.cache.Delete(postID); err != nil {
 		return fmt.Errorf("post data could not be purged from the database")
 	}
 
@@ -19,7 +19,18 @@ func NewPostRouter(postController *PostController) chi.Router {
 		r.Get("/{nick}", getUserPosts)
 	})*/
 
+	r.Patch("/{postID}", postController.Edit)
+	r.Get("/{postID}/revisions", postController.GetRevisions)
+	r.Get("/{postID}/thread", postController.GetThread)
+
 	r.Patch("/{postID}/star", postController.UpdateReactions)
+
+	r.Get("/{postID}/reactions", postController.GetReactions)
+	r.Put("/{postID}/reactions/{kind}", postController.AddReaction)
+	r.Delete("/{postID}/reactions/{kind}", postController.RemoveReaction)
+
+	r.Post("/{postID}/bookmark", postController.AddBookmark)
+	r.Delete("/{postID}/bookmark", postController.RemoveBookmark)
 	r.Delete("/{postID}", postController.Delete)
 
 	r.Get("/hashtags/{hashtag}", postController.GetByHashtag)
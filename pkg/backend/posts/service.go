@@ -8,11 +8,14 @@ import (
 	"strconv"
 	"time"
 
+	"go.vxn.dev/littr/pkg/backend/audit"
 	"go.vxn.dev/littr/pkg/backend/common"
 	"go.vxn.dev/littr/pkg/backend/image"
 	"go.vxn.dev/littr/pkg/backend/live"
 	"go.vxn.dev/littr/pkg/backend/pages"
 	"go.vxn.dev/littr/pkg/backend/push"
+	"go.vxn.dev/littr/pkg/backend/users"
+	"go.vxn.dev/littr/pkg/config"
 	"go.vxn.dev/littr/pkg/models"
 
 	"github.com/maxence-charriere/go-app/v10/pkg/app"
@@ -164,25 +167,118 @@ func (s *postService) Create(ctx context.Context, post *models.Post) error {
 	return nil
 }
 
-func (s *postService) Update(ctx context.Context, post *models.Post) error {
+// Edit replaces the content of the caller's post within the edit window (see config.PostEditWindow), the previous content is kept as a revision.
+func (s *postService) Edit(ctx context.Context, postID, content string) (*models.Post, error) {
 	// Fetch the caller's ID from the context.
 	callerID := common.GetCallerID(ctx)
 
-	// Fetch the actual post to verify its content to be updated..
-	dbPost, err := s.postRepository.GetByID(post.ID)
-	if err != nil {
-		return err
+	if postID == "" {
+		return nil, fmt.Errorf(common.ERR_POSTID_BLANK)
 	}
 
-	// Check the post's ownership. The author cannot vote on such post.
-	if dbPost.Nickname == callerID {
-		return fmt.Errorf(common.ERR_POSTER_INVALID)
+	// Fetch the post to verify it exists at all.
+	if _, err := s.postRepository.GetByID(postID); err != nil {
+		return nil, fmt.Errorf(common.ERR_POST_NOT_FOUND)
 	}
 
-	dbPost.ReactionCount++
+	var (
+		updated models.Post
+		changed bool
+	)
+
+	if err := s.postRepository.Update(postID, func(post *models.Post) error {
+		// Check the post's ownership.
+		if post.Nickname != callerID {
+			return fmt.Errorf(common.ERR_POST_UPDATE_FOREIGN)
+		}
+
+		// The polls and the system posts are not to be edited.
+		if post.Type != "post" {
+			return fmt.Errorf(common.ERR_POST_EDIT_TYPE)
+		}
+
+		if time.Since(post.Timestamp) > config.PostEditWindow {
+			return fmt.Errorf(common.ERR_POST_EDIT_EXPIRED)
+		}
+
+		// Deny blank post.
+		if content == "" && post.Figure == "" {
+			return fmt.Errorf(common.ERR_POST_BLANK)
+		}
 
-	// Save the changes in repository.
-	return s.postRepository.Save(dbPost)
+		// The very same content is no edit, no revision is added.
+		if content == post.Content {
+			updated, changed = *post, false
+			return nil
+		}
+
+		if len(post.Revisions) >= models.MaxPostRevisions {
+			return fmt.Errorf(common.ERR_POST_EDIT_LIMIT)
+		}
+
+		post.Edit(content, time.Now())
+
+		updated, changed = *post, true
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if changed {
+		// Broadcast the post edit event.
+		live.BroadcastMessage(live.EventPayload{Data: "post-edit," + updated.ID + "," + updated.Nickname, Type: "message"})
+	}
+
+	return &updated, nil
+}
+
+func (s *postService) AddReaction(ctx context.Context, postID, kind string) (*models.Post, error) {
+	return s.react(ctx, postID, kind, func(post *models.Post, callerID string) {
+		post.AddReaction(kind, callerID)
+	})
+}
+
+func (s *postService) RemoveReaction(ctx context.Context, postID, kind string) (*models.Post, error) {
+	return s.react(ctx, postID, kind, func(post *models.Post, callerID string) {
+		post.RemoveReaction(kind, callerID)
+	})
+}
+
+// react applies the caller's reaction change to the post. The change is idempotent, the update is retried if another reaction is saved meanwhile.
+func (s *postService) react(ctx context.Context, postID, kind string, change func(post *models.Post, callerID string)) (*models.Post, error) {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if postID == "" {
+		return nil, fmt.Errorf(common.ERR_POSTID_BLANK)
+	}
+
+	if !models.IsValidReaction(kind) {
+		return nil, fmt.Errorf(common.ERR_REACTION_KIND_UNKNOWN)
+	}
+
+	// Verify the post exists, and the caller is allowed to see it.
+	if _, err := s.FindVisible(ctx, postID); err != nil {
+		return nil, err
+	}
+
+	var updated models.Post
+
+	if err := s.postRepository.Update(postID, func(post *models.Post) error {
+		// The author cannot react to such post.
+		if post.Nickname == callerID {
+			return fmt.Errorf(common.ERR_POST_SELF_RATE)
+		}
+
+		change(post, callerID)
+
+		updated = *post
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
 }
 
 func (s *postService) Delete(ctx context.Context, postID string) error {
@@ -201,7 +297,21 @@ func (s *postService) Delete(ctx context.Context, postID string) error {
 	}
 
 	// Try to delete the post.
-	return s.postRepository.Delete(postID)
+	if err := s.postRepository.Delete(postID); err != nil {
+		return err
+	}
+
+	// Drop the post from the users' bookmarks.
+	if err := users.PurgeBookmarks(s.userRepository, postID); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, models.AuditEvent{
+		Action: audit.ActionPostDelete,
+		Target: postID,
+	})
+
+	return nil
 }
 
 func (s *postService) FindAll(ctx context.Context, pageOpts interface{}) (*map[string]models.Post, *map[string]models.User, error) {
@@ -229,7 +339,7 @@ func (s *postService) FindAll(ctx context.Context, pageOpts interface{}) (*map[s
 		},
 	}
 
-	allPosts, err := s.postRepository.GetAll()
+	postData, err := pages.PostData(s.postRepository)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -239,7 +349,7 @@ func (s *postService) FindAll(ctx context.Context, pageOpts interface{}) (*map[s
 		return nil, nil, err
 	}
 
-	iface, err := s.pagingService.GetOne(ctx, opts, allPosts, allUsers)
+	iface, err := s.pagingService.GetOne(ctx, opts, postData, allUsers)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -284,3 +394,31 @@ func (s *postService) FindByID(ctx context.Context, postID string) (*models.Post
 
 	return post, &patchedCaller, nil
 }
+
+// FindVisible returns the post if the caller is allowed to see its content, the same way as in the thread (see canSee).
+func (s *postService) FindVisible(ctx context.Context, postID string) (*models.Post, error) {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	post, err := s.postRepository.GetByID(postID)
+	if err != nil {
+		return nil, fmt.Errorf(common.ERR_POST_NOT_FOUND)
+	}
+
+	caller, err := s.userRepository.GetByID(callerID)
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := s.userRepository.GetByID(post.Nickname)
+	if err != nil {
+		// The author's account is gone, the post is kept as is.
+		author = &models.User{Nickname: post.Nickname}
+	}
+
+	if !canSee(*caller, *author) {
+		return nil, fmt.Errorf(common.ERR_POST_HIDDEN)
+	}
+
+	return post, nil
+}
@@ -0,0 +1,309 @@
+package posts
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/backend/push"
+	"go.vxn.dev/littr/pkg/backend/users"
+	"go.vxn.dev/littr/pkg/config"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+// asCaller returns the context of the request made by such user.
+func asCaller(nickname string) context.Context {
+	return context.WithValue(context.Background(), common.ContextUserKeyName, nickname)
+}
+
+func newTestPostService() (models.PostServiceInterface, *PostRepository) {
+	postRepository := NewPostRepository(db.NewSimpleCache("FlowCache"))
+	userRepository := users.NewUserRepository(db.NewSimpleCache("UserCache"))
+
+	for _, nickname := range []string{"alice", "bob", "cody"} {
+		_ = userRepository.Save(&models.User{Nickname: nickname})
+	}
+
+	return NewPostService(push.NewNotificationService(postRepository, userRepository), &common.MockPagingService{}, postRepository, userRepository), postRepository
+}
+
+func TestPostService_Reactions(t *testing.T) {
+	service, postRepository := newTestPostService()
+
+	// The post starred before the reactions were recorded per user.
+	if err := postRepository.Save(&models.Post{ID: "1", Nickname: "alice", Content: "hello", ReactionCount: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Adding the reaction twice counts it once.
+	for i := 0; i < 2; i++ {
+		post, err := service.AddReaction(asCaller("bob"), "1", models.ReactionStar)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if post.ReactionCounts[models.ReactionStar] != 4 || post.ReactionCount != 4 || !post.HasReacted(models.ReactionStar, "bob") {
+			t.Fatalf("attempt %d: unexpected reactions: %v (total %d)", i, post.ReactionCounts, post.ReactionCount)
+		}
+	}
+
+	post, err := service.AddReaction(asCaller("cody"), "1", models.ReactionHeart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if post.ReactionCounts[models.ReactionHeart] != 1 || post.ReactionCount != 5 {
+		t.Errorf("unexpected reactions: %v (total %d)", post.ReactionCounts, post.ReactionCount)
+	}
+
+	// Removing the reaction twice takes it back once, the anonymous stars are kept.
+	for i := 0; i < 2; i++ {
+		if post, err = service.RemoveReaction(asCaller("bob"), "1", models.ReactionStar); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if post.ReactionCounts[models.ReactionStar] != 3 || post.ReactionCount != 4 || post.HasReacted(models.ReactionStar, "bob") {
+		t.Errorf("unexpected reactions: %v (total %d)", post.ReactionCounts, post.ReactionCount)
+	}
+
+	// The author cannot react, the kinds are checked, and the post has to exist.
+	cases := []struct {
+		caller, postID, kind, err string
+	}{
+		{"alice", "1", models.ReactionStar, common.ERR_POST_SELF_RATE},
+		{"bob", "1", "thumbsdown", common.ERR_REACTION_KIND_UNKNOWN},
+		{"bob", "2", models.ReactionStar, common.ERR_POST_NOT_FOUND},
+	}
+
+	for _, tc := range cases {
+		if _, err := service.AddReaction(asCaller(tc.caller), tc.postID, tc.kind); err == nil || err.Error() != tc.err {
+			t.Errorf("%s reacting %s to %s: expected %q, got %v", tc.caller, tc.kind, tc.postID, tc.err, err)
+		}
+	}
+}
+
+func TestPostService_Edit(t *testing.T) {
+	service, postRepository := newTestPostService()
+
+	created := time.Now().Add(-time.Minute)
+
+	posts := []models.Post{
+		{ID: "1", Type: "post", Nickname: "alice", Content: "helo", Timestamp: created},
+		{ID: "2", Type: "post", Nickname: "alice", Content: "too late", Timestamp: time.Now().Add(-config.PostEditWindow - time.Minute)},
+		{ID: "3", Type: "poll", Nickname: "alice", Content: "a poll", Timestamp: created},
+	}
+
+	for i := range posts {
+		if err := postRepository.Save(&posts[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	post, err := service.Edit(asCaller("alice"), "1", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if post.Content != "hello" || !post.Edited || len(post.Revisions) != 1 {
+		t.Fatalf("the post has not been edited: %+v", post)
+	}
+
+	if rev := post.Revisions[0]; rev.Content != "helo" || !rev.Timestamp.Equal(created) {
+		t.Errorf("unexpected revision: %+v", rev)
+	}
+
+	// The very same content adds no revision.
+	if post, err = service.Edit(asCaller("alice"), "1", "hello"); err != nil || len(post.Revisions) != 1 {
+		t.Errorf("the unchanged content has been revised: %+v (%v)", post, err)
+	}
+
+	post, err = service.Edit(asCaller("alice"), "1", "hello world")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The second revision was published by the first edit.
+	if len(post.Revisions) != 2 || post.Revisions[1].Content != "hello" || post.Revisions[1].Timestamp.Equal(created) {
+		t.Errorf("unexpected revisions: %+v", post.Revisions)
+	}
+
+	stored, err := postRepository.GetByID("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored.Content != "hello world" || len(stored.Revisions) != 2 {
+		t.Errorf("the edit has not been saved: %+v", stored)
+	}
+
+	cases := []struct {
+		caller, postID, content, err string
+	}{
+		{"bob", "1", "hijacked", common.ERR_POST_UPDATE_FOREIGN},
+		{"alice", "1", "", common.ERR_POST_BLANK},
+		{"alice", "2", "still in time?", common.ERR_POST_EDIT_EXPIRED},
+		{"alice", "3", "a better poll", common.ERR_POST_EDIT_TYPE},
+		{"alice", "4", "hello", common.ERR_POST_NOT_FOUND},
+	}
+
+	for _, tc := range cases {
+		if _, err := service.Edit(asCaller(tc.caller), tc.postID, tc.content); err == nil || err.Error() != tc.err {
+			t.Errorf("%s editing %s: expected %q, got %v", tc.caller, tc.postID, tc.err, err)
+		}
+	}
+}
+
+func TestPostService_Bookmarks(t *testing.T) {
+	postRepository := NewPostRepository(db.NewSimpleCache("FlowCache"))
+	userRepository := users.NewUserRepository(db.NewSimpleCache("UserCache"))
+
+	service := NewPostService(push.NewNotificationService(postRepository, userRepository), &common.MockPagingService{}, postRepository, userRepository)
+
+	for _, user := range []models.User{{Nickname: "alice"}, {Nickname: "bob"}, {Nickname: "cody", Private: true}} {
+		if err := userRepository.Save(&user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for id, author := range map[string]string{"1": "alice", "2": "alice", "hidden": "cody"} {
+		if err := postRepository.Save(&models.Post{ID: id, Nickname: author, Content: "hello"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Bookmarking the post twice saves it once.
+	for i := 0; i < 2; i++ {
+		if err := service.AddBookmark(asCaller("bob"), "1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := service.AddBookmark(asCaller("bob"), "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The post has to exist.
+	if err := service.AddBookmark(asCaller("bob"), "3"); err == nil || err.Error() != common.ERR_POST_NOT_FOUND {
+		t.Errorf("bookmarking a missing post: expected %q, got %v", common.ERR_POST_NOT_FOUND, err)
+	}
+
+	// The private author's post is hidden to the non-followers.
+	if err := service.AddBookmark(asCaller("bob"), "hidden"); err == nil || err.Error() != common.ERR_POST_HIDDEN {
+		t.Errorf("bookmarking a hidden post: expected %q, got %v", common.ERR_POST_HIDDEN, err)
+	}
+
+	bob, err := userRepository.GetByID("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bob.Bookmarks) != 2 || !bob.HasBookmarked("1") || !bob.HasBookmarked("2") {
+		t.Errorf("unexpected bookmarks: %v", bob.Bookmarks)
+	}
+
+	// The deleted post is dropped from the bookmarks.
+	if err := service.Delete(asCaller("alice"), "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if bob, err = userRepository.GetByID("bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bob.Bookmarks) != 1 || bob.HasBookmarked("1") {
+		t.Errorf("unexpected bookmarks after the post deletion: %v", bob.Bookmarks)
+	}
+
+	// The bookmarks are limited.
+	if err := userRepository.Update("alice", func(user *models.User) error {
+		for i := 0; i < models.MaxBookmarks; i++ {
+			user.AddBookmark(fmt.Sprintf("gone-%d", i), time.Now())
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.AddBookmark(asCaller("alice"), "2"); err == nil || err.Error() != common.ERR_BOOKMARK_LIMIT {
+		t.Errorf("bookmarking over the limit: expected %q, got %v", common.ERR_BOOKMARK_LIMIT, err)
+	}
+
+	// The dangling bookmark can be removed, so can be one not saved at all.
+	for _, id := range []string{"gone-0", "gone-0"} {
+		if err := service.RemoveBookmark(asCaller("alice"), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := service.AddBookmark(asCaller("alice"), "2"); err != nil {
+		t.Errorf("bookmarking under the limit: %v", err)
+	}
+}
+
+func TestPostService_FindVisible(t *testing.T) {
+	postRepository := NewPostRepository(db.NewSimpleCache("FlowCache"))
+	userRepository := users.NewUserRepository(db.NewSimpleCache("UserCache"))
+
+	service := NewPostService(push.NewNotificationService(postRepository, userRepository), &common.MockPagingService{}, postRepository, userRepository)
+
+	accounts := []models.User{
+		{Nickname: "alice", FlowList: map[string]bool{"cody": true}},
+		{Nickname: "bob"},
+		{Nickname: "cody", Private: true},
+		{Nickname: "dave", ShadeList: map[string]bool{"bob": true}},
+	}
+
+	for i := range accounts {
+		if err := userRepository.Save(&accounts[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, author := range []string{"cody", "dave"} {
+		if err := postRepository.Save(&models.Post{ID: author, Nickname: author, Content: "hello"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The private author's posts are visible to the followers and the author only, the shaded callers cannot see the shading author's posts.
+	cases := []struct {
+		caller, postID, err string
+	}{
+		{"alice", "cody", ""},
+		{"cody", "cody", ""},
+		{"bob", "cody", common.ERR_POST_HIDDEN},
+		{"alice", "dave", ""},
+		{"bob", "dave", common.ERR_POST_HIDDEN},
+		{"bob", "eve", common.ERR_POST_NOT_FOUND},
+	}
+
+	for _, tc := range cases {
+		post, err := service.FindVisible(asCaller(tc.caller), tc.postID)
+
+		switch {
+		case tc.err == "" && (err != nil || post.ID != tc.postID):
+			t.Errorf("%s finding %s: unexpected result: %v, %v", tc.caller, tc.postID, post, err)
+
+		case tc.err != "" && (err == nil || err.Error() != tc.err):
+			t.Errorf("%s finding %s: expected %q, got %v", tc.caller, tc.postID, tc.err, err)
+		}
+	}
+
+	// The hidden posts cannot be reacted to either.
+	if _, err := service.AddReaction(asCaller("bob"), "cody", models.ReactionStar); err == nil || err.Error() != common.ERR_POST_HIDDEN {
+		t.Errorf("non-follower reacted to the private author's post: %v", err)
+	}
+
+	if _, err := service.RemoveReaction(asCaller("bob"), "dave", models.ReactionStar); err == nil || err.Error() != common.ERR_POST_HIDDEN {
+		t.Errorf("shaded caller reacted to the shading author's post: %v", err)
+	}
+
+	post, err := service.AddReaction(asCaller("alice"), "cody", models.ReactionHeart)
+	if err != nil || !post.HasReacted(models.ReactionHeart, "alice") {
+		t.Errorf("follower could not react to the private author's post: %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package posts
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+// The implementation of pkg/models.PostRepositoryInterface on the SQLite database (see pkg/backend/db/sqlite.go for the schema).
+type SQLitePostRepository struct {
+	db db.SQLHandle
+}
+
+func NewSQLitePostRepository(handle db.SQLHandle) *SQLitePostRepository {
+	if handle == nil {
+		return nil
+	}
+
+	return &SQLitePostRepository{
+		db: handle,
+	}
+}
+
+func (r *SQLitePostRepository) GetAll() (*map[string]models.Post, error) {
+	posts, err := r.query("SELECT data FROM posts")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*posts) == 0 {
+		return nil, fmt.Errorf(common.ERR_NO_ITEMS_FOUND)
+	}
+
+	return posts, nil
+}
+
+func (r *SQLitePostRepository) GetByID(postID string) (*models.Post, error) {
+	var raw []byte
+
+	err := r.db.QueryRow("SELECT data FROM posts WHERE id = ?", postID).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("requested post not found")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var post models.Post
+
+	if err := json.Unmarshal(raw, &post); err != nil {
+		return nil, fmt.Errorf("post's data corrupted")
+	}
+
+	return &post, nil
+}
+
+// GetReplies fetches all direct replies to such post using the reply_to_id index.
+func (r *SQLitePostRepository) GetReplies(postID string) (*map[string]models.Post, error) {
+	return r.query("SELECT data FROM posts WHERE reply_to_id = ?", postID)
+}
+
+func (r *SQLitePostRepository) Save(post *models.Post) error {
+	raw, err := json.Marshal(post)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(`INSERT INTO posts (id, nickname, timestamp, reply_to_id, data) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET nickname = excluded.nickname, timestamp = excluded.timestamp, reply_to_id = excluded.reply_to_id, data = excluded.data`,
+		post.ID, post.Nickname, post.Timestamp.UnixNano(), post.ReplyToID, raw); err != nil {
+		return fmt.Errorf("an error occurred while saving a post: %w", err)
+	}
+
+	return nil
+}
+
+// Update applies the function to the stored post and saves the result, only if the post has not been changed concurrently (see db.SQLVersionCondition).
+func (r *SQLitePostRepository) Update(postID string, fn func(post *models.Post) error) error {
+	return db.RetryOnConflict(func() error {
+		post, err := r.GetByID(postID)
+		if err != nil {
+			return err
+		}
+
+		version := post.Version
+
+		if err := fn(post); err != nil {
+			return err
+		}
+
+		post.Version = version + 1
+
+		raw, err := json.Marshal(post)
+		if err != nil {
+			return err
+		}
+
+		res, err := r.db.Exec("UPDATE posts SET nickname = ?, timestamp = ?, reply_to_id = ?, data = ? WHERE id = ? AND "+db.SQLVersionCondition,
+			post.Nickname, post.Timestamp.UnixNano(), post.ReplyToID, raw, postID, version)
+		if err != nil {
+			return fmt.Errorf("an error occurred while saving a post: %w", err)
+		}
+
+		return db.CheckSwapped(res)
+	})
+}
+
+func (r *SQLitePostRepository) Delete(postID string) error {
+	if _, err := r.db.Exec("DELETE FROM posts WHERE id = ?", postID); err != nil {
+		return fmt.Errorf("post data could not be purged from the database")
+	}
+
+	return nil
+}
+
+// query runs the query selecting the data column and decodes the rows into a map of posts.
+func (r *SQLitePostRepository) query(query string, args ...interface{}) (*map[string]models.Post, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := make(map[string]models.Post)
+
+	for rows.Next() {
+		var raw []byte
+
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+
+		var post models.Post
+
+		if err := json.Unmarshal(raw, &post); err != nil {
+			return nil, fmt.Errorf("post's data corrupted")
+		}
+
+		posts[post.ID] = post
+	}
+
+	return &posts, rows.Err()
+}
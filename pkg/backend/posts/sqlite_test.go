@@ -0,0 +1,74 @@
+package posts
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+func TestPosts_SQLiteRepository(t *testing.T) {
+	sqlDB, err := db.OpenSQLiteDatabase(filepath.Join(t.TempDir(), "littr.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	repo := NewSQLitePostRepository(sqlDB)
+	if repo == nil {
+		t.Fatal("nil SQLitePostRepository")
+	}
+
+	posts := []models.Post{
+		{ID: "1", Nickname: "alice", Content: "hello #littr and #go", Timestamp: time.Now()},
+		{ID: "2", Nickname: "bob", Content: "re: #littr #littr", ReplyToID: "1", Timestamp: time.Now()},
+		{ID: "3", Nickname: "alice", Content: "no tags", ReplyToID: "1", Timestamp: time.Now()},
+	}
+
+	for _, post := range posts {
+		if err := repo.Save(&post); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if replies, err := repo.GetReplies("1"); err != nil || len(*replies) != 2 {
+		t.Errorf("expected 2 replies, got %v (err: %v)", replies, err)
+	}
+
+	// The edited content has to be saved.
+	posts[0].Content = "hello again #go"
+	if err := repo.Save(&posts[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if edited, _ := repo.GetByID("1"); edited.Content != "hello again #go" {
+		t.Errorf("stale content after the edit: %+v", edited)
+	}
+
+	// The update has to bump the version.
+	if err := repo.Update("1", func(post *models.Post) error {
+		post.ReactionCount++
+		post.Content = "hello #Updated"
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if updated, _ := repo.GetByID("1"); updated.ReactionCount != 1 || updated.Version != 1 {
+		t.Errorf("unexpected updated post: %+v", updated)
+	}
+
+	if err := repo.Delete("2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.GetByID("2"); err == nil {
+		t.Error("deleted post found")
+	}
+
+	if replies, _ := repo.GetReplies("1"); len(*replies) != 1 {
+		t.Errorf("deleted reply found: %v", replies)
+	}
+}
@@ -0,0 +1,260 @@
+package posts
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Threads
+//  The conversation around a post: the chain of the posts replied to, and the depth-limited tree of the replies. The posts of the private
+//  accounts not followed by the caller, and of the accounts shading the caller, are kept in the tree with their content flushed.
+//
+
+const (
+	// DefaultThreadDepth is the depth of the replies' tree returned unless requested otherwise.
+	DefaultThreadDepth = 5
+
+	// MaxThreadDepth is the maximum depth of the replies' tree to be requested.
+	MaxThreadDepth = 20
+
+	// maxThreadNodes is the size limit of the replies' tree, the further replies are cut off once reached.
+	maxThreadNodes = 500
+
+	// maxThreadAncestors guards the walk up the reply chain against the loops in the corrupted data.
+	maxThreadAncestors = 1000
+)
+
+// replyFinder is implemented by the repositories fetching the replies to a post directly (see SQLitePostRepository.GetReplies).
+type replyFinder interface {
+	GetReplies(postID string) (*map[string]models.Post, error)
+}
+
+// threadBuilder composes the thread's nodes for such caller.
+type threadBuilder struct {
+	caller models.User
+	users  *map[string]models.User
+
+	// replies returns the direct replies to such post.
+	replies func(postID string) ([]models.Post, error)
+
+	// budget is the count of the nodes to be added to the tree still.
+	budget int
+
+	// authors hold the authors of the posts exported.
+	authors map[string]models.User
+}
+
+func (s *postService) FindThread(ctx context.Context, postID string, depth int) (*models.PostThread, *map[string]models.User, error) {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if postID == "" {
+		return nil, nil, fmt.Errorf(common.ERR_POSTID_BLANK)
+	}
+
+	if depth < 0 || depth > MaxThreadDepth {
+		return nil, nil, fmt.Errorf(common.ERR_THREAD_DEPTH_INVALID)
+	}
+
+	post, err := s.postRepository.GetByID(postID)
+	if err != nil {
+		return nil, nil, fmt.Errorf(common.ERR_POST_NOT_FOUND)
+	}
+
+	allUsers, err := s.userRepository.GetAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t := &threadBuilder{
+		caller:  (*allUsers)[callerID],
+		users:   allUsers,
+		replies: s.replyLoader(),
+		budget:  maxThreadNodes,
+		authors: make(map[string]models.User),
+	}
+
+	thread := &models.PostThread{
+		Ancestors: []models.Post{},
+	}
+
+	// Walk up the reply chain until the conversation's root, or a deleted post is reached.
+	seen := map[string]bool{post.ID: true}
+
+	for parentID := post.ReplyToID; parentID != "" && !seen[parentID] && len(thread.Ancestors) < maxThreadAncestors; {
+		seen[parentID] = true
+
+		parent, err := s.postRepository.GetByID(parentID)
+		if err != nil {
+			break
+		}
+
+		replies, err := t.replies(parent.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		parent.ReplyCount = int64(len(replies))
+
+		thread.Ancestors = append(thread.Ancestors, t.export(*parent))
+		parentID = parent.ReplyToID
+	}
+
+	slices.Reverse(thread.Ancestors)
+
+	t.budget--
+
+	if thread.Root, err = t.node(*post, depth); err != nil {
+		return nil, nil, err
+	}
+
+	// Patch the user's data for export.
+	return thread, common.FlushUserData(&t.authors, callerID), nil
+}
+
+// replyLoader returns the function fetching the direct replies to a post: using the post index, or the repository if able to, or the replies
+// of all posts grouped at once otherwise.
+func (s *postService) replyLoader() func(postID string) ([]models.Post, error) {
+	if indexer, ok := s.postRepository.(db.PostIndexer); ok {
+		if index := indexer.PostIndex(); index != nil && index.Len() > 0 {
+			return func(postID string) ([]models.Post, error) {
+				var replies []models.Post
+
+				for _, replyID := range index.Replies(postID) {
+					if reply, found := index.Get(replyID); found {
+						replies = append(replies, reply)
+					}
+				}
+
+				return replies, nil
+			}
+		}
+	}
+
+	if finder, ok := s.postRepository.(replyFinder); ok {
+		return func(postID string) ([]models.Post, error) {
+			posts, err := finder.GetReplies(postID)
+			if err != nil {
+				return nil, err
+			}
+
+			replies := make([]models.Post, 0, len(*posts))
+			for _, reply := range *posts {
+				replies = append(replies, reply)
+			}
+
+			return replies, nil
+		}
+	}
+
+	var byReplyTo map[string][]models.Post
+
+	return func(postID string) ([]models.Post, error) {
+		if byReplyTo == nil {
+			posts, err := s.postRepository.GetAll()
+			if err != nil {
+				return nil, err
+			}
+
+			byReplyTo = make(map[string][]models.Post)
+
+			for _, post := range *posts {
+				if post.ReplyToID != "" {
+					byReplyTo[post.ReplyToID] = append(byReplyTo[post.ReplyToID], post)
+				}
+			}
+		}
+
+		return byReplyTo[postID], nil
+	}
+}
+
+// node composes the post's node with the replies up to such depth.
+func (t *threadBuilder) node(post models.Post, depth int) (models.PostThreadNode, error) {
+	replies, err := t.replies(post.ID)
+	if err != nil {
+		return models.PostThreadNode{}, err
+	}
+
+	post.ReplyCount = int64(len(replies))
+
+	node := models.PostThreadNode{
+		Post:    t.export(post),
+		Replies: []models.PostThreadNode{},
+	}
+
+	if depth == 0 {
+		node.More = len(replies) > 0
+		return node, nil
+	}
+
+	// order replies by timestamp ASC
+	sort.SliceStable(replies, func(i, j int) bool {
+		if replies[i].Timestamp.Equal(replies[j].Timestamp) {
+			return replies[i].ID < replies[j].ID
+		}
+
+		return replies[i].Timestamp.Before(replies[j].Timestamp)
+	})
+
+	for _, reply := range replies {
+		if t.budget <= 0 {
+			node.More = true
+			break
+		}
+
+		t.budget--
+
+		child, err := t.node(reply, depth-1)
+		if err != nil {
+			return models.PostThreadNode{}, err
+		}
+
+		node.Replies = append(node.Replies, child)
+	}
+
+	return node, nil
+}
+
+// export flushes the content of the post the caller is not allowed to see, and keeps the post's author to be exported.
+func (t *threadBuilder) export(post models.Post) models.Post {
+	author := (*t.users)[post.Nickname]
+	t.authors[post.Nickname] = author
+
+	if !canSee(t.caller, author) {
+		return flushContent(post)
+	}
+
+	return post
+}
+
+// canSee tells whether the caller is allowed to see the content of the author's posts.
+func canSee(caller, author models.User) bool {
+	if author.Nickname == caller.Nickname {
+		return true
+	}
+
+	// mange private content
+	if value, found := caller.FlowList[author.Nickname]; (!value || !found) && author.Private {
+		return false
+	}
+
+	// the author has shaded the caller
+	return !author.ShadeList[caller.Nickname]
+}
+
+// flushContent keeps the post's position in the thread only.
+func flushContent(post models.Post) models.Post {
+	post.Content = ""
+	post.Figure = ""
+	post.Revisions = nil
+
+	return post
+}
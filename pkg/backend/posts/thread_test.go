@@ -0,0 +1,147 @@
+package posts
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/backend/push"
+	"go.vxn.dev/littr/pkg/backend/users"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+func TestPostService_FindThread(t *testing.T) {
+	sqlDB, err := db.OpenSQLiteDatabase(filepath.Join(t.TempDir(), "littr.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	repositories := map[string]models.PostRepositoryInterface{
+		"cache":   NewPostRepository(db.NewSimpleCache("FlowCache")),
+		"indexed": NewPostRepository(db.NewIndexedPostCache(db.NewSimpleCache("FlowCache"))),
+		"sqlite":  NewSQLitePostRepository(sqlDB),
+	}
+
+	for name, postRepository := range repositories {
+		t.Run(name, func(t *testing.T) {
+			testFindThread(t, postRepository)
+		})
+	}
+}
+
+func testFindThread(t *testing.T, postRepository models.PostRepositoryInterface) {
+	userRepository := users.NewUserRepository(db.NewSimpleCache("UserCache"))
+
+	service := NewPostService(push.NewNotificationService(postRepository, userRepository), &common.MockPagingService{}, postRepository, userRepository)
+
+	accounts := []models.User{
+		{Nickname: "alice", FlowList: map[string]bool{"alice": true, "bob": true}},
+		{Nickname: "bob"},
+		{Nickname: "cody", Private: true},
+	}
+
+	for i := range accounts {
+		if err := userRepository.Save(&accounts[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The conversation: 1 <- 2 <- 3 <- 4 <- 5, 1 <- 6, and 3 <- 7 (by the private account).
+	base := time.Now()
+
+	posts := []models.Post{
+		{ID: "1", Nickname: "alice", Content: "root"},
+		{ID: "2", Nickname: "bob", Content: "re: root", ReplyToID: "1"},
+		{ID: "3", Nickname: "alice", Content: "re: re: root", ReplyToID: "2"},
+		{ID: "4", Nickname: "bob", Content: "deep", ReplyToID: "3"},
+		{ID: "5", Nickname: "alice", Content: "deeper", ReplyToID: "4"},
+		{ID: "6", Nickname: "bob", Content: "another reply", ReplyToID: "1"},
+		{ID: "7", Nickname: "cody", Content: "secret", ReplyToID: "3"},
+	}
+
+	for i := range posts {
+		posts[i].Type = "post"
+		posts[i].Timestamp = base.Add(time.Duration(i) * time.Second)
+
+		if err := postRepository.Save(&posts[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	thread, users, err := service.FindThread(asCaller("alice"), "3", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The full ancestor chain, the root first.
+	if len(thread.Ancestors) != 2 || thread.Ancestors[0].ID != "1" || thread.Ancestors[1].ID != "2" {
+		t.Fatalf("unexpected ancestors: %+v", thread.Ancestors)
+	}
+
+	if thread.Ancestors[0].ReplyCount != 2 {
+		t.Errorf("expected 2 replies to the root, got %d", thread.Ancestors[0].ReplyCount)
+	}
+
+	root := thread.Root
+	if root.Post.ID != "3" || root.Post.ReplyCount != 2 || len(root.Replies) != 2 || root.More {
+		t.Fatalf("unexpected thread root: %+v", root)
+	}
+
+	// The replies are ordered, and cut off by the depth.
+	deep, secret := root.Replies[0], root.Replies[1]
+
+	if deep.Post.ID != "4" || deep.Post.ReplyCount != 1 || len(deep.Replies) != 0 || !deep.More {
+		t.Errorf("unexpected depth cut-off: %+v", deep)
+	}
+
+	// The private account's post is kept in the tree, without its content.
+	if secret.Post.ID != "7" || secret.Post.Content != "" {
+		t.Errorf("the private content has been exported: %+v", secret.Post)
+	}
+
+	for _, nickname := range []string{"alice", "bob", "cody"} {
+		if _, found := (*users)[nickname]; !found {
+			t.Errorf("the author %s has not been exported", nickname)
+		}
+	}
+
+	// The whole tree from the root.
+	thread, _, err = service.FindThread(asCaller("alice"), "1", MaxThreadDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(thread.Ancestors) != 0 || countNodes(thread.Root) != len(posts) {
+		t.Errorf("expected all %d posts in the thread, got %d", len(posts), countNodes(thread.Root))
+	}
+
+	cases := []struct {
+		postID string
+		depth  int
+		err    string
+	}{
+		{"", 1, common.ERR_POSTID_BLANK},
+		{"1", -1, common.ERR_THREAD_DEPTH_INVALID},
+		{"1", MaxThreadDepth + 1, common.ERR_THREAD_DEPTH_INVALID},
+		{"8", 1, common.ERR_POST_NOT_FOUND},
+	}
+
+	for _, tc := range cases {
+		if _, _, err := service.FindThread(asCaller("alice"), tc.postID, tc.depth); err == nil || err.Error() != tc.err {
+			t.Errorf("thread of %q (depth %d): expected %q, got %v", tc.postID, tc.depth, tc.err, err)
+		}
+	}
+}
+
+func countNodes(node models.PostThreadNode) int {
+	count := 1
+
+	for _, reply := range node.Replies {
+		count += countNodes(reply)
+	}
+
+	return count
+}
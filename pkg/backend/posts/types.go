@@ -8,6 +8,10 @@ type PostCreateRequest struct {
 	FigureData []byte `json:"figure_data" swaggertype:"string" format:"base64" example:"base64 encoded data"`
 }
 
+type PostEditRequest struct {
+	Content string `json:"content" example:"a very random post's content, with the typo fixed"`
+}
+
 type PostPagingRequest struct {
 	PageNo       int
 	PagingSize   int
@@ -38,7 +38,10 @@ func SendNotificationToDevices(opts *NotificationOpts) {
 	// prepare an array for possible invalid devices (expired subscriptions etc)
 	devicesToDelete := []string{}
 
-	var wg sync.WaitGroup
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
 
 	// range devices and fire notifications
 	for _, dev := range *opts.Devices {
@@ -105,7 +108,9 @@ func SendNotificationToDevices(opts *NotificationOpts) {
 			// successful notification processing (webpush) gateway's response is HTTP/201
 			// otherwise is expired or unsubscribed => delete subscription
 			if res.StatusCode != 201 {
+				mu.Lock()
 				devicesToDelete = append(devicesToDelete, dev.UUID)
+				mu.Unlock()
 			}
 
 			l.Msg(common.MSG_WEBPUSH_GW_RESPONSE + bodyString).Status(res.StatusCode).Log()
@@ -115,39 +120,35 @@ func SendNotificationToDevices(opts *NotificationOpts) {
 	wg.Wait()
 
 	// update device list --- do not include devs to delete (ones failed to send the notification to)
-	defer func(devs *[]models.Device, oldUUIDs []string) {
+	defer func(oldUUIDs []string) {
 		// no invalid devices = no worries
 		if len(devicesToDelete) == 0 {
 			return
 		}
 
-		// prepare a new device array
-		newDeviceList := []models.Device{}
+		// filter the receiver's current device list, so the devices subscribed meanwhile are kept
+		err := opts.Repo.Update(opts.Receiver, func(receiver *models.User) error {
+			// prepare a new device array
+			newDeviceList := []models.Device{}
 
-		// loop over devs to cherrypick the currently valid devs
-		for _, dev := range *devs {
-			if helpers.Contains(oldUUIDs, dev.UUID) {
-				continue
-			}
+			// loop over devs to cherrypick the currently valid devs
+			for _, dev := range receiver.Devices {
+				if helpers.Contains(oldUUIDs, dev.UUID) {
+					continue
+				}
 
-			dev.TimeLastUsed = time.Now()
-			newDeviceList = append(newDeviceList, dev)
-		}
+				dev.TimeLastUsed = time.Now()
+				newDeviceList = append(newDeviceList, dev)
+			}
 
-		receiver, err := opts.Repo.GetByID(opts.Receiver)
+			receiver.Devices = newDeviceList
+			return nil
+		})
 		if err != nil {
 			l.Msg(common.ERR_DEVICE_LIST_UPDATE_FAIL).Error(err).Status(http.StatusInternalServerError).Log()
 			return
 		}
 
-		receiver.Devices = newDeviceList
-
-		// save new device array upon the callerID
-		if err := opts.Repo.Save(receiver); err != nil {
-			l.Msg(common.ERR_DEVICE_LIST_UPDATE_FAIL).Error(err).Status(http.StatusInternalServerError).Log()
-			return
-		}
-
 		l.Msg("ok, device list updated").Status(http.StatusOK).Log()
-	}(opts.Devices, devicesToDelete)
+	}(devicesToDelete)
 }
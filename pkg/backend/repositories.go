@@ -0,0 +1,93 @@
+package backend
+
+import (
+	"go.vxn.dev/littr/pkg/backend/apitokens"
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/backend/polls"
+	"go.vxn.dev/littr/pkg/backend/posts"
+	"go.vxn.dev/littr/pkg/backend/requests"
+	"go.vxn.dev/littr/pkg/backend/search"
+	"go.vxn.dev/littr/pkg/backend/tokens"
+	"go.vxn.dev/littr/pkg/backend/users"
+)
+
+// NewRepositories initializes the repositories for services. The SQLite implementations are used when the database keeper holds the SQL database
+// (see config.DataRepositoryBackend), the cache-backed ones otherwise. The polls, posts and users repositories keep the search index up to date.
+func NewRepositories(d db.DatabaseKeeper) *db.Repositories {
+	if sqlDB := d.SQLDatabase(); sqlDB != nil {
+		return newSQLiteRepositories(sqlDB, nil)
+	}
+
+	return newCacheRepositories(d.Database(), nil)
+}
+
+// newSQLiteRepositories initializes the SQLite repositories, the search index updates are handed over to the after function (see search.IndexPosts).
+func newSQLiteRepositories(handle db.SQLHandle, after func(update func())) *db.Repositories {
+	return &db.Repositories{
+		APITokenRepository: apitokens.NewSQLiteAPITokenRepository(handle),
+		PollRepository:     search.IndexPolls(polls.NewSQLitePollRepository(handle), after),
+		PostRepository:     search.IndexPosts(posts.NewSQLitePostRepository(handle), after),
+		RequestRepository:  requests.NewSQLiteRequestRepository(handle),
+		TokenRepository:    tokens.NewSQLiteTokenRepository(handle),
+		UserRepository:     search.IndexUsers(users.NewSQLiteUserRepository(handle), after),
+	}
+}
+
+// newCacheRepositories initializes the cache-backed repositories, the search index updates are handed over to the after function (see search.IndexPosts).
+func newCacheRepositories(caches map[string]db.Cacher, after func(update func())) *db.Repositories {
+	return &db.Repositories{
+		APITokenRepository: apitokens.NewAPITokenRepository(caches["APITokenCache"]),
+		PollRepository:     search.IndexPolls(polls.NewPollRepository(caches["PollCache"]), after),
+		PostRepository:     search.IndexPosts(posts.NewPostRepository(caches["FlowCache"]), after),
+		RequestRepository:  requests.NewRequestRepository(caches["RequestCache"]),
+		TokenRepository:    tokens.NewTokenRepository(caches["TokenCache"]),
+		UserRepository:     search.IndexUsers(users.NewUserRepository(caches["UserCache"]), after),
+	}
+}
+
+// The implementation of db.Transactor.
+type transactor struct {
+	db db.DatabaseKeeper
+}
+
+func NewTransactor(d db.DatabaseKeeper) db.Transactor {
+	if d == nil {
+		return nil
+	}
+
+	return &transactor{
+		db: d,
+	}
+}
+
+// RunInTransaction runs the function using the repositories of a new transaction (see db.Tx). The transaction is committed when the function
+// returns nil, rolled back otherwise. The search index is updated after the commit only.
+func (t *transactor) RunInTransaction(fn func(repos *db.Repositories) error) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	repos := newCacheRepositories(tx.Database(), tx.OnCommit)
+
+	if sqlTx := tx.SQLTx(); sqlTx != nil {
+		repos = newSQLiteRepositories(sqlTx, tx.OnCommit)
+	}
+
+	if err := fn(repos); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ImportSnapshots imports the JSON snapshots into the empty SQLite database (the very first boot with such backend). No-op for the cache-backed repositories.
+func ImportSnapshots(d db.DatabaseKeeper) (string, error) {
+	sqlDB := d.SQLDatabase()
+	if sqlDB == nil {
+		return "", nil
+	}
+
+	return db.ImportSnapshots(sqlDB, NewRepositories(d))
+}
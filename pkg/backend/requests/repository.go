@@ -1,6 +1,7 @@
 package requests
 
 import (
+	"errors"
 	"fmt"
 
 	//"go.vxn.dev/littr/pkg/backend/common"
@@ -11,7 +12,7 @@ import (
 
 // The implementation of pkg/models.RequestRepositoryInterface.
 type RequestRepository struct {
-	cache db.Cacher
+	cache *db.TypedCache[models.Request]
 }
 
 func NewRequestRepository(cache db.Cacher) models.RequestRepositoryInterface {
@@ -20,7 +21,7 @@ func NewRequestRepository(cache db.Cacher) models.RequestRepositoryInterface {
 	}
 
 	return &RequestRepository{
-		cache: cache,
+		cache: db.NewTypedCache(cache, models.Request{}),
 	}
 }
 
@@ -52,14 +53,17 @@ func GetRequestByID(requestID string, cache db.Cacher) (*models.Request, error)
 		return nil, fmt.Errorf("requestID is blank, or cache is nil")
 	}
 
+	return getRequestByID(requestID, db.NewTypedCache(cache, models.Request{}))
+}
+
+func getRequestByID(requestID string, cache *db.TypedCache[models.Request]) (*models.Request, error) {
 	// Fetch the request from the cache.
-	reqRaw, found := cache.Load(requestID)
-	if !found {
+	request, err := cache.Get(requestID)
+	if errors.Is(err, db.ErrItemNotFound) {
 		return nil, fmt.Errorf("request not found")
 	}
 
-	request, ok := reqRaw.(models.Request)
-	if !ok {
+	if err != nil {
 		return nil, fmt.Errorf("could not assert type *models.Request")
 	}
 
@@ -68,7 +72,7 @@ func GetRequestByID(requestID string, cache db.Cacher) (*models.Request, error)
 
 func (r *RequestRepository) GetByID(requestID string) (*models.Request, error) {
 	// Use the static function to get such request.
-	request, err := GetRequestByID(requestID, r.cache)
+	request, err := getRequestByID(requestID, r.cache)
 	if err != nil {
 		return nil, err
 	}
@@ -78,8 +82,7 @@ func (r *RequestRepository) GetByID(requestID string) (*models.Request, error) {
 
 func (r *RequestRepository) Save(request *models.Request) error {
 	// Store the request using its key in the cache.
-	saved := r.cache.Store(request.ID, *request)
-	if !saved {
+	if err := r.cache.Put(*request); err != nil {
 		return fmt.Errorf("an error occurred while saving a request")
 	}
 
@@ -88,8 +91,7 @@ func (r *RequestRepository) Save(request *models.Request) error {
 
 func (r *RequestRepository) Delete(requestID string) error {
 	// Simple request's deletion.
-	deleted := r.cache.Delete(requestID)
-	if !deleted {
+	if err := r.cache.Delete(requestID); err != nil {
 		return fmt.Errorf("request data could not be purged from the database")
 	}
 
@@ -0,0 +1,74 @@
+package requests
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+// The implementation of pkg/models.RequestRepositoryInterface on the SQLite database (see pkg/backend/db/sqlite.go for the schema).
+type SQLiteRequestRepository struct {
+	db db.SQLHandle
+}
+
+func NewSQLiteRequestRepository(handle db.SQLHandle) *SQLiteRequestRepository {
+	if handle == nil {
+		return nil
+	}
+
+	return &SQLiteRequestRepository{
+		db: handle,
+	}
+}
+
+func (r *SQLiteRequestRepository) GetByID(requestID string) (*models.Request, error) {
+	if requestID == "" {
+		return nil, fmt.Errorf("requestID is blank")
+	}
+
+	var raw []byte
+
+	err := r.db.QueryRow("SELECT data FROM requests WHERE id = ?", requestID).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("request not found")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var request models.Request
+
+	if err := json.Unmarshal(raw, &request); err != nil {
+		return nil, fmt.Errorf("could not assert type *models.Request")
+	}
+
+	return &request, nil
+}
+
+func (r *SQLiteRequestRepository) Save(request *models.Request) error {
+	raw, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(`INSERT INTO requests (id, nickname, created_at, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET nickname = excluded.nickname, created_at = excluded.created_at, data = excluded.data`,
+		request.ID, request.Nickname, request.CreatedAt.UnixNano(), raw); err != nil {
+		return fmt.Errorf("an error occurred while saving a request: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteRequestRepository) Delete(requestID string) error {
+	if _, err := r.db.Exec("DELETE FROM requests WHERE id = ?", requestID); err != nil {
+		return fmt.Errorf("request data could not be purged from the database")
+	}
+
+	return nil
+}
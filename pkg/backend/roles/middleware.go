@@ -0,0 +1,39 @@
+package roles
+
+import (
+	"net/http"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+const loggerWorkerName string = "rolesMiddleware"
+
+// RequirePermission returns the middleware passing only the requests of the callers holding such permission. The caller is expected to be
+// authenticated already (see auth.AuthMiddleware), their roles are read from the user's record on every request, so a change applies at once.
+func RequirePermission(userRepository models.UserRepositoryInterface, perm Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l := common.NewLogger(r, loggerWorkerName)
+
+			callerID := common.GetCallerID(r.Context())
+			if callerID == common.DefaultCallerID {
+				l.Msg(common.ERR_CALLER_BLANK).Status(http.StatusUnauthorized).Log().Payload(nil).Write(w)
+				return
+			}
+
+			caller, err := userRepository.GetByID(callerID)
+			if err != nil {
+				l.Msg(common.ERR_CALLER_NOT_FOUND).Status(http.StatusUnauthorized).Error(err).Log().Payload(nil).Write(w)
+				return
+			}
+
+			if !HasPermission(caller, perm) {
+				l.Msg(common.ERR_PERMISSION_DENIED).Status(http.StatusForbidden).Log().Payload(nil).Write(w)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Roles and permissions package for the backend.
+package roles
+
+import (
+	"slices"
+
+	"go.vxn.dev/littr/pkg/config"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Role-based access control
+//  The roles are stored among the user's tags (see models.User.Tags), each role grants a set of permissions. The admins listed in the
+//  config.AdminNicknames hold the admin role regardless of their tags.
+//
+
+type Role string
+
+const (
+	RoleAdmin     Role = "admin"
+	RoleModerator Role = "moderator"
+)
+
+type Permission string
+
+const (
+	// PermUserManage allows one to (de)activate, and delete any user.
+	PermUserManage Permission = "user:manage"
+
+	// PermRoleAssign allows one to assign the roles to users.
+	PermRoleAssign Permission = "role:assign"
+
+	// PermPostDelete allows one to delete any post.
+	PermPostDelete Permission = "post:delete"
+
+	// PermPollDelete allows one to delete any poll.
+	PermPollDelete Permission = "poll:delete"
+
+	// PermDataManage allows one to use the data-intervention routes (dumps, snapshots, migrations).
+	PermDataManage Permission = "data:manage"
+
+	// PermAuditRead allows one to query the audit log.
+	PermAuditRead Permission = "audit:read"
+)
+
+// rolePermissions maps the roles to the permissions granted.
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermUserManage,
+		PermRoleAssign,
+		PermPostDelete,
+		PermPollDelete,
+		PermDataManage,
+		PermAuditRead,
+	},
+	RoleModerator: {
+		PermPostDelete,
+		PermPollDelete,
+	},
+}
+
+// IsValid reports whether the role (or the user's tag) is a known role.
+func IsValid(role string) bool {
+	_, ok := rolePermissions[Role(role)]
+	return ok
+}
+
+// Of returns the roles of such user.
+func Of(user *models.User) []Role {
+	if user == nil {
+		return nil
+	}
+
+	var roles []Role
+
+	if slices.Contains(config.AdminNicknames, user.Nickname) {
+		roles = append(roles, RoleAdmin)
+	}
+
+	for _, tag := range user.Tags {
+		if IsValid(tag) && !slices.Contains(roles, Role(tag)) {
+			roles = append(roles, Role(tag))
+		}
+	}
+
+	return roles
+}
+
+// HasRole reports whether such user holds the role.
+func HasRole(user *models.User, role Role) bool {
+	return slices.Contains(Of(user), role)
+}
+
+// HasPermission reports whether any of the user's roles grants the permission.
+func HasPermission(user *models.User, perm Permission) bool {
+	for _, role := range Of(user) {
+		if slices.Contains(rolePermissions[role], perm) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+package roles
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/config"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+func TestRoles_HasPermission(t *testing.T) {
+	admin := &models.User{Nickname: "alice", Tags: []string{"user", "admin"}}
+	moderator := &models.User{Nickname: "bob", Tags: []string{"moderator"}}
+	user := &models.User{Nickname: "cody", Tags: []string{"user"}}
+
+	if !HasPermission(admin, PermRoleAssign) || !HasPermission(admin, PermPostDelete) {
+		t.Error("admin lacks a permission")
+	}
+
+	if !HasPermission(moderator, PermPollDelete) || HasPermission(moderator, PermUserManage) {
+		t.Error("unexpected moderator's permissions")
+	}
+
+	if HasPermission(user, PermPostDelete) || HasPermission(nil, PermPostDelete) {
+		t.Error("plain user granted a permission")
+	}
+
+	// The bootstrap admins hold the role regardless of their tags.
+	defer func(nicknames []string) { config.AdminNicknames = nicknames }(config.AdminNicknames)
+	config.AdminNicknames = []string{"cody"}
+
+	if !HasRole(user, RoleAdmin) || !HasPermission(user, PermUserManage) {
+		t.Error("bootstrap admin not recognized")
+	}
+}
+
+func TestRoles_RequirePermission(t *testing.T) {
+	handler := RequirePermission(&common.MockUserRepository{}, PermPostDelete)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// No caller in the context.
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/posts/123", nil))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected HTTP 401, got %d", rec.Code)
+	}
+
+	// The caller without any role.
+	req := httptest.NewRequest(http.MethodDelete, "/posts/123", nil)
+	req = req.WithContext(context.WithValue(req.Context(), common.ContextUserKeyName, common.MockUserNickname))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected HTTP 403, got %d", rec.Code)
+	}
+}
@@ -3,6 +3,9 @@
 //	@description		A simple nanoblogging platform.
 //	@description
 //	@description		HTTP cookies must be used for authentication on most routes. These can be obtained by calling the `/auth` route with the appropriate parameters.
+//	@description		Alternatively, a personal API token (see `/users/{userID}/tokens`) can be sent in the `Authorization: Bearer` header.
+//	@description
+//	@description		The `/dump` routes accept the requests signed with the server's secret instead: the `X-Dump-Signature` header holds the HMAC-SHA256 of the method, the URI, the `X-Dump-Timestamp` and `X-Dump-Nonce` headers, and the body's SHA-256 checksum.
 //	@termsOfService		https://www.littr.eu/tos
 
 //	@contact.name		API Support
@@ -28,6 +31,9 @@
 //	@externalDocs.description	Documentation
 //	@externalDocs.url		https://krusty.space/projects/littr/
 
+//	@tag.name		admin
+//	@tag.description	Administration, moderation and the audit log
+
 //	@tag.name		auth
 //	@tag.description	Authentication and HTTP cookies management
 
@@ -43,6 +49,9 @@
 //	@tag.name		posts
 //	@tag.description	Operations with contributions
 
+//	@tag.name		search
+//	@tag.description	Full-text search over posts, users and polls
+
 //	@tag.name		stats
 //	@tag.description	System statistics
 
@@ -61,6 +70,8 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/httprate"
 
+	"go.vxn.dev/littr/pkg/backend/admin"
+	"go.vxn.dev/littr/pkg/backend/audit"
 	"go.vxn.dev/littr/pkg/backend/auth"
 	"go.vxn.dev/littr/pkg/backend/common"
 	"go.vxn.dev/littr/pkg/backend/db"
@@ -70,9 +81,8 @@ import (
 	"go.vxn.dev/littr/pkg/backend/polls"
 	"go.vxn.dev/littr/pkg/backend/posts"
 	"go.vxn.dev/littr/pkg/backend/push"
-	"go.vxn.dev/littr/pkg/backend/requests"
+	"go.vxn.dev/littr/pkg/backend/search"
 	"go.vxn.dev/littr/pkg/backend/stats"
-	"go.vxn.dev/littr/pkg/backend/tokens"
 	"go.vxn.dev/littr/pkg/backend/users"
 	"go.vxn.dev/littr/pkg/config"
 )
@@ -126,43 +136,58 @@ var limiter = httprate.Limit(config.ApiLimiterRequestsCount, time.Second*config.
 func NewAPIRouter(d db.DatabaseKeeper) chi.Router {
 	r := chi.NewRouter()
 
+	// Init repositories for services.
+	repos := NewRepositories(d)
+
+	// Keep the client's IP address for the audit log.
+	r.Use(audit.Middleware)
+
 	// Use the authentication middleware.
-	r.Use(auth.AuthMiddleware(d))
+	r.Use(auth.AuthMiddleware(repos.APITokenRepository, repos.TokenRepository, repos.UserRepository))
 
 	// Use the rate limiter, feature-flagged.
 	if config.IsApiLimiterEnabled {
 		r.Use(limiter)
 	}
 
-	caches := d.Database()
-
 	// Define notFound and methodNotAllowed default handlers.
 	r.NotFound(http.HandlerFunc(NotFoundHandler))
 	r.MethodNotAllowed(http.HandlerFunc(MethodNotAllowedHandler))
 
 	mailService := mail.NewMailService()
 	pagingService := pages.NewPagingService()
-
-	// Init repositories for services.
-	pollRepository := polls.NewPollRepository(caches["PollCache"])
-	postRepository := posts.NewPostRepository(caches["FlowCache"])
-	requestRepository := requests.NewRequestRepository(caches["RequestCache"])
-	tokenRepository := tokens.NewTokenRepository(caches["TokenCache"])
-	userRepository := users.NewUserRepository(caches["UserCache"])
+	transactor := NewTransactor(d)
+
+	apiTokenRepository := repos.APITokenRepository
+	pollRepository := repos.PollRepository
+	postRepository := repos.PostRepository
+	requestRepository := repos.RequestRepository
+	tokenRepository := repos.TokenRepository
+	userRepository := repos.UserRepository
+
+	// Build the search index from the running data, the repositories keep it up to date since.
+	reindex := func() {
+		search.Rebuild(pollRepository, postRepository, userRepository)
+	}
+	reindex()
 
 	// Init services for controllers.
-	authService := auth.NewAuthService(tokenRepository, userRepository)
+	adminService := admin.NewAdminService(transactor, pollRepository, postRepository, tokenRepository, userRepository)
+	authService := auth.NewAuthService(mailService, config.OIDCProviders, tokenRepository, userRepository)
 	notifService := push.NewNotificationService(postRepository, userRepository)
-	pollService := polls.NewPollService(pagingService, pollRepository, postRepository, userRepository)
+	pollService := polls.NewPollService(pagingService, transactor, pollRepository, postRepository, userRepository)
 	postService := posts.NewPostService(notifService, pagingService, postRepository, userRepository)
+	searchService := search.NewSearchService(pollRepository, postRepository, userRepository)
 	statService := stats.NewStatService(pollRepository, postRepository, userRepository)
-	userService := users.NewUserService(mailService, pagingService, pollRepository, postRepository, requestRepository, tokenRepository, userRepository)
+	userService := users.NewUserService(mailService, pagingService, transactor, apiTokenRepository, pollRepository, postRepository, requestRepository, tokenRepository, userRepository)
 
 	// Init controllers for routers.
+	adminController := admin.NewAdminController(adminService)
 	authController := auth.NewAuthController(authService)
-	dumpController := db.NewDumpController(d)
+	dumpController := db.NewDumpController(d, reindex)
 	pollController := polls.NewPollController(pollService)
 	postController := posts.NewPostController(postService, userService)
+	searchController := search.NewSearchController(searchService)
 	statController := stats.NewStatController(statService)
 	userController := users.NewUserController(postService, statService, userService)
 
@@ -174,11 +199,13 @@ func NewAPIRouter(d db.DatabaseKeeper) chi.Router {
 	r.Get("/", rootHandler)
 	r.Get("/health", healthHandler)
 
+	r.Mount("/admin", admin.NewAdminRouter(adminController, userRepository))
 	r.Mount("/auth", auth.NewAuthRouter(authController))
-	r.Mount("/dump", db.NewDumpRouter(dumpController))
+	r.Mount("/dump", db.NewDumpRouter(dumpController, userRepository))
 	r.Mount("/live", live.NewLiveRouter())
 	r.Mount("/polls", polls.NewPollRouter(pollController))
 	r.Mount("/posts", posts.NewPostRouter(postController))
+	r.Mount("/search", search.NewSearchRouter(searchController))
 	r.Mount("/stats", stats.NewStatRouter(statController))
 	r.Mount("/users", users.NewUserRouter(userController))
 
@@ -58,7 +58,7 @@ func TestAPIRouter(t *testing.T) {
 
 	r := chi.NewRouter()
 
-	r.Use(auth.AuthMiddleware(nil))
+	r.Use(auth.AuthMiddleware(nil, nil, nil))
 
 	// Rate limiter (see limiter in pkg/backend/router.go).
 	if config.IsApiLimiterEnabled {
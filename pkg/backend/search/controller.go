@@ -0,0 +1,76 @@
+package search
+
+import (
+	"net/http"
+	"strconv"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+const (
+	loggerWorkerName string = "searchController"
+)
+
+type SearchController struct {
+	searchService models.SearchServiceInterface
+}
+
+func NewSearchController(searchService models.SearchServiceInterface) *SearchController {
+	if searchService == nil {
+		return nil
+	}
+
+	return &SearchController{
+		searchService: searchService,
+	}
+}
+
+// Search fetches a page of the posts, users and polls matching the query.
+//
+//	@Summary		Search posts, users and polls
+//	@Description		This function call searches the posts' content, the users' nicknames, names and descriptions, and the polls' questions and options. The case and the diacritics are ignored, all the words of the query have to be matched. The last word (and every word ending with `*`) matches the words beginning with it. The page number is to be specified using the `X-Page-No` header (default is 0), the page holds up to 25 items of each kind. The posts and polls of the private accounts not followed by the caller, and of the accounts shading the caller, are left out.
+//	@Tags			search
+//	@Produce		json
+//	@Param			q		query		string		true						"The search query."
+//	@Param			type		query		string		false						"The kind of the items to search (posts, users, or polls), all kinds are searched if not set."
+//	@Param			X-Page-No	header		integer		false						"A page number (default is 0)."
+//	@Success		200		{object}	common.APIResponse{data=search.Search.responseData}		"The matching items are returned."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}				"Invalid input data."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}				"User unauthorized."
+//	@Failure		429		{object}	common.APIResponse{data=models.Stub}				"Too many requests, try again later."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}				"Internal server problem occurred while processing the request."
+//	@Router			/search [get]
+func (c *SearchController) Search(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, loggerWorkerName)
+
+	type responseData struct {
+		Results models.SearchResult    `json:"results"`
+		Users   map[string]models.User `json:"users"`
+	}
+
+	// Skip the blank caller's ID.
+	if l.CallerID() == "" {
+		l.Msg(common.ERR_CALLER_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// Parse the X-Page-No header.
+	pageNo, err := strconv.Atoi(r.Header.Get(common.HDR_PAGE_NO))
+	if err != nil {
+		pageNo = 0
+	}
+
+	results, users, err := c.searchService.Search(r.Context(), r.URL.Query().Get("q"), r.URL.Query().Get("type"), pageNo)
+	if err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	pl := &responseData{
+		Results: *results,
+		Users:   *users,
+	}
+
+	l.Msg("ok, dumping the search results").Status(http.StatusOK).Log().Payload(pl).Write(w)
+}
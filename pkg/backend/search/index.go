@@ -0,0 +1,252 @@
+package search
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The kinds of the indexed items.
+const (
+	KindPosts = "posts"
+	KindUsers = "users"
+	KindPolls = "polls"
+)
+
+// Kinds lists all the kinds of the indexed items.
+var Kinds = []string{
+	KindPosts,
+	KindUsers,
+	KindPolls,
+}
+
+// Entry is an item to be indexed.
+type Entry struct {
+	// Kind is the kind of the item (see Kinds).
+	Kind string
+
+	// ID is the item's key in its repository.
+	ID string
+
+	// Owner is the nickname of the item's author (or of the user itself).
+	Owner string
+
+	// Order is the sort key of the hits, the highest first (e.g. the post's timestamp).
+	Order int64
+
+	// Text is the item's searchable text.
+	Text string
+}
+
+// Hit is an item matching the query.
+type Hit struct {
+	ID    string
+	Owner string
+	Order int64
+}
+
+// doc is the indexed item.
+type doc struct {
+	hit   Hit
+	text  string
+	terms []string
+}
+
+// shard is the inverted index of a single kind of the items.
+type shard struct {
+	docs map[string]doc
+
+	// postings map the terms to the IDs of the items containing them.
+	postings map[string]map[string]struct{}
+
+	// terms are the sorted keys of the postings for the prefix lookups, nil when to be sorted again.
+	terms []string
+}
+
+// Index is the in-memory inverted index of the items' text.
+type Index struct {
+	mu     sync.Mutex
+	shards map[string]*shard
+}
+
+func NewIndex() *Index {
+	shards := make(map[string]*shard, len(Kinds))
+
+	for _, kind := range Kinds {
+		shards[kind] = &shard{
+			docs:     make(map[string]doc),
+			postings: make(map[string]map[string]struct{}),
+		}
+	}
+
+	return &Index{
+		shards: shards,
+	}
+}
+
+// Put indexes the entry, the previously indexed text of the same item is replaced.
+func (i *Index) Put(entry Entry) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	s, found := i.shards[entry.Kind]
+	if !found {
+		return
+	}
+
+	hit := Hit{ID: entry.ID, Owner: entry.Owner, Order: entry.Order}
+
+	// Skip the tokenizing when the text is unchanged (e.g. the user's activity has been updated only).
+	if old, found := s.docs[entry.ID]; found && old.text == entry.Text {
+		old.hit = hit
+		s.docs[entry.ID] = old
+		return
+	}
+
+	s.remove(entry.ID)
+
+	terms := Tokenize(entry.Text)
+	slices.Sort(terms)
+	terms = slices.Compact(terms)
+
+	for _, term := range terms {
+		ids, found := s.postings[term]
+		if !found {
+			ids = make(map[string]struct{})
+			s.postings[term] = ids
+			s.terms = nil
+		}
+
+		ids[entry.ID] = struct{}{}
+	}
+
+	s.docs[entry.ID] = doc{
+		hit:   hit,
+		text:  entry.Text,
+		terms: terms,
+	}
+}
+
+// Remove drops the item from the index.
+func (i *Index) Remove(kind, id string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if s, found := i.shards[kind]; found {
+		s.remove(id)
+	}
+}
+
+// Len returns the count of the indexed items of such kind.
+func (i *Index) Len(kind string) int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if s, found := i.shards[kind]; found {
+		return len(s.docs)
+	}
+
+	return 0
+}
+
+// Search returns the items of such kind containing all the terms, ordered by Hit.Order (the highest first), then by their IDs.
+func (i *Index) Search(kind string, terms []Term) []Hit {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	s, found := i.shards[kind]
+	if !found || len(terms) == 0 {
+		return nil
+	}
+
+	var ids map[string]struct{}
+
+	for _, term := range terms {
+		matched := s.match(term)
+
+		if ids == nil {
+			ids = matched
+		} else {
+			for id := range ids {
+				if _, found := matched[id]; !found {
+					delete(ids, id)
+				}
+			}
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+	}
+
+	hits := make([]Hit, 0, len(ids))
+	for id := range ids {
+		hits = append(hits, s.docs[id].hit)
+	}
+
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Order != hits[b].Order {
+			return hits[a].Order > hits[b].Order
+		}
+
+		return hits[a].ID < hits[b].ID
+	})
+
+	return hits
+}
+
+// match returns a new set of the IDs of the items containing the term.
+func (s *shard) match(term Term) map[string]struct{} {
+	ids := make(map[string]struct{})
+
+	if !term.Prefix {
+		for id := range s.postings[term.Text] {
+			ids[id] = struct{}{}
+		}
+
+		return ids
+	}
+
+	if s.terms == nil {
+		s.terms = make([]string, 0, len(s.postings))
+
+		for key := range s.postings {
+			s.terms = append(s.terms, key)
+		}
+
+		slices.Sort(s.terms)
+	}
+
+	start, _ := slices.BinarySearch(s.terms, term.Text)
+
+	for _, key := range s.terms[start:] {
+		if !strings.HasPrefix(key, term.Text) {
+			break
+		}
+
+		for id := range s.postings[key] {
+			ids[id] = struct{}{}
+		}
+	}
+
+	return ids
+}
+
+func (s *shard) remove(id string) {
+	old, found := s.docs[id]
+	if !found {
+		return
+	}
+
+	for _, term := range old.terms {
+		delete(s.postings[term], id)
+
+		if len(s.postings[term]) == 0 {
+			delete(s.postings, term)
+			s.terms = nil
+		}
+	}
+
+	delete(s.docs, id)
+}
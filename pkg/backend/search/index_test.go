@@ -0,0 +1,92 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSearch_Tokenize(t *testing.T) {
+	cases := []struct {
+		text  string
+		terms []string
+	}{
+		{"Příliš žluťoučký kůň úpěl ďábelské ódy", []string{"prilis", "zlutoucky", "kun", "upel", "dabelske", "ody"}},
+		{"#Praha, @alice!", []string{"praha", "alice"}},
+		{"Łódź Øresund Straße Æsir", []string{"lodz", "oresund", "strasse", "aesir"}},
+		{"v2.0 — 42 km", []string{"v2", "0", "42", "km"}},
+		{"   ", []string{}},
+	}
+
+	for _, c := range cases {
+		if terms := Tokenize(c.text); !slices.Equal(terms, c.terms) {
+			t.Errorf("%q: expected %v, got %v", c.text, c.terms, terms)
+		}
+	}
+}
+
+func TestSearch_ParseQuery(t *testing.T) {
+	terms := ParseQuery("Kůň prah* DOG")
+
+	expected := []Term{{"kun", false}, {"prah", true}, {"dog", true}}
+
+	if !slices.Equal(terms, expected) {
+		t.Errorf("expected %v, got %v", expected, terms)
+	}
+
+	if terms := ParseQuery(" !? "); terms != nil {
+		t.Errorf("expected no terms, got %v", terms)
+	}
+}
+
+func TestSearch_Index(t *testing.T) {
+	index := NewIndex()
+
+	index.Put(Entry{Kind: KindPosts, ID: "1", Owner: "alice", Order: 1, Text: "Dobré ráno z Prahy"})
+	index.Put(Entry{Kind: KindPosts, ID: "2", Owner: "bob", Order: 2, Text: "praha is lovely in the morning"})
+	index.Put(Entry{Kind: KindPosts, ID: "3", Owner: "bob", Order: 3, Text: "Brno"})
+	index.Put(Entry{Kind: KindUsers, ID: "praha", Owner: "praha", Text: "praha"})
+
+	ids := func(hits []Hit) []string {
+		var ids []string
+		for _, hit := range hits {
+			ids = append(ids, hit.ID)
+		}
+		return ids
+	}
+
+	cases := []struct {
+		query string
+		ids   []string
+	}{
+		// The newest hit first, the last term matches by its prefix.
+		{"PRAH", []string{"2", "1"}},
+		{"praha", []string{"2"}},
+		{"prah* rano", []string{"1"}},
+		{"prah* morning", []string{"2"}},
+		{"prah* brno", nil},
+		{"ostrava", nil},
+	}
+
+	for _, c := range cases {
+		if got := ids(index.Search(KindPosts, ParseQuery(c.query))); !slices.Equal(got, c.ids) {
+			t.Errorf("%q: expected %v, got %v", c.query, c.ids, got)
+		}
+	}
+
+	// The changed text replaces the old one.
+	index.Put(Entry{Kind: KindPosts, ID: "3", Owner: "bob", Order: 3, Text: "Praha again"})
+
+	if got := ids(index.Search(KindPosts, ParseQuery("brno"))); got != nil {
+		t.Errorf("expected the old text to be dropped, got %v", got)
+	}
+
+	index.Remove(KindPosts, "2")
+
+	if got := ids(index.Search(KindPosts, ParseQuery("prah"))); !slices.Equal(got, []string{"3", "1"}) {
+		t.Errorf("expected [3 1] after the removal, got %v", got)
+	}
+
+	if index.Len(KindPosts) != 2 || index.Len(KindUsers) != 1 {
+		t.Errorf("unexpected index sizes: %d posts, %d users", index.Len(KindPosts), index.Len(KindUsers))
+	}
+}
@@ -0,0 +1,239 @@
+package search
+
+import (
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Indexing repositories
+//  The posts, users and polls repositories wrapped to update the default index after their successful writes. The updates are handed over to
+//  the after function: run at once for the plain repositories, deferred until the commit for the repositories of a transaction (see
+//  db.Tx.OnCommit), so the index never holds the writes rolled back.
+//
+
+// now runs the index update at once.
+func now(update func()) {
+	update()
+}
+
+// replyFinder mirrors the optional method of the post repositories fetching the replies directly (see posts.SQLitePostRepository.GetReplies).
+type replyFinder interface {
+	GetReplies(postID string) (*map[string]models.Post, error)
+}
+
+type indexingPostRepository struct {
+	models.PostRepositoryInterface
+	after func(update func())
+}
+
+// indexedPostRepository keeps the secondary indexes of the wrapped repository visible (see db.PostIndexer).
+type indexedPostRepository struct {
+	*indexingPostRepository
+	indexer db.PostIndexer
+}
+
+func (r *indexedPostRepository) PostIndex() *db.PostIndex {
+	return r.indexer.PostIndex()
+}
+
+// replyFindingPostRepository keeps the direct reply lookup of the wrapped repository visible.
+type replyFindingPostRepository struct {
+	*indexingPostRepository
+	finder replyFinder
+}
+
+func (r *replyFindingPostRepository) GetReplies(postID string) (*map[string]models.Post, error) {
+	return r.finder.GetReplies(postID)
+}
+
+// IndexPosts returns the post repository updating the default index after its successful writes, using the after function (at once if nil).
+func IndexPosts(repo models.PostRepositoryInterface, after func(update func())) models.PostRepositoryInterface {
+	if repo == nil {
+		return nil
+	}
+
+	if after == nil {
+		after = now
+	}
+
+	wrapped := &indexingPostRepository{
+		PostRepositoryInterface: repo,
+		after:                   after,
+	}
+
+	if indexer, ok := repo.(db.PostIndexer); ok {
+		return &indexedPostRepository{indexingPostRepository: wrapped, indexer: indexer}
+	}
+
+	if finder, ok := repo.(replyFinder); ok {
+		return &replyFindingPostRepository{indexingPostRepository: wrapped, finder: finder}
+	}
+
+	return wrapped
+}
+
+func (r *indexingPostRepository) Save(post *models.Post) error {
+	if err := r.PostRepositoryInterface.Save(post); err != nil {
+		return err
+	}
+
+	entry := PostEntry(*post)
+	r.after(func() { Default().Put(entry) })
+
+	return nil
+}
+
+func (r *indexingPostRepository) Update(postID string, fn func(post *models.Post) error) error {
+	var entry Entry
+
+	// The function is run again on the conflicts, the last run is the one saved.
+	if err := r.PostRepositoryInterface.Update(postID, func(post *models.Post) error {
+		if err := fn(post); err != nil {
+			return err
+		}
+
+		entry = PostEntry(*post)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	r.after(func() { Default().Put(entry) })
+
+	return nil
+}
+
+func (r *indexingPostRepository) Delete(postID string) error {
+	if err := r.PostRepositoryInterface.Delete(postID); err != nil {
+		return err
+	}
+
+	r.after(func() { Default().Remove(KindPosts, postID) })
+
+	return nil
+}
+
+type indexingUserRepository struct {
+	models.UserRepositoryInterface
+	after func(update func())
+}
+
+// IndexUsers returns the user repository updating the default index after its successful writes, using the after function (at once if nil).
+func IndexUsers(repo models.UserRepositoryInterface, after func(update func())) models.UserRepositoryInterface {
+	if repo == nil {
+		return nil
+	}
+
+	if after == nil {
+		after = now
+	}
+
+	return &indexingUserRepository{
+		UserRepositoryInterface: repo,
+		after:                   after,
+	}
+}
+
+func (r *indexingUserRepository) Save(user *models.User) error {
+	if err := r.UserRepositoryInterface.Save(user); err != nil {
+		return err
+	}
+
+	entry := UserEntry(*user)
+	r.after(func() { Default().Put(entry) })
+
+	return nil
+}
+
+func (r *indexingUserRepository) Update(userID string, fn func(user *models.User) error) error {
+	var entry Entry
+
+	// The function is run again on the conflicts, the last run is the one saved.
+	if err := r.UserRepositoryInterface.Update(userID, func(user *models.User) error {
+		if err := fn(user); err != nil {
+			return err
+		}
+
+		entry = UserEntry(*user)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	r.after(func() { Default().Put(entry) })
+
+	return nil
+}
+
+func (r *indexingUserRepository) Delete(userID string) error {
+	if err := r.UserRepositoryInterface.Delete(userID); err != nil {
+		return err
+	}
+
+	r.after(func() { Default().Remove(KindUsers, userID) })
+
+	return nil
+}
+
+type indexingPollRepository struct {
+	models.PollRepositoryInterface
+	after func(update func())
+}
+
+// IndexPolls returns the poll repository updating the default index after its successful writes, using the after function (at once if nil).
+func IndexPolls(repo models.PollRepositoryInterface, after func(update func())) models.PollRepositoryInterface {
+	if repo == nil {
+		return nil
+	}
+
+	if after == nil {
+		after = now
+	}
+
+	return &indexingPollRepository{
+		PollRepositoryInterface: repo,
+		after:                   after,
+	}
+}
+
+func (r *indexingPollRepository) Save(poll *models.Poll) error {
+	if err := r.PollRepositoryInterface.Save(poll); err != nil {
+		return err
+	}
+
+	entry := PollEntry(*poll)
+	r.after(func() { Default().Put(entry) })
+
+	return nil
+}
+
+func (r *indexingPollRepository) Update(pollID string, fn func(poll *models.Poll) error) error {
+	var entry Entry
+
+	// The function is run again on the conflicts, the last run is the one saved.
+	if err := r.PollRepositoryInterface.Update(pollID, func(poll *models.Poll) error {
+		if err := fn(poll); err != nil {
+			return err
+		}
+
+		entry = PollEntry(*poll)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	r.after(func() { Default().Put(entry) })
+
+	return nil
+}
+
+func (r *indexingPollRepository) Delete(pollID string) error {
+	if err := r.PollRepositoryInterface.Delete(pollID); err != nil {
+		return err
+	}
+
+	r.after(func() { Default().Remove(KindPolls, pollID) })
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package search_test
+
+import (
+	"testing"
+
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/backend/posts"
+	"go.vxn.dev/littr/pkg/backend/search"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+func TestIndexPosts(t *testing.T) {
+	search.SetDefault(search.NewIndex())
+
+	// The updates are held until "committed".
+	var pending []func()
+
+	postRepository := search.IndexPosts(posts.NewPostRepository(db.NewIndexedPostCache(db.NewSimpleCache("FlowCache"))), func(update func()) {
+		pending = append(pending, update)
+	})
+
+	// The optional lookups of the wrapped repository are kept.
+	if _, ok := postRepository.(db.PostIndexer); !ok {
+		t.Error("post index of the wrapped repository hidden")
+	}
+
+	if err := postRepository.Save(&models.Post{ID: "1", Nickname: "alice", Content: "hello world"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := postRepository.Update("1", func(post *models.Post) error {
+		post.Content = "hello there"
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The failed writes are not indexed.
+	if err := postRepository.Update("2", func(post *models.Post) error { return nil }); err == nil {
+		t.Fatal("missing post updated")
+	}
+
+	if len(pending) != 2 || search.Default().Len(search.KindPosts) != 0 {
+		t.Fatalf("unexpected index updates: %d pending, %d indexed", len(pending), search.Default().Len(search.KindPosts))
+	}
+
+	for _, update := range pending {
+		update()
+	}
+
+	terms := search.ParseQuery("there")
+	if hits := search.Default().Search(search.KindPosts, terms); len(hits) != 1 || hits[0].ID != "1" {
+		t.Errorf("unexpected hits: %v", hits)
+	}
+
+	if err := postRepository.Delete("1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pending) != 3 || search.Default().Len(search.KindPosts) != 1 {
+		t.Fatalf("post removed from the index before the commit")
+	}
+
+	pending[2]()
+
+	if search.Default().Len(search.KindPosts) != 0 {
+		t.Error("deleted post kept in the index")
+	}
+}
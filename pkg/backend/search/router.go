@@ -0,0 +1,13 @@
+package search
+
+import (
+	chi "github.com/go-chi/chi/v5"
+)
+
+func NewSearchRouter(searchController *SearchController) chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", searchController.Search)
+
+	return r
+}
@@ -0,0 +1,113 @@
+// Full-text search package for the backend.
+package search
+
+import (
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Search index
+//  The posts, users and polls are indexed in memory after every successful write by the indexing repositories (see repositories.go), those
+//  of a transaction after its commit only. The whole index is rebuilt from the running data on the server's start and after the data
+//  interventions (see pkg/backend/db.NewDumpController). The hits are checked against the repositories on every search still.
+//
+
+// defaultIndex is the index the indexing repositories write to.
+var defaultIndex atomic.Pointer[Index]
+
+func init() {
+	defaultIndex.Store(NewIndex())
+}
+
+// Default returns the index the indexing repositories write to.
+func Default() *Index {
+	return defaultIndex.Load()
+}
+
+// SetDefault replaces the index the indexing repositories write to (e.g. with a rebuilt one).
+func SetDefault(index *Index) {
+	if index != nil {
+		defaultIndex.Store(index)
+	}
+}
+
+// PostEntry returns the post's index entry.
+func PostEntry(post models.Post) Entry {
+	return Entry{
+		Kind:  KindPosts,
+		ID:    post.ID,
+		Owner: post.Nickname,
+		Order: post.Timestamp.UnixNano(),
+		Text:  post.Content,
+	}
+}
+
+// UserEntry returns the user's index entry, the users are ordered by their nicknames.
+func UserEntry(user models.User) Entry {
+	return Entry{
+		Kind:  KindUsers,
+		ID:    user.Nickname,
+		Owner: user.Nickname,
+		Text:  strings.Join([]string{user.Nickname, user.FullName, user.About}, " "),
+	}
+}
+
+// PollEntry returns the poll's index entry.
+func PollEntry(poll models.Poll) Entry {
+	return Entry{
+		Kind:  KindPolls,
+		ID:    poll.ID,
+		Owner: poll.Author,
+		Order: poll.Timestamp.UnixNano(),
+		Text:  strings.Join([]string{poll.Question, poll.OptionOne.Content, poll.OptionTwo.Content, poll.OptionThree.Content}, " "),
+	}
+}
+
+// Rebuild indexes all the items of the repositories, and replaces the default index with the new one.
+func Rebuild(pollRepository models.PollRepositoryInterface, postRepository models.PostRepositoryInterface, userRepository models.UserRepositoryInterface) {
+	index := NewIndex()
+
+	// The empty repositories report an error.
+	if posts, err := postRepository.GetAll(); err == nil {
+		for _, post := range *posts {
+			index.Put(PostEntry(post))
+		}
+	}
+
+	if users, err := userRepository.GetAll(); err == nil {
+		for _, user := range *users {
+			index.Put(UserEntry(user))
+		}
+	}
+
+	if polls, err := pollRepository.GetAll(); err == nil {
+		for _, poll := range *polls {
+			index.Put(PollEntry(poll))
+		}
+	}
+
+	SetDefault(index)
+}
+
+// Matches reports whether the text contains all the terms.
+func Matches(text string, terms []Term) bool {
+	tokens := Tokenize(text)
+
+	for _, term := range terms {
+		if !slices.ContainsFunc(tokens, func(token string) bool {
+			if term.Prefix {
+				return strings.HasPrefix(token, term.Text)
+			}
+
+			return token == term.Text
+		}) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,207 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/pages"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+// maxQueryLength is the maximum length (in runes) of the search query.
+const maxQueryLength = 200
+
+type searchService struct {
+	pollRepository models.PollRepositoryInterface
+	postRepository models.PostRepositoryInterface
+	userRepository models.UserRepositoryInterface
+}
+
+func NewSearchService(
+	pollRepository models.PollRepositoryInterface,
+	postRepository models.PostRepositoryInterface,
+	userRepository models.UserRepositoryInterface,
+) models.SearchServiceInterface {
+	if pollRepository == nil || postRepository == nil || userRepository == nil {
+		return nil
+	}
+
+	return &searchService{
+		pollRepository: pollRepository,
+		postRepository: postRepository,
+		userRepository: userRepository,
+	}
+}
+
+// Search returns a page of the items of such kind (all kinds if blank) matching the query. The posts and polls of the private accounts not
+// followed by the caller, and of the accounts shading the caller (or shaded by the caller), are left out, so are the users shading the caller.
+func (s *searchService) Search(ctx context.Context, query, kind string, pageNo int) (*models.SearchResult, *map[string]models.User, error) {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if len([]rune(query)) > maxQueryLength {
+		return nil, nil, fmt.Errorf(common.ERR_SEARCH_QUERY_TOO_LONG)
+	}
+
+	if kind != "" && !slices.Contains(Kinds, kind) {
+		return nil, nil, fmt.Errorf(common.ERR_SEARCH_TYPE_UNKNOWN)
+	}
+
+	terms := ParseQuery(query)
+	if len(terms) == 0 {
+		return nil, nil, fmt.Errorf(common.ERR_SEARCH_QUERY_BLANK)
+	}
+
+	if pageNo < 0 {
+		pageNo = 0
+	}
+
+	allUsers, err := s.userRepository.GetAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	caller := (*allUsers)[callerID]
+	index := Default()
+
+	// users hold the matching users and the authors of the matching posts and polls to be exported.
+	users := make(map[string]models.User)
+
+	result := &models.SearchResult{
+		Posts: []models.Post{},
+		Users: []models.User{},
+		Polls: []models.Poll{},
+	}
+
+	// visible reports whether the caller is allowed to see the content of such author.
+	visible := func(nickname string) bool {
+		if nickname == callerID {
+			return true
+		}
+
+		author, found := (*allUsers)[nickname]
+		if !found {
+			return false
+		}
+
+		if author.Private && !caller.FlowList[nickname] {
+			return false
+		}
+
+		return !author.ShadeList[callerID] && !caller.ShadeList[nickname]
+	}
+
+	if kind == "" || kind == KindPosts {
+		var more bool
+
+		result.Posts, more = collect(index.Search(KindPosts, terms), pageNo, func(hit Hit) (models.Post, bool) {
+			if !visible(hit.Owner) {
+				return models.Post{}, false
+			}
+
+			// The hits are checked against the repository as the index could have been outdated.
+			post, err := s.postRepository.GetByID(hit.ID)
+			if err != nil || !visible(post.Nickname) || !Matches(post.Content, terms) {
+				return models.Post{}, false
+			}
+
+			return *post, true
+		})
+
+		for _, post := range result.Posts {
+			users[post.Nickname] = (*allUsers)[post.Nickname]
+		}
+
+		result.More = result.More || more
+	}
+
+	if kind == "" || kind == KindUsers {
+		var more bool
+
+		result.Users, more = collect(index.Search(KindUsers, terms), pageNo, func(hit Hit) (models.User, bool) {
+			user, found := (*allUsers)[hit.ID]
+			if !found || (hit.ID != callerID && user.ShadeList[callerID]) || !Matches(UserEntry(user).Text, terms) {
+				return models.User{}, false
+			}
+
+			return user, true
+		})
+
+		for _, user := range result.Users {
+			users[user.Nickname] = user
+		}
+
+		result.More = result.More || more
+	}
+
+	if kind == "" || kind == KindPolls {
+		var more bool
+
+		result.Polls, more = collect(index.Search(KindPolls, terms), pageNo, func(hit Hit) (models.Poll, bool) {
+			if !visible(hit.Owner) {
+				return models.Poll{}, false
+			}
+
+			poll, err := s.pollRepository.GetByID(hit.ID)
+			if err != nil || !visible(poll.Author) || !Matches(PollEntry(*poll).Text, terms) {
+				return models.Poll{}, false
+			}
+
+			// The hidden and private polls are listed to their authors only.
+			if (poll.Hidden || poll.Private) && poll.Author != callerID {
+				return models.Poll{}, false
+			}
+
+			return *poll, true
+		})
+
+		for _, poll := range result.Polls {
+			users[poll.Author] = (*allUsers)[poll.Author]
+		}
+
+		result.More = result.More || more
+	}
+
+	// Patch the user's data for export.
+	exported := common.FlushUserData(&users, callerID)
+
+	if exported != nil {
+		for i, user := range result.Users {
+			result.Users[i] = (*exported)[user.Nickname]
+		}
+	}
+
+	return result, exported, nil
+}
+
+// collect loads the hits of such page, the hits not to be returned are skipped. The more flag is set when there is another hit to be returned
+// after the page.
+func collect[T any](hits []Hit, pageNo int, load func(hit Hit) (T, bool)) (items []T, more bool) {
+	items = []T{}
+
+	start := pageNo * pages.PAGE_SIZE
+	end := start + pages.PAGE_SIZE
+
+	var count int
+
+	for _, hit := range hits {
+		item, ok := load(hit)
+		if !ok {
+			continue
+		}
+
+		if count >= end {
+			return items, true
+		}
+
+		if count >= start {
+			items = append(items, item)
+		}
+
+		count++
+	}
+
+	return items, false
+}
@@ -0,0 +1,121 @@
+package search_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/backend/polls"
+	"go.vxn.dev/littr/pkg/backend/posts"
+	"go.vxn.dev/littr/pkg/backend/search"
+	"go.vxn.dev/littr/pkg/backend/users"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+func asCaller(nickname string) context.Context {
+	return context.WithValue(context.Background(), common.ContextUserKeyName, nickname)
+}
+
+func TestSearchService_Search(t *testing.T) {
+	search.SetDefault(search.NewIndex())
+
+	pollRepository := search.IndexPolls(polls.NewPollRepository(db.NewSimpleCache("PollCache")), nil)
+	postRepository := search.IndexPosts(posts.NewPostRepository(db.NewSimpleCache("FlowCache")), nil)
+	userRepository := search.IndexUsers(users.NewUserRepository(db.NewSimpleCache("UserCache")), nil)
+
+	service := search.NewSearchService(pollRepository, postRepository, userRepository)
+
+	now := time.Now()
+
+	for _, user := range []models.User{
+		{Nickname: "alice", FullName: "Alice Nováková", FlowList: models.UserGenericMap{"alice": true, "cody": true}},
+		{Nickname: "bob", About: "Praha", ShadeList: models.UserGenericMap{"alice": true}},
+		{Nickname: "cody", Private: true},
+		{Nickname: "dave", Private: true},
+	} {
+		if err := userRepository.Save(&user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, post := range []models.Post{
+		{ID: "1", Nickname: "alice", Content: "Dobré ráno z Prahy"},
+		{ID: "2", Nickname: "bob", Content: "Praha by night"},
+		{ID: "3", Nickname: "cody", Content: "Pražský hrad, praha"},
+		{ID: "4", Nickname: "dave", Content: "praha secret"},
+	} {
+		post.Timestamp = now.Add(time.Duration(i) * time.Minute)
+
+		if err := postRepository.Save(&post); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, poll := range []models.Poll{
+		{ID: "5", Author: "alice", Question: "Praha, nebo Brno?", Timestamp: now},
+		{ID: "6", Author: "cody", Question: "Praha?", Hidden: true, Timestamp: now},
+	} {
+		if err := pollRepository.Save(&poll); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, exported, err := service.Search(asCaller("alice"), "PRAH", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The shading author (bob) and the unfollowed private one (dave) are left out, and so is the foreign hidden poll.
+	var postIDs []string
+	for _, post := range result.Posts {
+		postIDs = append(postIDs, post.ID)
+	}
+
+	if !slices.Equal(postIDs, []string{"3", "1"}) {
+		t.Errorf("expected posts [3 1], got %v", postIDs)
+	}
+
+	if len(result.Polls) != 1 || result.Polls[0].ID != "5" {
+		t.Errorf("expected the poll 5 only, got %v", result.Polls)
+	}
+
+	if len(result.Users) != 0 {
+		t.Errorf("expected no users (bob shades the caller), got %v", result.Users)
+	}
+
+	if _, found := (*exported)["cody"]; !found || (*exported)["cody"].FlowList != nil {
+		t.Errorf("expected the flushed authors to be exported, got %v", *exported)
+	}
+
+	// The edited post is matched by its new content only.
+	if err := postRepository.Update("1", func(post *models.Post) error {
+		post.Content = "Dobrý večer"
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if result, _, err = service.Search(asCaller("alice"), "prahy", search.KindPosts, 0); err != nil || len(result.Posts) != 0 {
+		t.Errorf("expected no posts after the edit, got %v (%v)", result, err)
+	}
+
+	if result, _, err = service.Search(asCaller("cody"), "nováková", search.KindUsers, 0); err != nil || len(result.Users) != 1 {
+		t.Errorf("expected alice to be found, got %v (%v)", result, err)
+	}
+
+	cases := []struct {
+		query, kind, err string
+	}{
+		{" ", "", common.ERR_SEARCH_QUERY_BLANK},
+		{"praha", "hashtags", common.ERR_SEARCH_TYPE_UNKNOWN},
+	}
+
+	for _, c := range cases {
+		if _, _, err := service.Search(asCaller("alice"), c.query, c.kind, 0); err == nil || err.Error() != c.err {
+			t.Errorf("%q (%s): expected %q, got %v", c.query, c.kind, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// maxTermLength is the length (in runes) the terms are cut to, the longer words are matched by their beginning.
+	maxTermLength = 40
+
+	// maxQueryTerms is the maximum count of the terms of a single query.
+	maxQueryTerms = 8
+)
+
+// foldTable lists the letters not decomposed by the NFD normalization, those are folded to their basic latin counterparts.
+var foldTable = map[rune]string{
+	'ł': "l",
+	'ø': "o",
+	'đ': "d",
+	'ð': "d",
+	'ħ': "h",
+	'ı': "i",
+	'ŀ': "l",
+	'ŧ': "t",
+	'þ': "th",
+	'ß': "ss",
+	'æ': "ae",
+	'œ': "oe",
+}
+
+// Fold returns the lowercased text without the diacritics (e.g. "Žluťoučký kůň" => "zlutoucky kun").
+func Fold(text string) string {
+	var b strings.Builder
+
+	b.Grow(len(text))
+
+	for _, r := range norm.NFD.String(text) {
+		// Drop the combining marks (the accents) split off the letters by the decomposition.
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+
+		r = unicode.ToLower(r)
+
+		if folded, found := foldTable[r]; found {
+			b.WriteString(folded)
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// Tokenize splits the text into the folded terms, the letters and digits only are kept (e.g. "#Praha, @alice!" => [praha alice]).
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(Fold(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for i, field := range fields {
+		if runes := []rune(field); len(runes) > maxTermLength {
+			fields[i] = string(runes[:maxTermLength])
+		}
+	}
+
+	return fields
+}
+
+// Term is a single term of the search query.
+type Term struct {
+	// Text is the folded term.
+	Text string
+
+	// Prefix marks the term to match the words beginning with it.
+	Prefix bool
+}
+
+// ParseQuery splits the query into its terms. The words ending with an asterisk (e.g. "prah*") are the prefix terms, and so is the query's
+// last term to match the words being typed.
+func ParseQuery(query string) []Term {
+	var terms []Term
+
+	for _, word := range strings.Fields(query) {
+		tokens := Tokenize(word)
+
+		for i, token := range tokens {
+			terms = append(terms, Term{
+				Text:   token,
+				Prefix: i == len(tokens)-1 && strings.HasSuffix(word, "*"),
+			})
+		}
+	}
+
+	if len(terms) == 0 {
+		return nil
+	}
+
+	if len(terms) > maxQueryTerms {
+		terms = terms[:maxQueryTerms]
+	}
+
+	terms[len(terms)-1].Prefix = true
+
+	return terms
+}
@@ -58,6 +58,7 @@ func (s *StatService) Calculate(ctx context.Context) (*map[string]int64, *map[st
 	//flowStats["users"] = int64(len(*users))
 	flowStats["users"] = -1
 	flowStats["stars"] = 0
+	flowStats["reactions"] = 0
 
 	// Iterate over all posts, compose stats results.
 	for _, val := range *posts {
@@ -69,12 +70,19 @@ func (s *StatService) Calculate(ctx context.Context) (*map[string]int64, *map[st
 			stat.Searched = true
 		}
 
-		// Increase the post count, increase the reaction count sum.
+		// Increase the post count, sum the reactions of all kinds.
 		stat.PostCount++
-		stat.ReactionCount += val.ReactionCount
+
+		for kind, count := range val.CountReactions() {
+			stat.ReactionCount += count
+			flowStats["reactions"] += count
+
+			if kind == models.ReactionStar {
+				flowStats["stars"] += count
+			}
+		}
 
 		userStats[val.Nickname] = stat
-		flowStats["stars"] += val.ReactionCount
 	}
 
 	// Iterate over all users, compose global flower and shade count.
@@ -1,9 +1,10 @@
 package tokens
 
 import (
+	"errors"
 	"fmt"
 
-	//"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/common"
 	"go.vxn.dev/littr/pkg/backend/db"
 	//"go.vxn.dev/littr/pkg/backend/pages"
 	"go.vxn.dev/littr/pkg/models"
@@ -11,7 +12,7 @@ import (
 
 // The implementation of pkg/models.TokenRepositoryInterface.
 type TokenRepository struct {
-	cache db.Cacher
+	cache *db.TypedCache[models.Token]
 }
 
 func NewTokenRepository(cache db.Cacher) models.TokenRepositoryInterface {
@@ -20,7 +21,7 @@ func NewTokenRepository(cache db.Cacher) models.TokenRepositoryInterface {
 	}
 
 	return &TokenRepository{
-		cache: cache,
+		cache: db.NewTypedCache(cache, models.Token{}),
 	}
 }
 
@@ -52,14 +53,17 @@ func GetTokenByID(tokenID string, cache db.Cacher) (*models.Token, error) {
 		return nil, fmt.Errorf("tokenID is blank, or cache is nil")
 	}
 
+	return getTokenByID(tokenID, db.NewTypedCache(cache, models.Token{}))
+}
+
+func getTokenByID(tokenID string, cache *db.TypedCache[models.Token]) (*models.Token, error) {
 	// Fetch the token from the cache.
-	tokRaw, found := cache.Load(tokenID)
-	if !found {
+	token, err := cache.Get(tokenID)
+	if errors.Is(err, db.ErrItemNotFound) {
 		return nil, fmt.Errorf("could not find requested token")
 	}
 
-	token, ok := tokRaw.(models.Token)
-	if !ok {
+	if err != nil {
 		return nil, fmt.Errorf("could not assert type *models.Token")
 	}
 
@@ -67,21 +71,18 @@ func GetTokenByID(tokenID string, cache db.Cacher) (*models.Token, error) {
 }
 
 func (r *TokenRepository) GetAll() (*map[string]models.Token, error) {
-	rawTokens, count := r.cache.Range()
-	if count == 0 {
-		return nil, fmt.Errorf("no items found")
+	tokens := make(map[string]models.Token, r.cache.Len())
+
+	// Loop over the cache items directly, no copy of the whole cache is made.
+	if err := r.cache.Iterate(func(token models.Token) bool {
+		tokens[token.Hash] = token
+		return true
+	}); err != nil {
+		return nil, fmt.Errorf("token's data corrupted: %w", err)
 	}
 
-	tokens := make(map[string]models.Token)
-
-	// Assert types to fetched interface map.
-	for key, rawToken := range *rawTokens {
-		token, ok := rawToken.(models.Token)
-		if !ok {
-			return nil, fmt.Errorf("token's data corrupted")
-		}
-
-		tokens[key] = token
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf(common.ERR_NO_ITEMS_FOUND)
 	}
 
 	return &tokens, nil
@@ -89,7 +90,7 @@ func (r *TokenRepository) GetAll() (*map[string]models.Token, error) {
 
 func (r *TokenRepository) GetByID(tokenID string) (*models.Token, error) {
 	// Use the static function to get such token.
-	token, err := GetTokenByID(tokenID, r.cache)
+	token, err := getTokenByID(tokenID, r.cache)
 	if err != nil {
 		return nil, err
 	}
@@ -97,20 +98,43 @@ func (r *TokenRepository) GetByID(tokenID string) (*models.Token, error) {
 	return token, nil
 }
 
+// GetByNickname fetches all refresh tokens of such user.
+func (r *TokenRepository) GetByNickname(nickname string) (*map[string]models.Token, error) {
+	tokens := make(map[string]models.Token)
+
+	if err := r.cache.Iterate(func(token models.Token) bool {
+		if token.Nickname == nickname {
+			tokens[token.Hash] = token
+		}
+		return true
+	}); err != nil {
+		return nil, fmt.Errorf("token's data corrupted: %w", err)
+	}
+
+	return &tokens, nil
+}
+
 func (r *TokenRepository) Save(token *models.Token) error {
 	// Store the token using its key in the cache.
-	saved := r.cache.Store(token.Hash, *token)
-	if !saved {
+	if err := r.cache.Put(*token); err != nil {
 		return fmt.Errorf("an error occurred while saving a token")
 	}
 
 	return nil
 }
 
+func (r *TokenRepository) Update(tokenID string, fn func(token *models.Token) error) error {
+	err := r.cache.Update(tokenID, fn)
+	if errors.Is(err, db.ErrItemNotFound) {
+		return fmt.Errorf("could not find requested token")
+	}
+
+	return err
+}
+
 func (r *TokenRepository) Delete(tokenID string) error {
 	// Simple token's deletion.
-	deleted := r.cache.Delete(tokenID)
-	if !deleted {
+	if err := r.cache.Delete(tokenID); err != nil {
 		return fmt.Errorf("token data could not be purged from the database")
 	}
 
@@ -0,0 +1,168 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/models"
+
+	"github.com/golang-jwt/jwt"
+)
+
+//
+//  Refresh token rotation
+//  Every use of a refresh token to get a new access token replaces it with a new refresh token of the same family (a chain of the tokens
+//  issued since one login). The replaced token is kept marked as rotated for the grace period, and pruned on the next rotation. Its hash is
+//  kept on the new token (see models.Token.RotatedHashes), so its later use is recognized as a reuse: the token must have been copied, and
+//  the whole family is revoked then, logging out both the thief and the victim.
+//
+
+// RotationGracePeriod is the period a rotated token is still accepted for (without being rotated again), so the concurrent requests sent by
+// one client with the previous refresh token are not mistaken for a reuse.
+const RotationGracePeriod = 30 * time.Second
+
+// MaxRotatedHashes is the count of the family's rotated token hashes kept on the live token (a day of the refreshes every 15 minutes), the
+// reuse of the tokens rotated earlier is not recognized.
+const MaxRotatedHashes = 96
+
+var (
+	ErrTokenRotated = errors.New("such refresh token has been rotated already")
+)
+
+// RotateToken replaces the refresh token with a new one of the same family, and returns the new signed refresh token with its record. The new
+// token expires together with the family (the lifetime of the session is not extended). ErrTokenRotated is returned when the token has been
+// rotated already (e.g. by a concurrent request).
+func RotateToken(token *models.Token, r models.TokenRepositoryInterface, userAgent, ipAddress string) (string, *models.Token, error) {
+	secret := os.Getenv("APP_PEPPER")
+	if secret == "" {
+		return "", nil, fmt.Errorf(common.ERR_NO_SERVER_SECRET)
+	}
+
+	// Mark the token rotated first, only one request can succeed in that.
+	if err := r.Update(token.Hash, func(t *models.Token) error {
+		if t.IsRotated() {
+			return ErrTokenRotated
+		}
+
+		t.RotatedAt = time.Now()
+		return nil
+	}); err != nil {
+		return "", nil, err
+	}
+
+	// Keep the latest rotated hashes of the family, including this token's one.
+	rotated := append(slices.Clone(token.RotatedHashes), token.Hash)
+	if len(rotated) > MaxRotatedHashes {
+		rotated = rotated[len(rotated)-MaxRotatedHashes:]
+	}
+
+	newToken := models.Token{
+		Nickname:      token.Nickname,
+		CreatedAt:     token.CreatedAt,
+		TTL:           token.TTL,
+		UserAgent:     userAgent,
+		IPAddress:     ipAddress,
+		LastUsedAt:    time.Now(),
+		FamilyID:      token.Family(),
+		RotatedHashes: rotated,
+	}
+
+	signedRefreshToken, err := signRefreshToken(&newToken, secret)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := r.Save(&newToken); err != nil {
+		return "", nil, fmt.Errorf(common.ERR_TOKEN_SAVE_FAIL)
+	}
+
+	// The rotated records past the grace period are not needed anymore, their hashes are kept on the new token.
+	if err := pruneRotated(&newToken, r); err != nil {
+		return "", nil, err
+	}
+
+	return signedRefreshToken, &newToken, nil
+}
+
+// pruneRotated deletes the records of the family's tokens rotated longer than the grace period ago.
+func pruneRotated(token *models.Token, r models.TokenRepositoryInterface) error {
+	tokens, err := r.GetByNickname(token.Nickname)
+	if err != nil {
+		return err
+	}
+
+	for key, t := range *tokens {
+		if t.Family() != token.Family() || !IsReused(&t) {
+			continue
+		}
+
+		if err := r.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindRotated returns the live token of the family the pruned refresh token (of such hash) was rotated from, nil if there is none. Only the
+// refresh tokens signed by the server and not expired yet are looked up.
+func FindRotated(refreshToken, hash string, r models.TokenRepositoryInterface) *models.Token {
+	secret := os.Getenv("APP_PEPPER")
+	if secret == "" {
+		return nil
+	}
+
+	claims := &jwt.StandardClaims{}
+
+	parsed, err := jwt.ParseWithClaims(refreshToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	})
+	if err != nil || !parsed.Valid || claims.Subject == "" {
+		return nil
+	}
+
+	// The subject is the token owner's nickname.
+	tokens, err := r.GetByNickname(claims.Subject)
+	if err != nil {
+		return nil
+	}
+
+	for _, t := range *tokens {
+		if !t.IsRotated() && slices.Contains(t.RotatedHashes, hash) {
+			return &t
+		}
+	}
+
+	return nil
+}
+
+// IsReused reports whether the token has been rotated longer than the grace period ago, so it must not be used anymore.
+func IsReused(token *models.Token) bool {
+	return token.IsRotated() && time.Since(token.RotatedAt) > RotationGracePeriod
+}
+
+// RevokeFamily deletes all the tokens of such token's family.
+func RevokeFamily(token *models.Token, r models.TokenRepositoryInterface) error {
+	tokens, err := r.GetByNickname(token.Nickname)
+	if err != nil {
+		return err
+	}
+
+	family := token.Family()
+
+	for key, t := range *tokens {
+		if t.Family() != family {
+			continue
+		}
+
+		if err := r.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package tokens
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+func TestTokens_RotateToken(t *testing.T) {
+	t.Setenv("APP_PEPPER", "test-secret")
+
+	repo := NewTokenRepository(db.NewSimpleCache("TokenCache"))
+
+	if _, err := NewToken(&models.User{Nickname: "alice"}, repo, "agent", "192.0.2.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := repo.GetByNickname("alice")
+	if err != nil || len(*all) != 1 {
+		t.Fatalf("expected one token: %v", err)
+	}
+
+	var first models.Token
+	for _, token := range *all {
+		first = token
+	}
+
+	if first.Family() != first.Hash {
+		t.Errorf("the first token does not start its family: %s", first.FamilyID)
+	}
+
+	_, second, err := RotateToken(&first, repo, "agent", "192.0.2.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if second.Hash == first.Hash || second.Family() != first.Family() || !second.CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("unexpected rotated token: %+v", second)
+	}
+
+	// The previous token cannot be rotated again.
+	if _, _, err := RotateToken(&first, repo, "agent", "192.0.2.3"); !errors.Is(err, ErrTokenRotated) {
+		t.Errorf("the rotated token rotated again: %v", err)
+	}
+
+	rotated, err := repo.GetByID(first.Hash)
+	if err != nil || !rotated.IsRotated() || IsReused(rotated) {
+		t.Errorf("the rotated token is not kept within the grace period: %v", err)
+	}
+
+	// Another login starts another family.
+	if _, err := NewToken(&models.User{Nickname: "alice"}, repo, "agent", "192.0.2.4"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RevokeFamily(rotated, repo); err != nil {
+		t.Fatal(err)
+	}
+
+	if all, _ := repo.GetByNickname("alice"); len(*all) != 1 {
+		t.Errorf("expected the other family's token only, got %d tokens", len(*all))
+	}
+}
+
+func TestTokens_PruneRotated(t *testing.T) {
+	t.Setenv("APP_PEPPER", "test-secret")
+
+	repo := NewTokenRepository(db.NewSimpleCache("TokenCache"))
+
+	pair, err := NewToken(&models.User{Nickname: "alice"}, repo, "agent", "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := repo.GetByNickname("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var first models.Token
+	for _, token := range *all {
+		first = token
+	}
+
+	_, second, err := RotateToken(&first, repo, "agent", "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first token's grace period passes.
+	if err := repo.Update(first.Hash, func(token *models.Token) error {
+		token.RotatedAt = time.Now().Add(-2 * RotationGracePeriod)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, third, err := RotateToken(second, repo, "agent", "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first token is pruned, the second one is kept within its grace period.
+	if _, err := repo.GetByID(first.Hash); err == nil {
+		t.Error("the rotated token past the grace period has not been pruned")
+	}
+
+	if _, err := repo.GetByID(second.Hash); err != nil {
+		t.Errorf("the rotated token within the grace period has been pruned: %v", err)
+	}
+
+	if len(third.RotatedHashes) != 2 || third.RotatedHashes[0] != first.Hash || third.RotatedHashes[1] != second.Hash {
+		t.Errorf("unexpected rotated hashes: %v", third.RotatedHashes)
+	}
+
+	// The reuse of the pruned token is recognized by the family's live token.
+	if family := FindRotated(pair[1], first.Hash, repo); family == nil || family.Hash != third.Hash {
+		t.Errorf("the family of the pruned token not found: %+v", family)
+	}
+
+	// The tokens not signed by the server are not looked up.
+	if family := FindRotated("forged", first.Hash, repo); family != nil {
+		t.Errorf("the family found by a forged token: %+v", family)
+	}
+}
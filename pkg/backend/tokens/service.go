@@ -11,6 +11,7 @@ import (
 	"go.vxn.dev/littr/pkg/models"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 )
 
 /*type TokenServiceInterface interface {
@@ -34,7 +35,7 @@ func (s *TokenService) Create(ctx context.Context, user *models.User) ([]string,
 		return nil, fmt.Errorf("given user is nil")
 	}
 
-	tokens, err := NewToken(user, s.tokenRepository)
+	tokens, err := NewToken(user, s.tokenRepository, "", "")
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +43,9 @@ func (s *TokenService) Create(ctx context.Context, user *models.User) ([]string,
 	return tokens, nil
 }
 
-func NewToken(user *models.User, r models.TokenRepositoryInterface) ([]string, error) {
+// NewToken issues a new pair of the access and refresh tokens for such user. The refresh token's hash is saved together with the client's
+// user agent and IP address to be listed among the user's sessions.
+func NewToken(user *models.User, r models.TokenRepositoryInterface, userAgent, ipAddress string) ([]string, error) {
 	secret := os.Getenv("APP_PEPPER")
 
 	if secret == "" {
@@ -65,37 +68,57 @@ func NewToken(user *models.User, r models.TokenRepositoryInterface) ([]string, e
 		return nil, fmt.Errorf(common.ERR_AUTH_ACC_TOKEN_FAIL)
 	}
 
-	// Compose the user's personal (refresh) token content. Refresh token is restricted (mainly) to 4 weeks of its validity.
+	// Prepare the refresh token struct for the database saving, the token starts a new token family.
+	token := models.Token{
+		CreatedAt:  time.Now(),
+		Nickname:   user.Nickname,
+		TTL:        common.TokenTTL,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		LastUsedAt: time.Now(),
+	}
+
+	signedRefreshToken, err := signRefreshToken(&token, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save new refresh token's hash to the Token database.
+	if err := r.Save(&token); err != nil {
+		return nil, fmt.Errorf(common.ERR_TOKEN_SAVE_FAIL)
+	}
+
+	return []string{signedAccessToken, signedRefreshToken}, nil
+}
+
+// signRefreshToken signs a new refresh token valid until the token's expiration (CreatedAt + TTL), and sets the token's hash. The token
+// without a family is made the first one of a new family.
+func signRefreshToken(token *models.Token, secret string) (string, error) {
+	// Compose the user's personal (refresh) token content. The random ID keeps the tokens issued at the same second unique.
+	// The subject (the owner) lets the family of a pruned rotated token be found (see FindRotated).
 	refreshClaims := jwt.StandardClaims{
+		Id:        uuid.NewString(),
+		Subject:   token.Nickname,
 		IssuedAt:  time.Now().Unix(),
-		ExpiresAt: time.Now().Add(common.TokenTTL).Unix(),
+		ExpiresAt: token.CreatedAt.Add(token.TTL).Unix(),
 	}
 
 	// Get new refresh token = sign the refresh token with the server's secret.
 	signedRefreshToken, err := NewRefreshToken(refreshClaims, secret)
 	if err != nil {
-		return nil, fmt.Errorf(common.ERR_AUTH_REF_TOKEN_FAIL)
+		return "", fmt.Errorf(common.ERR_AUTH_REF_TOKEN_FAIL)
 	}
 
 	// Prepare the refresh token's hash for the database payload.
 	refreshSum := sha256.New()
 	refreshSum.Write([]byte(signedRefreshToken))
-	refreshTokenSum := fmt.Sprintf("%x", refreshSum.Sum(nil))
+	token.Hash = fmt.Sprintf("%x", refreshSum.Sum(nil))
 
-	// Prepare the refresh token struct for the database saving.
-	token := models.Token{
-		Hash:      refreshTokenSum,
-		CreatedAt: time.Now(),
-		Nickname:  user.Nickname,
-		TTL:       common.TokenTTL,
+	if token.FamilyID == "" {
+		token.FamilyID = token.Hash
 	}
 
-	// Save new refresh token's hash to the Token database.
-	if err := r.Save(&token); err != nil {
-		return nil, fmt.Errorf(common.ERR_TOKEN_SAVE_FAIL)
-	}
-
-	return []string{signedAccessToken, signedRefreshToken}, nil
+	return signedRefreshToken, nil
 }
 
 func (s *TokenService) Delete(ctx context.Context, tokenID string) error {
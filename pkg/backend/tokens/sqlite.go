@@ -0,0 +1,151 @@
+package tokens
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+// The implementation of pkg/models.TokenRepositoryInterface on the SQLite database (see pkg/backend/db/sqlite.go for the schema).
+type SQLiteTokenRepository struct {
+	db db.SQLHandle
+}
+
+func NewSQLiteTokenRepository(handle db.SQLHandle) *SQLiteTokenRepository {
+	if handle == nil {
+		return nil
+	}
+
+	return &SQLiteTokenRepository{
+		db: handle,
+	}
+}
+
+func (r *SQLiteTokenRepository) GetAll() (*map[string]models.Token, error) {
+	tokens, err := r.query("SELECT data FROM tokens")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*tokens) == 0 {
+		return nil, fmt.Errorf(common.ERR_NO_ITEMS_FOUND)
+	}
+
+	return tokens, nil
+}
+
+// GetByNickname fetches all refresh tokens of such user using the nickname index.
+func (r *SQLiteTokenRepository) GetByNickname(nickname string) (*map[string]models.Token, error) {
+	return r.query("SELECT data FROM tokens WHERE nickname = ?", nickname)
+}
+
+func (r *SQLiteTokenRepository) GetByID(tokenID string) (*models.Token, error) {
+	if tokenID == "" {
+		return nil, fmt.Errorf("tokenID is blank")
+	}
+
+	var raw []byte
+
+	err := r.db.QueryRow("SELECT data FROM tokens WHERE hash = ?", tokenID).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("could not find requested token")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var token models.Token
+
+	if err := json.Unmarshal(raw, &token); err != nil {
+		return nil, fmt.Errorf("could not assert type *models.Token")
+	}
+
+	return &token, nil
+}
+
+func (r *SQLiteTokenRepository) Save(token *models.Token) error {
+	raw, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(`INSERT INTO tokens (hash, nickname, created_at, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (hash) DO UPDATE SET nickname = excluded.nickname, created_at = excluded.created_at, data = excluded.data`,
+		token.Hash, token.Nickname, token.CreatedAt.UnixNano(), raw); err != nil {
+		return fmt.Errorf("an error occurred while saving a token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteTokenRepository) Update(tokenID string, fn func(token *models.Token) error) error {
+	return db.RetryOnConflict(func() error {
+		token, err := r.GetByID(tokenID)
+		if err != nil {
+			return err
+		}
+
+		version := token.Version
+
+		if err := fn(token); err != nil {
+			return err
+		}
+
+		token.Version = version + 1
+
+		raw, err := json.Marshal(token)
+		if err != nil {
+			return err
+		}
+
+		res, err := r.db.Exec("UPDATE tokens SET data = ? WHERE hash = ? AND "+db.SQLVersionCondition, raw, tokenID, version)
+		if err != nil {
+			return fmt.Errorf("an error occurred while saving a token: %w", err)
+		}
+
+		return db.CheckSwapped(res)
+	})
+}
+
+func (r *SQLiteTokenRepository) Delete(tokenID string) error {
+	if _, err := r.db.Exec("DELETE FROM tokens WHERE hash = ?", tokenID); err != nil {
+		return fmt.Errorf("token data could not be purged from the database")
+	}
+
+	return nil
+}
+
+// query runs the query selecting the data column and decodes the rows into a map of tokens.
+func (r *SQLiteTokenRepository) query(query string, args ...interface{}) (*map[string]models.Token, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make(map[string]models.Token)
+
+	for rows.Next() {
+		var raw []byte
+
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+
+		var token models.Token
+
+		if err := json.Unmarshal(raw, &token); err != nil {
+			return nil, fmt.Errorf("token's data corrupted")
+		}
+
+		tokens[token.Hash] = token
+	}
+
+	return &tokens, rows.Err()
+}
@@ -0,0 +1,204 @@
+// TOTP (RFC 6238) second factor package for the backend.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  TOTP
+//  The time-based one-time passwords of the authenticator apps: HMAC-SHA1, 6 digits, 30 seconds period (the defaults of RFC 6238 all the apps
+//  support). The codes of the adjacent periods are accepted too to tolerate the clock skew, and every time step can be used just once.
+//
+
+const (
+	// Period is the validity period of one code.
+	Period = 30 * time.Second
+
+	// Digits is the number of the code's digits.
+	Digits = 6
+
+	// skew is the number of the adjacent periods accepted.
+	skew = 1
+
+	// secretLen is the secret's length in bytes (160 bits as recommended by RFC 4226).
+	secretLen = 20
+
+	// RecoveryCodeCount is the number of the recovery codes generated at once.
+	RecoveryCodeCount = 10
+
+	// recoveryCodeLen is the recovery code's length in bytes (hex-encoded in the code).
+	recoveryCodeLen = 5
+)
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random secret, base32-encoded for the authenticator apps.
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretLen)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return secretEncoding.EncodeToString(secret), nil
+}
+
+// ProvisioningURI returns the otpauth:// URI of the secret to be imported to an authenticator app (usually as a QR code).
+func ProvisioningURI(issuer, account, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", Digits))
+	params.Set("period", fmt.Sprintf("%d", int(Period.Seconds())))
+
+	label := url.PathEscape(issuer + ":" + account)
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, params.Encode())
+}
+
+// Validate checks the code against the secret at the given time. The time step of the code is returned to be stored, the codes of the steps
+// up to the lastStep are rejected, so a code cannot be used twice.
+func Validate(secret, code string, at time.Time, lastStep int64) (int64, bool) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return 0, false
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	current := at.Unix() / int64(Period.Seconds())
+
+	for step := current - skew; step <= current+skew; step++ {
+		if step <= lastStep {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare([]byte(generate(key, step, Digits)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// Code returns the code of the secret at the given time.
+func Code(secret string, at time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return generate(key, at.Unix()/int64(Period.Seconds()), Digits), nil
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	return secretEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+}
+
+// generate computes the HOTP value (RFC 4226) of the counter.
+func generate(key []byte, counter int64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation.
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
+
+// GenerateRecoveryCodes returns the new one-time recovery codes to be shown to the user, and their hashes to be stored.
+func GenerateRecoveryCodes() ([]string, []string, error) {
+	var codes, hashes []string
+
+	for i := 0; i < RecoveryCodeCount; i++ {
+		raw := make([]byte, recoveryCodeLen)
+
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+
+		code := hex.EncodeToString(raw)
+		code = code[:5] + "-" + code[5:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode returns the stored form of the recovery code. The codes are random, so a fast hash is sufficient.
+func HashRecoveryCode(code string) string {
+	code = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(code)), "-", "")
+
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// UseRecoveryCode returns the hashes without the one of the code given, and true if the code has been found.
+func UseRecoveryCode(hashes []string, code string) ([]string, bool) {
+	hash := HashRecoveryCode(code)
+
+	for i, stored := range hashes {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(hash)) == 1 {
+			remaining := append([]string{}, hashes[:i]...)
+			return append(remaining, hashes[i+1:]...), true
+		}
+	}
+
+	return hashes, false
+}
+
+// Authenticate checks the second factor of the user: the TOTP code, or one of the recovery codes, which is consumed then. The user is modified
+// to be saved by the caller, so the same code cannot be used again.
+func Authenticate(user *models.User, code string) error {
+	if user == nil || !user.TOTPEnabled {
+		return fmt.Errorf(common.ERR_TOTP_NOT_ENABLED)
+	}
+
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return fmt.Errorf(common.ERR_TOTP_CODE_BLANK)
+	}
+
+	if step, ok := Validate(user.TOTPSecret, code, time.Now(), user.TOTPLastStep); ok {
+		user.TOTPLastStep = step
+		return nil
+	}
+
+	if remaining, ok := UseRecoveryCode(user.RecoveryCodes, code); ok {
+		user.RecoveryCodes = remaining
+		return nil
+	}
+
+	return fmt.Errorf(common.ERR_TOTP_INVALID)
+}
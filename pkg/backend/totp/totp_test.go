@@ -0,0 +1,79 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// The SHA1 test vectors of RFC 6238, Appendix B.
+func TestTOTP_RFC6238(t *testing.T) {
+	key := []byte("12345678901234567890")
+
+	vectors := map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	}
+
+	for unix, expected := range vectors {
+		if code := generate(key, unix/30, 8); code != expected {
+			t.Errorf("time %d: expected %s, got %s", unix, expected, code)
+		}
+	}
+}
+
+func TestTOTP_Validate(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1700000000, 0)
+
+	code, err := Code(secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	step, ok := Validate(secret, code, now.Add(Period), 0)
+	if !ok {
+		t.Fatal("the code of the previous period rejected")
+	}
+
+	// The code cannot be used twice.
+	if _, ok := Validate(secret, code, now, step); ok {
+		t.Error("the used code accepted")
+	}
+
+	if _, ok := Validate(secret, code, now.Add(3*Period), 0); ok {
+		t.Error("the expired code accepted")
+	}
+
+	if uri := ProvisioningURI("littr", "alice", secret); !strings.HasPrefix(uri, "otpauth://totp/littr:alice?") || !strings.Contains(uri, "secret="+secret) {
+		t.Errorf("unexpected provisioning URI: %s", uri)
+	}
+}
+
+func TestTOTP_RecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(codes) != RecoveryCodeCount || len(hashes) != RecoveryCodeCount {
+		t.Fatalf("unexpected number of codes: %d, %d", len(codes), len(hashes))
+	}
+
+	remaining, ok := UseRecoveryCode(hashes, strings.ToUpper(codes[3]))
+	if !ok || len(remaining) != RecoveryCodeCount-1 {
+		t.Fatal("valid recovery code rejected")
+	}
+
+	if _, ok := UseRecoveryCode(remaining, codes[3]); ok {
+		t.Error("the used recovery code accepted")
+	}
+}
@@ -0,0 +1,119 @@
+package users
+
+import (
+	"context"
+	"fmt"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/pages"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+//
+//  Bookmarks
+//  The posts saved by the user to come back to later. The bookmarks are kept in the user's record (see models.User.Bookmarks), and are
+//  purged from all users once the posts are deleted.
+//
+
+func (s *UserService) FindBookmarks(ctx context.Context, userID string, pageOpts interface{}) (*map[string]models.Post, *map[string]models.User, error) {
+	// Fetch the caller's ID from context.
+	callerID := common.GetCallerID(ctx)
+
+	// The bookmarks are private.
+	if callerID != userID {
+		return nil, nil, fmt.Errorf(common.ERR_BOOKMARKS_FOREIGN)
+	}
+
+	caller, err := s.userRepository.GetByID(callerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, ok := pageOpts.(*UserPagingRequest)
+	if !ok {
+		return nil, nil, fmt.Errorf(common.ERR_PAGENO_INCORRECT)
+	}
+
+	// Set the page options.
+	opts := &pages.PageOptions{
+		CallerID: callerID,
+		PageNo:   req.PageNo,
+		FlowList: nil,
+
+		Flow: pages.FlowOptions{
+			HideReplies: req.HideReplies,
+			Bookmarks:   true,
+		},
+	}
+
+	postData, err := pages.PostData(s.postRepository)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allUsers, err := s.userRepository.GetAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	iface, err := s.pagingService.GetOne(ctx, opts, postData, allUsers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ptrs, ok := iface.(*pages.PagePointers)
+	if !ok {
+		return nil, nil, fmt.Errorf(common.ERR_PAGE_EXPORT_NIL)
+	}
+
+	dummyPosts := make(map[string]models.Post)
+	dummyUsers := make(map[string]models.User)
+
+	if ptrs.Posts == nil {
+		ptrs.Posts = &dummyPosts
+	}
+
+	if ptrs.Users == nil {
+		ptrs.Users = &dummyUsers
+	}
+
+	(*ptrs.Users)[callerID] = *caller
+
+	// Patch the user data export.
+	users := common.FlushUserData(ptrs.Users, callerID)
+
+	return ptrs.Posts, users, nil
+}
+
+// PurgeBookmarks drops the deleted posts from the bookmarks of all users.
+func PurgeBookmarks(userRepository models.UserRepositoryInterface, postIDs ...string) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+
+	users, err := userRepository.GetAll()
+	if err != nil {
+		// No users, no bookmarks.
+		if err.Error() == common.ERR_NO_ITEMS_FOUND {
+			return nil
+		}
+
+		return err
+	}
+
+	for key, user := range *users {
+		// Skip the users not having bookmarked any of the posts.
+		if !user.RemoveBookmarks(postIDs...) {
+			continue
+		}
+
+		if err := userRepository.Update(key, func(user *models.User) error {
+			user.RemoveBookmarks(postIDs...)
+			return nil
+		}); err != nil {
+			return fmt.Errorf("could not purge the bookmarks of %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
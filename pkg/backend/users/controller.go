@@ -15,6 +15,8 @@ const (
 	LOGGER_WORKER_NAME string = "userController"
 
 	userIDParam     string = "userID"
+	sessionIDParam  string = "sessionID"
+	tokenIDParam    string = "tokenID"
 	updateTypeParam string = "updateType"
 )
 
@@ -280,13 +282,12 @@ func (c *UserController) UpdateOptions(w http.ResponseWriter, r *http.Request) {
 // UpdatePassphrase is the users handler that allows the user to change their passphrase.
 //
 //	@Summary		Update user's passphrase
-//	@Description		This function call enables the caller to modify their current passphrase. The current and a new passphrase are to be sent (hashed using sha512 algorithm).
+//	@Description		This function call enables the caller to modify their current passphrase. The current and a new passphrase are to be sent in the plain-text form.
 //	@Description
-//	@Description		The problem there is on how to fetch the current passphrase. This can be achieved using a web browser in dev tools (F12), where the hash is to be found on the Network card.
-//	@Description		Another problem is that the server uses a secret (pepper), that is appended to a passphrase before loading it into the has algorithm. This secret cannot be fetched via API, as it is a sensitive variable saved as the environmental variable where the server is run.
+//	@Description		The new passphrase is stored hashed using the Argon2id algorithm.
 //	@Tags			users
 //	@Produce		json
-//	@Param			request	body		users.UserUpdatePassphraseRequest	true	"Hexadecimal representation of the sha512-hashed passphrases."
+//	@Param			request	body		users.UserUpdatePassphraseRequest	true	"The current and the new plain-text passphrases."
 //	@Param			userID	path		string					true	"ID of the user to update"
 //	@Success		200		{object}	common.APIResponse{data=models.Stub} 	"User has been updated."
 //	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid data received."
@@ -328,6 +329,472 @@ func (c *UserController) UpdatePassphrase(w http.ResponseWriter, r *http.Request
 
 }
 
+// SetupTwoFactor is the users handler that starts the caller's two-factor authentication enrollment.
+//
+//	@Summary		Start the two-factor authentication setup
+//	@Description		This function call generates a new TOTP secret for the caller. The provisioning URI is to be imported into an authenticator app (e.g. as a QR code).
+//	@Description
+//	@Description		The two-factor authentication is not enabled until a valid code is verified using the `/users/{userID}/2fa/verify` endpoint.
+//	@Tags			users
+//	@Produce		json
+//	@Param			userID	path		string					true	"ID of the user to set the two-factor authentication up for"
+//	@Success		200		{object}	common.APIResponse{data=users.SetupTwoFactor.responseData}	"The TOTP secret has been generated."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid data received."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"Unauthorized attempt to modify a foreigner's account."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}	"Such user does not exist in the system."
+//	@Failure		409		{object}	common.APIResponse{data=models.Stub}	"The two-factor authentication is already enabled."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"There is an internal processing problem present (e.g. data could not be saved to the database)."
+//	@Router			/users/{userID}/2fa [post]
+func (c *UserController) SetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, LOGGER_WORKER_NAME)
+
+	type responseData struct {
+		Secret          string `json:"secret"`
+		ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/littr:alice?secret=..."`
+	}
+
+	// Skip the blank caller's ID.
+	if l.CallerID() == "" {
+		l.Msg(common.ERR_CALLER_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// Fetch the userID/nickname from the URI.
+	userID := chi.URLParam(r, userIDParam)
+	if userID == "" {
+		l.Msg(common.ERR_USERID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	secret, uri, err := c.userService.SetupTwoFactor(r.Context(), userID)
+	if err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	pl := &responseData{
+		Secret:          secret,
+		ProvisioningURI: uri,
+	}
+
+	l.Msg("ok, the two-factor authentication secret generated").Status(http.StatusOK).Log().Payload(pl).Write(w)
+}
+
+// EnableTwoFactor is the users handler that verifies the first TOTP code, and enables the two-factor authentication.
+//
+//	@Summary		Enable the two-factor authentication
+//	@Description		This function call verifies the TOTP code of the secret generated by the setup endpoint, and enables the two-factor authentication for the caller.
+//	@Description
+//	@Description		The one-time recovery codes are returned just once. Each of them can be used instead of a TOTP code, when the authenticator app is not available.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		users.UserTwoFactorRequest		true	"The TOTP code."
+//	@Param			userID	path		string					true	"ID of the user to enable the two-factor authentication for"
+//	@Success		200		{object}	common.APIResponse{data=users.EnableTwoFactor.responseData}	"The two-factor authentication has been enabled."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid data received."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}	"Invalid TOTP code."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"Unauthorized attempt to modify a foreigner's account."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}	"Such user does not exist in the system."
+//	@Failure		409		{object}	common.APIResponse{data=models.Stub}	"The two-factor authentication is already enabled, or its setup has not been started."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"There is an internal processing problem present (e.g. data could not be saved to the database)."
+//	@Router			/users/{userID}/2fa/verify [post]
+func (c *UserController) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, LOGGER_WORKER_NAME)
+
+	type responseData struct {
+		RecoveryCodes []string `json:"recovery_codes" example:"1a2b3-c4d5e"`
+	}
+
+	// Skip the blank caller's ID.
+	if l.CallerID() == "" {
+		l.Msg(common.ERR_CALLER_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// Fetch the userID/nickname from the URI.
+	userID := chi.URLParam(r, userIDParam)
+	if userID == "" {
+		l.Msg(common.ERR_USERID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	var dtoIn UserTwoFactorRequest
+
+	// Decode the incoming data.
+	if err := common.UnmarshalRequestData(r, &dtoIn); err != nil {
+		l.Msg(common.ERR_INPUT_DATA_FAIL).Status(http.StatusBadRequest).Error(err).Log().Payload(nil).Write(w)
+		return
+	}
+
+	recoveryCodes, err := c.userService.EnableTwoFactor(r.Context(), userID, dtoIn.Code)
+	if err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	pl := &responseData{
+		RecoveryCodes: recoveryCodes,
+	}
+
+	l.Msg("ok, the two-factor authentication enabled").Status(http.StatusOK).Log().Payload(pl).Write(w)
+}
+
+// DisableTwoFactor is the users handler that disables the caller's two-factor authentication.
+//
+//	@Summary		Disable the two-factor authentication
+//	@Description		This function call disables the two-factor authentication for the caller. A valid TOTP code (or one of the recovery codes) is required.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		users.UserTwoFactorRequest		true	"The TOTP code, or one of the recovery codes."
+//	@Param			userID	path		string					true	"ID of the user to disable the two-factor authentication for"
+//	@Success		200		{object}	common.APIResponse{data=models.Stub}	"The two-factor authentication has been disabled."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid data received."
+//	@Failure		401		{object}	common.APIResponse{data=models.Stub}	"Invalid TOTP code."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"Unauthorized attempt to modify a foreigner's account."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}	"Such user does not exist in the system."
+//	@Failure		409		{object}	common.APIResponse{data=models.Stub}	"The two-factor authentication is not enabled."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"There is an internal processing problem present (e.g. data could not be saved to the database)."
+//	@Router			/users/{userID}/2fa [delete]
+func (c *UserController) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, LOGGER_WORKER_NAME)
+
+	// Skip the blank caller's ID.
+	if l.CallerID() == "" {
+		l.Msg(common.ERR_CALLER_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// Fetch the userID/nickname from the URI.
+	userID := chi.URLParam(r, userIDParam)
+	if userID == "" {
+		l.Msg(common.ERR_USERID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	var dtoIn UserTwoFactorRequest
+
+	// Decode the incoming data.
+	if err := common.UnmarshalRequestData(r, &dtoIn); err != nil {
+		l.Msg(common.ERR_INPUT_DATA_FAIL).Status(http.StatusBadRequest).Error(err).Log().Payload(nil).Write(w)
+		return
+	}
+
+	if err := c.userService.DisableTwoFactor(r.Context(), userID, dtoIn.Code); err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	l.Msg("ok, the two-factor authentication disabled").Status(http.StatusOK).Log().Payload(nil).Write(w)
+}
+
+// GetSessions is the users handler that lists the caller's active sessions (refresh tokens).
+//
+//	@Summary		List the active sessions
+//	@Description		This function call lists the caller's active sessions, one per a refresh token issued at the login. The most recently used sessions are listed first.
+//	@Tags			users
+//	@Produce		json
+//	@Param			userID	path		string					true	"ID of the user to list the sessions of"
+//	@Success		200		{object}	common.APIResponse{data=users.GetSessions.responseData}	"The sessions have been listed."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid data received."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"Unauthorized attempt to list a foreigner's sessions."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"There is an internal processing problem present."
+//	@Router			/users/{userID}/sessions [get]
+func (c *UserController) GetSessions(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, LOGGER_WORKER_NAME)
+
+	type responseData struct {
+		Sessions []UserSession `json:"sessions"`
+	}
+
+	// Skip the blank caller's ID.
+	if l.CallerID() == "" {
+		l.Msg(common.ERR_CALLER_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// Fetch the userID/nickname from the URI.
+	userID := chi.URLParam(r, userIDParam)
+	if userID == "" {
+		l.Msg(common.ERR_USERID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	tokens, err := c.userService.FindSessions(r.Context(), userID)
+	if err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// The current session is marked, so it can be told apart in the list.
+	currentID := common.GetTokenID(r.Context())
+
+	pl := &responseData{
+		Sessions: []UserSession{},
+	}
+
+	for _, token := range *tokens {
+		pl.Sessions = append(pl.Sessions, UserSession{
+			ID:         token.Hash,
+			UserAgent:  token.UserAgent,
+			IPAddress:  token.IPAddress,
+			CreatedAt:  token.CreatedAt,
+			LastUsedAt: token.LastUsedAt,
+			ExpiresAt:  token.CreatedAt.Add(token.TTL),
+			Current:    token.Hash == currentID,
+		})
+	}
+
+	l.Msg("ok, listing the sessions").Status(http.StatusOK).Log().Payload(pl).Write(w)
+}
+
+// DeleteSession is the users handler that revokes one of the caller's sessions.
+//
+//	@Summary		Revoke a session
+//	@Description		This function call deletes the refresh token of such session. The client using it is logged out on its next request.
+//	@Tags			users
+//	@Produce		json
+//	@Param			userID		path		string					true	"ID of the user to revoke the session of"
+//	@Param			sessionID	path		string					true	"ID of the session to revoke"
+//	@Success		200		{object}	common.APIResponse{data=models.Stub}	"The session has been revoked."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid data received."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"Unauthorized attempt to revoke a foreigner's session."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}	"Such session does not exist."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"There is an internal processing problem present (e.g. data could not be deleted from the database)."
+//	@Router			/users/{userID}/sessions/{sessionID} [delete]
+func (c *UserController) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, LOGGER_WORKER_NAME)
+
+	// Skip the blank caller's ID.
+	if l.CallerID() == "" {
+		l.Msg(common.ERR_CALLER_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// Fetch the userID/nickname from the URI.
+	userID := chi.URLParam(r, userIDParam)
+	if userID == "" {
+		l.Msg(common.ERR_USERID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// Fetch the sessionID from the URI.
+	sessionID := chi.URLParam(r, sessionIDParam)
+	if sessionID == "" {
+		l.Msg(common.ERR_SESSION_ID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	if err := c.userService.DeleteSession(r.Context(), userID, sessionID); err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	l.Msg("ok, the session revoked").Status(http.StatusOK).Log().Payload(nil).Write(w)
+}
+
+// DeleteSessions is the users handler that revokes all the caller's sessions (log out everywhere).
+//
+//	@Summary		Log out everywhere
+//	@Description		This function call deletes all the caller's refresh tokens, the current one included. All the clients are logged out on their next request.
+//	@Tags			users
+//	@Produce		json
+//	@Param			userID	path		string					true	"ID of the user to revoke all the sessions of"
+//	@Success		200		{object}	common.APIResponse{data=models.Stub}	"All the sessions have been revoked."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid data received."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"Unauthorized attempt to revoke a foreigner's sessions."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"There is an internal processing problem present (e.g. data could not be deleted from the database)."
+//	@Router			/users/{userID}/sessions [delete]
+func (c *UserController) DeleteSessions(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, LOGGER_WORKER_NAME)
+
+	// Skip the blank caller's ID.
+	if l.CallerID() == "" {
+		l.Msg(common.ERR_CALLER_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// Fetch the userID/nickname from the URI.
+	userID := chi.URLParam(r, userIDParam)
+	if userID == "" {
+		l.Msg(common.ERR_USERID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	if err := c.userService.DeleteSessions(r.Context(), userID); err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	l.Msg("ok, all the sessions revoked").Status(http.StatusOK).Log().Payload(nil).Write(w)
+}
+
+// GetAPITokens is the users handler that lists the caller's personal API tokens.
+//
+//	@Summary		List the API tokens
+//	@Description		This function call lists the caller's personal API tokens, the newest first. The tokens themselves are not listed, those are shown just once at the creation.
+//	@Tags			users
+//	@Produce		json
+//	@Param			userID	path		string					true	"ID of the user to list the API tokens of"
+//	@Success		200		{object}	common.APIResponse{data=users.GetAPITokens.responseData}	"The API tokens have been listed."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid data received."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"Unauthorized attempt to list a foreigner's API tokens."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"There is an internal processing problem present."
+//	@Router			/users/{userID}/tokens [get]
+func (c *UserController) GetAPITokens(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, LOGGER_WORKER_NAME)
+
+	type responseData struct {
+		Tokens []UserAPIToken `json:"tokens"`
+	}
+
+	// Skip the blank caller's ID.
+	if l.CallerID() == "" {
+		l.Msg(common.ERR_CALLER_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// Fetch the userID/nickname from the URI.
+	userID := chi.URLParam(r, userIDParam)
+	if userID == "" {
+		l.Msg(common.ERR_USERID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	tokens, err := c.userService.FindAPITokens(r.Context(), userID)
+	if err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	pl := &responseData{
+		Tokens: []UserAPIToken{},
+	}
+
+	for _, token := range *tokens {
+		pl.Tokens = append(pl.Tokens, UserAPIToken{
+			ID:         token.Hash,
+			Name:       token.Name,
+			Scopes:     token.Scopes,
+			CreatedAt:  token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+			LastUsedAt: token.LastUsedAt,
+		})
+	}
+
+	l.Msg("ok, listing the API tokens").Status(http.StatusOK).Log().Payload(pl).Write(w)
+}
+
+// CreateAPIToken is the users handler that creates a new personal API token for the caller.
+//
+//	@Summary		Create an API token
+//	@Description		This function call creates a new personal API token with such scopes and expiration. The token is to be sent in the `Authorization: Bearer` header instead of the HTTP cookies.
+//	@Description
+//	@Description		The token is returned just once, only its hash is kept in the database.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		users.UserAPITokenRequest		true	"The token's name, scopes, and validity."
+//	@Param			userID	path		string					true	"ID of the user to create the API token for"
+//	@Success		201		{object}	common.APIResponse{data=users.CreateAPIToken.responseData}	"The API token has been created."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid data received (e.g. an unknown scope)."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"Unauthorized attempt to create an API token for a foreigner."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"There is an internal processing problem present (e.g. data could not be saved to the database)."
+//	@Router			/users/{userID}/tokens [post]
+func (c *UserController) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, LOGGER_WORKER_NAME)
+
+	type responseData struct {
+		Token UserAPIToken `json:"token"`
+	}
+
+	// Skip the blank caller's ID.
+	if l.CallerID() == "" {
+		l.Msg(common.ERR_CALLER_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// Fetch the userID/nickname from the URI.
+	userID := chi.URLParam(r, userIDParam)
+	if userID == "" {
+		l.Msg(common.ERR_USERID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	var dtoIn UserAPITokenRequest
+
+	// Decode the incoming data.
+	if err := common.UnmarshalRequestData(r, &dtoIn); err != nil {
+		l.Msg(common.ERR_INPUT_DATA_FAIL).Status(http.StatusBadRequest).Error(err).Log().Payload(nil).Write(w)
+		return
+	}
+
+	plain, token, err := c.userService.CreateAPIToken(r.Context(), userID, &dtoIn)
+	if err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	pl := &responseData{
+		Token: UserAPIToken{
+			ID:        token.Hash,
+			Name:      token.Name,
+			Scopes:    token.Scopes,
+			CreatedAt: token.CreatedAt,
+			ExpiresAt: token.ExpiresAt,
+			Token:     plain,
+		},
+	}
+
+	l.Msg("ok, the API token created").Status(http.StatusCreated).Log().Payload(pl).Write(w)
+}
+
+// DeleteAPIToken is the users handler that revokes one of the caller's personal API tokens.
+//
+//	@Summary		Revoke an API token
+//	@Description		This function call deletes such API token, the requests authenticated with it are refused right away.
+//	@Tags			users
+//	@Produce		json
+//	@Param			userID	path		string					true	"ID of the user to revoke the API token of"
+//	@Param			tokenID	path		string					true	"ID of the API token to revoke"
+//	@Success		200		{object}	common.APIResponse{data=models.Stub}	"The API token has been revoked."
+//	@Failure		400		{object}	common.APIResponse{data=models.Stub}	"Invalid data received."
+//	@Failure		403		{object}	common.APIResponse{data=models.Stub}	"Unauthorized attempt to revoke a foreigner's API token."
+//	@Failure		404		{object}	common.APIResponse{data=models.Stub}	"Such API token does not exist."
+//	@Failure		500		{object}	common.APIResponse{data=models.Stub}	"There is an internal processing problem present (e.g. data could not be deleted from the database)."
+//	@Router			/users/{userID}/tokens/{tokenID} [delete]
+func (c *UserController) DeleteAPIToken(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, LOGGER_WORKER_NAME)
+
+	// Skip the blank caller's ID.
+	if l.CallerID() == "" {
+		l.Msg(common.ERR_CALLER_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// Fetch the userID/nickname from the URI.
+	userID := chi.URLParam(r, userIDParam)
+	if userID == "" {
+		l.Msg(common.ERR_USERID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// Fetch the tokenID from the URI.
+	tokenID := chi.URLParam(r, tokenIDParam)
+	if tokenID == "" {
+		l.Msg(common.ERR_API_TOKEN_ID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	if err := c.userService.DeleteAPIToken(r.Context(), userID, tokenID); err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	l.Msg("ok, the API token revoked").Status(http.StatusOK).Log().Payload(nil).Write(w)
+}
+
 // UpdateSubscription is the handler function used to update an existing subscription.
 //
 //	@Summary		Update the notification subscription tag
@@ -839,3 +1306,72 @@ func (c *UserController) GetPosts(w http.ResponseWriter, r *http.Request) {
 
 	l.Msg("ok, listing user's posts").Status(http.StatusOK).Log().Payload(pl).Write(w)
 }
+
+// GetBookmarks fetches the posts bookmarked by the user.
+//
+//	@Summary		Get user bookmarks
+//	@Description		This function call retrieves a paginated list of the posts bookmarked by the caller, the newest post first. The bookmarks are private, only the caller's own ones can be listed. The posts of the private accounts not followed by the caller are left out until followed.
+//	@Tags			users
+//	@Produce		json
+//	@Param			X-Hide-Replies	header		string	false	"Optional boolean specifying the request of so-called root posts (those not being a reply). Default is false."
+//	@Param			X-Page-No	header		string	false	"Page number (default is 0)."
+//	@Param			userID		path		string	true	"User's ID (usually the nickname)."
+//	@Success		200				{object}	common.APIResponse{data=users.GetBookmarks.responseData}	"A paginated list of the bookmarked posts."
+//	@Failure		400				{object}	common.APIResponse{data=models.Stub}			"Invalid input data."
+//	@Failure		403				{object}	common.APIResponse{data=models.Stub}			"The bookmarks of another user were requested."
+//	@Failure		500				{object}	common.APIResponse{data=models.Stub}			"A very internal service's logic problem. See the `message` field to gain more information."
+//	@Router			/users/{userID}/bookmarks [get]
+func (c *UserController) GetBookmarks(w http.ResponseWriter, r *http.Request) {
+	l := common.NewLogger(r, "userController")
+
+	type responseData struct {
+		Users map[string]models.User `json:"users"`
+		Posts map[string]models.Post `json:"posts"`
+		Key   string                 `json:"key"`
+	}
+
+	// Skip the blank caller's ID.
+	if l.CallerID() == "" {
+		l.Msg(common.ERR_CALLER_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// Fetch the param from URL path.
+	userID := chi.URLParam(r, "userID")
+	if userID == "" {
+		l.Msg(common.ERR_USERID_BLANK).Status(http.StatusBadRequest).Log().Payload(nil).Write(w)
+		return
+	}
+
+	// Fetch the pageNo from headers.
+	pageNo, err := strconv.Atoi(r.Header.Get(common.HDR_PAGE_NO))
+	if err != nil {
+		pageNo = 0
+	}
+
+	// Fetch the optional X-Hide-Replies header's value.
+	hideReplies, err := strconv.ParseBool(r.Header.Get(common.HDR_HIDE_REPLIES))
+	if err != nil {
+		hideReplies = false
+	}
+
+	opts := &UserPagingRequest{
+		PageNo:      pageNo,
+		PagingSize:  25,
+		HideReplies: hideReplies,
+	}
+
+	posts, users, err := c.userService.FindBookmarks(r.Context(), userID, opts)
+	if err != nil {
+		l.Msg(err.Error()).Status(common.DecideStatusFromError(err)).Log().Payload(nil).Write(w)
+		return
+	}
+
+	pl := &responseData{
+		Posts: *posts,
+		Users: *users,
+		Key:   l.CallerID(),
+	}
+
+	l.Msg("ok, listing user's bookmarks").Status(http.StatusOK).Log().Payload(pl).Write(w)
+}
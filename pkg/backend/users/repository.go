@@ -1,6 +1,7 @@
 package users
 
 import (
+	"errors"
 	"fmt"
 
 	"go.vxn.dev/littr/pkg/backend/common"
@@ -10,7 +11,7 @@ import (
 
 // The implementation of pkg/models.UserRepositoryInterface.
 type UserRepository struct {
-	cache db.Cacher
+	cache *db.TypedCache[models.User]
 }
 
 func NewUserRepository(cache db.Cacher) models.UserRepositoryInterface {
@@ -19,26 +20,23 @@ func NewUserRepository(cache db.Cacher) models.UserRepositoryInterface {
 	}
 
 	return &UserRepository{
-		cache: cache,
+		cache: db.NewTypedCache(cache, models.User{}),
 	}
 }
 
 func (r *UserRepository) GetAll() (*map[string]models.User, error) {
-	rawUsers, count := r.cache.Range()
-	if count == 0 {
-		return nil, fmt.Errorf("no items found")
+	users := make(map[string]models.User, r.cache.Len())
+
+	// Loop over the cache items directly, no copy of the whole cache is made.
+	if err := r.cache.Iterate(func(user models.User) bool {
+		users[user.Nickname] = user
+		return true
+	}); err != nil {
+		return nil, fmt.Errorf("user's data corrupted: %w", err)
 	}
 
-	users := make(map[string]models.User)
-
-	// Assert types to fetched interface map.
-	for key, rawUser := range *rawUsers {
-		user, ok := rawUser.(models.User)
-		if !ok {
-			return nil, fmt.Errorf("user's data corrupted")
-		}
-
-		users[key] = user
+	if len(users) == 0 {
+		return nil, fmt.Errorf(common.ERR_NO_ITEMS_FOUND)
 	}
 
 	return &users, nil
@@ -46,14 +44,12 @@ func (r *UserRepository) GetAll() (*map[string]models.User, error) {
 
 func (r *UserRepository) GetByID(userID string) (*models.User, error) {
 	// Fetch the user from the cache.
-	rawUser, found := r.cache.Load(userID)
-	if !found {
+	user, err := r.cache.Get(userID)
+	if errors.Is(err, db.ErrItemNotFound) {
 		return nil, fmt.Errorf(common.ERR_USER_NOT_FOUND)
 	}
 
-	// Assert the type
-	user, ok := rawUser.(models.User)
-	if !ok {
+	if err != nil {
 		return nil, fmt.Errorf(common.ERR_USER_DATA_CORRUPTED)
 	}
 
@@ -62,18 +58,26 @@ func (r *UserRepository) GetByID(userID string) (*models.User, error) {
 
 func (r *UserRepository) Save(user *models.User) error {
 	// Store the user using its key in the cache.
-	saved := r.cache.Store(user.Nickname, *user)
-	if !saved {
+	if err := r.cache.Put(*user); err != nil {
 		return fmt.Errorf("an error occurred while saving a user")
 	}
 
 	return nil
 }
 
+// Update applies the function to the stored user and saves the result, only if the user has not been changed concurrently (see db.TypedCache.Update).
+func (r *UserRepository) Update(userID string, fn func(user *models.User) error) error {
+	err := r.cache.Update(userID, fn)
+	if errors.Is(err, db.ErrItemNotFound) {
+		return fmt.Errorf(common.ERR_USER_NOT_FOUND)
+	}
+
+	return err
+}
+
 func (r *UserRepository) Delete(userID string) error {
 	// Simple user's deleting.
-	deleted := r.cache.Delete(userID)
-	if !deleted {
+	if err := r.cache.Delete(userID); err != nil {
 		return fmt.Errorf("user data could not be purged from the database")
 	}
 
@@ -29,11 +29,27 @@ func NewUserRouter(userController *UserController) chi.Router {
 	// User's settings modification routes handlers.
 	r.Post("/{userID}/avatar", userController.UploadAvatar)
 	r.Get("/{userID}/posts", userController.GetPosts)
+	r.Get("/{userID}/bookmarks", userController.GetBookmarks)
 
 	r.Patch("/{userID}/lists", userController.UpdateLists)
 	r.Patch("/{userID}/options", userController.UpdateOptions)
 	r.Patch("/{userID}/passphrase", userController.UpdatePassphrase)
 
+	// Two-factor authentication routes handlers.
+	r.Post("/{userID}/2fa", userController.SetupTwoFactor)
+	r.Post("/{userID}/2fa/verify", userController.EnableTwoFactor)
+	r.Delete("/{userID}/2fa", userController.DisableTwoFactor)
+
+	// Session management routes handlers.
+	r.Get("/{userID}/sessions", userController.GetSessions)
+	r.Delete("/{userID}/sessions", userController.DeleteSessions)
+	r.Delete("/{userID}/sessions/{sessionID}", userController.DeleteSession)
+
+	// Personal API tokens routes handlers.
+	r.Get("/{userID}/tokens", userController.GetAPITokens)
+	r.Post("/{userID}/tokens", userController.CreateAPIToken)
+	r.Delete("/{userID}/tokens/{tokenID}", userController.DeleteAPIToken)
+
 	r.Post("/{userID}/subscriptions", userController.Subscribe)
 	r.Patch("/{userID}/subscriptions/{uuid}", userController.UpdateSubscription)
 	r.Delete("/{userID}/subscriptions/{uuid}", userController.Unsubscribe)
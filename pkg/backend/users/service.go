@@ -2,8 +2,8 @@ package users
 
 import (
 	"context"
-	"crypto/sha512"
 	"fmt"
+	"maps"
 	"net/http"
 	netmail "net/mail"
 	"os"
@@ -14,12 +14,18 @@ import (
 	"strings"
 	"time"
 
+	"go.vxn.dev/littr/pkg/backend/apitokens"
+	"go.vxn.dev/littr/pkg/backend/audit"
 	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
 	"go.vxn.dev/littr/pkg/backend/image"
 	"go.vxn.dev/littr/pkg/backend/mail"
 
 	//"go.vxn.dev/littr/pkg/backend/live"
 	"go.vxn.dev/littr/pkg/backend/pages"
+	"go.vxn.dev/littr/pkg/backend/passphrase"
+	"go.vxn.dev/littr/pkg/backend/tokens"
+	"go.vxn.dev/littr/pkg/backend/totp"
 	"go.vxn.dev/littr/pkg/config"
 	"go.vxn.dev/littr/pkg/helpers"
 	"go.vxn.dev/littr/pkg/models"
@@ -32,18 +38,22 @@ import (
 //
 
 type UserService struct {
-	mailService       models.MailServiceInterface
-	pagingService     models.PagingServiceInterface
-	pollRepository    models.PollRepositoryInterface
-	postRepository    models.PostRepositoryInterface
-	requestRepository models.RequestRepositoryInterface
-	tokenRepository   models.TokenRepositoryInterface
-	userRepository    models.UserRepositoryInterface
+	mailService        models.MailServiceInterface
+	pagingService      models.PagingServiceInterface
+	transactor         db.Transactor
+	apiTokenRepository models.APITokenRepositoryInterface
+	pollRepository     models.PollRepositoryInterface
+	postRepository     models.PostRepositoryInterface
+	requestRepository  models.RequestRepositoryInterface
+	tokenRepository    models.TokenRepositoryInterface
+	userRepository     models.UserRepositoryInterface
 }
 
 func NewUserService(
 	mailService models.MailServiceInterface,
 	pagingService models.PagingServiceInterface,
+	transactor db.Transactor,
+	apiTokenRepository models.APITokenRepositoryInterface,
 	pollRepository models.PollRepositoryInterface,
 	postRepository models.PostRepositoryInterface,
 	requestRepository models.RequestRepositoryInterface,
@@ -53,6 +63,8 @@ func NewUserService(
 
 	if mailService == nil ||
 		pagingService == nil ||
+		transactor == nil ||
+		apiTokenRepository == nil ||
 		pollRepository == nil ||
 		postRepository == nil ||
 		requestRepository == nil ||
@@ -63,13 +75,15 @@ func NewUserService(
 	}
 
 	return &UserService{
-		mailService:       mailService,
-		pagingService:     pagingService,
-		pollRepository:    pollRepository,
-		postRepository:    postRepository,
-		requestRepository: requestRepository,
-		tokenRepository:   tokenRepository,
-		userRepository:    userRepository,
+		mailService:        mailService,
+		pagingService:      pagingService,
+		transactor:         transactor,
+		apiTokenRepository: apiTokenRepository,
+		pollRepository:     pollRepository,
+		postRepository:     postRepository,
+		requestRepository:  requestRepository,
+		tokenRepository:    tokenRepository,
+		userRepository:     userRepository,
 	}
 }
 
@@ -138,12 +152,14 @@ func (s *UserService) Create(ctx context.Context, createRequestI interface{}) er
 
 	user := new(models.User)
 
-	passHash := sha512.Sum512([]byte(createRequest.PassphrasePlain + os.Getenv("APP_PEPPER")))
-	passHashHex := fmt.Sprintf("%x", passHash)
+	passHash, err := passphrase.Hash(createRequest.PassphrasePlain)
+	if err != nil {
+		return err
+	}
 
 	// Transfer fields from the request to a new User object.
 	user.Nickname = createRequest.Nickname
-	user.PassphraseHex = passHashHex
+	user.PassphraseHex = passHash
 	user.Email = createRequest.Email
 
 	user.FlowList = make(models.UserGenericMap)
@@ -259,6 +275,12 @@ func (s *UserService) Create(ctx context.Context, createRequestI interface{}) er
 		return fmt.Errorf(common.ERR_POSTREG_POST_SAVE_FAIL)
 	}
 
+	audit.Record(ctx, models.AuditEvent{
+		Action: audit.ActionUserCreate,
+		Actor:  user.Nickname,
+		Target: user.Nickname,
+	})
+
 	return nil
 }
 
@@ -271,60 +293,50 @@ func (s *UserService) Subscribe(ctx context.Context, device *models.Device) erro
 		return fmt.Errorf(common.ERR_DEVICE_BLANK)
 	}
 
-	dbUser, err := s.userRepository.GetByID(callerID)
-	if err != nil {
-		return err
-	}
-
-	for _, dev := range dbUser.Devices {
-		if dev.UUID == device.UUID {
-			return fmt.Errorf("%s", common.ERR_DEVICE_SUBSCRIBED_ALREADY)
+	return s.userRepository.Update(callerID, func(dbUser *models.User) error {
+		for _, dev := range dbUser.Devices {
+			if dev.UUID == device.UUID {
+				return fmt.Errorf("%s", common.ERR_DEVICE_SUBSCRIBED_ALREADY)
+			}
 		}
-	}
-
-	dbUser.Devices = append(dbUser.Devices, *device)
 
-	if err := s.userRepository.Save(dbUser); err != nil {
-		return err
-	}
-
-	return nil
+		// The devices are copied first, as the slice is shared with the stored user.
+		dbUser.Devices = append(slices.Clone(dbUser.Devices), *device)
+		return nil
+	})
 }
 
 func (s *UserService) Unsubscribe(ctx context.Context, uuid string) error {
 	// Fetch the callerID from the given context.
 	callerID := common.GetCallerID(ctx)
 
-	dbUser, err := s.userRepository.GetByID(callerID)
-	if err != nil {
-		return nil
-	}
+	err := s.userRepository.Update(callerID, func(dbUser *models.User) error {
+		var newDevices []models.Device
 
-	var newDevices []models.Device
+		for _, dev := range dbUser.Devices {
+			if dev.UUID == uuid {
+				continue
+			}
 
-	for _, dev := range dbUser.Devices {
-		if dev.UUID == uuid {
-			continue
-		}
+			if reflect.DeepEqual(dev, (models.Device{})) {
+				continue
+			}
 
-		if reflect.DeepEqual(dev, (models.Device{})) {
-			continue
-		}
+			if dev.UUID == "" {
+				continue
+			}
 
-		if dev.UUID == "" {
-			continue
+			newDevices = append(newDevices, dev)
 		}
 
-		newDevices = append(newDevices, dev)
-	}
-
-	dbUser.Devices = newDevices
-
-	if err := s.userRepository.Save(dbUser); err != nil {
-		return err
+		dbUser.Devices = newDevices
+		return nil
+	})
+	if err != nil && err.Error() == common.ERR_USER_NOT_FOUND {
+		return nil
 	}
 
-	return nil
+	return err
 }
 
 func (s *UserService) Activate(ctx context.Context, UUID string) error {
@@ -363,15 +375,18 @@ func (s *UserService) Activate(ctx context.Context, UUID string) error {
 	}
 
 	// Update the user's activation status (a deprecated and a new method).
-	user.Active = true
+	err = s.userRepository.Update(user.Nickname, func(user *models.User) error {
+		user.Active = true
 
-	if user.Options == nil {
-		user.Options = make(map[string]bool)
-	}
-	user.Options["active"] = true
+		// The options are copied first, as the map is shared with the stored user.
+		user.Options = maps.Clone(user.Options)
+		if user.Options == nil {
+			user.Options = make(map[string]bool)
+		}
+		user.Options["active"] = true
 
-	// Save the just-activated user back to the database.
-	err = s.userRepository.Save(user)
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf(common.ERR_USER_UPDATE_FAIL)
 	}
@@ -382,6 +397,12 @@ func (s *UserService) Activate(ctx context.Context, UUID string) error {
 		return fmt.Errorf(common.ERR_REQUEST_DELETE_FAIL)
 	}
 
+	audit.Record(ctx, models.AuditEvent{
+		Action: audit.ActionUserActivate,
+		Actor:  user.Nickname,
+		Target: user.Nickname,
+	})
+
 	return nil
 }
 
@@ -403,43 +424,52 @@ func (s *UserService) Update(ctx context.Context, userID, reqType string, userRe
 			return fmt.Errorf(common.ERR_CALLER_NOT_FOUND)
 		}
 
-		// Fetch requested user by userID from repository.
-		dbUser, err := s.userRepository.GetByID(userID)
-		if err != nil {
-			return fmt.Errorf(common.ERR_USER_NOT_FOUND)
-		}
+		// Process the lists on the current user's data, the update is retried if the user is saved by another request meanwhile.
+		err = s.userRepository.Update(userID, func(dbUser *models.User) error {
+			// The lists are copied first, as the maps are shared with the stored user.
+			dbUser.FlowList = maps.Clone(dbUser.FlowList)
+			dbUser.RequestList = maps.Clone(dbUser.RequestList)
+			dbUser.ShadeList = maps.Clone(dbUser.ShadeList)
 
-		// Process the flowList request.
-		if data.FlowList != nil {
-			dbUser = processFlowList(data, dbUser, caller, s.userRepository)
+			// Process the flowList request.
+			if data.FlowList != nil {
+				dbUser = processFlowList(data, dbUser, caller, s.userRepository)
 
-			/*for key, value := range data.FlowList {
-				if value && dbUser.FlowList[key] != data.FlowList[key] {
-					return fmt.Errorf(common.ERR_USER_SHADED)
-				}
-			}*/
-		}
+				/*for key, value := range data.FlowList {
+					if value && dbUser.FlowList[key] != data.FlowList[key] {
+						return fmt.Errorf(common.ERR_USER_SHADED)
+					}
+				}*/
+			}
 
-		// Process the requestList request.
-		if data.RequestList != nil {
-			dbUser = processRequestList(data, dbUser, caller)
+			// Process the requestList request.
+			if data.RequestList != nil {
+				dbUser = processRequestList(data, dbUser, caller)
 
-			for key, value := range data.RequestList {
-				if value && dbUser.RequestList[key] != data.RequestList[key] {
-					return fmt.Errorf(common.ERR_USER_SHADED)
+				for key, value := range data.RequestList {
+					if value && dbUser.RequestList[key] != data.RequestList[key] {
+						return fmt.Errorf(common.ERR_USER_SHADED)
+					}
 				}
 			}
-		}
 
-		// Process the shadeList request.
-		if data.ShadeList != nil {
-			dbUser = processShadeList(data, dbUser, caller)
-		}
+			// Process the shadeList request.
+			if data.ShadeList != nil {
+				dbUser = processShadeList(data, dbUser, caller)
+			}
 
-		if err := s.userRepository.Save(dbUser); err != nil {
+			return nil
+		})
+		if err != nil {
 			return err
 		}
 
+		audit.Record(ctx, models.AuditEvent{
+			Action:  audit.ActionUserListsUpdate,
+			Target:  userID,
+			Details: listsChanged(data),
+		})
+
 	case "options":
 		// Assert the type for the user update request.
 		data, ok := userRequest.(*UserUpdateOptionsRequest)
@@ -451,54 +481,54 @@ func (s *UserService) Update(ctx context.Context, userID, reqType string, userRe
 			return fmt.Errorf(common.ERR_USER_UPDATE_FOREIGN)
 		}
 
-		// Fetch requested user by userID from repository.
-		dbUser, err := s.userRepository.GetByID(userID)
-		if err != nil {
-			return fmt.Errorf(common.ERR_USER_NOT_FOUND)
-		}
-		// Patch the nil map.
-		if dbUser.Options == nil {
-			dbUser.Options = models.UserOptionsMap{}
-		}
+		// Apply the options to the current user's data, the update is retried if the user is saved by another request meanwhile.
+		err := s.userRepository.Update(userID, func(dbUser *models.User) error {
+			// The options are copied first, as the map is shared with the stored user (the nil map is patched too).
+			dbUser.Options = maps.Clone(dbUser.Options)
+			if dbUser.Options == nil {
+				dbUser.Options = models.UserOptionsMap{}
+			}
 
-		// Toggle dark mode to light mode and vice versa.
-		if data.UIMode != dbUser.UIMode {
-			dbUser.UIMode = !dbUser.UIMode
-			dbUser.Options["uiMode"] = data.UIMode
-		}
-		if data.UITheme != dbUser.UITheme {
-			dbUser.UITheme = data.UITheme
-		}
+			// Toggle dark mode to light mode and vice versa.
+			if data.UIMode != dbUser.UIMode {
+				dbUser.UIMode = !dbUser.UIMode
+				dbUser.Options["uiMode"] = data.UIMode
+			}
+			if data.UITheme != dbUser.UITheme {
+				dbUser.UITheme = data.UITheme
+			}
 
-		// Toggle the live mode.
-		if data.LiveMode != dbUser.LiveMode {
-			dbUser.LiveMode = !dbUser.LiveMode
-			dbUser.Options["liveMode"] = data.LiveMode
-		}
+			// Toggle the live mode.
+			if data.LiveMode != dbUser.LiveMode {
+				dbUser.LiveMode = !dbUser.LiveMode
+				dbUser.Options["liveMode"] = data.LiveMode
+			}
 
-		// Toggle the local time mode.
-		if data.LocalTimeMode != dbUser.LocalTimeMode {
-			dbUser.LocalTimeMode = !dbUser.LocalTimeMode
-			dbUser.Options["localTimeMode"] = data.LocalTimeMode
-		}
+			// Toggle the local time mode.
+			if data.LocalTimeMode != dbUser.LocalTimeMode {
+				dbUser.LocalTimeMode = !dbUser.LocalTimeMode
+				dbUser.Options["localTimeMode"] = data.LocalTimeMode
+			}
 
-		// Toggle the private mode.
-		if data.Private != dbUser.Private {
-			dbUser.Private = !dbUser.Private
-			dbUser.Options["private"] = data.Private
-		}
+			// Toggle the private mode.
+			if data.Private != dbUser.Private {
+				dbUser.Private = !dbUser.Private
+				dbUser.Options["private"] = data.Private
+			}
 
-		// Change the about text if present and differs from the current one.
-		if data.AboutText != "" && data.AboutText != dbUser.About {
-			dbUser.About = data.AboutText
-		}
+			// Change the about text if present and differs from the current one.
+			if data.AboutText != "" && data.AboutText != dbUser.About {
+				dbUser.About = data.AboutText
+			}
 
-		// Change the website link if present and differs from the current one.
-		if data.WebsiteLink != "" && data.WebsiteLink != dbUser.Web {
-			dbUser.Web = data.WebsiteLink
-		}
+			// Change the website link if present and differs from the current one.
+			if data.WebsiteLink != "" && data.WebsiteLink != dbUser.Web {
+				dbUser.Web = data.WebsiteLink
+			}
 
-		if err := s.userRepository.Save(dbUser); err != nil {
+			return nil
+		})
+		if err != nil {
 			return err
 		}
 
@@ -524,23 +554,33 @@ func (s *UserService) Update(ctx context.Context, userID, reqType string, userRe
 			return fmt.Errorf(common.ERR_PASSPHRASE_REQ_INCOMPLETE)
 		}
 
-		pepper := os.Getenv("APP_PEPPER")
-
-		passHashNew := sha512.Sum512([]byte(data.NewPassphrase + pepper))
-		passHashCurrent := sha512.Sum512([]byte(data.CurrentPassphrase + pepper))
-
-		// Check if the current passphrasë́'s hash is correct.
-		if fmt.Sprintf("%x", passHashCurrent) != dbUser.PassphraseHex {
+		// Check if the current passphrase is correct (the legacy hashes are accepted too).
+		if ok, _, err := passphrase.Verify(data.CurrentPassphrase, dbUser.PassphraseHex); err != nil || !ok {
 			return fmt.Errorf(common.ERR_PASSPHRASE_CURRENT_WRONG)
 		}
 
-		// Update user's passphrase.
-		dbUser.PassphraseHex = fmt.Sprintf("%x", passHashNew)
+		passHashNew, err := passphrase.Hash(data.NewPassphrase)
+		if err != nil {
+			return err
+		}
 
-		if err := s.userRepository.Save(dbUser); err != nil {
+		// Update user's passphrase, unless it has been changed since verified.
+		if err := s.userRepository.Update(userID, func(user *models.User) error {
+			if user.PassphraseHex != dbUser.PassphraseHex {
+				return fmt.Errorf(common.ERR_PASSPHRASE_CURRENT_WRONG)
+			}
+
+			user.PassphraseHex = passHashNew
+			return nil
+		}); err != nil {
 			return err
 		}
 
+		audit.Record(ctx, models.AuditEvent{
+			Action: audit.ActionPassphraseChange,
+			Target: userID,
+		})
+
 	default:
 		return fmt.Errorf("unknown request type")
 	}
@@ -594,10 +634,11 @@ func (s *UserService) UpdateAvatar(ctx context.Context, userRequest interface{})
 		_ = os.Remove(fileName)
 	}
 
-	user.AvatarURL = "/web/pix/thumb_" + *imageBaseURL
-
 	// Update user's data.
-	err = s.userRepository.Save(user)
+	err = s.userRepository.Update(callerID, func(user *models.User) error {
+		user.AvatarURL = "/web/pix/thumb_" + *imageBaseURL
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -608,49 +649,51 @@ func (s *UserService) UpdateAvatar(ctx context.Context, userRequest interface{})
 func (s *UserService) UpdateSubscriptionTags(ctx context.Context, uuid string, tags []string) error {
 	callerID := common.GetCallerID(ctx)
 
-	dbUser, err := s.userRepository.GetByID(callerID)
-	if err != nil {
-		return nil
-	}
+	err := s.userRepository.Update(callerID, func(dbUser *models.User) error {
+		var (
+			devIdx int
+			found  bool
+		)
 
-	var (
-		devIdx int
-		found  bool
-	)
+		for idx, dev := range dbUser.Devices {
+			if dev.UUID == uuid {
+				found = true
+				devIdx = idx
+				break
+			}
+		}
 
-	for idx, dev := range dbUser.Devices {
-		if dev.UUID == uuid {
-			found = true
-			devIdx = idx
-			break
+		if !found {
+			return fmt.Errorf("%s", common.ERR_SUBSCRIPTION_NOT_FOUND)
 		}
-	}
 
-	if !found {
-		return fmt.Errorf("%s", common.ERR_SUBSCRIPTION_NOT_FOUND)
-	}
+		// The devices and their tags are copied first, as the slices are shared with the stored user.
+		dbUser.Devices = slices.Clone(dbUser.Devices)
+		dbUser.Devices[devIdx].Tags = slices.Clone(dbUser.Devices[devIdx].Tags)
 
-	for _, tag := range tags {
-		if !slices.Contains(dbUser.Devices[devIdx].Tags, tag) {
-			dbUser.Devices[devIdx].Tags = append(dbUser.Devices[devIdx].Tags, tag)
-		} else {
-			var newTags []string
+		for _, tag := range tags {
+			if !slices.Contains(dbUser.Devices[devIdx].Tags, tag) {
+				dbUser.Devices[devIdx].Tags = append(dbUser.Devices[devIdx].Tags, tag)
+			} else {
+				var newTags []string
 
-			for _, t := range dbUser.Devices[devIdx].Tags {
-				if t != tag {
-					newTags = append(newTags, t)
+				for _, t := range dbUser.Devices[devIdx].Tags {
+					if t != tag {
+						newTags = append(newTags, t)
+					}
 				}
-			}
 
-			dbUser.Devices[devIdx].Tags = newTags
+				dbUser.Devices[devIdx].Tags = newTags
+			}
 		}
-	}
 
-	if err := s.userRepository.Save(dbUser); err != nil {
-		return err
+		return nil
+	})
+	if err != nil && err.Error() == common.ERR_USER_NOT_FOUND {
+		return nil
 	}
 
-	return nil
+	return err
 }
 
 func (s *UserService) ProcessPassphraseRequest(ctx context.Context, requestType string, userRequest interface{}) error {
@@ -714,6 +757,12 @@ func (s *UserService) ProcessPassphraseRequest(ctx context.Context, requestType
 		mailType = "reset_request"
 		user = &dbUser
 
+		audit.Record(ctx, models.AuditEvent{
+			Action: audit.ActionPassphraseRequest,
+			Actor:  dbUser.Nickname,
+			Target: dbUser.Nickname,
+		})
+
 		randomUUID = randomID
 
 	case "reset":
@@ -753,17 +802,19 @@ func (s *UserService) ProcessPassphraseRequest(ctx context.Context, requestType
 
 		// Reset the passphrase = generete a new one (32 chars long).
 		randomPassphrase = helpers.RandSeq(32)
-		pepper := config.ServerSecret
 
-		if pepper == "" {
-			return fmt.Errorf(common.ERR_NO_SERVER_SECRET)
+		passHash, err := passphrase.Hash(randomPassphrase)
+		if err != nil {
+			return err
 		}
 
-		// Convert new passphrase into the hexadecimal format with pepper added.
-		passHash := sha512.Sum512([]byte(randomPassphrase + pepper))
-		dbUser.PassphraseHex = fmt.Sprintf("%x", passHash)
+		if err := s.userRepository.Update(dbUser.Nickname, func(dbUser *models.User) error {
+			dbUser.PassphraseHex = passHash
 
-		if err := s.userRepository.Save(dbUser); err != nil {
+			// Lift the lockout, the user has proven the e-mail address.
+			dbUser.FailedLogins = 0
+			return nil
+		}); err != nil {
 			return err
 		}
 
@@ -774,6 +825,12 @@ func (s *UserService) ProcessPassphraseRequest(ctx context.Context, requestType
 		mailType = "reset_passphrase"
 		user = dbUser
 
+		audit.Record(ctx, models.AuditEvent{
+			Action: audit.ActionPassphraseReset,
+			Actor:  dbUser.Nickname,
+			Target: dbUser.Nickname,
+		})
+
 	default:
 		return fmt.Errorf(common.ERR_REQUEST_TYPE_UNKNOWN)
 	}
@@ -801,6 +858,332 @@ func (s *UserService) ProcessPassphraseRequest(ctx context.Context, requestType
 	return nil
 }
 
+// totpIssuer is the issuer label shown in the authenticator apps.
+const totpIssuer = "littr"
+
+func (s *UserService) SetupTwoFactor(ctx context.Context, userID string) (string, string, error) {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if callerID != userID {
+		return "", "", fmt.Errorf(common.ERR_USER_TOTP_FOREIGN)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	// Store the new secret, it is not used to log-in until verified (see EnableTwoFactor).
+	if err := s.userRepository.Update(userID, func(user *models.User) error {
+		if user.TOTPEnabled {
+			return fmt.Errorf(common.ERR_TOTP_ALREADY_ENABLED)
+		}
+
+		user.TOTPSecret = secret
+		user.TOTPLastStep = 0
+
+		return nil
+	}); err != nil {
+		return "", "", err
+	}
+
+	return secret, totp.ProvisioningURI(totpIssuer, userID, secret), nil
+}
+
+func (s *UserService) EnableTwoFactor(ctx context.Context, userID, code string) ([]string, error) {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if callerID != userID {
+		return nil, fmt.Errorf(common.ERR_USER_TOTP_FOREIGN)
+	}
+
+	if code == "" {
+		return nil, fmt.Errorf(common.ERR_TOTP_CODE_BLANK)
+	}
+
+	// Prepare the recovery codes, only their hashes are stored.
+	recoveryCodes, recoveryHashes, err := totp.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepository.Update(userID, func(user *models.User) error {
+		if user.TOTPEnabled {
+			return fmt.Errorf(common.ERR_TOTP_ALREADY_ENABLED)
+		}
+
+		if user.TOTPSecret == "" {
+			return fmt.Errorf(common.ERR_TOTP_NOT_SETUP)
+		}
+
+		// The code proves the secret has been imported to the authenticator app properly.
+		step, ok := totp.Validate(user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+		if !ok {
+			return fmt.Errorf(common.ERR_TOTP_INVALID)
+		}
+
+		user.TOTPEnabled = true
+		user.TOTPLastStep = step
+		user.RecoveryCodes = recoveryHashes
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, models.AuditEvent{
+		Action: audit.ActionTwoFactorEnable,
+		Target: userID,
+	})
+
+	return recoveryCodes, nil
+}
+
+func (s *UserService) DisableTwoFactor(ctx context.Context, userID, code string) error {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if callerID != userID {
+		return fmt.Errorf(common.ERR_USER_TOTP_FOREIGN)
+	}
+
+	if err := s.userRepository.Update(userID, func(user *models.User) error {
+		// Require the second factor, so a stolen session cannot disable it.
+		if err := totp.Authenticate(user, code); err != nil {
+			return err
+		}
+
+		user.TOTPEnabled = false
+		user.TOTPSecret = ""
+		user.TOTPLastStep = 0
+		user.RecoveryCodes = nil
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, models.AuditEvent{
+		Action: audit.ActionTwoFactorDisable,
+		Target: userID,
+	})
+
+	return nil
+}
+
+func (s *UserService) FindSessions(ctx context.Context, userID string) (*[]models.Token, error) {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if callerID != userID {
+		return nil, fmt.Errorf(common.ERR_USER_SESSION_FOREIGN)
+	}
+
+	tokens, err := s.tokenRepository.GetByNickname(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []models.Token{}
+
+	// Skip the expired and rotated refresh tokens, those cannot be used anymore.
+	for _, token := range *tokens {
+		if time.Since(token.CreatedAt) > token.TTL || token.IsRotated() {
+			continue
+		}
+
+		// The family's rotated hashes are of no use to the client.
+		token.RotatedHashes = nil
+
+		sessions = append(sessions, token)
+	}
+
+	// The most recently used sessions go first.
+	slices.SortFunc(sessions, func(a, b models.Token) int {
+		return b.LastUsedAt.Compare(a.LastUsedAt)
+	})
+
+	return &sessions, nil
+}
+
+func (s *UserService) DeleteSession(ctx context.Context, userID, sessionID string) error {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if callerID != userID {
+		return fmt.Errorf(common.ERR_USER_SESSION_FOREIGN)
+	}
+
+	if sessionID == "" {
+		return fmt.Errorf(common.ERR_SESSION_ID_BLANK)
+	}
+
+	// Foreign sessions are reported as non-existing ones.
+	token, err := s.tokenRepository.GetByID(sessionID)
+	if err != nil || token.Nickname != userID {
+		return fmt.Errorf(common.ERR_SESSION_NOT_FOUND)
+	}
+
+	// Revoke the tokens rotated before too, so their reuse is not reported.
+	if err := tokens.RevokeFamily(token, s.tokenRepository); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, models.AuditEvent{
+		Action:  audit.ActionSessionRevoke,
+		Target:  userID,
+		Details: map[string]string{"session": sessionID},
+	})
+
+	return nil
+}
+
+func (s *UserService) DeleteSessions(ctx context.Context, userID string) error {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if callerID != userID {
+		return fmt.Errorf(common.ERR_USER_SESSION_FOREIGN)
+	}
+
+	tokens, err := s.tokenRepository.GetByNickname(userID)
+	if err != nil {
+		return err
+	}
+
+	// Log out everywhere = delete all refresh tokens of such user, including the current one.
+	for key := range *tokens {
+		if err := s.tokenRepository.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	audit.Record(ctx, models.AuditEvent{
+		Action:  audit.ActionSessionRevoke,
+		Target:  userID,
+		Details: map[string]string{"session": "all"},
+	})
+
+	return nil
+}
+
+func (s *UserService) CreateAPIToken(ctx context.Context, userID string, createRequestI interface{}) (string, *models.APIToken, error) {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if callerID != userID {
+		return "", nil, fmt.Errorf(common.ERR_USER_API_TOKEN_FOREIGN)
+	}
+
+	createRequest, ok := createRequestI.(*UserAPITokenRequest)
+	if !ok {
+		return "", nil, fmt.Errorf(common.ERR_INPUT_DATA_FAIL)
+	}
+
+	name := strings.TrimSpace(createRequest.Name)
+	if name == "" || len(name) > apitokens.MaxNameLength {
+		return "", nil, fmt.Errorf(common.ERR_API_TOKEN_NAME_INVALID)
+	}
+
+	if len(createRequest.Scopes) == 0 {
+		return "", nil, fmt.Errorf(common.ERR_API_TOKEN_SCOPES_BLANK)
+	}
+
+	var scopes []string
+
+	for _, scope := range createRequest.Scopes {
+		if !apitokens.IsValidScope(scope) {
+			return "", nil, fmt.Errorf(common.ERR_API_TOKEN_SCOPE_UNKNOWN)
+		}
+
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	ttl := time.Duration(createRequest.ExpiresInDays) * 24 * time.Hour
+	if ttl <= 0 || ttl > apitokens.MaxTTL {
+		return "", nil, fmt.Errorf(common.ERR_API_TOKEN_TTL_INVALID)
+	}
+
+	plain, token, err := apitokens.NewAPIToken(userID, name, scopes, ttl)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := s.apiTokenRepository.Save(token); err != nil {
+		return "", nil, err
+	}
+
+	audit.Record(ctx, models.AuditEvent{
+		Action:  audit.ActionAPITokenCreate,
+		Target:  userID,
+		Details: map[string]string{"name": token.Name, "scopes": strings.Join(token.Scopes, ",")},
+	})
+
+	return plain, token, nil
+}
+
+func (s *UserService) FindAPITokens(ctx context.Context, userID string) (*[]models.APIToken, error) {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if callerID != userID {
+		return nil, fmt.Errorf(common.ERR_USER_API_TOKEN_FOREIGN)
+	}
+
+	tokens, err := s.apiTokenRepository.GetByNickname(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []models.APIToken{}
+
+	for _, token := range *tokens {
+		list = append(list, token)
+	}
+
+	// The newest tokens go first.
+	slices.SortFunc(list, func(a, b models.APIToken) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return &list, nil
+}
+
+func (s *UserService) DeleteAPIToken(ctx context.Context, userID, tokenID string) error {
+	// Fetch the caller's ID from the context.
+	callerID := common.GetCallerID(ctx)
+
+	if callerID != userID {
+		return fmt.Errorf(common.ERR_USER_API_TOKEN_FOREIGN)
+	}
+
+	if tokenID == "" {
+		return fmt.Errorf(common.ERR_API_TOKEN_ID_BLANK)
+	}
+
+	// Foreign tokens are reported as non-existing ones.
+	token, err := s.apiTokenRepository.GetByID(tokenID)
+	if err != nil || token.Nickname != userID {
+		return fmt.Errorf(common.ERR_API_TOKEN_NOT_FOUND)
+	}
+
+	if err := s.apiTokenRepository.Delete(tokenID); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, models.AuditEvent{
+		Action:  audit.ActionAPITokenDelete,
+		Target:  userID,
+		Details: map[string]string{"name": token.Name},
+	})
+
+	return nil
+}
+
 func (s *UserService) Delete(ctx context.Context, userID string) error {
 	// Fetch the caller's ID from the context.
 	callerID := common.GetCallerID(ctx)
@@ -821,81 +1204,138 @@ func (s *UserService) Delete(ctx context.Context, userID string) error {
 		return fmt.Errorf(common.ERR_USER_NOT_FOUND)
 	}
 
-	// Delete requested user's record from database.
-	if err := s.userRepository.Delete(userID); err != nil {
-		return fmt.Errorf(common.ERR_USER_DELETE_FAIL)
+	if err := PurgeUser(s.transactor, userID); err != nil {
+		return err
 	}
 
-	//
-	//  Delete all posts, delete polls, delete tokens
-	//
+	audit.Record(ctx, models.AuditEvent{
+		Action: audit.ActionUserDelete,
+		Target: userID,
+	})
 
-	polls, err := s.pollRepository.GetAll()
-	if err != nil {
-		return err
-	}
+	return nil
+}
 
-	posts, err := s.postRepository.GetAll()
-	if err != nil {
-		return err
-	}
+// PurgeUser deletes the user together with all their polls, posts and tokens, the images of the posts are removed afterwards.
+func PurgeUser(transactor db.Transactor, userID string) error {
+	// Figures of the posts deleted, removed once the deletion is committed.
+	var figures []string
 
-	tokens, err := s.tokenRepository.GetAll()
-	if err != nil {
-		return err
-	}
+	// IDs of the posts deleted, purged from the bookmarks.
+	var postIDs []string
 
-	// Spinoff a goroutine to process all the deletions async.
-	go func(pollRepo models.PollRepositoryInterface, postRepo models.PostRepositoryInterface, tokenRepo models.TokenRepositoryInterface) {
-		l := common.NewLogger(nil, "userDelete")
+	// Delete the user together with all their polls, posts and tokens as a unit, so no account is left half-deleted.
+	err := transactor.RunInTransaction(func(repos *db.Repositories) error {
+		// Delete requested user's record from database.
+		if err := repos.UserRepository.Delete(userID); err != nil {
+			return fmt.Errorf(common.ERR_USER_DELETE_FAIL)
+		}
 
-		for key, poll := range *polls {
-			if poll.Author == userID {
-				// Delete the poll.
-				if err := pollRepo.Delete(key); err != nil {
-					l.Msg("could not delete a poll: " + key).Status(http.StatusInternalServerError).Log()
+		//
+		//  Delete all posts, delete polls, delete tokens
+		//
+
+		polls, err := repos.PollRepository.GetAll()
+		if err != nil && err.Error() != common.ERR_NO_ITEMS_FOUND {
+			return err
+		}
+
+		if polls != nil {
+			for key, poll := range *polls {
+				if poll.Author != userID {
 					continue
 				}
+
+				// Delete the poll.
+				if err := repos.PollRepository.Delete(key); err != nil {
+					return fmt.Errorf("could not delete a poll: %s: %w", key, err)
+				}
 			}
 		}
 
-		for key, post := range *posts {
-			if post.Nickname == userID {
-				// Delete the post.
-				if err := postRepo.Delete(key); err != nil {
-					l.Msg("could not delete a post: " + key).Status(http.StatusInternalServerError).Log()
+		posts, err := repos.PostRepository.GetAll()
+		if err != nil && err.Error() != common.ERR_NO_ITEMS_FOUND {
+			return err
+		}
+
+		if posts != nil {
+			for key, post := range *posts {
+				if post.Nickname != userID {
 					continue
 				}
 
-				// Delete associated image and its thumbnail.
-				if post.Figure != "" {
-					err := os.Remove("/opt/pix/thumb_" + post.Figure)
-					if err != nil {
-						l.Msg(common.ERR_POST_DELETE_THUMB).Status(http.StatusInternalServerError).Error(err).Log()
-						continue
-					}
+				// Delete the post.
+				if err := repos.PostRepository.Delete(key); err != nil {
+					return fmt.Errorf("could not delete a post: %s: %w", key, err)
+				}
 
-					err = os.Remove("/opt/pix/" + post.Figure)
-					if err != nil {
-						l.Msg(common.ERR_POST_DELETE_FULLIMG).Status(http.StatusInternalServerError).Error(err).Log()
-						continue
-					}
+				if post.Figure != "" {
+					figures = append(figures, post.Figure)
 				}
+
+				postIDs = append(postIDs, key)
 			}
 		}
 
-		for key, token := range *tokens {
-			if token.Nickname == userID {
-				// Delete the token.
-				if err := tokenRepo.Delete(key); err != nil {
-					l.Msg("could not delete a token: " + key).Status(http.StatusInternalServerError).Log()
+		tokens, err := repos.TokenRepository.GetAll()
+		if err != nil && err.Error() != common.ERR_NO_ITEMS_FOUND {
+			return err
+		}
+
+		if tokens != nil {
+			for key, token := range *tokens {
+				if token.Nickname != userID {
 					continue
 				}
+
+				// Delete the token.
+				if err := repos.TokenRepository.Delete(key); err != nil {
+					return fmt.Errorf("could not delete a token: %s: %w", key, err)
+				}
+			}
+		}
+
+		// Drop the posts deleted from the remaining users' bookmarks.
+		if err := PurgeBookmarks(repos.UserRepository, postIDs...); err != nil {
+			return err
+		}
+
+		apiTokens, err := repos.APITokenRepository.GetByNickname(userID)
+		if err != nil {
+			return err
+		}
+
+		for key := range *apiTokens {
+			// Delete the API token.
+			if err := repos.APITokenRepository.Delete(key); err != nil {
+				return fmt.Errorf("could not delete an API token: %s: %w", key, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Spinoff a goroutine to delete the associated images and their thumbnails, the files cannot be part of the transaction.
+	go func(figures []string) {
+		l := common.NewLogger(nil, "userDelete")
+
+		for _, figure := range figures {
+			if err := os.Remove("/opt/pix/thumb_" + figure); err != nil {
+				l.Msg(common.ERR_POST_DELETE_THUMB).Status(http.StatusInternalServerError).Error(err).Log()
+				continue
+			}
+
+			if err := os.Remove("/opt/pix/" + figure); err != nil {
+				l.Msg(common.ERR_POST_DELETE_FULLIMG).Status(http.StatusInternalServerError).Error(err).Log()
+				continue
 			}
 		}
 
 		l.Msg("associated data linked to a just deleted user have been purged").Status(http.StatusOK).Log()
-	}(s.pollRepository, s.postRepository, s.tokenRepository)
+	}(figures)
 
 	return nil
 }
@@ -1001,7 +1441,7 @@ func (s *UserService) FindPostsByID(ctx context.Context, userID string, pageOpts
 		},
 	}
 
-	allPosts, err := s.postRepository.GetAll()
+	postData, err := pages.PostData(s.postRepository)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1011,7 +1451,7 @@ func (s *UserService) FindPostsByID(ctx context.Context, userID string, pageOpts
 		return nil, nil, err
 	}
 
-	iface, err := s.pagingService.GetOne(ctx, opts, allPosts, allUsers)
+	iface, err := s.pagingService.GetOne(ctx, opts, postData, allUsers)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1164,3 +1604,28 @@ func processShadeList(data *UserUpdateListsRequest, user *models.User, caller *m
 
 	return user
 }
+
+// listsChanged returns the lists' changes requested (e.g. shade_list: dave:true) as the audit event's details.
+func listsChanged(data *UserUpdateListsRequest) map[string]string {
+	details := make(map[string]string)
+
+	for name, list := range map[string]map[string]bool{
+		"flow_list":    data.FlowList,
+		"request_list": data.RequestList,
+		"shade_list":   data.ShadeList,
+	} {
+		if list == nil {
+			continue
+		}
+
+		var changes []string
+		for key, value := range list {
+			changes = append(changes, key+":"+strconv.FormatBool(value))
+		}
+
+		slices.Sort(changes)
+		details[name] = strings.Join(changes, ",")
+	}
+
+	return details
+}
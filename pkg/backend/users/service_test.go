@@ -2,14 +2,31 @@ package users
 
 import (
 	"context"
+	"slices"
 	"testing"
+	"time"
 
 	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
 	"go.vxn.dev/littr/pkg/models"
 )
 
 type mockNickname string
 
+// mockTransactor runs the function using the mock repositories directly.
+type mockTransactor struct{}
+
+func (m *mockTransactor) RunInTransaction(fn func(repos *db.Repositories) error) error {
+	return fn(&db.Repositories{
+		APITokenRepository: &common.MockAPITokenRepository{},
+		PollRepository:     &common.MockPollRepository{},
+		PostRepository:     &common.MockPostRepository{},
+		RequestRepository:  &common.MockRequestRepository{},
+		TokenRepository:    &common.MockTokenRepository{},
+		UserRepository:     &common.MockUserRepository{},
+	})
+}
+
 //
 //  Tests
 //
@@ -19,7 +36,7 @@ func newTestContext() context.Context {
 }
 
 func newTestService(t *testing.T) models.UserServiceInterface {
-	service := NewUserService(&common.MockMailService{}, &common.MockPagingService{}, &common.MockPollRepository{}, &common.MockPostRepository{}, &common.MockRequestRepository{}, &common.MockTokenRepository{}, &common.MockUserRepository{})
+	service := NewUserService(&common.MockMailService{}, &common.MockPagingService{}, &mockTransactor{}, &common.MockAPITokenRepository{}, &common.MockPollRepository{}, &common.MockPostRepository{}, &common.MockRequestRepository{}, &common.MockTokenRepository{}, &common.MockUserRepository{})
 	if service == nil {
 		t.Fatal("nil UserService")
 	}
@@ -50,3 +67,122 @@ func TestUsers_UserServiceActivate(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestUsers_UserServiceSessions(t *testing.T) {
+	ctx := context.WithValue(context.Background(), common.ContextUserKeyName, common.MockUserNickname)
+	service := newTestService(t)
+
+	sessions, err := service.FindSessions(ctx, common.MockUserNickname)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The mock token has expired already.
+	if len(*sessions) != 0 {
+		t.Errorf("expired session listed: %v", *sessions)
+	}
+
+	if _, err := service.FindSessions(ctx, "tweaker66"); err == nil || err.Error() != common.ERR_USER_SESSION_FOREIGN {
+		t.Errorf("foreign sessions listed: %v", err)
+	}
+
+	if err := service.DeleteSession(ctx, common.MockUserNickname, "ff00lertT5"); err != nil {
+		t.Error(err)
+	}
+
+	if err := service.DeleteSessions(ctx, common.MockUserNickname); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUsers_UserServiceAPITokens(t *testing.T) {
+	ctx := context.WithValue(context.Background(), common.ContextUserKeyName, common.MockUserNickname)
+	service := newTestService(t)
+
+	plain, token, err := service.CreateAPIToken(ctx, common.MockUserNickname, &UserAPITokenRequest{
+		Name:          "bot",
+		Scopes:        []string{"posts:write", "posts:write"},
+		ExpiresInDays: 30,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plain == "" || len(token.Scopes) != 1 {
+		t.Errorf("unexpected token: %+v", token)
+	}
+
+	invalid := []struct {
+		req *UserAPITokenRequest
+		err string
+	}{
+		{&UserAPITokenRequest{Name: " ", Scopes: []string{"posts:read"}, ExpiresInDays: 30}, common.ERR_API_TOKEN_NAME_INVALID},
+		{&UserAPITokenRequest{Name: "bot", ExpiresInDays: 30}, common.ERR_API_TOKEN_SCOPES_BLANK},
+		{&UserAPITokenRequest{Name: "bot", Scopes: []string{"admin:all"}, ExpiresInDays: 30}, common.ERR_API_TOKEN_SCOPE_UNKNOWN},
+		{&UserAPITokenRequest{Name: "bot", Scopes: []string{"posts:read"}, ExpiresInDays: 366}, common.ERR_API_TOKEN_TTL_INVALID},
+	}
+
+	for _, c := range invalid {
+		if _, _, err := service.CreateAPIToken(ctx, common.MockUserNickname, c.req); err == nil || err.Error() != c.err {
+			t.Errorf("expected %q, got %v", c.err, err)
+		}
+	}
+
+	if _, _, err := service.CreateAPIToken(ctx, "tweaker66", &UserAPITokenRequest{}); err == nil || err.Error() != common.ERR_USER_API_TOKEN_FOREIGN {
+		t.Errorf("foreign API token created: %v", err)
+	}
+
+	tokens, err := service.FindAPITokens(ctx, common.MockUserNickname)
+	if err != nil || len(*tokens) != 1 {
+		t.Fatalf("unexpected API tokens: %v, %v", tokens, err)
+	}
+
+	if err := service.DeleteAPIToken(ctx, common.MockUserNickname, (*tokens)[0].Hash); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUsers_UserServiceDevices(t *testing.T) {
+	ctx := context.WithValue(context.Background(), common.ContextUserKeyName, "alice")
+	userRepository := NewUserRepository(db.NewSimpleCache("UserCache"))
+
+	service := NewUserService(&common.MockMailService{}, &common.MockPagingService{}, &mockTransactor{}, &common.MockAPITokenRepository{}, &common.MockPollRepository{}, &common.MockPostRepository{}, &common.MockRequestRepository{}, &common.MockTokenRepository{}, userRepository)
+
+	if err := userRepository.Save(&models.User{Nickname: "alice", Bookmarks: map[string]time.Time{"1": time.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.Subscribe(ctx, &models.Device{UUID: "dev-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.Subscribe(ctx, &models.Device{UUID: "dev-1"}); err == nil || err.Error() != common.ERR_DEVICE_SUBSCRIBED_ALREADY {
+		t.Errorf("subscribing twice: expected %q, got %v", common.ERR_DEVICE_SUBSCRIBED_ALREADY, err)
+	}
+
+	if err := service.UpdateSubscriptionTags(ctx, "dev-1", []string{"mention"}); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := userRepository.GetByID("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(user.Devices) != 1 || !slices.Contains(user.Devices[0].Tags, "mention") {
+		t.Errorf("unexpected devices: %+v", user.Devices)
+	}
+
+	if err := service.Unsubscribe(ctx, "dev-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if user, err = userRepository.GetByID("alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the devices are changed, the rest of the user is kept.
+	if len(user.Devices) != 0 || !user.HasBookmarked("1") {
+		t.Errorf("unexpected user after unsubscribing: devices %+v, bookmarks %v", user.Devices, user.Bookmarks)
+	}
+}
@@ -0,0 +1,143 @@
+package users
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.vxn.dev/littr/pkg/backend/common"
+	"go.vxn.dev/littr/pkg/backend/db"
+	"go.vxn.dev/littr/pkg/models"
+)
+
+// The implementation of pkg/models.UserRepositoryInterface on the SQLite database (see pkg/backend/db/sqlite.go for the schema).
+type SQLiteUserRepository struct {
+	db db.SQLHandle
+}
+
+func NewSQLiteUserRepository(handle db.SQLHandle) *SQLiteUserRepository {
+	if handle == nil {
+		return nil
+	}
+
+	return &SQLiteUserRepository{
+		db: handle,
+	}
+}
+
+func (r *SQLiteUserRepository) GetAll() (*map[string]models.User, error) {
+	rows, err := r.db.Query("SELECT data FROM users")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make(map[string]models.User)
+
+	for rows.Next() {
+		var raw []byte
+
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+
+		var user models.User
+
+		if err := json.Unmarshal(raw, &user); err != nil {
+			return nil, fmt.Errorf("user's data corrupted")
+		}
+
+		users[user.Nickname] = user
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(users) == 0 {
+		return nil, fmt.Errorf(common.ERR_NO_ITEMS_FOUND)
+	}
+
+	return &users, nil
+}
+
+func (r *SQLiteUserRepository) GetByID(userID string) (*models.User, error) {
+	return r.get("SELECT data FROM users WHERE nickname = ?", userID)
+}
+
+func (r *SQLiteUserRepository) Save(user *models.User) error {
+	raw, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(`INSERT INTO users (nickname, email, data) VALUES (?, ?, ?)
+		ON CONFLICT (nickname) DO UPDATE SET email = excluded.email, data = excluded.data`,
+		user.Nickname, user.Email, raw); err != nil {
+		return fmt.Errorf("an error occurred while saving a user: %w", err)
+	}
+
+	return nil
+}
+
+// Update applies the function to the stored user and saves the result, only if the user has not been changed concurrently (see db.SQLVersionCondition).
+func (r *SQLiteUserRepository) Update(userID string, fn func(user *models.User) error) error {
+	return db.RetryOnConflict(func() error {
+		user, err := r.GetByID(userID)
+		if err != nil {
+			return err
+		}
+
+		version := user.Version
+
+		if err := fn(user); err != nil {
+			return err
+		}
+
+		user.Version = version + 1
+
+		raw, err := json.Marshal(user)
+		if err != nil {
+			return err
+		}
+
+		res, err := r.db.Exec("UPDATE users SET email = ?, data = ? WHERE nickname = ? AND "+db.SQLVersionCondition,
+			user.Email, raw, userID, version)
+		if err != nil {
+			return fmt.Errorf("an error occurred while saving a user: %w", err)
+		}
+
+		return db.CheckSwapped(res)
+	})
+}
+
+func (r *SQLiteUserRepository) Delete(userID string) error {
+	if _, err := r.db.Exec("DELETE FROM users WHERE nickname = ?", userID); err != nil {
+		return fmt.Errorf("user data could not be purged from the database")
+	}
+
+	return nil
+}
+
+// get runs the query selecting the data column of a single user.
+func (r *SQLiteUserRepository) get(query string, args ...interface{}) (*models.User, error) {
+	var raw []byte
+
+	err := r.db.QueryRow(query, args...).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(common.ERR_USER_NOT_FOUND)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+
+	if err := json.Unmarshal(raw, &user); err != nil {
+		return nil, fmt.Errorf(common.ERR_USER_DATA_CORRUPTED)
+	}
+
+	return &user, nil
+}
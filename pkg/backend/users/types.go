@@ -1,6 +1,8 @@
 package users
 
 import (
+	"time"
+
 	"go.vxn.dev/littr/pkg/models"
 )
 
@@ -55,6 +57,46 @@ type UserUpdatePassphraseRequest struct {
 	CurrentPassphrase string `json:"current_passphrase_plain"`
 }
 
+type UserTwoFactorRequest struct {
+	// Code is the TOTP code, or one of the recovery codes.
+	Code string `json:"code" example:"123456"`
+}
+
+// UserSession is the refresh token's representation in the sessions list.
+type UserSession struct {
+	// ID is the refresh token's hash, used to revoke the session.
+	ID         string    `json:"id" example:"6f2d5c3b..."`
+	UserAgent  string    `json:"user_agent" example:"Mozilla/5.0 (X11; Linux x86_64)"`
+	IPAddress  string    `json:"ip_address" example:"192.0.2.1"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+
+	// Current is true for the session the request has been sent within.
+	Current bool `json:"current"`
+}
+
+// UserAPITokenRequest is the request to create a new personal API token.
+type UserAPITokenRequest struct {
+	Name          string   `json:"name" example:"weather bot"`
+	Scopes        []string `json:"scopes" example:"posts:write"`
+	ExpiresInDays int      `json:"expires_in_days" example:"90"`
+}
+
+// UserAPIToken is the API token's representation in the tokens list.
+type UserAPIToken struct {
+	// ID is the token's hash, used to revoke the token.
+	ID         string    `json:"id" example:"9b71d224..."`
+	Name       string    `json:"name" example:"weather bot"`
+	Scopes     []string  `json:"scopes" example:"posts:write"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+
+	// Token is the plain token, returned just once at the creation.
+	Token string `json:"token,omitempty" example:"littr_4f1c..."`
+}
+
 type UserUpdateSubscriptionRequest []string
 
 type UserUploadAvatarRequest struct {
@@ -4,6 +4,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -17,15 +18,23 @@ const (
 )
 
 const (
+	envAppAdmins           string = "APP_ADMINS"
 	envAppEnvironment      string = "APP_ENVIRONMENT"
 	envAppPort             string = "APP_PORT"
 	envAppUrl              string = "APP_URL_MAIN"
 	envAppVersion          string = "APP_VERSION"
+	envAuditLogRetain      string = "AUDIT_LOG_RETENTION"
 	envDataDumpFormat      string = "DATA_DUMP_FORMAT"
+	envDataJournalEnabled  string = "DATA_JOURNAL_ENABLED"
 	envDataLoadFormat      string = "DATA_LOAD_FORMAT"
+	envDataRepoBackend     string = "DATA_REPOSITORY_BACKEND"
+	envDataSnapshotRetain  string = "DATA_SNAPSHOT_RETENTION"
+	envDataStorageBackend  string = "DATA_STORAGE_BACKEND"
 	envDockerInternalPort  string = "DOCKER_INTERNAL_PORT"
 	envDumpToken           string = "API_TOKEN"
 	envLimiterEnabled      string = "LIMITER_ENABLED"
+	envOIDCProviders       string = "APP_OIDC_PROVIDERS"
+	envPostEditWindow      string = "POST_EDIT_WINDOW"
 	envRegistrationEnabled string = "REGISTRATION_ENABLED"
 	envServerSecret        string = "APP_PEPPER"
 	envServerPort          string = "SERVER_PORT"
@@ -37,10 +46,16 @@ const (
 	defaultApiLimiterEnabled     bool   = true
 	defaultAppEnvironment        string = "dev"
 	defaultAppUrl                string = "https://www.littr.eu"
+	defaultAuditLogRetain        int    = 5
 	defaultDataDumpFormat        string = "JSON"
+	defaultDataJournalEnabled    bool   = true
 	defaultDataLoadFormat        string = "JSON"
+	defaultDataRepoBackend       string = "cache"
+	defaultDataSnapshotRetain    int    = 5
+	defaultDataStorageBackend    string = "memory"
 	defaultDumpToken             string = ""
 	defaultPagingCount           int    = 25
+	defaultPostEditWindow        string = "15m"
 	defaultRegistrationEnabled   bool   = true
 	defaultServerPort            string = "8054"
 	defaultServerSecret          string = ""
@@ -50,6 +65,38 @@ const (
 )
 
 var (
+	// AdminNicknames lists the accounts holding the admin role regardless of their stored roles (a comma-separated list). It is meant to bootstrap
+	// the very first admin, the other roles are assigned using the admin API without a restart.
+	AdminNicknames []string = func() []string {
+		var nicknames []string
+
+		for _, nickname := range strings.Split(os.Getenv(envAppAdmins), ",") {
+			if nickname = strings.TrimSpace(nickname); nickname != "" {
+				nicknames = append(nicknames, nickname)
+			}
+		}
+
+		return nicknames
+	}()
+
+	// OIDCProviders holds the OpenID Connect identity providers to sign in with, listed in APP_OIDC_PROVIDERS (a comma-separated list of names).
+	// Each provider is configured using the APP_OIDC_<NAME>_* variables (see loadOIDCProvider).
+	OIDCProviders map[string]OIDCProvider = func() map[string]OIDCProvider {
+		providers := make(map[string]OIDCProvider)
+
+		for _, name := range strings.Split(os.Getenv(envOIDCProviders), ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name == "" {
+				continue
+			}
+
+			if provider, ok := loadOIDCProvider(name); ok {
+				providers[name] = provider
+			}
+		}
+
+		return providers
+	}()
+
 	// AppEnvironment is a string variable that determines the purpose of the very instance.
 	AppEnvironment string = func() string {
 		if val := os.Getenv(envAppEnvironment); val != "" {
@@ -67,6 +114,7 @@ var (
 		return "unknown"
 	}()
 
+	// DataDumpFormat selects the snapshot format written by the dumps: "JSON", or "JSONL_GZ" (the streaming gzip-compressed JSON Lines). The format is detected automatically on load.
 	DataDumpFormat string = func() string {
 		if val := os.Getenv(envDataDumpFormat); val != "" {
 			return val
@@ -75,6 +123,7 @@ var (
 		return defaultDataDumpFormat
 	}()
 
+	// DataDumpToken is the secret key the data-intervention requests are signed with (see pkg/backend/db/dump_auth.go). It is never sent itself.
 	DataDumpToken string = func() string {
 		if val := os.Getenv(envDumpToken); val != "" {
 			return val
@@ -91,6 +140,66 @@ var (
 		return defaultDataLoadFormat
 	}()
 
+	// IsDataJournalEnabled is a feature flag for the write-ahead journal of the in-memory caches (see pkg/backend/db/journal.go).
+	IsDataJournalEnabled bool = func() bool {
+		if val := os.Getenv(envDataJournalEnabled); val != "" {
+			boolVal, err := strconv.ParseBool(val)
+			if err != nil {
+				return false
+			}
+
+			return boolVal
+		}
+
+		return defaultDataJournalEnabled
+	}()
+
+	// DataRepositoryBackend selects the repositories' implementation: "cache" (the repositories over the caches, see DataStorageBackend), or "sqlite" (the embedded SQL database).
+	DataRepositoryBackend string = func() string {
+		if val := os.Getenv(envDataRepoBackend); val != "" {
+			return val
+		}
+
+		return defaultDataRepoBackend
+	}()
+
+	// AuditLogRetention is the number of the rotated audit log files to keep (see pkg/backend/audit).
+	AuditLogRetention int = func() int {
+		if val := os.Getenv(envAuditLogRetain); val != "" {
+			intVal, err := strconv.Atoi(val)
+			if err != nil || intVal < 1 {
+				return defaultAuditLogRetain
+			}
+
+			return intVal
+		}
+
+		return defaultAuditLogRetain
+	}()
+
+	// DataSnapshotRetention is the number of the snapshot generations to keep per cache (see pkg/backend/db/snapshot.go).
+	DataSnapshotRetention int = func() int {
+		if val := os.Getenv(envDataSnapshotRetain); val != "" {
+			intVal, err := strconv.Atoi(val)
+			if err != nil || intVal < 1 {
+				return defaultDataSnapshotRetain
+			}
+
+			return intVal
+		}
+
+		return defaultDataSnapshotRetain
+	}()
+
+	// DataStorageBackend selects the caches' implementation: "memory" (the in-memory caches dumped periodically), or "bolt" (the embedded on-disk key-value store).
+	DataStorageBackend string = func() string {
+		if val := os.Getenv(envDataStorageBackend); val != "" {
+			return val
+		}
+
+		return defaultDataStorageBackend
+	}()
+
 	// EnchartedSW is a string variable to hold the templated ServiceWorker contents to load into the very main FE app handler.
 	EnchartedSW = func() string {
 		// Parse the custom Service Worker template string for the app handler.
@@ -130,6 +239,21 @@ var (
 		return defaultRegistrationEnabled
 	}()
 
+	// PostEditWindow is the time after the post's creation the author can edit the post within (e.g. "15m"), zero disables the editing.
+	PostEditWindow time.Duration = func() time.Duration {
+		val := os.Getenv(envPostEditWindow)
+		if val == "" {
+			val = defaultPostEditWindow
+		}
+
+		window, err := time.ParseDuration(val)
+		if err != nil || window < 0 {
+			window, _ = time.ParseDuration(defaultPostEditWindow)
+		}
+
+		return window
+	}()
+
 	// ServerPort is a string variable holding the TCP port where the main HTTP server is to listen for incoming connections.
 	ServerPort = func() string {
 		if val := os.Getenv(envServerPort); val != "" {
@@ -188,3 +312,63 @@ var (
 	// Thus listed accounts should have a zero (0) on stats page.
 	UsersToUnshade []string = []string{}
 )
+
+// OIDCProvider holds the settings of an OpenID Connect identity provider (see pkg/backend/oidc).
+type OIDCProvider struct {
+	// Name is the provider's name used in the login route (/api/v1/auth/oidc/{name}).
+	Name string
+
+	// Issuer is the provider's issuer URL, the discovery document is fetched from its /.well-known/openid-configuration path.
+	Issuer string
+
+	ClientID     string
+	ClientSecret string
+
+	// RedirectURL is the callback route's URL registered at the provider.
+	RedirectURL string
+
+	// LinkByEmail allows the existing accounts to be linked to the provider's users by their verified e-mail address.
+	LinkByEmail bool
+
+	// AutoProvision allows the new accounts to be created for the provider's users not linked to any account.
+	AutoProvision bool
+}
+
+// loadOIDCProvider reads the provider's settings from the APP_OIDC_<NAME>_* variables. The provider is skipped if the issuer or the client ID is blank.
+func loadOIDCProvider(name string) (OIDCProvider, bool) {
+	prefix := "APP_OIDC_" + strings.ToUpper(name) + "_"
+
+	boolVar := func(key string, fallback bool) bool {
+		boolVal, err := strconv.ParseBool(os.Getenv(prefix + key))
+		if err != nil {
+			return fallback
+		}
+
+		return boolVal
+	}
+
+	provider := OIDCProvider{
+		Name:          name,
+		Issuer:        strings.TrimSuffix(os.Getenv(prefix+"ISSUER"), "/"),
+		ClientID:      os.Getenv(prefix + "CLIENT_ID"),
+		ClientSecret:  os.Getenv(prefix + "CLIENT_SECRET"),
+		RedirectURL:   os.Getenv(prefix + "REDIRECT_URL"),
+		LinkByEmail:   boolVar("LINK_EMAIL", true),
+		AutoProvision: boolVar("AUTO_PROVISION", false),
+	}
+
+	if provider.Issuer == "" || provider.ClientID == "" {
+		return provider, false
+	}
+
+	if provider.RedirectURL == "" {
+		host := "www.littr.eu"
+		if val := os.Getenv(envAppUrl); val != "" {
+			host = val
+		}
+
+		provider.RedirectURL = "https://" + host + "/api/v1/auth/oidc/" + name + "/callback"
+	}
+
+	return provider, true
+}
@@ -1,8 +1,12 @@
 package molecules
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/maxence-charriere/go-app/v10/pkg/app"
 
+	"go.vxn.dev/littr/pkg/config"
 	"go.vxn.dev/littr/pkg/frontend/atomic/atoms"
 	"go.vxn.dev/littr/pkg/models"
 )
@@ -15,19 +19,40 @@ type PostFooter struct {
 
 	Post models.Post
 
+	// Bookmarked tells whether the post is bookmarked by the logged user.
+	Bookmarked      bool
 	ButtonsDisabled bool
 
-	OnClickDeleteActionName string
-	OnClickStarActionName   string
-	OnClickReplyActionName  string
+	OnClickBookmarkActionName string
+	OnClickDeleteActionName   string
+	OnClickEditActionName     string
+	OnClickReactActionName    string
+	OnClickReplyActionName    string
+	OnClickThreadActionName   string
+}
+
+// ReactionSeparator joins the post's ID and the reaction kind in the value of the react action.
+const ReactionSeparator = "-"
+
+// reactionIcons maps the reaction kinds to their icons.
+var reactionIcons = map[string]string{
+	models.ReactionStar:  "bomb", // snowflake = ac_unit
+	models.ReactionHeart: "favorite",
+	models.ReactionLaugh: "sentiment_very_satisfied",
+	models.ReactionWow:   "sentiment_excited",
+	models.ReactionSad:   "sentiment_sad",
 }
 
 func (p *PostFooter) Render() app.UI {
-	// post footer (timestamp + reply buttom + star/delete button)
+	// post footer (timestamp + reply buttom + reaction/delete buttons)
 	return app.Div().Class("row").Body(
 		app.Div().Class("max").Body(
 			// app.Text(post.Timestamp.Format("Jan 02, 2006 / 15:04:05")),
 			app.Text(p.PostTimestamp),
+
+			app.If(p.Post.Edited, func() app.UI {
+				return app.Span().Class("left-padding italic").Title("edited at " + p.Post.EditedAt.Format("Jan 02, 2006 / 15:04:05")).Text("(edited)")
+			}),
 		),
 
 		app.If(p.Post.Nickname != "system", func() app.UI {
@@ -36,6 +61,21 @@ func (p *PostFooter) Render() app.UI {
 					return app.B().Title("reply count").Text(p.Post.ReplyCount).Class("left-padding")
 				}),
 
+				app.If(p.OnClickThreadActionName != "" && (p.Post.ReplyCount > 0 || p.Post.ReplyToID != ""), func() app.UI {
+					return &atoms.Button{
+						ID:                p.Post.ID,
+						Title:             "show the thread",
+						Class:             "transparent circle",
+						Icon:              "forum",
+						OnClickActionName: p.OnClickThreadActionName,
+						Disabled:          p.ButtonsDisabled,
+					}
+				}),
+
+				app.If(p.OnClickBookmarkActionName != "", func() app.UI {
+					return p.renderBookmarkButton()
+				}),
+
 				&atoms.Button{
 					ID:                p.Post.ID,
 					Title:             "reply",
@@ -48,9 +88,17 @@ func (p *PostFooter) Render() app.UI {
 		}),
 
 		app.If(p.LoggedUserNickname == p.Post.Nickname, func() app.UI {
-			return app.Div().Body(
-				app.B().Title("reaction count").Text(p.Post.ReactionCount).Class("left-padding"),
-
+			return app.Div().Body(append(p.renderReactionCounts(),
+				app.If(p.isEditable(), func() app.UI {
+					return &atoms.Button{
+						ID:                p.Post.ID,
+						Title:             "edit this post",
+						Class:             "transparent circle",
+						Icon:              "edit",
+						OnClickActionName: p.OnClickEditActionName,
+						Disabled:          p.ButtonsDisabled,
+					}
+				}),
 				&atoms.Button{
 					ID:                p.Post.ID,
 					Title:             "delete this post",
@@ -59,23 +107,85 @@ func (p *PostFooter) Render() app.UI {
 					OnClickActionName: p.OnClickDeleteActionName,
 					Disabled:          p.ButtonsDisabled,
 				},
-			)
+			)...)
 		}).ElseIf(p.Post.Nickname == "system", func() app.UI {
 			return app.Div()
 		}).Else(func() app.UI {
-			return app.Div().Body(
-				app.B().Title("reaction count").Text(p.Post.ReactionCount).Class("left-padding"),
-
-				&atoms.Button{
-					ID:                p.Post.ID,
-					Title:             "increase the reaction count",
-					Class:             "transparent circle",
-					Icon:              "bomb", // snowflake = ac_unit
-					OnClickActionName: p.OnClickStarActionName,
-					Disabled:          p.ButtonsDisabled,
-					Attr:              map[string]string{"touch-action": "none"},
-				},
-			)
+			return app.Div().Body(p.renderReactionButtons()...)
 		}),
 	)
 }
+
+// isEditable reports whether the post can be edited still: the text posts can be edited within the edit window after their creation.
+func (p *PostFooter) isEditable() bool {
+	return p.OnClickEditActionName != "" && p.Post.Type == "post" && time.Since(p.Post.Timestamp) < config.PostEditWindow
+}
+
+// renderBookmarkButton shows the button to toggle the logged user's bookmark of the post.
+func (p *PostFooter) renderBookmarkButton() app.UI {
+	class, title := "transparent circle", "bookmark this post"
+	if p.Bookmarked {
+		class, title = "primary circle", "remove the bookmark"
+	}
+
+	return &atoms.Button{
+		ID:                p.Post.ID,
+		Title:             title,
+		Class:             class,
+		Icon:              "bookmark",
+		OnClickActionName: p.OnClickBookmarkActionName,
+		Disabled:          p.ButtonsDisabled,
+	}
+}
+
+// renderReactionCounts shows the counts of the reactions given to the post.
+func (p *PostFooter) renderReactionCounts() []app.UI {
+	counts := p.Post.CountReactions()
+
+	var elems []app.UI
+
+	for _, kind := range models.ReactionKinds {
+		if counts[kind] == 0 {
+			continue
+		}
+
+		elems = append(elems, app.Span().Title(kind).Class("left-padding").Body(
+			app.I().Class("small").Text(reactionIcons[kind]),
+			app.B().Text(counts[kind]),
+		))
+	}
+
+	return elems
+}
+
+// renderReactionButtons shows the buttons to toggle the caller's reactions.
+func (p *PostFooter) renderReactionButtons() []app.UI {
+	counts := p.Post.CountReactions()
+
+	var elems []app.UI
+
+	for _, kind := range models.ReactionKinds {
+		class, title := "transparent circle", "react with "+kind
+		if p.Post.HasReacted(kind, p.LoggedUserNickname) {
+			class, title = "primary circle", "take the "+kind+" back"
+		}
+
+		var count string
+		if counts[kind] > 0 {
+			count = strconv.FormatInt(counts[kind], 10)
+		}
+
+		elems = append(elems, &atoms.Button{
+			ID:                p.Post.ID + ReactionSeparator + kind,
+			Title:             title,
+			Class:             class,
+			Icon:              reactionIcons[kind],
+			BadgeText:         count,
+			OnClickActionName: p.OnClickReactActionName,
+			Disabled:          p.ButtonsDisabled,
+			Attr:              map[string]string{"touch-action": "none"},
+		})
+	}
+
+	return elems
+}
@@ -0,0 +1,62 @@
+package organisms
+
+import (
+	"github.com/maxence-charriere/go-app/v10/pkg/app"
+
+	"go.vxn.dev/littr/pkg/frontend/atomic/atoms"
+)
+
+type ModalPostEdit struct {
+	app.Compo
+
+	EditPostContent *string
+
+	ModalButtonsDisabled *bool
+	ModalShow            bool
+
+	OnClickDismissActionName string
+	OnClickEditActionName    string
+}
+
+func (m *ModalPostEdit) Render() app.UI {
+	return app.Div().Body(
+		app.If(m.ModalShow, func() app.UI {
+			return app.Dialog().ID("edit-modal").Class("grey10 white-text center-align active thicc center").Style("max-width", "90%").Style("z-index", "75").Body(
+				app.Nav().Class("center-align").Body(
+					app.H5().Text("edit"),
+				),
+				app.Div().Class("space"),
+
+				&atoms.Textarea{
+					ID:             "edit-textarea",
+					Class:          "field label textarea border extra primary-text thicc",
+					Content:        *m.EditPostContent,
+					ContentPointer: m.EditPostContent,
+					Name:           "editPost",
+					LabelText:      "Edit the post",
+				},
+
+				// Edit buttons.
+				app.Div().Class("row").Body(
+					&atoms.Button{
+						Class:             "max bold black white-text thicc",
+						Icon:              "close",
+						Text:              "Cancel",
+						OnClickActionName: m.OnClickDismissActionName,
+						Disabled:          *m.ModalButtonsDisabled,
+					},
+
+					&atoms.Button{
+						ID:                "button-edit",
+						Class:             "max bold primary-container white-text thicc",
+						Icon:              "edit",
+						Text:              "Save",
+						OnClickActionName: m.OnClickEditActionName,
+						Disabled:          *m.ModalButtonsDisabled,
+					},
+				),
+				app.Div().Class("space"),
+			)
+		}),
+	)
+}
@@ -14,9 +14,10 @@ type ModalUserLogout struct {
 
 	ShowModal bool
 
-	OnClickDismissActionName string
-	OnClickLogoutActionName  string
-	OnClickFlowActionName    string
+	OnClickBookmarksActionName string
+	OnClickDismissActionName   string
+	OnClickLogoutActionName    string
+	OnClickFlowActionName      string
 }
 
 func (m *ModalUserLogout) Render() app.UI {
@@ -49,6 +50,18 @@ func (m *ModalUserLogout) Render() app.UI {
 				),
 				app.Div().Class("space"),
 
+				app.If(m.OnClickBookmarksActionName != "", func() app.UI {
+					return app.Div().Class("row").Body(
+						&atoms.Button{
+							Class:             "max bold primary-container white-text thicc",
+							Icon:              "bookmarks",
+							Text:              "Bookmarks",
+							OnClickActionName: m.OnClickBookmarksActionName,
+						},
+					)
+				}),
+				app.Div().Class("space"),
+
 				app.Div().Class("row").Body(
 					&atoms.Button{
 						Class:             "max bold black white-text thicc",
@@ -33,15 +33,18 @@ type PostFeed struct {
 	SinglePostID string
 	SingleUserID string
 
-	OnClickDeleteActionName  string
-	OnClickHistoryActionName string
-	OnClickImageActionName   string
-	OnClickLinkActionName    string
-	OnClickReplyActionName   string
-	OnClickStarActionName    string
-	OnClickUserActionName    string
-	OnMouseEnterActionName   string
-	OnMouseLeaveActionName   string
+	OnClickBookmarkActionName string
+	OnClickDeleteActionName   string
+	OnClickEditActionName     string
+	OnClickHistoryActionName  string
+	OnClickImageActionName    string
+	OnClickLinkActionName     string
+	OnClickReplyActionName    string
+	OnClickReactActionName    string
+	OnClickThreadActionName   string
+	OnClickUserActionName     string
+	OnMouseEnterActionName    string
+	OnMouseLeaveActionName    string
 
 	imageSource     string
 	postSummary     string
@@ -238,10 +241,14 @@ func (p *PostFeed) Render() app.UI {
 					PostTimestamp:      p.postTimestamp,
 					ButtonsDisabled:    p.ButtonsDisabled,
 					LoggedUserNickname: p.LoggedUser.Nickname,
+					Bookmarked:         p.LoggedUser.HasBookmarked(post.ID),
 					//
-					OnClickDeleteActionName: p.OnClickDeleteActionName,
-					OnClickStarActionName:   p.OnClickStarActionName,
-					OnClickReplyActionName:  p.OnClickReplyActionName,
+					OnClickBookmarkActionName: p.OnClickBookmarkActionName,
+					OnClickDeleteActionName:   p.OnClickDeleteActionName,
+					OnClickEditActionName:     p.OnClickEditActionName,
+					OnClickReactActionName:    p.OnClickReactActionName,
+					OnClickReplyActionName:    p.OnClickReplyActionName,
+					OnClickThreadActionName:   p.OnClickThreadActionName,
 				},
 			)
 		}),
@@ -0,0 +1,78 @@
+package bookmarks
+
+import (
+	"go.vxn.dev/littr/pkg/frontend/common"
+
+	"github.com/maxence-charriere/go-app/v10/pkg/app"
+)
+
+// handleRemove drops the post from the bookmarks, the post is hidden at once and shown again when the removal fails.
+func (c *Content) handleRemove(ctx app.Context, a app.Action) {
+	key, ok := a.Value.(string)
+	if !ok || key == "" {
+		return
+	}
+
+	// runs on the main UI goroutine via a component ActionHandler
+	original, found := c.posts[key]
+	if !found {
+		return
+	}
+
+	delete(c.posts, key)
+
+	toast := common.Toast{AppContext: &ctx}
+
+	ctx.Async(func() {
+		input := &common.CallInput{
+			Method:   "DELETE",
+			Url:      "/api/v1/posts/" + key + "/bookmark",
+			Data:     nil,
+			CallerID: c.user.Nickname,
+			PageNo:   0,
+		}
+
+		output := &common.Response{}
+
+		if ok := common.FetchData(input, output); !ok {
+			toast.Text(common.ERR_CANNOT_REACH_BE).Type(common.TTYPE_ERR).Dispatch()
+		}
+
+		if output.Code != 200 {
+			toast.Text(output.Message).Type(common.TTYPE_ERR).Dispatch()
+
+			// Revert the change shown.
+			ctx.Dispatch(func(ctx app.Context) {
+				c.posts[key] = original
+			})
+			return
+		}
+
+		ctx.Dispatch(func(ctx app.Context) {
+			c.user.RemoveBookmarks(key)
+		})
+	})
+}
+
+// handleMore loads the next page of the bookmarks.
+func (c *Content) handleMore(ctx app.Context, a app.Action) {
+	if c.loaderShow || !c.more {
+		return
+	}
+
+	c.fetch(ctx, c.user.Nickname, c.pageNo+1)
+}
+
+func (c *Content) handleLink(ctx app.Context, a app.Action) {
+	id, ok := a.Value.(string)
+	if !ok || id == "" {
+		return
+	}
+
+	switch a.Name {
+	case "flow-link":
+		ctx.Navigate("/flow/posts/" + id)
+	case "user-link":
+		ctx.Navigate("/flow/users/" + id)
+	}
+}
@@ -0,0 +1,136 @@
+// The bookmarks view and view-controllers logic package.
+package bookmarks
+
+import (
+	"go.vxn.dev/littr/pkg/frontend/common"
+	"go.vxn.dev/littr/pkg/models"
+
+	"github.com/maxence-charriere/go-app/v10/pkg/app"
+)
+
+// Content is the view of the posts bookmarked by the logged user, the newest post first.
+type Content struct {
+	app.Compo
+
+	loaderShow bool
+
+	// user is the logged user loaded from the LocalStorage.
+	user models.User
+
+	// more is set when the last page fetched was not empty, so there could be another one.
+	pageNo int
+	more   bool
+
+	posts map[string]models.Post
+	users map[string]models.User
+}
+
+func (c *Content) OnMount(ctx app.Context) {
+	if app.IsServer {
+		return
+	}
+
+	ctx.Handle("bookmark-remove", c.handleRemove)
+	ctx.Handle("bookmarks-more", c.handleMore)
+	ctx.Handle("flow-link", c.handleLink)
+	ctx.Handle("user-link", c.handleLink)
+}
+
+func (c *Content) OnNav(ctx app.Context) {
+	if app.IsServer {
+		return
+	}
+
+	var user models.User
+
+	if err := common.LoadUser(&user, &ctx); err != nil || user.Nickname == "" {
+		toast := common.Toast{AppContext: &ctx}
+		toast.Text(common.ERR_LOGIN_AGAIN).Type(common.TTYPE_INFO).Link("/logout").Dispatch()
+		return
+	}
+
+	ctx.Dispatch(func(ctx app.Context) {
+		c.user = user
+		c.pageNo = 0
+		c.more = false
+		c.posts = nil
+		c.users = nil
+	})
+
+	c.fetch(ctx, user.Nickname, 0)
+}
+
+// fetch loads such page of the bookmarks, the further pages are added to the posts shown.
+func (c *Content) fetch(ctx app.Context, nickname string, pageNo int) {
+	ctx.Dispatch(func(ctx app.Context) {
+		c.loaderShow = true
+	})
+
+	toast := common.Toast{AppContext: &ctx}
+
+	ctx.Async(func() {
+		defer ctx.Dispatch(func(ctx app.Context) {
+			c.loaderShow = false
+		})
+
+		input := &common.CallInput{
+			Method:   "GET",
+			Url:      "/api/v1/users/" + nickname + "/bookmarks",
+			Data:     nil,
+			CallerID: nickname,
+			PageNo:   pageNo,
+		}
+
+		type dataModel struct {
+			Posts map[string]models.Post `json:"posts"`
+			Users map[string]models.User `json:"users"`
+			Key   string                 `json:"key"`
+		}
+
+		output := &common.Response{Data: &dataModel{}}
+
+		if ok := common.FetchData(input, output); !ok {
+			toast.Text(common.ERR_CANNOT_REACH_BE).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		if output.Code == 401 {
+			toast.Text(common.ERR_LOGIN_AGAIN).Type(common.TTYPE_INFO).Link("/logout").Dispatch()
+			return
+		}
+
+		if output.Code != 200 {
+			toast.Text(output.Message).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		data, ok := output.Data.(*dataModel)
+		if !ok {
+			toast.Text(common.ERR_CANNOT_GET_DATA).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		ctx.Dispatch(func(ctx app.Context) {
+			c.pageNo = pageNo
+			c.more = len(data.Posts) > 0
+
+			if pageNo == 0 || c.posts == nil {
+				c.posts = data.Posts
+				c.users = data.Users
+			} else {
+				for key, post := range data.Posts {
+					c.posts[key] = post
+				}
+
+				for key, user := range data.Users {
+					c.users[key] = user
+				}
+			}
+
+			// Keep the bookmarks of the logged user up to date.
+			if user, found := c.users[data.Key]; found {
+				c.user.Bookmarks = user.Bookmarks
+			}
+		})
+	})
+}
@@ -0,0 +1,116 @@
+package bookmarks
+
+import (
+	"sort"
+
+	"go.vxn.dev/littr/pkg/frontend/atomic/atoms"
+	"go.vxn.dev/littr/pkg/frontend/common"
+	"go.vxn.dev/littr/pkg/models"
+
+	"github.com/maxence-charriere/go-app/v10/pkg/app"
+)
+
+// sortPosts orders the bookmarked posts by their timestamp DESC.
+func (c *Content) sortPosts() []models.Post {
+	sorted := make([]models.Post, 0, len(c.posts))
+
+	for _, post := range c.posts {
+		sorted = append(sorted, post)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.After(sorted[j].Timestamp)
+	})
+
+	return sorted
+}
+
+func (c *Content) Render() app.UI {
+	posts := c.sortPosts()
+
+	return app.Main().Class("responsive").Body(
+		&atoms.PageHeading{
+			Title: "bookmarks",
+		},
+		app.Div().Class("space"),
+
+		app.If(len(posts) == 0 && !c.loaderShow, func() app.UI {
+			return app.P().Class("bold").Text(common.MSG_NO_BOOKMARKS)
+		}),
+
+		app.If(len(posts) > 0, func() app.UI {
+			return app.Div().Class("post-feed").Body(
+				app.Range(posts).Slice(func(i int) app.UI {
+					return c.renderPost(posts[i])
+				}),
+			)
+		}),
+
+		&atoms.Loader{
+			ID:         "bookmarks-loader",
+			ShowLoader: c.loaderShow,
+		},
+
+		app.If(c.more && !c.loaderShow, func() app.UI {
+			return app.Div().Class("row center-align").Body(
+				&atoms.Button{
+					ID:                "bookmarks-more",
+					Title:             "show more bookmarks",
+					Class:             "transparent bold primary-text",
+					Icon:              "expand_more",
+					Text:              "more bookmarks",
+					OnClickActionName: "bookmarks-more",
+				},
+			)
+		}),
+	)
+}
+
+// renderPost renders the post's author and content linking to the post, with the button to remove the bookmark.
+func (c *Content) renderPost(post models.Post) app.UI {
+	return app.Div().Class("post").Body(
+		app.Div().Class("row top-padding").Body(
+			app.Img().Class("responsive left").Src(c.users[post.Nickname].AvatarURL).Style("max-width", "40px").Style("border-radius", "50%"),
+
+			app.Div().Class("max").Body(
+				&atoms.Button{
+					ID:                post.Nickname,
+					Title:             "show the user's flow",
+					Class:             "transparent bold primary-text",
+					Text:              post.Nickname,
+					OnClickActionName: "user-link",
+				},
+			),
+
+			&atoms.Button{
+				ID:                post.ID,
+				Title:             "link to this post",
+				Class:             "transparent circle",
+				Icon:              "link",
+				OnClickActionName: "flow-link",
+			},
+		),
+
+		app.Article().Class("border thicc").Style("max-width", "100%").Body(
+			app.Span().Text(post.Content).Style("word-break", "break-word").Style("hyphens", "auto"),
+		),
+
+		app.Div().Class("row").Body(
+			app.Div().Class("max").Body(
+				app.Text(post.Timestamp.Format("Jan 02, 2006 / 15:04:05")),
+
+				app.If(post.Edited, func() app.UI {
+					return app.Span().Class("left-padding italic").Text("(edited)")
+				}),
+			),
+
+			&atoms.Button{
+				ID:                post.ID,
+				Title:             "remove the bookmark",
+				Class:             "primary circle",
+				Icon:              "bookmark",
+				OnClickActionName: "bookmark-remove",
+			},
+		),
+	)
+}
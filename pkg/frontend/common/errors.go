@@ -14,21 +14,30 @@ const (
 	MSG_SERVER_RESTART = "The server is restarting now..."
 	MSG_NEW_POLL       = "New poll has been just added"
 	MSG_NEW_POST       = "New post added by %s"
+	MSG_POST_EDITED    = "A post by %s has been edited"
 	MSG_STATE_OFFLINE  = "You have gone offline. Check your Internet connection"
 	MSG_STATE_ONLINE   = "You are back online"
 
 	// Flow/Posts-related error messages.
 	MSG_DELETE_SUCCESS      = "Post deleted"
+	MSG_EDIT_SUCCESS        = "Post edited"
 	MSG_REPLY_ADDED         = "Reply added"
 	MSG_EMPTY_FLOW          = "This flow is very empty, you can try expanding it"
 	MSG_USER_HAS_NOT_POSTED = "This user has apparently not published any post yet"
 	ERR_INVALID_REPLY       = "No valid content was entered"
 	ERR_POST_UNAUTH_DELETE  = "You can only delete your own posts"
+	ERR_POST_UNAUTH_EDIT    = "You can only edit your own posts"
 	ERR_POST_NOT_FOUND      = "Post not found (may be deleted)"
 	ERR_USER_NOT_FOUND      = "User not found"
 	ERR_PRIVATE_ACC         = "This account is private"
 	ERR_INVALID_REQ_PARAMS  = "Invalid page request params"
 
+	// Search-related (non-)error messages.
+	MSG_SEARCH_NO_RESULTS = "Nothing found, try other words"
+
+	// Bookmark-related (non-)error messages.
+	MSG_NO_BOOKMARKS = "No bookmarks yet, use the bookmark button under any post to save it here"
+
 	// Generic error messages on the FE.
 	ERR_CANNOT_REACH_BE = "littr can't connect to the server"
 	ERR_CANNOT_GET_DATA = "littr can't read the data"
@@ -87,6 +96,13 @@ const (
 	MSG_LOCAL_TIME_TOGGLE        = "Local time mode changed"
 	MSG_PRIVATE_MODE_TOGGLE      = "Private mode changed"
 	MSG_AVATAR_CHANGE_SUCCESS    = "Avatar has been successfully updated"
+	MSG_TOTP_SETUP_STARTED       = "Import the secret into your authenticator app, then enter the code to verify it"
+	MSG_TOTP_ENABLED             = "Two-factor authentication enabled, store the recovery codes safely"
+	MSG_TOTP_DISABLED            = "Two-factor authentication disabled"
+	MSG_TOTP_REQUIRED            = "Enter the code from your authenticator app (or a recovery code)"
+	MSG_SESSION_REVOKED          = "The session has been revoked"
+	MSG_API_TOKEN_CREATED        = "API token created, copy it now, it will not be shown again"
+	MSG_API_TOKEN_REVOKED        = "The API token has been revoked"
 	ERR_PASSPHRASE_MISMATCH      = "Passphrases do not match"
 	ERR_PASSPHRASE_MISSING       = "The passphrase field must be filled in"
 	ERR_ABOUT_TEXT_UNCHANGED     = "The About text area is empty or the text has not changed"
@@ -96,6 +112,10 @@ const (
 	ERR_WEBSITE_INVALID          = "The website is probably not a valid URL"
 	ERR_SUBSCRIPTION_BLANK_UUID  = "Blank UUID string"
 	ERR_SUBSCRIPTION_REQ_FAIL    = "Failed to subscribe to notifications: "
+	ERR_TOTP_CODE_MISSING        = "The two-factor authentication code must be filled in"
+	ERR_API_TOKEN_NAME_MISSING   = "The API token name must be filled in"
+	ERR_API_TOKEN_SCOPES_MISSING = "Choose one scope at least for the API token"
+	ERR_API_TOKEN_DAYS_INVALID   = "The API token has to expire in 1 to 365 days"
 
 	// Users-related (non-)error messages.
 	MSG_USER_UPDATED_SUCCESS   = "User updated, request deleted"
@@ -98,6 +98,20 @@ func (e *Event) ParseEventData(user *models.User) (text, link string, keep bool)
 		// Notify the user via toast.
 		text = fmt.Sprintf(MSG_NEW_POST, author)
 
+	// A post edited, the data are the post's ID and its author.
+	case "post-edit":
+		if len(slice) < 3 || slice[2] == user.Nickname {
+			return
+		}
+
+		// Exit when the author is not followed.
+		if flowed, found := user.FlowList[slice[2]]; !flowed || !found {
+			return
+		}
+
+		link = "/flow/posts/" + slice[1]
+		text = fmt.Sprintf(MSG_POST_EDITED, slice[2])
+
 	// New poll added.
 	case "poll":
 		pollID := slice[1]
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"go.vxn.dev/littr/pkg/frontend/atomic/molecules"
 	"go.vxn.dev/littr/pkg/frontend/common"
 	"go.vxn.dev/littr/pkg/models"
 
@@ -75,6 +76,93 @@ func (c *Content) handleDelete(ctx app.Context, a app.Action) {
 	})
 }
 
+func (c *Content) handleEdit(ctx app.Context, a app.Action) {
+	// Prevent double-submitting.
+	if c.postButtonsDisabled {
+		return
+	}
+
+	key := c.interactedPostKey
+
+	toast := common.Toast{AppContext: &ctx}
+
+	if c.posts[key].Nickname != c.user.Nickname {
+		toast.Text(common.ERR_POST_UNAUTH_EDIT).Type(common.TTYPE_ERR).Dispatch()
+		return
+	}
+
+	ctx.Dispatch(func(ctx app.Context) {
+		c.postButtonsDisabled = true
+	})
+
+	ctx.Async(func() {
+		defer ctx.Dispatch(func(ctx app.Context) {
+			c.postButtonsDisabled = false
+		})
+
+		// trim the spaces on the extremites
+		content := strings.TrimSpace(c.editPostContent)
+
+		if elem := app.Window().GetElementByID("edit-textarea"); !elem.IsNull() {
+			content = strings.TrimSpace(elem.Get("value").String())
+		}
+
+		if content == "" && c.posts[key].Figure == "" {
+			toast.Text(common.ERR_INVALID_REPLY).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		input := &common.CallInput{
+			Method:      "PATCH",
+			Url:         "/api/v1/posts/" + key,
+			Data:        map[string]string{"content": content},
+			CallerID:    c.user.Nickname,
+			PageNo:      c.pageNo,
+			HideReplies: c.hideReplies,
+		}
+
+		type dataModel struct {
+			Posts map[string]models.Post `json:"posts"`
+		}
+
+		output := &common.Response{Data: &dataModel{}}
+
+		if ok := common.FetchData(input, output); !ok {
+			toast.Text(common.ERR_CANNOT_REACH_BE).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		if output.Code != 200 {
+			toast.Text(output.Message).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		data, ok := output.Data.(*dataModel)
+		if !ok {
+			toast.Text(common.ERR_CANNOT_GET_DATA).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		ctx.Dispatch(func(ctx app.Context) {
+			// keep the reply count!
+			newPost := data.Posts[key]
+			newPost.ReplyCount = c.posts[key].ReplyCount
+
+			c.posts[key] = newPost
+
+			c.modalEditActive = false
+			c.buttonDisabled = false
+
+			c.interactedPostKey = ""
+			c.editPostContent = ""
+		})
+
+		ctx.Defer(func(ctx app.Context) {
+			toast.Text(common.MSG_EDIT_SUCCESS).Type(common.TTYPE_SUCCESS).Dispatch()
+		})
+	})
+}
+
 func (c *Content) handleDismiss(ctx app.Context, a app.Action) {
 	// change title back to the clean one
 	/*title := app.Window().Get("document")
@@ -90,6 +178,10 @@ func (c *Content) handleDismiss(ctx app.Context, a app.Action) {
 			c.modalReplyActive = false
 		}
 
+		if c.toast.TText == "" && c.modalEditActive {
+			c.modalEditActive = false
+		}
+
 		c.toast.TText = ""
 		c.toast.TType = ""
 
@@ -170,6 +262,25 @@ func (c *Content) handleModalPostReplyShow(ctx app.Context, a app.Action) {
 	})
 }
 
+func (c *Content) handleModalPostEditShow(ctx app.Context, a app.Action) {
+	id, ok := a.Value.(string)
+	if !ok {
+		return
+	}
+
+	ctx.Dispatch(func(ctx app.Context) {
+		c.interactedPostKey = id
+		c.editPostContent = c.posts[id].Content
+		c.modalEditActive = true
+		c.postButtonsDisabled = false
+		c.buttonDisabled = true
+	})
+
+	ctx.Defer(func(app.Context) {
+		app.Window().Get("document").Call("getElementById", "edit-textarea").Call("focus")
+	})
+}
+
 func (c *Content) handleMouseEnter(ctx app.Context, a app.Action) {
 	common.HandleMouseEnter(ctx, a)
 }
@@ -178,6 +289,60 @@ func (c *Content) handleMouseLeave(ctx app.Context, a app.Action) {
 	common.HandleMouseLeave(ctx, a)
 }
 
+// handlePostEdited refetches the edited post if shown (see the post-edit live event).
+func (c *Content) handlePostEdited(ctx app.Context, a app.Action) {
+	key, ok := a.Value.(string)
+	if !ok {
+		return
+	}
+
+	if _, found := c.posts[key]; !found {
+		return
+	}
+
+	ctx.Async(func() {
+		input := &common.CallInput{
+			Method:      "GET",
+			Url:         "/api/v1/posts/" + key,
+			Data:        nil,
+			CallerID:    c.user.Nickname,
+			PageNo:      0,
+			HideReplies: true,
+		}
+
+		type dataModel struct {
+			Posts map[string]models.Post `json:"posts"`
+		}
+
+		output := &common.Response{Data: &dataModel{}}
+
+		if ok := common.FetchData(input, output); !ok || output.Code != 200 {
+			return
+		}
+
+		data, ok := output.Data.(*dataModel)
+		if !ok {
+			return
+		}
+
+		newPost, found := data.Posts[key]
+		if !found {
+			return
+		}
+
+		ctx.Dispatch(func(ctx app.Context) {
+			if _, found := c.posts[key]; !found {
+				return
+			}
+
+			// keep the reply count!
+			newPost.ReplyCount = c.posts[key].ReplyCount
+
+			c.posts[key] = newPost
+		})
+	})
+}
+
 func (c *Content) handlePrivateMode(ctx app.Context, a app.Action) {
 	key, ok := a.Value.(string)
 	if !ok {
@@ -484,6 +649,15 @@ func (c *Content) handleScroll(ctx app.Context, a app.Action) {
 	})
 }
 
+func (c *Content) handleThread(ctx app.Context, a app.Action) {
+	id, ok := a.Value.(string)
+	if !ok {
+		return
+	}
+
+	ctx.Navigate("/post/" + id + "/thread")
+}
+
 // handleToggle is an action handler that takes care of user follow toggling.
 func (c *Content) handleToggle(ctx app.Context, a app.Action) {
 	ctx.Dispatch(func(ctx app.Context) {
@@ -533,32 +707,42 @@ func (c *Content) handleUserShade(ctx app.Context, a app.Action) {
 	common.HandleUserShade(ctx, a, c.users[key], callback)
 }
 
-func (c *Content) handleStar(ctx app.Context, a app.Action) {
-	key, ok := a.Value.(string)
+func (c *Content) handleReact(ctx app.Context, a app.Action) {
+	value, ok := a.Value.(string)
 	if !ok {
 		return
 	}
 
+	// The value is composed of the post's key, and the reaction kind.
+	sep := strings.LastIndex(value, molecules.ReactionSeparator)
+	if sep < 0 {
+		return
+	}
+
+	key, kind := value[:sep], value[sep+1:]
+
+	// The reaction is toggled: added, or taken back when given already.
+	method := "PUT"
+
 	// runs on the main UI goroutine via a component ActionHandler
+	original := c.posts[key]
+
 	post := c.posts[key]
-	post.ReactionCount++
+	if post.HasReacted(kind, c.user.Nickname) {
+		method = "DELETE"
+		post.RemoveReaction(kind, c.user.Nickname)
+	} else {
+		post.AddReaction(kind, c.user.Nickname)
+	}
 	c.posts[key] = post
-	c.postKey = key
 
 	toast := common.Toast{AppContext: &ctx}
 
 	ctx.Async(func() {
-		//key := ctx.JSSrc().Get("id").String()
-		key := c.postKey
-		//author = c.user.Nickname
-
-		interactedPost := c.posts[key]
-		//interactedPost.ReactionCount++
-
 		input := &common.CallInput{
-			Method:      "PATCH",
-			Url:         "/api/v1/posts/" + interactedPost.ID + "/star",
-			Data:        interactedPost,
+			Method:      method,
+			Url:         "/api/v1/posts/" + key + "/reactions/" + kind,
+			Data:        nil,
 			CallerID:    c.user.Nickname,
 			PageNo:      c.pageNo,
 			HideReplies: c.hideReplies,
@@ -570,13 +754,17 @@ func (c *Content) handleStar(ctx app.Context, a app.Action) {
 
 		output := &common.Response{Data: &dataModel{}}
 
-		// add new post to backend struct
 		if ok := common.FetchData(input, output); !ok {
 			toast.Text(common.ERR_CANNOT_REACH_BE).Type(common.TTYPE_ERR).Dispatch()
 		}
 
 		if output.Code != 200 {
 			toast.Text(output.Message).Type(common.TTYPE_ERR).Dispatch()
+
+			// Revert the change shown.
+			ctx.Dispatch(func(ctx app.Context) {
+				c.posts[key] = original
+			})
 			return
 		}
 
@@ -586,17 +774,64 @@ func (c *Content) handleStar(ctx app.Context, a app.Action) {
 			return
 		}
 
-		// keep the reply count!
-		oldPost := c.posts[key]
-		newPost := data.Posts[key]
-		newPost.ReplyCount = oldPost.ReplyCount
-
 		ctx.Dispatch(func(ctx app.Context) {
+			// keep the reply count!
+			newPost := data.Posts[key]
+			newPost.ReplyCount = c.posts[key].ReplyCount
+
 			c.posts[key] = newPost
 		})
 	})
 }
 
+// handleBookmark toggles the logged user's bookmark of the post: the post is bookmarked, or the bookmark is removed when saved already.
+func (c *Content) handleBookmark(ctx app.Context, a app.Action) {
+	key, ok := a.Value.(string)
+	if !ok || key == "" {
+		return
+	}
+
+	method := "POST"
+
+	// runs on the main UI goroutine via a component ActionHandler
+	original := c.user.Bookmarks
+
+	if c.user.HasBookmarked(key) {
+		method = "DELETE"
+		c.user.RemoveBookmarks(key)
+	} else {
+		c.user.AddBookmark(key, time.Now())
+	}
+
+	toast := common.Toast{AppContext: &ctx}
+
+	ctx.Async(func() {
+		input := &common.CallInput{
+			Method:   method,
+			Url:      "/api/v1/posts/" + key + "/bookmark",
+			Data:     nil,
+			CallerID: c.user.Nickname,
+			PageNo:   0,
+		}
+
+		output := &common.Response{}
+
+		if ok := common.FetchData(input, output); !ok {
+			toast.Text(common.ERR_CANNOT_REACH_BE).Type(common.TTYPE_ERR).Dispatch()
+		}
+
+		if output.Code != 200 {
+			toast.Text(output.Message).Type(common.TTYPE_ERR).Dispatch()
+
+			// Revert the change shown.
+			ctx.Dispatch(func(ctx app.Context) {
+				c.user.Bookmarks = original
+			})
+			return
+		}
+	})
+}
+
 func (c *Content) handleTextareaBlur(ctx app.Context, _ app.Action) {
 	// Save a new post draft, if the focus on textarea is lost.
 	if err := ctx.LocalStorage().Set("newReplyDraft", ctx.JSSrc().Get("value").String()); err != nil {
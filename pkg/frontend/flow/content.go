@@ -3,6 +3,7 @@ package flow
 
 import (
 	"encoding/base64"
+	"strings"
 
 	"go.vxn.dev/littr/pkg/frontend/common"
 	"go.vxn.dev/littr/pkg/models"
@@ -29,6 +30,8 @@ type Content struct {
 	postButtonsDisabled bool
 	modalReplyActive    bool
 	replyPostContent    string
+	modalEditActive     bool
+	editPostContent     string
 	newFigLink          string
 	newFigFile          string
 	newFigData          []byte
@@ -52,13 +55,15 @@ type Content struct {
 
 	lastPageFetched bool
 
-	postKey string
-	posts   map[string]models.Post
-	users   map[string]models.User
+	posts map[string]models.Post
+	users map[string]models.User
 
 	refreshClicked bool
 
 	hashtag string
+
+	// eventListener passes the live events (see common.NewSSEEvent) to the post-edited action handler.
+	eventListener app.Func
 }
 
 func (c *Content) OnMount(ctx app.Context) {
@@ -68,23 +73,28 @@ func (c *Content) OnMount(ctx app.Context) {
 
 	ctx.Handle("ask", c.handlePrivateMode)
 	ctx.Handle("blur-post", c.handleTextareaBlur)
+	ctx.Handle("bookmark", c.handleBookmark)
 	ctx.Handle("cancel", c.handlePrivateMode)
 	ctx.Handle("clear", c.handleClear)
 	ctx.Handle("delete", c.handleDelete)
 	ctx.Handle("dismiss", c.handleDismiss)
+	ctx.Handle("edit", c.handleEdit)
 	ctx.Handle("follow", c.handleToggle)
 	ctx.Handle("history", c.handleLink)
 	ctx.Handle("image-click", c.handleImage)
 	ctx.Handle("link", c.handleLink)
 	ctx.Handle("modal-post-delete", c.handleModalPostDeleteShow)
+	ctx.Handle("modal-post-edit", c.handleModalPostEditShow)
 	ctx.Handle("modal-post-reply", c.handleModalPostReplyShow)
 	ctx.Handle("mouse-enter", c.handleMouseEnter)
 	ctx.Handle("mouse-leave", c.handleMouseLeave)
+	ctx.Handle("post-edited", c.handlePostEdited)
 	ctx.Handle("refresh", c.handleRefresh)
 	ctx.Handle("reply", c.handleReply)
 	ctx.Handle("scroll", c.handleScroll)
 	ctx.Handle("shade", c.handleUserShade)
-	ctx.Handle("star", c.handleStar)
+	ctx.Handle("react", c.handleReact)
+	ctx.Handle("thread", c.handleThread)
 	ctx.Handle("unfollow", c.handleToggle)
 	ctx.Handle("user", c.handleUser)
 
@@ -99,6 +109,22 @@ func (c *Content) OnMount(ctx app.Context) {
 
 	ctx.GetState(common.StateNameUser, &c.user)
 
+	// Refresh the posts shown when edited, the live events are dispatched as the DOM message events.
+	c.eventListener = app.FuncOf(func(this app.Value, args []app.Value) interface{} {
+		data := args[0].Get("data")
+		if data.Type() != app.TypeString {
+			return nil
+		}
+
+		if parts := strings.Split(data.String(), ","); len(parts) >= 2 && parts[0] == "post-edit" {
+			ctx.NewActionWithValue("post-edited", parts[1])
+		}
+
+		return nil
+	})
+
+	app.Window().Call("addEventListener", "message", c.eventListener)
+
 	// Load the saved draft from localStorage.
 	_ = ctx.LocalStorage().Get("newReplyDraft", &c.replyPostContent)
 	_ = ctx.LocalStorage().Get("newReplyFigFile", &c.newFigFile)
@@ -109,6 +135,16 @@ func (c *Content) OnMount(ctx app.Context) {
 	c.newFigData, _ = base64.StdEncoding.DecodeString(data)
 }
 
+func (c *Content) OnDismount() {
+	if c.eventListener == nil {
+		return
+	}
+
+	app.Window().Call("removeEventListener", "message", c.eventListener)
+	c.eventListener.Release()
+	c.eventListener = nil
+}
+
 func (c *Content) OnNav(ctx app.Context) {
 	if app.IsServer {
 		return
@@ -95,6 +95,15 @@ func (c *Content) Render() app.UI {
 			OnBlurActionName:         "blur-post",
 		},
 
+		// Post edit modal.
+		&organisms.ModalPostEdit{
+			EditPostContent:          &c.editPostContent,
+			ModalShow:                c.modalEditActive,
+			ModalButtonsDisabled:     &c.postButtonsDisabled,
+			OnClickDismissActionName: "dismiss",
+			OnClickEditActionName:    "edit",
+		},
+
 		// The very post feed.
 		&organisms.PostFeed{
 			Pagination:      c.pagination,
@@ -109,15 +118,18 @@ func (c *Content) Render() app.UI {
 			LoggedUser:      c.user,
 			SortedPosts:     c.sortPosts(),
 			//
-			OnClickImageActionName:   "image-click",
-			OnClickStarActionName:    "star",
-			OnClickReplyActionName:   "modal-post-reply",
-			OnClickLinkActionName:    "link",
-			OnClickHistoryActionName: "history",
-			OnClickDeleteActionName:  "modal-post-delete",
-			OnClickUserActionName:    "user",
-			OnMouseEnterActionName:   "mouse-enter",
-			OnMouseLeaveActionName:   "mouse-leave",
+			OnClickBookmarkActionName: "bookmark",
+			OnClickImageActionName:    "image-click",
+			OnClickReactActionName:    "react",
+			OnClickReplyActionName:    "modal-post-reply",
+			OnClickThreadActionName:   "thread",
+			OnClickLinkActionName:     "link",
+			OnClickHistoryActionName:  "history",
+			OnClickDeleteActionName:   "modal-post-delete",
+			OnClickEditActionName:     "modal-post-edit",
+			OnClickUserActionName:     "user",
+			OnMouseEnterActionName:    "mouse-enter",
+			OnMouseLeaveActionName:    "mouse-leave",
 		},
 
 		&atoms.Loader{
@@ -15,6 +15,10 @@ type Content struct {
 
 	nickname   string
 	passphrase string
+	totpCode   string
+
+	// totpRequired shows the TOTP code field, once the passphrase is verified.
+	totpRequired bool
 
 	toast common.Toast
 
@@ -46,12 +46,25 @@ func (c *Content) onClick(ctx app.Context, e app.Event) {
 			return
 		}
 
+		totpCode := strings.TrimSpace(c.totpCode)
+
+		if c.totpRequired && totpCode == "" && !app.Window().GetElementByID("totp-input").IsNull() {
+			totpCode = strings.TrimSpace(app.Window().GetElementByID("totp-input").Get("value").String())
+		}
+
+		if c.totpRequired && totpCode == "" {
+			toast.Text(common.ERR_TOTP_CODE_MISSING).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
 		payload := &struct {
 			Nickname        string `json:"nickname"`
 			PassphrasePlain string `json:"passphrase_plain"`
+			TOTPCode        string `json:"totp_code,omitempty"`
 		}{
 			Nickname:        nickname,
 			PassphrasePlain: passphrase,
+			TOTPCode:        totpCode,
 		}
 
 		input := &common.CallInput{
@@ -61,8 +74,9 @@ func (c *Content) onClick(ctx app.Context, e app.Event) {
 		}
 
 		type dataModel struct {
-			AuthGranted bool         `json:"auth_granted"`
-			User        *models.User `json:"user"`
+			AuthGranted  bool         `json:"auth_granted"`
+			TOTPRequired bool         `json:"totp_required"`
+			User         *models.User `json:"user"`
 		}
 
 		output := &common.Response{Data: &dataModel{}}
@@ -77,17 +91,27 @@ func (c *Content) onClick(ctx app.Context, e app.Event) {
 			return
 		}
 
-		if output.Code != 200 {
-			toast.Text(output.Message).Type(common.TTYPE_ERR).Dispatch()
-			return
-		}
-
 		data, ok := output.Data.(*dataModel)
 		if !ok {
 			toast.Text(common.ERR_CANNOT_GET_DATA).Type(common.TTYPE_ERR).Dispatch()
 			return
 		}
 
+		// The passphrase is valid, ask for the second factor.
+		if data.TOTPRequired {
+			ctx.Dispatch(func(ctx app.Context) {
+				c.totpRequired = true
+			})
+
+			toast.Text(common.MSG_TOTP_REQUIRED).Type(common.TTYPE_INFO).Dispatch()
+			return
+		}
+
+		if output.Code != 200 {
+			toast.Text(output.Message).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
 		if !data.AuthGranted {
 			toast.Text(common.ERR_ACCESS_DENIED).Type(common.TTYPE_ERR).Dispatch()
 			return
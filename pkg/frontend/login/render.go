@@ -26,6 +26,14 @@ func (c *Content) Render() app.UI {
 			app.Label().Text("Passphrase").Class("active primary-text"),
 		),
 
+		// The second factor field, shown for the accounts with the two-factor authentication enabled.
+		app.If(c.totpRequired, func() app.UI {
+			return app.Div().Class("field border label primary-text thicc center-align").Body(
+				app.Input().ID("totp-input").Type("text").Required(true).TabIndex(2).OnChange(c.ValueTo(&c.totpCode)).MaxLength(16).Class("active").Attr("autocomplete", "one-time-code").Attr("inputmode", "numeric"),
+				app.Label().Text("Code").Class("active primary-text"),
+			)
+		}),
+
 		// Session duration infobox.
 		app.Article().Class("row border blue-border info thicc").Body(
 			app.I().Text("info").Class("blue-text"),
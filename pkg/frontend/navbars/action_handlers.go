@@ -1,6 +1,7 @@
 package navbars
 
 import (
+	"net/url"
 	"slices"
 	"strings"
 
@@ -34,6 +35,7 @@ func (h *Header) handleDismiss(ctx app.Context, a app.Action) {
 	ctx.Dispatch(func(ctx app.Context) {
 		h.modalInfoShow = false
 		h.modalLogoutShow = false
+		h.searchShow = false
 
 		h.toastShow = false
 		h.toastText = ""
@@ -90,7 +92,8 @@ func (h *Header) handleKeydown(ctx app.Context, a app.Action) {
 		event.Get("key").String() == "X" ||
 		event.Get("key").String() == "r" ||
 		event.Get("key").String() == "R") &&
-		textareaReply.IsNull() {
+		textareaReply.IsNull() &&
+		app.Window().Get("document").Get("activeElement").Get("id").String() != "nav-search" {
 
 		ctx.NewAction("dismiss")
 		ctx.NewAction("clear")
@@ -140,6 +143,7 @@ func (h *Header) handleKeydown(ctx app.Context, a app.Action) {
 		"reply-textarea",
 		"fig-upload",
 		"search",
+		"nav-search",
 		"passphrase-current",
 		"passphrase-new",
 		"passphrase-new-again",
@@ -152,7 +156,7 @@ func (h *Header) handleKeydown(ctx app.Context, a app.Action) {
 		return
 	}
 
-	// Use keys 1-6 to navigate through the UI.
+	// Use keys 1-7 to navigate through the UI.
 	switch event.Get("key").String() {
 	case "1":
 		ctx.Navigate("/stats")
@@ -166,6 +170,8 @@ func (h *Header) handleKeydown(ctx app.Context, a app.Action) {
 		ctx.Navigate("/flow")
 	case "6":
 		ctx.Navigate("/settings")
+	case "7":
+		ctx.NewAction("search-click")
 	}
 }
 
@@ -199,6 +205,12 @@ func (h *Header) handleLinkClick(ctx app.Context, a app.Action) {
 		if path != "/settings" {
 			ctx.Navigate("/settings")
 		}
+	case "bookmarks-click":
+		ctx.NewAction("dismiss-general")
+
+		if path != "/bookmarks" {
+			ctx.Navigate("/bookmarks")
+		}
 	case "user-flow-click":
 		id, ok := a.Value.(string)
 		if !ok {
@@ -263,6 +275,43 @@ func (h *Header) handleReload(ctx app.Context, a app.Action) {
 	ctx.Reload()
 }
 
+// handleSearchClick toggles the search box, and focuses its input when shown.
+func (h *Header) handleSearchClick(ctx app.Context, a app.Action) {
+	ctx.Dispatch(func(ctx app.Context) {
+		h.searchShow = !h.searchShow
+	})
+
+	ctx.Defer(func(ctx app.Context) {
+		if input := app.Window().GetElementByID("nav-search"); !input.IsNull() {
+			input.Call("focus")
+		}
+	})
+}
+
+// handleSearch navigates to the results of the query typed in the search box.
+func (h *Header) handleSearch(ctx app.Context, a app.Action) {
+	id, ok := a.Value.(string)
+	if !ok {
+		return
+	}
+
+	input := app.Window().GetElementByID(id)
+	if input.IsNull() {
+		return
+	}
+
+	query := strings.TrimSpace(input.Get("value").String())
+	if query == "" {
+		return
+	}
+
+	ctx.Dispatch(func(ctx app.Context) {
+		h.searchShow = false
+	})
+
+	ctx.Navigate("/search?q=" + url.QueryEscape(query))
+}
+
 func (h *Header) handleUserModalShow(ctx app.Context, a app.Action) {
 	ctx.Dispatch(func(ctx app.Context) {
 		h.modalLogoutShow = true
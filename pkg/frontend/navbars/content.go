@@ -31,6 +31,9 @@ type Header struct {
 	modalInfoShow   bool
 	modalLogoutShow bool
 
+	// searchShow replaces the header with the search box.
+	searchShow bool
+
 	// Experimental function.
 	onlineState bool
 
@@ -225,6 +228,8 @@ func (h *Header) OnMount(ctx app.Context) {
 	ctx.Handle("user-modal-show", h.handleUserModalShow)
 	ctx.Handle("install-click", h.handleInstallClick)
 	ctx.Handle("logout", h.handleLogout)
+	ctx.Handle("search-click", h.handleSearchClick)
+	ctx.Handle("nav-search", h.handleSearch)
 
 	ctx.Handle("login-click", h.handleLinkClick)
 	ctx.Handle("stats-click", h.handleLinkClick)
@@ -233,6 +238,7 @@ func (h *Header) OnMount(ctx app.Context) {
 	ctx.Handle("polls-click", h.handleLinkClick)
 	ctx.Handle("flow-click", h.handleLinkClick)
 	ctx.Handle("settings-click", h.handleLinkClick)
+	ctx.Handle("bookmarks-click", h.handleLinkClick)
 	ctx.Handle("user-flow-click", h.handleLinkClick)
 
 	ctx.Dispatch(func(ctx app.Context) {
@@ -83,11 +83,12 @@ func (h *Header) Render() app.UI {
 				}),
 
 				&organisms.ModalUserLogout{
-					User:                     h.user,
-					ShowModal:                h.modalLogoutShow,
-					OnClickDismissActionName: "dismiss-general",
-					OnClickLogoutActionName:  "logout",
-					OnClickFlowActionName:    "user-flow-click",
+					User:                       h.user,
+					ShowModal:                  h.modalLogoutShow,
+					OnClickBookmarksActionName: "bookmarks-click",
+					OnClickDismissActionName:   "dismiss-general",
+					OnClickLogoutActionName:    "logout",
+					OnClickFlowActionName:      "user-flow-click",
 				},
 
 				// littr header
@@ -100,10 +101,17 @@ func (h *Header) Render() app.UI {
 						Text:     toastTopText,
 					},
 
-					&molecules.LittrHeader{
-						HeaderString:              headerString,
-						OnClickHeadlineActionName: "littr-header-click",
-					},
+					app.If(h.searchShow, func() app.UI {
+						return &atoms.SearchBar{
+							ID:                 "nav-search",
+							OnSearchActionName: "nav-search",
+						}
+					}).Else(func() app.UI {
+						return &molecules.LittrHeader{
+							HeaderString:              headerString,
+							OnClickHeadlineActionName: "littr-header-click",
+						}
+					}),
 
 					&atoms.Snackbar{
 						Class:    "snackbar white-text thicc shrink-30 center",
@@ -121,6 +129,21 @@ func (h *Header) Render() app.UI {
 					OnClickReloadActionName:  "reload",
 				},
 
+				// Search button
+				app.If(h.authGranted, func() app.UI {
+					return &atoms.Button{
+						ID:                "button-search",
+						Class:             "circle transparent primary-text",
+						Title:             "search [7]",
+						Aria:              map[string]string{"label": "search"},
+						Icon:              "search",
+						OnClickActionName: "search-click",
+					}
+				}).Else(func() app.UI {
+					// hotfix to keep the nav items' distances
+					return app.A().Class("").OnClick(nil).Body()
+				}),
+
 				// Update button
 				app.If(h.updateAvailable, func() app.UI {
 					return &atoms.Button{
@@ -1,4 +1,4 @@
-// The post (new flow post, or new poll) view and view-controllers logic package.
+// The post (new flow post, or new poll) and the post thread views and view-controllers logic package.
 package post
 
 import (
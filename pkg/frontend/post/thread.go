@@ -0,0 +1,145 @@
+package post
+
+import (
+	"strings"
+
+	"go.vxn.dev/littr/pkg/frontend/atomic/atoms"
+	"go.vxn.dev/littr/pkg/frontend/common"
+	"go.vxn.dev/littr/pkg/models"
+
+	"github.com/maxence-charriere/go-app/v10/pkg/app"
+)
+
+// Thread is the view of the conversation around a post: the posts replied to, and the nested replies.
+type Thread struct {
+	app.Compo
+
+	loaderShow bool
+
+	postID string
+
+	thread models.PostThread
+	users  map[string]models.User
+}
+
+func (c *Thread) OnMount(ctx app.Context) {
+	if app.IsServer {
+		return
+	}
+
+	ctx.Handle("flow-link", c.handleFlowLink)
+	ctx.Handle("thread-link", c.handleThreadLink)
+}
+
+func (c *Thread) OnNav(ctx app.Context) {
+	if app.IsServer {
+		return
+	}
+
+	// The URL is /post/{postID}/thread.
+	parts := strings.Split(strings.Trim(ctx.Page().URL().Path, "/"), "/")
+	if len(parts) < 2 {
+		return
+	}
+
+	postID := parts[1]
+
+	ctx.Dispatch(func(ctx app.Context) {
+		c.loaderShow = true
+		c.postID = postID
+	})
+
+	toast := common.Toast{AppContext: &ctx}
+
+	ctx.Async(func() {
+		defer ctx.Dispatch(func(ctx app.Context) {
+			c.loaderShow = false
+		})
+
+		input := &common.CallInput{
+			Method: "GET",
+			Url:    "/api/v1/posts/" + postID + "/thread",
+			Data:   nil,
+		}
+
+		type dataModel struct {
+			Thread models.PostThread      `json:"thread"`
+			Users  map[string]models.User `json:"users"`
+		}
+
+		output := &common.Response{Data: &dataModel{}}
+
+		if ok := common.FetchData(input, output); !ok {
+			toast.Text(common.ERR_CANNOT_REACH_BE).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		if output.Code == 401 {
+			toast.Text(common.ERR_LOGIN_AGAIN).Type(common.TTYPE_INFO).Link("/logout").Dispatch()
+			return
+		}
+
+		if output.Code != 200 {
+			toast.Text(output.Message).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		data, ok := output.Data.(*dataModel)
+		if !ok {
+			toast.Text(common.ERR_CANNOT_GET_DATA).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		ctx.Dispatch(func(ctx app.Context) {
+			c.thread = data.Thread
+			c.users = data.Users
+		})
+	})
+}
+
+func (c *Thread) handleFlowLink(ctx app.Context, a app.Action) {
+	id, ok := a.Value.(string)
+	if !ok {
+		return
+	}
+
+	ctx.Navigate("/flow/posts/" + id)
+}
+
+func (c *Thread) handleThreadLink(ctx app.Context, a app.Action) {
+	id, ok := a.Value.(string)
+	if !ok || id == c.postID {
+		return
+	}
+
+	ctx.Navigate("/post/" + id + "/thread")
+}
+
+func (c *Thread) Render() app.UI {
+	return app.Main().Class("responsive").Body(
+		&atoms.PageHeading{
+			Title: "thread",
+		},
+		app.Div().Class("space"),
+
+		&atoms.Loader{
+			ID:         "thread-loader",
+			ShowLoader: c.loaderShow,
+		},
+
+		app.If(!c.loaderShow && c.thread.Root.Post.ID != "", func() app.UI {
+			return app.Div().Class("post-feed").Body(
+				// The chain of the posts replied to, the deleted part of the chain first if any.
+				app.If(len(c.thread.Ancestors) > 0 && c.thread.Ancestors[0].ReplyToID != "", func() app.UI {
+					return app.P().Class("italic").Text("the post was deleted bye")
+				}),
+
+				app.Range(c.thread.Ancestors).Slice(func(i int) app.UI {
+					return c.renderPost(c.thread.Ancestors[i], "post")
+				}),
+
+				c.renderNode(c.thread.Root, 0),
+			)
+		}),
+	)
+}
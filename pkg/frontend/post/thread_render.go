@@ -0,0 +1,97 @@
+package post
+
+import (
+	"strconv"
+
+	"go.vxn.dev/littr/pkg/frontend/atomic/atoms"
+	"go.vxn.dev/littr/pkg/models"
+
+	"github.com/maxence-charriere/go-app/v10/pkg/app"
+)
+
+// threadIndent is the indentation of the replies per depth level (in pixels), limited so the deep threads fit the narrow screens.
+const (
+	threadIndent    = 16
+	threadIndentMax = 8
+)
+
+// renderNode renders the post with its replies nested.
+func (c *Thread) renderNode(node models.PostThreadNode, depth int) app.UI {
+	class := "post"
+	if depth == 0 {
+		class = "post original"
+	}
+
+	indent := depth
+	if indent > threadIndentMax {
+		indent = threadIndentMax
+	}
+
+	return app.Div().Style("margin-left", strconv.Itoa(indent*threadIndent)+"px").Body(
+		c.renderPost(node.Post, class),
+
+		app.Range(node.Replies).Slice(func(i int) app.UI {
+			return c.renderNode(node.Replies[i], depth+1)
+		}),
+
+		// The replies cut off are shown in the thread of such post.
+		app.If(node.More, func() app.UI {
+			return &atoms.Button{
+				ID:                node.Post.ID,
+				Title:             "show more replies",
+				Class:             "transparent bold primary-text",
+				Icon:              "expand_more",
+				Text:              "more replies",
+				OnClickActionName: "thread-link",
+			}
+		}),
+	)
+}
+
+// renderPost renders the post's author, content, and reply count.
+func (c *Thread) renderPost(post models.Post, class string) app.UI {
+	content := post.Content
+	if content == "" && post.Figure == "" {
+		content = "this content is private"
+	}
+
+	return app.Div().Class(class).Body(
+		app.Div().Class("row top-padding").Body(
+			app.Img().Class("responsive left").Src(c.users[post.Nickname].AvatarURL).Style("max-width", "40px").Style("border-radius", "50%"),
+			app.Span().Class("max bold primary-text").Text(post.Nickname),
+
+			&atoms.Button{
+				ID:                post.ID,
+				Title:             "link to this post",
+				Class:             "transparent circle",
+				Icon:              "link",
+				OnClickActionName: "flow-link",
+			},
+		),
+
+		app.Article().Class("border thicc").Style("max-width", "100%").Body(
+			app.Span().Text(content).Style("word-break", "break-word").Style("hyphens", "auto"),
+		),
+
+		app.Div().Class("row").Body(
+			app.Div().Class("max").Body(
+				app.Text(post.Timestamp.Format("Jan 02, 2006 / 15:04:05")),
+
+				app.If(post.Edited, func() app.UI {
+					return app.Span().Class("left-padding italic").Text("(edited)")
+				}),
+			),
+
+			app.If(post.ReplyCount > 0, func() app.UI {
+				return &atoms.Button{
+					ID:                post.ID,
+					Title:             "show the thread of this post",
+					Class:             "transparent circle",
+					Icon:              "forum",
+					BadgeText:         strconv.FormatInt(post.ReplyCount, 10),
+					OnClickActionName: "thread-link",
+				}
+			}),
+		),
+	)
+}
@@ -0,0 +1,57 @@
+package search
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/maxence-charriere/go-app/v10/pkg/app"
+)
+
+// handleType switches the kind of the items searched, the tab's ID holds the kind (see typeTabs).
+func (c *Content) handleType(ctx app.Context, a app.Action) {
+	id, ok := a.Value.(string)
+	if !ok {
+		return
+	}
+
+	kind := strings.TrimPrefix(id, typeTabPrefix)
+	if kind == typeAll {
+		kind = ""
+	}
+
+	if kind == c.kind {
+		return
+	}
+
+	path := "/search?q=" + url.QueryEscape(c.query)
+	if kind != "" {
+		path += "&type=" + url.QueryEscape(kind)
+	}
+
+	ctx.Navigate(path)
+}
+
+// handleMore loads the next page of the results.
+func (c *Content) handleMore(ctx app.Context, a app.Action) {
+	if c.loaderShow || !c.results.More {
+		return
+	}
+
+	c.fetch(ctx, c.query, c.kind, c.pageNo+1)
+}
+
+func (c *Content) handleLink(ctx app.Context, a app.Action) {
+	id, ok := a.Value.(string)
+	if !ok || id == "" {
+		return
+	}
+
+	switch a.Name {
+	case "flow-link":
+		ctx.Navigate("/flow/posts/" + id)
+	case "user-link":
+		ctx.Navigate("/flow/users/" + id)
+	case "poll-link":
+		ctx.Navigate("/polls/" + id)
+	}
+}
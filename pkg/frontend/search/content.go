@@ -0,0 +1,132 @@
+// The full-text search results view and view-controllers logic package.
+package search
+
+import (
+	"net/url"
+
+	"go.vxn.dev/littr/pkg/frontend/common"
+	"go.vxn.dev/littr/pkg/models"
+
+	"github.com/maxence-charriere/go-app/v10/pkg/app"
+)
+
+// Content is the view of the items matching the query of the navbar's search box (see navbars.Header).
+type Content struct {
+	app.Compo
+
+	loaderShow bool
+
+	// query and kind are taken from the URL (/search?q=&type=).
+	query string
+	kind  string
+
+	pageNo  int
+	results models.SearchResult
+	users   map[string]models.User
+}
+
+func (c *Content) OnMount(ctx app.Context) {
+	if app.IsServer {
+		return
+	}
+
+	ctx.Handle("search-type", c.handleType)
+	ctx.Handle("search-more", c.handleMore)
+	ctx.Handle("flow-link", c.handleLink)
+	ctx.Handle("user-link", c.handleLink)
+	ctx.Handle("poll-link", c.handleLink)
+}
+
+func (c *Content) OnNav(ctx app.Context) {
+	if app.IsServer {
+		return
+	}
+
+	values := ctx.Page().URL().Query()
+
+	query := values.Get("q")
+	kind := values.Get("type")
+
+	ctx.Dispatch(func(ctx app.Context) {
+		c.query = query
+		c.kind = kind
+		c.pageNo = 0
+		c.results = models.SearchResult{}
+		c.users = nil
+	})
+
+	if query == "" {
+		return
+	}
+
+	c.fetch(ctx, query, kind, 0)
+}
+
+// fetch loads such page of the results, the further pages are appended to the results shown.
+func (c *Content) fetch(ctx app.Context, query, kind string, pageNo int) {
+	ctx.Dispatch(func(ctx app.Context) {
+		c.loaderShow = true
+	})
+
+	toast := common.Toast{AppContext: &ctx}
+
+	ctx.Async(func() {
+		defer ctx.Dispatch(func(ctx app.Context) {
+			c.loaderShow = false
+		})
+
+		input := &common.CallInput{
+			Method: "GET",
+			Url:    "/api/v1/search?q=" + url.QueryEscape(query) + "&type=" + url.QueryEscape(kind),
+			Data:   nil,
+			PageNo: pageNo,
+		}
+
+		type dataModel struct {
+			Results models.SearchResult    `json:"results"`
+			Users   map[string]models.User `json:"users"`
+		}
+
+		output := &common.Response{Data: &dataModel{}}
+
+		if ok := common.FetchData(input, output); !ok {
+			toast.Text(common.ERR_CANNOT_REACH_BE).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		if output.Code == 401 {
+			toast.Text(common.ERR_LOGIN_AGAIN).Type(common.TTYPE_INFO).Link("/logout").Dispatch()
+			return
+		}
+
+		if output.Code != 200 {
+			toast.Text(output.Message).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		data, ok := output.Data.(*dataModel)
+		if !ok {
+			toast.Text(common.ERR_CANNOT_GET_DATA).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		ctx.Dispatch(func(ctx app.Context) {
+			c.pageNo = pageNo
+
+			if pageNo == 0 || c.users == nil {
+				c.results = data.Results
+				c.users = data.Users
+				return
+			}
+
+			c.results.Posts = append(c.results.Posts, data.Results.Posts...)
+			c.results.Users = append(c.results.Users, data.Results.Users...)
+			c.results.Polls = append(c.results.Polls, data.Results.Polls...)
+			c.results.More = data.Results.More
+
+			for key, user := range data.Users {
+				c.users[key] = user
+			}
+		})
+	})
+}
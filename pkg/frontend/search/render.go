@@ -0,0 +1,184 @@
+package search
+
+import (
+	"go.vxn.dev/littr/pkg/frontend/atomic/atoms"
+	"go.vxn.dev/littr/pkg/frontend/common"
+	"go.vxn.dev/littr/pkg/models"
+
+	"github.com/maxence-charriere/go-app/v10/pkg/app"
+)
+
+const (
+	// typeTabPrefix prefixes the IDs of the tabs switching the kind of the items searched.
+	typeTabPrefix = "search-type-"
+
+	// typeAll is the tab of all kinds.
+	typeAll = "all"
+)
+
+// typeTabs list the kinds of the items to be searched, all kinds first.
+var typeTabs = []string{typeAll, "posts", "users", "polls"}
+
+func (c *Content) Render() app.UI {
+	empty := len(c.results.Posts) == 0 && len(c.results.Users) == 0 && len(c.results.Polls) == 0
+
+	active := c.kind
+	if active == "" {
+		active = typeAll
+	}
+
+	return app.Main().Class("responsive").Body(
+		&atoms.PageHeading{
+			Title: "search",
+		},
+		app.Div().Class("space"),
+
+		app.Div().Class("row center-align").Body(
+			app.Range(typeTabs).Slice(func(i int) app.UI {
+				class := "transparent bold"
+				if typeTabs[i] == active {
+					class = "primary-container bold"
+				}
+
+				return &atoms.Button{
+					ID:                typeTabPrefix + typeTabs[i],
+					Title:             "search " + typeTabs[i],
+					Class:             class,
+					Text:              typeTabs[i],
+					OnClickActionName: "search-type",
+				}
+			}),
+		),
+
+		app.If(c.query != "", func() app.UI {
+			return app.P().Class("italic").Text("results for \"" + c.query + "\"")
+		}),
+
+		app.If(c.query != "" && empty && !c.loaderShow, func() app.UI {
+			return app.P().Class("bold").Text(common.MSG_SEARCH_NO_RESULTS)
+		}),
+
+		app.If(len(c.results.Users) > 0, func() app.UI {
+			return app.Div().Body(
+				app.H6().Class("primary-text").Text("users"),
+
+				app.Range(c.results.Users).Slice(func(i int) app.UI {
+					return c.renderUser(c.results.Users[i])
+				}),
+			)
+		}),
+
+		app.If(len(c.results.Posts) > 0, func() app.UI {
+			return app.Div().Class("post-feed").Body(
+				app.H6().Class("primary-text").Text("posts"),
+
+				app.Range(c.results.Posts).Slice(func(i int) app.UI {
+					return c.renderPost(c.results.Posts[i])
+				}),
+			)
+		}),
+
+		app.If(len(c.results.Polls) > 0, func() app.UI {
+			return app.Div().Body(
+				app.H6().Class("primary-text").Text("polls"),
+
+				app.Range(c.results.Polls).Slice(func(i int) app.UI {
+					return c.renderPoll(c.results.Polls[i])
+				}),
+			)
+		}),
+
+		&atoms.Loader{
+			ID:         "search-loader",
+			ShowLoader: c.loaderShow,
+		},
+
+		app.If(c.results.More && !c.loaderShow, func() app.UI {
+			return app.Div().Class("row center-align").Body(
+				&atoms.Button{
+					ID:                "search-more",
+					Title:             "show more results",
+					Class:             "transparent bold primary-text",
+					Icon:              "expand_more",
+					Text:              "more results",
+					OnClickActionName: "search-more",
+				},
+			)
+		}),
+	)
+}
+
+// renderUser renders the user's avatar, nickname and full name linking to their flow.
+func (c *Content) renderUser(user models.User) app.UI {
+	return app.Article().Class("border thicc").Body(
+		app.Div().Class("row").Body(
+			app.Img().Class("responsive left").Src(user.AvatarURL).Style("max-width", "40px").Style("border-radius", "50%"),
+
+			app.Div().Class("max").Body(
+				app.Span().Class("bold primary-text").Text(user.Nickname),
+				app.Div().Text(user.FullName),
+			),
+
+			&atoms.Button{
+				ID:                user.Nickname,
+				Title:             "show the user's flow",
+				Class:             "transparent circle",
+				Icon:              "tsunami",
+				OnClickActionName: "user-link",
+			},
+		),
+	)
+}
+
+// renderPost renders the post's author and content linking to the post.
+func (c *Content) renderPost(post models.Post) app.UI {
+	return app.Div().Class("post").Body(
+		app.Div().Class("row top-padding").Body(
+			app.Img().Class("responsive left").Src(c.users[post.Nickname].AvatarURL).Style("max-width", "40px").Style("border-radius", "50%"),
+			app.Span().Class("max bold primary-text").Text(post.Nickname),
+
+			&atoms.Button{
+				ID:                post.ID,
+				Title:             "link to this post",
+				Class:             "transparent circle",
+				Icon:              "link",
+				OnClickActionName: "flow-link",
+			},
+		),
+
+		app.Article().Class("border thicc").Style("max-width", "100%").Body(
+			app.Span().Text(post.Content).Style("word-break", "break-word").Style("hyphens", "auto"),
+		),
+
+		app.Div().Class("row").Body(
+			app.Div().Class("max").Body(
+				app.Text(post.Timestamp.Format("Jan 02, 2006 / 15:04:05")),
+
+				app.If(post.Edited, func() app.UI {
+					return app.Span().Class("left-padding italic").Text("(edited)")
+				}),
+			),
+		),
+	)
+}
+
+// renderPoll renders the poll's question and options linking to the poll.
+func (c *Content) renderPoll(poll models.Poll) app.UI {
+	return app.Article().Class("border thicc").Body(
+		app.Div().Class("row").Body(
+			app.Div().Class("max").Body(
+				app.Span().Class("bold").Text(poll.Question),
+				app.Div().Text(poll.OptionOne.Content+" / "+poll.OptionTwo.Content+" / "+poll.OptionThree.Content),
+				app.Div().Class("italic").Text(poll.Author+", "+poll.Timestamp.Format("Jan 02, 2006")),
+			),
+
+			&atoms.Button{
+				ID:                poll.ID,
+				Title:             "show the poll",
+				Class:             "transparent circle",
+				Icon:              "equalizer",
+				OnClickActionName: "poll-link",
+			},
+		),
+	)
+}
@@ -511,3 +511,108 @@ func (c *Content) handleUserDelete(ctx app.Context, a app.Action) {
 		ctx.Navigate("/logout")
 	})
 }
+
+func (c *Content) handleTwoFactor(ctx app.Context, a app.Action) {
+	ctx.Dispatch(func(ctx app.Context) {
+		c.settingsButtonDisabled = true
+	})
+
+	toast := common.Toast{AppContext: &ctx}
+
+	ctx.Async(func() {
+		defer ctx.Dispatch(func(ctx app.Context) {
+			c.settingsButtonDisabled = false
+		})
+
+		type dataModel struct {
+			Secret          string   `json:"secret"`
+			ProvisioningURI string   `json:"provisioning_uri"`
+			RecoveryCodes   []string `json:"recovery_codes"`
+		}
+
+		input := &common.CallInput{
+			Method:      "POST",
+			Url:         "/api/v1/users/" + c.user.Nickname + "/2fa",
+			CallerID:    c.user.Nickname,
+			PageNo:      0,
+			HideReplies: false,
+		}
+
+		var message string
+
+		switch a.Name {
+		case "totp-setup":
+			message = common.MSG_TOTP_SETUP_STARTED
+
+		case "totp-enable", "totp-disable":
+			codeCompo := app.Window().GetElementByID("totp-code")
+			if codeCompo.IsNull() {
+				return
+			}
+
+			code := strings.TrimSpace(codeCompo.Get("value").String())
+			if code == "" {
+				toast.Text(common.ERR_TOTP_CODE_MISSING).Type(common.TTYPE_ERR).Dispatch()
+				return
+			}
+
+			input.Data = struct {
+				Code string `json:"code"`
+			}{
+				Code: code,
+			}
+
+			if a.Name == "totp-enable" {
+				message = common.MSG_TOTP_ENABLED
+				input.Url += "/verify"
+			} else {
+				message = common.MSG_TOTP_DISABLED
+				input.Method = "DELETE"
+			}
+
+		default:
+			return
+		}
+
+		output := &common.Response{Data: &dataModel{}}
+
+		if ok := common.FetchData(input, output); !ok {
+			toast.Text(common.ERR_CANNOT_REACH_BE).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		if output.Code != 200 {
+			toast.Text(output.Message).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		data, ok := output.Data.(*dataModel)
+		if !ok {
+			toast.Text(common.ERR_CANNOT_GET_DATA).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		ctx.Dispatch(func(ctx app.Context) {
+			c.totpCode = ""
+
+			switch a.Name {
+			case "totp-setup":
+				c.totpSecret = data.Secret
+				c.totpURI = data.ProvisioningURI
+				c.totpRecoveryCodes = nil
+
+			case "totp-enable":
+				c.user.TOTPEnabled = true
+				c.totpSecret = ""
+				c.totpURI = ""
+				c.totpRecoveryCodes = data.RecoveryCodes
+
+			case "totp-disable":
+				c.user.TOTPEnabled = false
+				c.totpRecoveryCodes = nil
+			}
+		})
+
+		toast.Text(message).Type(common.TTYPE_SUCCESS).Dispatch()
+	})
+}
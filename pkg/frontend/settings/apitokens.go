@@ -0,0 +1,234 @@
+package settings
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"go.vxn.dev/littr/pkg/frontend/common"
+
+	"github.com/maxence-charriere/go-app/v10/pkg/app"
+)
+
+// apiTokenScopes are the scopes a new API token can be granted (see pkg/backend/apitokens.Scopes).
+var apiTokenScopes = []string{
+	"posts:read",
+	"posts:write",
+	"polls:read",
+	"polls:write",
+	"users:read",
+	"users:write",
+	"stats:read",
+}
+
+// apiTokenScopeSwitchPrefix prefixes the IDs of the scope switches, the rest of the ID is the scope.
+const apiTokenScopeSwitchPrefix = "api-token-scope-"
+
+// apiToken is the item of the caller's API tokens list (see pkg/backend/users.UserAPIToken).
+type apiToken struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Scopes     []string  `json:"scopes"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Token      string    `json:"token"`
+}
+
+// fetchAPITokens loads the caller's API tokens, it is to be called from within the ctx.Async() function.
+func (c *Content) fetchAPITokens(ctx app.Context, nickname string) {
+	toast := common.Toast{AppContext: &ctx}
+
+	input := &common.CallInput{
+		Method:   "GET",
+		Url:      "/api/v1/users/" + nickname + "/tokens",
+		CallerID: nickname,
+		PageNo:   0,
+	}
+
+	type dataModel struct {
+		Tokens []apiToken `json:"tokens"`
+	}
+
+	output := &common.Response{Data: &dataModel{}}
+
+	if ok := common.FetchData(input, output); !ok {
+		toast.Text(common.ERR_CANNOT_REACH_BE).Type(common.TTYPE_ERR).Dispatch()
+		return
+	}
+
+	if output.Code != 200 {
+		toast.Text(output.Message).Type(common.TTYPE_ERR).Dispatch()
+		return
+	}
+
+	data, ok := output.Data.(*dataModel)
+	if !ok {
+		toast.Text(common.ERR_CANNOT_GET_DATA).Type(common.TTYPE_ERR).Dispatch()
+		return
+	}
+
+	ctx.Dispatch(func(ctx app.Context) {
+		c.apiTokens = data.Tokens
+	})
+}
+
+func (c *Content) handleAPITokenScopeToggle(ctx app.Context, a app.Action) {
+	id, ok := a.Value.(string)
+	if !ok || !strings.HasPrefix(id, apiTokenScopeSwitchPrefix) {
+		return
+	}
+
+	scope := strings.TrimPrefix(id, apiTokenScopeSwitchPrefix)
+
+	ctx.Dispatch(func(ctx app.Context) {
+		if c.apiTokenScopes == nil {
+			c.apiTokenScopes = make(map[string]bool)
+		}
+
+		c.apiTokenScopes[scope] = !c.apiTokenScopes[scope]
+	})
+}
+
+func (c *Content) handleAPITokenCreate(ctx app.Context, a app.Action) {
+	toast := common.Toast{AppContext: &ctx}
+
+	name := strings.TrimSpace(c.apiTokenName)
+	if name == "" {
+		toast.Text(common.ERR_API_TOKEN_NAME_MISSING).Type(common.TTYPE_ERR).Dispatch()
+		return
+	}
+
+	var scopes []string
+
+	for _, scope := range apiTokenScopes {
+		if c.apiTokenScopes[scope] {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if len(scopes) == 0 {
+		toast.Text(common.ERR_API_TOKEN_SCOPES_MISSING).Type(common.TTYPE_ERR).Dispatch()
+		return
+	}
+
+	days, err := strconv.Atoi(strings.TrimSpace(c.apiTokenDays))
+	if err != nil || days < 1 || days > 365 {
+		toast.Text(common.ERR_API_TOKEN_DAYS_INVALID).Type(common.TTYPE_ERR).Dispatch()
+		return
+	}
+
+	ctx.Dispatch(func(ctx app.Context) {
+		c.settingsButtonDisabled = true
+	})
+
+	ctx.Async(func() {
+		defer ctx.Dispatch(func(ctx app.Context) {
+			c.settingsButtonDisabled = false
+		})
+
+		input := &common.CallInput{
+			Method:      "POST",
+			Url:         "/api/v1/users/" + c.user.Nickname + "/tokens",
+			CallerID:    c.user.Nickname,
+			PageNo:      0,
+			HideReplies: false,
+			Data: struct {
+				Name          string   `json:"name"`
+				Scopes        []string `json:"scopes"`
+				ExpiresInDays int      `json:"expires_in_days"`
+			}{
+				Name:          name,
+				Scopes:        scopes,
+				ExpiresInDays: days,
+			},
+		}
+
+		type dataModel struct {
+			Token apiToken `json:"token"`
+		}
+
+		output := &common.Response{Data: &dataModel{}}
+
+		if ok := common.FetchData(input, output); !ok {
+			toast.Text(common.ERR_CANNOT_REACH_BE).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		if output.Code != 201 {
+			toast.Text(output.Message).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		data, ok := output.Data.(*dataModel)
+		if !ok {
+			toast.Text(common.ERR_CANNOT_GET_DATA).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		ctx.Dispatch(func(ctx app.Context) {
+			// The plain token is shown just once, it is not kept in the list.
+			c.apiTokenPlain = data.Token.Token
+			data.Token.Token = ""
+
+			c.apiTokens = append([]apiToken{data.Token}, c.apiTokens...)
+			c.apiTokenName = ""
+			c.apiTokenScopes = make(map[string]bool)
+		})
+
+		toast.Text(common.MSG_API_TOKEN_CREATED).Type(common.TTYPE_SUCCESS).Dispatch()
+	})
+}
+
+func (c *Content) handleAPITokenDelete(ctx app.Context, a app.Action) {
+	tokenID, ok := a.Value.(string)
+	if !ok || tokenID == "" {
+		return
+	}
+
+	ctx.Dispatch(func(ctx app.Context) {
+		c.settingsButtonDisabled = true
+	})
+
+	toast := common.Toast{AppContext: &ctx}
+
+	ctx.Async(func() {
+		defer ctx.Dispatch(func(ctx app.Context) {
+			c.settingsButtonDisabled = false
+		})
+
+		input := &common.CallInput{
+			Method:      "DELETE",
+			Url:         "/api/v1/users/" + c.user.Nickname + "/tokens/" + tokenID,
+			CallerID:    c.user.Nickname,
+			PageNo:      0,
+			HideReplies: false,
+		}
+
+		output := &common.Response{}
+
+		if ok := common.FetchData(input, output); !ok {
+			toast.Text(common.ERR_CANNOT_REACH_BE).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		if output.Code != 200 {
+			toast.Text(output.Message).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		ctx.Dispatch(func(ctx app.Context) {
+			tokens := []apiToken{}
+			for _, t := range c.apiTokens {
+				if t.ID == tokenID {
+					continue
+				}
+				tokens = append(tokens, t)
+			}
+
+			c.apiTokens = tokens
+		})
+
+		toast.Text(common.MSG_API_TOKEN_REVOKED).Type(common.TTYPE_SUCCESS).Dispatch()
+	})
+}
@@ -20,6 +20,12 @@ type Content struct {
 	passphraseCurrent string
 	aboutText         string
 	website           string
+	totpCode          string
+
+	// two-factor authentication enrollment
+	totpSecret        string
+	totpURI           string
+	totpRecoveryCodes []string
 
 	// loaded logged user's struct
 	user models.User
@@ -44,6 +50,16 @@ type Content struct {
 	VAPIDpublic string
 	thisDevice  models.Device
 
+	// the caller's active sessions
+	sessions []session
+
+	// the caller's API tokens, and the new token's form
+	apiTokens      []apiToken
+	apiTokenName   string
+	apiTokenDays   string
+	apiTokenScopes map[string]bool
+	apiTokenPlain  string
+
 	thisDeviceUUID string
 	interactedUUID string
 
@@ -64,6 +80,9 @@ func (c *Content) OnMount(ctx app.Context) {
 
 	c.notificationPermission = ctx.Notifications().Permission()
 
+	// The default validity of a new API token.
+	c.apiTokenDays = "90"
+
 	ctx.Handle("dismiss", c.handleDismiss)
 
 	ctx.Handle("options-switch-change", c.handleOptionsSwitchChange)
@@ -79,6 +98,17 @@ func (c *Content) OnMount(ctx app.Context) {
 	ctx.Handle("passphrase-submit", c.handlePassphraseChange)
 	ctx.Handle("about-you-submit", c.handleOptionsChange)
 	ctx.Handle("website-submit", c.handleOptionsChange)
+
+	ctx.Handle("totp-setup", c.handleTwoFactor)
+	ctx.Handle("totp-enable", c.handleTwoFactor)
+	ctx.Handle("totp-disable", c.handleTwoFactor)
+
+	ctx.Handle("session-delete", c.handleSessionDelete)
+	ctx.Handle("sessions-delete", c.handleSessionDelete)
+
+	ctx.Handle("api-token-scope-toggle", c.handleAPITokenScopeToggle)
+	ctx.Handle("api-token-create", c.handleAPITokenCreate)
+	ctx.Handle("api-token-delete", c.handleAPITokenDelete)
 }
 
 func (c *Content) OnNav(ctx app.Context) {
@@ -170,5 +200,8 @@ func (c *Content) OnNav(ctx app.Context) {
 
 			c.settingsButtonDisabled = false
 		})
+
+		c.fetchSessions(ctx, data.User.Nickname)
+		c.fetchAPITokens(ctx, data.User.Nickname)
 	})
 }
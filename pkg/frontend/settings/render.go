@@ -3,6 +3,7 @@ package settings
 import (
 	"log"
 	"net/url"
+	"strings"
 
 	"github.com/maxence-charriere/go-app/v10/pkg/app"
 
@@ -303,6 +304,255 @@ func (c *Content) Render() app.UI {
 
 		app.Div().Class("space"),
 
+		//
+		// Section two-factor authentication
+		//
+
+		&atoms.PageHeading{
+			Title: "two-factor authentication",
+			Level: 6,
+		},
+
+		&molecules.TextBox{
+			Class:       "row border blue-border thicc info",
+			Icon:        "info",
+			IconClass:   "blue-text",
+			MarkupText:  InfoTwoFactor,
+			MakeSummary: true,
+		},
+
+		// Recovery codes are shown once right after the enrollment.
+		app.If(len(c.totpRecoveryCodes) > 0, func() app.UI {
+			return &molecules.TextBox{
+				Class:      "row border thicc primary-border",
+				Icon:       "key",
+				IconClass:  "primary-text",
+				MarkupText: FormatRecoveryCodes,
+				FormatArgs: []interface{}{strings.Join(c.totpRecoveryCodes, ", ")},
+			}
+		}),
+
+		app.If(c.user.TOTPEnabled, func() app.UI {
+			return &molecules.TextBox{
+				Class:      "row border thicc",
+				Icon:       "verified_user",
+				MarkupText: InfoTwoFactorEnabled,
+			}
+		}).ElseIf(c.totpSecret != "", func() app.UI {
+			return &molecules.TextBox{
+				Class:      "row border thicc",
+				Icon:       "qr_code",
+				MarkupText: FormatTwoFactorSetup,
+				FormatArgs: []interface{}{c.totpSecret, c.totpURI},
+			}
+		}),
+
+		// The code field is used to verify the new secret, or to disable the two-factor authentication.
+		app.If(c.user.TOTPEnabled || c.totpSecret != "", func() app.UI {
+			return app.Div().Class("field label border primary-text thicc").Body(
+				&atoms.Input{
+					ID:           "totp-code",
+					Type:         "text",
+					Class:        "active",
+					OnChangeType: atoms.InputOnChangeValueTo,
+					Content:      c.totpCode,
+					Value:        &c.totpCode,
+					AutoComplete: false,
+					MaxLength:    16,
+					Attr:         map[string]string{"autocomplete": "one-time-code", "inputmode": "numeric"},
+				},
+				app.Label().Text("Code").Class("active primary-text"),
+			)
+		}),
+
+		app.If(c.user.TOTPEnabled, func() app.UI {
+			return &atoms.Button{
+				Class:             "max responsive shrink center red10 white-text bold thicc",
+				OnClickActionName: "totp-disable",
+				Disabled:          c.settingsButtonDisabled,
+				Icon:              "lock_open",
+				Text:              "Disable",
+			}
+		}).ElseIf(c.totpSecret != "", func() app.UI {
+			return &atoms.Button{
+				Class:             "max responsive shrink center primary-container white-text bold thicc",
+				OnClickActionName: "totp-enable",
+				Disabled:          c.settingsButtonDisabled,
+				Icon:              "verified_user",
+				Text:              "Verify and enable",
+			}
+		}).Else(func() app.UI {
+			return &atoms.Button{
+				Class:             "max responsive shrink center primary-container white-text bold thicc",
+				OnClickActionName: "totp-setup",
+				Disabled:          c.settingsButtonDisabled,
+				Icon:              "lock",
+				Text:              "Set up",
+			}
+		}),
+
+		app.Div().Class("space"),
+
+		//
+		// Section sessions
+		//
+
+		&atoms.PageHeading{
+			Title: "sessions",
+			Level: 6,
+		},
+
+		&molecules.TextBox{
+			Class:       "row border blue-border thicc info",
+			Icon:        "info",
+			IconClass:   "blue-text",
+			MarkupText:  InfoSessions,
+			MakeSummary: true,
+		},
+
+		// Loop over the array of active sessions.
+		app.Div().Class().Body(
+			app.Range(c.sessions).Slice(func(i int) app.UI {
+				sess := c.sessions[i]
+
+				sessionText := "Session"
+				if sess.Current {
+					sessionText = "This session"
+				}
+
+				return &molecules.TextBox{
+					Class:      "row border thicc primary-border",
+					Icon:       "",
+					IconClass:  "deep-orange-text",
+					MarkupText: InfoSession,
+					FormatArgs: []interface{}{sessionText, sess.UserAgent, sess.IPAddress, sess.LastUsedAt.Format("2006-01-02 15:04:05"), sess.ExpiresAt.Format("2006-01-02 15:04:05")},
+					Button: &atoms.Button{
+						ID:                sess.ID,
+						Class:             "transparent circle",
+						OnClickActionName: "session-delete",
+						Disabled:          c.settingsButtonDisabled,
+						Icon:              "logout",
+					},
+				}
+			}),
+		),
+
+		&atoms.Button{
+			Class:             "max responsive shrink center red10 white-text bold thicc",
+			OnClickActionName: "sessions-delete",
+			Disabled:          c.settingsButtonDisabled,
+			Icon:              "logout",
+			Text:              "Log out everywhere",
+		},
+
+		app.Div().Class("space"),
+
+		//
+		// Section API tokens
+		//
+
+		&atoms.PageHeading{
+			Title: "API tokens",
+			Level: 6,
+		},
+
+		&molecules.TextBox{
+			Class:       "row border blue-border thicc info",
+			Icon:        "info",
+			IconClass:   "blue-text",
+			MarkupText:  InfoAPITokens,
+			MakeSummary: true,
+		},
+
+		// The new token is shown once right after its creation.
+		app.If(c.apiTokenPlain != "", func() app.UI {
+			return &molecules.TextBox{
+				Class:      "row border thicc primary-border",
+				Icon:       "key",
+				IconClass:  "primary-text",
+				MarkupText: FormatAPITokenPlain,
+				FormatArgs: []interface{}{c.apiTokenPlain},
+			}
+		}),
+
+		// Loop over the array of API tokens.
+		app.Div().Class().Body(
+			app.Range(c.apiTokens).Slice(func(i int) app.UI {
+				token := c.apiTokens[i]
+
+				lastUsed := "never"
+				if !token.LastUsedAt.IsZero() {
+					lastUsed = token.LastUsedAt.Format("2006-01-02 15:04:05")
+				}
+
+				return &molecules.TextBox{
+					Class:      "row border thicc primary-border",
+					Icon:       "",
+					IconClass:  "deep-orange-text",
+					MarkupText: InfoAPIToken,
+					FormatArgs: []interface{}{token.Name, strings.Join(token.Scopes, ", "), lastUsed, token.ExpiresAt.Format("2006-01-02 15:04:05")},
+					Button: &atoms.Button{
+						ID:                token.ID,
+						Class:             "transparent circle",
+						OnClickActionName: "api-token-delete",
+						Disabled:          c.settingsButtonDisabled,
+						Icon:              "delete",
+					},
+				}
+			}),
+		),
+
+		app.Div().Class("field label border primary-text thicc").Body(
+			&atoms.Input{
+				ID:           "api-token-name",
+				Type:         "text",
+				Class:        "active",
+				OnChangeType: atoms.InputOnChangeValueTo,
+				Content:      c.apiTokenName,
+				Value:        &c.apiTokenName,
+				AutoComplete: false,
+				MaxLength:    40,
+			},
+			app.Label().Text("Token name").Class("active primary-text"),
+		),
+
+		app.Range(apiTokenScopes).Slice(func(i int) app.UI {
+			scope := apiTokenScopes[i]
+
+			return &molecules.Switch{
+				Icon:               "key",
+				ID:                 apiTokenScopeSwitchPrefix + scope,
+				Text:               scope,
+				Checked:            c.apiTokenScopes[scope],
+				Disabled:           c.settingsButtonDisabled,
+				OnChangeActionName: "api-token-scope-toggle",
+			}
+		}),
+
+		app.Div().Class("field label border primary-text thicc").Body(
+			&atoms.Input{
+				ID:           "api-token-days",
+				Type:         "number",
+				Class:        "active",
+				OnChangeType: atoms.InputOnChangeValueTo,
+				Content:      c.apiTokenDays,
+				Value:        &c.apiTokenDays,
+				AutoComplete: false,
+				MaxLength:    3,
+			},
+			app.Label().Text("Expires in (days)").Class("active primary-text"),
+		),
+
+		&atoms.Button{
+			Class:             "max responsive shrink center primary-container white-text bold thicc",
+			OnClickActionName: "api-token-create",
+			Disabled:          c.settingsButtonDisabled,
+			Icon:              "add",
+			Text:              "Create token",
+		},
+
+		app.Div().Class("space"),
+
 		//
 		// Section about-you
 		//
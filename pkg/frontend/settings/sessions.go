@@ -0,0 +1,132 @@
+package settings
+
+import (
+	"time"
+
+	"go.vxn.dev/littr/pkg/frontend/common"
+
+	"github.com/maxence-charriere/go-app/v10/pkg/app"
+)
+
+// session is the item of the caller's sessions list (see pkg/backend/users.UserSession).
+type session struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// fetchSessions loads the caller's active sessions, it is to be called from within the ctx.Async() function.
+func (c *Content) fetchSessions(ctx app.Context, nickname string) {
+	toast := common.Toast{AppContext: &ctx}
+
+	input := &common.CallInput{
+		Method:   "GET",
+		Url:      "/api/v1/users/" + nickname + "/sessions",
+		CallerID: nickname,
+		PageNo:   0,
+	}
+
+	type dataModel struct {
+		Sessions []session `json:"sessions"`
+	}
+
+	output := &common.Response{Data: &dataModel{}}
+
+	if ok := common.FetchData(input, output); !ok {
+		toast.Text(common.ERR_CANNOT_REACH_BE).Type(common.TTYPE_ERR).Dispatch()
+		return
+	}
+
+	if output.Code != 200 {
+		toast.Text(output.Message).Type(common.TTYPE_ERR).Dispatch()
+		return
+	}
+
+	data, ok := output.Data.(*dataModel)
+	if !ok {
+		toast.Text(common.ERR_CANNOT_GET_DATA).Type(common.TTYPE_ERR).Dispatch()
+		return
+	}
+
+	ctx.Dispatch(func(ctx app.Context) {
+		c.sessions = data.Sessions
+	})
+}
+
+func (c *Content) handleSessionDelete(ctx app.Context, a app.Action) {
+	ctx.Dispatch(func(ctx app.Context) {
+		c.settingsButtonDisabled = true
+	})
+
+	toast := common.Toast{AppContext: &ctx}
+
+	input := &common.CallInput{
+		Method:      "DELETE",
+		Url:         "/api/v1/users/" + c.user.Nickname + "/sessions",
+		CallerID:    c.user.Nickname,
+		PageNo:      0,
+		HideReplies: false,
+	}
+
+	// The current session is revoked when logging out everywhere.
+	var sessionID string
+	logout := true
+
+	if a.Name == "session-delete" {
+		id, ok := a.Value.(string)
+		if !ok || id == "" {
+			return
+		}
+
+		sessionID = id
+		input.Url += "/" + sessionID
+
+		for _, s := range c.sessions {
+			if s.ID == sessionID {
+				logout = s.Current
+				break
+			}
+		}
+	}
+
+	ctx.Async(func() {
+		defer ctx.Dispatch(func(ctx app.Context) {
+			c.settingsButtonDisabled = false
+		})
+
+		output := &common.Response{}
+
+		if ok := common.FetchData(input, output); !ok {
+			toast.Text(common.ERR_CANNOT_REACH_BE).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		if output.Code != 200 {
+			toast.Text(output.Message).Type(common.TTYPE_ERR).Dispatch()
+			return
+		}
+
+		if logout {
+			ctx.Navigate("/logout")
+			return
+		}
+
+		ctx.Dispatch(func(ctx app.Context) {
+			sessions := []session{}
+			for _, s := range c.sessions {
+				if s.ID == sessionID {
+					continue
+				}
+				sessions = append(sessions, s)
+			}
+
+			c.sessions = sessions
+		})
+
+		toast.Text(common.MSG_SESSION_REVOKED).Type(common.TTYPE_SUCCESS).Dispatch()
+	})
+}
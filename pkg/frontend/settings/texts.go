@@ -13,5 +13,14 @@ const (
 	InfoLiveMode         = "#bold class='blue-text'#The live mode##bold# is a feature for the live flow experience. When enabled, a notice about some followed account's/user's new post is shown on the bottom of the page."
 	InfoPrivateMode      = "#bold class='blue-text'#Private account##bold# is a feature allowing one to be hidden on the site. When enabled, other accounts/users need to ask you to follow you (the follow request will show on the users page). Any reply to your post will be shown as redacted (a private content notice) to those not following you."
 	InfoNotifications    = "#bold class='blue-text'#Reply##bold# notifications are fired when someone posts a reply to your post. #break###break##bold class='blue-text'#Mention##bold# notifications are fired when someone mentions you via the at-sign (@) handler in their post (e.g. Hello, @example!).#break###break# #break###break#You will be prompted for the notification permission, which is required if you want to subscribe to the notification service. Your device's UUID (unique identification string) will be saved in the database to be used by the notification service. You can delete any subscribed device any time (if listed below)."
+	InfoTwoFactor        = "#bold class='blue-text'#Two-factor authentication##bold# adds the second step to the login: a 6-digit code generated by an authenticator app (e.g. Aegis, FreeOTP, or Google Authenticator). #break###break# #break###break#When set up, import the secret into the app (or open the link on your phone), and enter the code shown to verify it. The one-time recovery codes are shown once then, each of them can be used instead of a code when the app is not at hand."
+	InfoTwoFactorEnabled = "Two-factor authentication is #bold class='primary-text'#enabled##bold# for your account. Enter a code (or a recovery code) to disable it."
+	FormatTwoFactorSetup = "Secret: #bold class='primary-text'#%s##bold##break###break# #break###break##link class='bold' to='%s'#Open in the authenticator app##link#"
+	FormatRecoveryCodes  = "Recovery codes (shown just once): #break###break# #break###break##bold class='primary-text'#%s##bold#"
+	InfoSessions         = "Every login creates a new session on such device. A session lasts 30 days, and can be revoked any time here, so the device using it gets logged out.#break###break# #break###break#The #bold class='blue-text'#log out everywhere##bold# button revokes all the sessions, this one included."
+	InfoSession          = "#bold#%s##bold##break###break# #break###break#%s#break###break#IP address: %s#break###break#Last used: %s#break###break#Expires: %s"
+	InfoAPITokens        = "#bold class='blue-text'#API tokens##bold# let your bots and scripts use the API without logging in: the token is sent in the #bold#Authorization: Bearer##bold# header. Each token is granted the chosen scopes only (e.g. posts:write to add posts), and expires in the given number of days.#break###break# #break###break#The token is shown just once right after its creation, keep it secret. The account's security settings (passphrase, sessions, tokens) cannot be changed using a token."
+	InfoAPIToken         = "#bold#%s##bold##break###break# #break###break#Scopes: %s#break###break#Last used: %s#break###break#Expires: %s"
+	FormatAPITokenPlain  = "New API token (shown just once): #break###break# #break###break##bold class='primary-text'#%s##bold#"
 	InfoSubscribedDevice = "#bold#%s##bold##break###break# #break###break#Subsctibed to: %v#break###break#Registered: %s"
 )
@@ -50,7 +50,7 @@ func (c *Content) Render() app.UI {
 						app.Span().Style("writing-mode", "vertical-lr").Text("posts"),
 					),
 					app.Th().Class("right-align no-padding").Body(
-						app.Span().Style("writing-mode", "vertical-lr").Text("stars"),
+						app.Span().Style("writing-mode", "vertical-lr").Text("reactions"),
 					),
 					app.Th().Class("right-align no-padding").Body(
 						app.Span().Style("writing-mode", "vertical-lr").Text("flowers"),
@@ -73,12 +73,12 @@ func (c *Content) Render() app.UI {
 							return 0
 						}
 
-						stars := float64(users[key].ReactionCount)
+						reactions := float64(users[key].ReactionCount)
 						posts := float64(users[key].PostCount)
 						shades := float64(users[key].ShadeCount)
 						users := float64(flowStats["users"])
 
-						baseRatio := stars / posts
+						baseRatio := reactions / posts
 						shadeCoeff := 1.0
 
 						if users > 1 && shades > 1 {
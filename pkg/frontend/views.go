@@ -2,6 +2,7 @@
 package frontend
 
 import (
+	"go.vxn.dev/littr/pkg/frontend/bookmarks"
 	"go.vxn.dev/littr/pkg/frontend/flow"
 	"go.vxn.dev/littr/pkg/frontend/login"
 	"go.vxn.dev/littr/pkg/frontend/navbars"
@@ -9,6 +10,7 @@ import (
 	"go.vxn.dev/littr/pkg/frontend/post"
 	"go.vxn.dev/littr/pkg/frontend/register"
 	"go.vxn.dev/littr/pkg/frontend/reset"
+	"go.vxn.dev/littr/pkg/frontend/search"
 	"go.vxn.dev/littr/pkg/frontend/settings"
 	"go.vxn.dev/littr/pkg/frontend/stats"
 	"go.vxn.dev/littr/pkg/frontend/tos"
@@ -18,6 +20,26 @@ import (
 	"github.com/maxence-charriere/go-app/v10/pkg/app"
 )
 
+//
+//  bookmarks view
+//
+
+type BookmarksView struct {
+	app.Compo
+}
+
+func (v *BookmarksView) OnNav(ctx app.Context) {
+	ctx.Page().SetTitle("bookmarks / littr")
+}
+
+func (v *BookmarksView) Render() app.UI {
+	return app.Div().Body(
+		&navbars.Header{},
+		&navbars.Footer{},
+		&bookmarks.Content{},
+	)
+}
+
 //
 //  flow view
 //
@@ -115,6 +137,26 @@ func (v *PostView) Render() app.UI {
 	)
 }
 
+//
+//  thread view
+//
+
+type ThreadView struct {
+	app.Compo
+}
+
+func (v *ThreadView) OnNav(ctx app.Context) {
+	ctx.Page().SetTitle("thread / littr")
+}
+
+func (v *ThreadView) Render() app.UI {
+	return app.Div().Body(
+		&navbars.Header{},
+		&navbars.Footer{},
+		&post.Thread{},
+	)
+}
+
 //
 //  register view
 //
@@ -158,6 +200,26 @@ func (v *ResetView) Render() app.UI {
 	)
 }
 
+//
+//  search view
+//
+
+type SearchView struct {
+	app.Compo
+}
+
+func (v *SearchView) OnNav(ctx app.Context) {
+	ctx.Page().SetTitle("search / littr")
+}
+
+func (v *SearchView) Render() app.UI {
+	return app.Div().Body(
+		&navbars.Header{},
+		&navbars.Footer{},
+		&search.Content{},
+	)
+}
+
 //
 //  settings view
 //
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// APIToken is a model structure which is to hold the personal API token's properties. The token itself is never stored, just its hash.
+type APIToken struct {
+	// Unique hash = sha256 sum of the token.
+	Hash string `json:"hash"`
+
+	// User's name the token authenticates as.
+	Nickname string `json:"nickname"`
+
+	// Name is the token's label given by the user (e.g. the bot's name).
+	Name string `json:"name"`
+
+	// Scopes are the permissions granted to the token (e.g. posts:write, see pkg/backend/apitokens).
+	Scopes []string `json:"scopes"`
+
+	// Timestamp of the token's generation.
+	CreatedAt time.Time `json:"created_at"`
+
+	// ExpiresAt is the time the token stops being accepted.
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// LastUsedAt is the time the token was last used to authenticate a request.
+	LastUsedAt time.Time `json:"last_used_at"`
+
+	// Version is increased on every update of the token (see models.Versioned).
+	Version int64 `json:"version"`
+}
+
+func (t APIToken) GetID() string {
+	return t.Hash
+}
+
+func (t APIToken) GetVersion() int64 {
+	return t.Version
+}
+
+func (t *APIToken) SetVersion(version int64) {
+	t.Version = version
+}
+
+// IsExpired reports whether the token cannot be used anymore.
+func (t APIToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// HasScope reports whether such scope has been granted to the token.
+func (t APIToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// AuditEvent is a model structure which is to hold one entry of the audit log (see pkg/backend/audit).
+type AuditEvent struct {
+	// Unique ID of the event (its timestamp in nanoseconds, and a sequence number).
+	ID string `json:"id"`
+
+	// Timestamp of the event.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Action is the type of the event (e.g. auth.login, post.delete).
+	Action string `json:"action"`
+
+	// Actor is the nickname of the user who has performed the action ("system" for the server itself, and the signed requests).
+	Actor string `json:"actor"`
+
+	// Target is the ID of the entity affected (e.g. the user's nickname, the post's ID).
+	Target string `json:"target,omitempty"`
+
+	// IPAddress is the actor's IP address (if known).
+	IPAddress string `json:"ip_address,omitempty"`
+
+	// Details hold any other attributes of the event (e.g. the roles assigned, the reason of a failure).
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// AuditFilter holds the criteria of the audit log query, the blank ones match any event.
+type AuditFilter struct {
+	// Actor's nickname.
+	Actor string
+
+	// Target's ID.
+	Target string
+
+	// Action is matched exactly, or as a prefix when ending with a dot (e.g. "auth.").
+	Action string
+
+	// IPAddress of the actor.
+	IPAddress string
+
+	// Since and Until bound the events' timestamps (both inclusive).
+	Since time.Time
+	Until time.Time
+
+	// Limit is the maximum count of the events returned.
+	Limit int
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"maps"
+	"time"
+)
+
+// MaxBookmarks is the maximum count of the posts bookmarked by a single user.
+const MaxBookmarks = 1000
+
+// HasBookmarked reports whether the user has bookmarked such post.
+func (u *User) HasBookmarked(postID string) bool {
+	_, found := u.Bookmarks[postID]
+	return found
+}
+
+// AddBookmark saves the post to the user's bookmarks, false is returned when bookmarked already. The bookmarks are copied first, as they may
+// be shared with the stored user.
+func (u *User) AddBookmark(postID string, savedAt time.Time) bool {
+	if u.HasBookmarked(postID) {
+		return false
+	}
+
+	bookmarks := maps.Clone(u.Bookmarks)
+	if bookmarks == nil {
+		bookmarks = make(map[string]time.Time)
+	}
+
+	bookmarks[postID] = savedAt
+	u.Bookmarks = bookmarks
+
+	return true
+}
+
+// RemoveBookmarks drops the posts from the user's bookmarks, false is returned when none of them was bookmarked.
+func (u *User) RemoveBookmarks(postIDs ...string) bool {
+	var bookmarks map[string]time.Time
+
+	for _, postID := range postIDs {
+		if !u.HasBookmarked(postID) {
+			continue
+		}
+
+		if bookmarks == nil {
+			bookmarks = maps.Clone(u.Bookmarks)
+		}
+
+		delete(bookmarks, postID)
+	}
+
+	if bookmarks == nil {
+		return false
+	}
+
+	u.Bookmarks = bookmarks
+
+	return true
+}
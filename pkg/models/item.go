@@ -3,3 +3,9 @@ package models
 type Item interface {
 	GetID() string
 }
+
+// Versioned is implemented by the items updated concurrently. The version is increased on every update (see the repositories' Update method),
+// so an update based on a stale copy of such item is detected and retried instead of overwriting the newer data.
+type Versioned interface {
+	GetVersion() int64
+}
@@ -36,6 +36,9 @@ type Poll struct {
 	Hidden  bool     `json:"hidden"`
 	Private bool     `json:"private"`
 	Tags    []string `json:"tags"`
+
+	// Version is increased on every update of the poll (see models.Versioned).
+	Version int64 `json:"version"`
 }
 
 type PollOption struct {
@@ -49,3 +52,11 @@ type PollOption struct {
 func (p Poll) GetID() string {
 	return p.ID
 }
+
+func (p Poll) GetVersion() int64 {
+	return p.Version
+}
+
+func (p *Poll) SetVersion(version int64) {
+	p.Version = version
+}
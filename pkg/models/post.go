@@ -31,14 +31,32 @@ type Post struct {
 	// ReplyToID is a reference key to another post, that is being replied to.
 	ReplyToID string `json:"reply_to_id"`
 
-	// ReactionCount counts the number of item's reactions.
+	// ReactionCount is the total of the post's reactions of all kinds (see ReactionCounts).
 	ReactionCount int64 `json:"reaction_count"`
 
+	// Reactions list the nicknames of the users reacted per reaction kind (see models.ReactionKinds).
+	Reactions map[string][]string `json:"reactions,omitempty"`
+
+	// ReactionCounts hold the count of the reactions per kind, including the anonymous stars given before the reactions were recorded per user.
+	ReactionCounts map[string]int64 `json:"reaction_counts,omitempty"`
+
 	// ReplyCount hold the count of replies for such post.
 	ReplyCount int64 `json:"reply_count"`
 
+	// Edited marks the post's content has been changed by the author after the post was published.
+	Edited bool `json:"edited"`
+
+	// EditedAt is the time of the latest edit.
+	EditedAt time.Time `json:"edited_at,omitempty"`
+
+	// Revisions hold the post's previous contents, the oldest one first.
+	Revisions []PostRevision `json:"revisions,omitempty"`
+
 	// Data is a helper field for the actual figure upload.
 	Data []byte `json:"data" swaggerignore:"true"`
+
+	// Version is increased on every update of the post (see models.Versioned).
+	Version int64 `json:"version"`
 }
 
 func (p Post) MarshalBinary() []byte {
@@ -60,3 +78,11 @@ func (p *Post) UnmarshalBinary(data *[]byte) error {
 func (p Post) GetID() string {
 	return p.ID
 }
+
+func (p Post) GetVersion() int64 {
+	return p.Version
+}
+
+func (p *Post) SetVersion(version int64) {
+	p.Version = version
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"maps"
+	"slices"
+)
+
+// The reaction kinds.
+const (
+	ReactionStar  = "star"
+	ReactionHeart = "heart"
+	ReactionLaugh = "laugh"
+	ReactionWow   = "wow"
+	ReactionSad   = "sad"
+)
+
+// ReactionKinds lists the reaction kinds in the order shown.
+var ReactionKinds = []string{
+	ReactionStar,
+	ReactionHeart,
+	ReactionLaugh,
+	ReactionWow,
+	ReactionSad,
+}
+
+// IsValidReaction reports whether such reaction kind is known.
+func IsValidReaction(kind string) bool {
+	return slices.Contains(ReactionKinds, kind)
+}
+
+// HasReacted reports whether the user has reacted to the post with such kind.
+func (p Post) HasReacted(kind, nickname string) bool {
+	return slices.Contains(p.Reactions[kind], nickname)
+}
+
+// CountReactions returns (a copy of) the reaction counts per kind. The reaction count of the post not migrated yet (see the migratePostReactions
+// migration) is made of the anonymous stars.
+func (p Post) CountReactions() map[string]int64 {
+	if p.ReactionCounts == nil && p.ReactionCount > 0 {
+		return map[string]int64{ReactionStar: p.ReactionCount}
+	}
+
+	return maps.Clone(p.ReactionCounts)
+}
+
+// AddReaction records the user's reaction, false is returned if the user has reacted so already. The maps are copied first, as they may be
+// shared with the stored post.
+func (p *Post) AddReaction(kind, nickname string) bool {
+	if p.HasReacted(kind, nickname) {
+		return false
+	}
+
+	p.Reactions = maps.Clone(p.Reactions)
+	p.ReactionCounts = p.CountReactions()
+
+	if p.Reactions == nil {
+		p.Reactions = make(map[string][]string)
+	}
+
+	if p.ReactionCounts == nil {
+		p.ReactionCounts = make(map[string]int64)
+	}
+
+	reactors := append(slices.Clone(p.Reactions[kind]), nickname)
+	slices.Sort(reactors)
+
+	p.Reactions[kind] = reactors
+	p.ReactionCounts[kind]++
+	p.sumReactions()
+
+	return true
+}
+
+// RemoveReaction takes the user's reaction back, false is returned if the user has not reacted so.
+func (p *Post) RemoveReaction(kind, nickname string) bool {
+	if !p.HasReacted(kind, nickname) {
+		return false
+	}
+
+	p.Reactions = maps.Clone(p.Reactions)
+	p.ReactionCounts = maps.Clone(p.ReactionCounts)
+
+	p.Reactions[kind] = slices.DeleteFunc(slices.Clone(p.Reactions[kind]), func(reactor string) bool {
+		return reactor == nickname
+	})
+
+	if len(p.Reactions[kind]) == 0 {
+		delete(p.Reactions, kind)
+	}
+
+	// The count includes the anonymous stars given before the reactions were recorded per user, so it cannot go below the reactors count.
+	p.ReactionCounts[kind] = max(p.ReactionCounts[kind]-1, int64(len(p.Reactions[kind])))
+
+	if p.ReactionCounts[kind] == 0 {
+		delete(p.ReactionCounts, kind)
+	}
+
+	p.sumReactions()
+
+	return true
+}
+
+// sumReactions sets the total reaction count.
+func (p *Post) sumReactions() {
+	p.ReactionCount = 0
+
+	for _, count := range p.ReactionCounts {
+		p.ReactionCount += count
+	}
+}
@@ -4,10 +4,20 @@ package models
 //  Repository interfaces
 //
 
+type APITokenRepositoryInterface interface {
+	GetAll() (*map[string]APIToken, error)
+	GetByID(tokenID string) (*APIToken, error)
+	GetByNickname(nickname string) (*map[string]APIToken, error)
+	Save(token *APIToken) error
+	Update(tokenID string, fn func(token *APIToken) error) error
+	Delete(tokenID string) error
+}
+
 type PollRepositoryInterface interface {
 	GetAll() (*map[string]Poll, error)
 	GetByID(pollID string) (*Poll, error)
 	Save(poll *Poll) error
+	Update(pollID string, fn func(poll *Poll) error) error
 	Delete(pollID string) error
 }
 
@@ -15,6 +25,7 @@ type PostRepositoryInterface interface {
 	GetAll() (*map[string]Post, error)
 	GetByID(postID string) (*Post, error)
 	Save(post *Post) error
+	Update(postID string, fn func(post *Post) error) error
 	Delete(postID string) error
 }
 
@@ -34,7 +45,9 @@ type SubscriptionRepositoryInterface interface {
 type TokenRepositoryInterface interface {
 	GetAll() (*map[string]Token, error)
 	GetByID(tokenID string) (*Token, error)
+	GetByNickname(nickname string) (*map[string]Token, error)
 	Save(token *Token) error
+	Update(tokenID string, fn func(token *Token) error) error
 	Delete(tokenID string) error
 }
 
@@ -42,5 +55,6 @@ type UserRepositoryInterface interface {
 	GetAll() (*map[string]User, error)
 	GetByID(userID string) (*User, error)
 	Save(user *User) error
+	Update(userID string, fn func(user *User) error) error
 	Delete(userID string) error
 }
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// MaxPostRevisions is the maximum count of the post's previous contents kept, the post cannot be edited any further then.
+const MaxPostRevisions = 20
+
+// PostRevision holds a previous content of the edited post.
+type PostRevision struct {
+	// Content is the post's content as it was before the edit.
+	Content string `json:"content"`
+
+	// Timestamp is the time such content was published (the post's creation, or its previous edit).
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Edit replaces the post's content, the previous one is kept among the revisions. The revisions are copied first, as they may be shared with
+// the stored post.
+func (p *Post) Edit(content string, editedAt time.Time) {
+	publishedAt := p.Timestamp
+	if p.Edited {
+		publishedAt = p.EditedAt
+	}
+
+	revisions := make([]PostRevision, len(p.Revisions), len(p.Revisions)+1)
+	copy(revisions, p.Revisions)
+
+	p.Revisions = append(revisions, PostRevision{
+		Content:   p.Content,
+		Timestamp: publishedAt,
+	})
+
+	p.Content = content
+	p.Edited = true
+	p.EditedAt = editedAt
+}
@@ -0,0 +1,16 @@
+package models
+
+// SearchResult is a page of the items matching the search query.
+type SearchResult struct {
+	// Posts list the matching posts, the newest first.
+	Posts []Post `json:"posts"`
+
+	// Users list the matching users ordered by their nicknames.
+	Users []User `json:"users"`
+
+	// Polls list the matching polls, the newest first.
+	Polls []Poll `json:"polls"`
+
+	// More marks there are more items on the next page (of any kind).
+	More bool `json:"more"`
+}
@@ -10,9 +10,20 @@ import (
 //  Service interfaces
 //
 
+type AdminServiceInterface interface {
+	SetUserActive(ctx context.Context, userID string, active bool) error
+	SetUserRoles(ctx context.Context, userID string, roles []string) (*User, error)
+	DeleteUser(ctx context.Context, userID string) error
+	DeletePost(ctx context.Context, postID string) error
+	DeletePoll(ctx context.Context, pollID string) error
+	FindAuditEvents(ctx context.Context, filter *AuditFilter) ([]AuditEvent, error)
+}
+
 type AuthServiceInterface interface {
 	Auth(ctx context.Context, user interface{}) (*User, []string, error)
 	Logout(ctx context.Context) error
+	OIDCBegin(ctx context.Context, provider string) (string, string, error)
+	OIDCComplete(ctx context.Context, provider, state, code, userAgent, ipAddress string) (*User, []string, error)
 }
 
 type MailServiceInterface interface {
@@ -39,11 +50,21 @@ type PollServiceInterface interface {
 
 type PostServiceInterface interface {
 	Create(ctx context.Context, post *Post) error
-	Update(ctx context.Context, post *Post) error
+	Edit(ctx context.Context, postID, content string) (*Post, error)
+	AddReaction(ctx context.Context, postID, kind string) (*Post, error)
+	RemoveReaction(ctx context.Context, postID, kind string) (*Post, error)
+	AddBookmark(ctx context.Context, postID string) error
+	RemoveBookmark(ctx context.Context, postID string) error
 	Delete(ctx context.Context, postID string) error
 	FindAll(ctx context.Context, pageOpts interface{}) (*map[string]Post, *map[string]User, error)
 	//FindPage(ctx context.Context, opts interface{}) (*map[string]Post, *map[string]User, error)
 	FindByID(ctx context.Context, postID string) (*Post, *User, error)
+	FindVisible(ctx context.Context, postID string) (*Post, error)
+	FindThread(ctx context.Context, postID string, depth int) (*PostThread, *map[string]User, error)
+}
+
+type SearchServiceInterface interface {
+	Search(ctx context.Context, query, kind string, pageNo int) (*SearchResult, *map[string]User, error)
 }
 
 type StatServiceInterface interface {
@@ -65,8 +86,18 @@ type UserServiceInterface interface {
 	UpdateAvatar(ctx context.Context, updateRequest interface{}) (*string, error)
 	UpdateSubscriptionTags(ctx context.Context, uuid string, tags []string) error
 	ProcessPassphraseRequest(ctx context.Context, reqType string, updateRequest interface{}) error
+	SetupTwoFactor(ctx context.Context, userID string) (secret, uri string, err error)
+	EnableTwoFactor(ctx context.Context, userID, code string) (recoveryCodes []string, err error)
+	DisableTwoFactor(ctx context.Context, userID, code string) error
+	FindSessions(ctx context.Context, userID string) (*[]Token, error)
+	DeleteSession(ctx context.Context, userID, sessionID string) error
+	DeleteSessions(ctx context.Context, userID string) error
+	CreateAPIToken(ctx context.Context, userID string, createRequest interface{}) (string, *APIToken, error)
+	FindAPITokens(ctx context.Context, userID string) (*[]APIToken, error)
+	DeleteAPIToken(ctx context.Context, userID, tokenID string) error
 	Delete(ctx context.Context, userID string) error
 	FindAll(ctx context.Context, pageOpts interface{}) (*map[string]User, error)
 	FindByID(ctx context.Context, userID string) (*User, error)
 	FindPostsByID(ctx context.Context, userID string, pageOpts interface{}) (*map[string]Post, *map[string]User, error)
+	FindBookmarks(ctx context.Context, userID string, pageOpts interface{}) (*map[string]Post, *map[string]User, error)
 }
@@ -0,0 +1,23 @@
+package models
+
+// PostThread is the conversation around a post: the chain of the posts replied to, and the tree of the replies.
+type PostThread struct {
+	// Ancestors list the posts replied to, the conversation's root first. The first ancestor replying to another post means the rest of the
+	// chain has been deleted.
+	Ancestors []Post `json:"ancestors"`
+
+	// Root is the tree of the replies to the very post requested.
+	Root PostThreadNode `json:"root"`
+}
+
+// PostThreadNode is a post of the thread together with its replies.
+type PostThreadNode struct {
+	// Post is the very post, its ReplyCount holds the count of all direct replies.
+	Post Post `json:"post"`
+
+	// Replies list the direct replies to the post, the oldest one first.
+	Replies []PostThreadNode `json:"replies"`
+
+	// More marks the replies have been cut off by the depth (or the size) limit of the thread, and are to be fetched as another thread.
+	More bool `json:"more,omitempty"`
+}
@@ -17,8 +17,52 @@ type Token struct {
 
 	// Time to live, period of validity since the token creation.
 	TTL time.Duration `json:"ttl"`
+
+	// UserAgent is the User-Agent header of the client the token was issued to (or last refreshed by).
+	UserAgent string `json:"user_agent"`
+
+	// IPAddress is the client's IP address at the token's issuance (or last refresh).
+	IPAddress string `json:"ip_address"`
+
+	// LastUsedAt is the time the token was last used to refresh the access token.
+	LastUsedAt time.Time `json:"last_used_at"`
+
+	// FamilyID links the refresh tokens rotated from the same login together (the hash of the very first token).
+	FamilyID string `json:"family_id"`
+
+	// RotatedAt is the time the token was replaced by a new one, such token cannot be used anymore (zero for the active ones).
+	RotatedAt time.Time `json:"rotated_at"`
+
+	// RotatedHashes are the hashes of the family's tokens rotated before this one (the latest last), so their reuse is recognized once their
+	// records are pruned.
+	RotatedHashes []string `json:"rotated_hashes,omitempty"`
+
+	// Version is increased on every update of the token (see models.Versioned).
+	Version int64 `json:"version"`
 }
 
 func (t Token) GetID() string {
 	return t.Hash
 }
+
+func (t Token) GetVersion() int64 {
+	return t.Version
+}
+
+func (t *Token) SetVersion(version int64) {
+	t.Version = version
+}
+
+// Family returns the token family's ID, the tokens issued before the rotation was introduced form the families of their own.
+func (t Token) Family() string {
+	if t.FamilyID == "" {
+		return t.Hash
+	}
+
+	return t.FamilyID
+}
+
+// IsRotated reports whether the token has been replaced by a new one already.
+func (t Token) IsRotated() bool {
+	return !t.RotatedAt.IsZero()
+}
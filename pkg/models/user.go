@@ -29,7 +29,7 @@ type User struct {
 	// Passphrase is a hashed pass phrase string (binary form).
 	Passphrase string `json:"passphrase,omitempty" swaggerignore:"true"`
 
-	// PassphraseHex is a hashed pass phrase string (hexadecimal alphanumberic form).
+	// PassphraseHex is a hashed pass phrase string: the Argon2id hash in the PHC string format, or the legacy SHA-512 sum (hexadecimal alphanumberic form).
 	PassphraseHex string `json:"passphrase_hex,omitempty" swaggerignore:"true"`
 
 	// Email is a primary user's e-mail address.
@@ -62,6 +62,9 @@ type User struct {
 	// RequestList is a map of account requested to add this user to their flow --- used with the Private property.
 	RequestList UserGenericMap `json:"request_list,omitempty" example:"dave:true"`
 
+	// Bookmarks map the IDs of the posts saved by the user to the time those were saved. Kept private to the user.
+	Bookmarks map[string]time.Time `json:"bookmarks,omitempty"`
+
 	// Color is the user's UI color scheme.
 	Color string `json:"color" default:"#000000"`
 
@@ -97,6 +100,28 @@ type User struct {
 
 	// Tags is an array of possible roles and other various attributes assigned to such user.
 	Tags []string `json:"tags" example:"user"`
+
+	// TOTPEnabled indicates the two-factor authentication using the TOTP codes is required to log-in.
+	TOTPEnabled bool `json:"totp_enabled"`
+
+	// TOTPSecret is the base32-encoded TOTP secret. It is set at the enrollment, and in use once TOTPEnabled.
+	TOTPSecret string `json:"totp_secret,omitempty" swaggerignore:"true"`
+
+	// TOTPLastStep is the time step of the last TOTP code used, so a code cannot be used twice.
+	TOTPLastStep int64 `json:"totp_last_step,omitempty" swaggerignore:"true"`
+
+	// RecoveryCodes are the SHA-256 hashes of the unused one-time recovery codes (the second factor substitutes).
+	RecoveryCodes []string `json:"recovery_codes,omitempty" swaggerignore:"true"`
+
+	// FailedLogins is the count of the recent failed logins, LastFailedLoginTime is the time of the last one (see pkg/backend/auth/lockout.go).
+	FailedLogins        int       `json:"failed_logins,omitempty" swaggerignore:"true"`
+	LastFailedLoginTime time.Time `json:"last_failed_login_time" swaggerignore:"true"`
+
+	// ExternalIDs link the account to the users of the OpenID Connect providers (provider's name => subject).
+	ExternalIDs map[string]string `json:"external_ids,omitempty" swaggerignore:"true"`
+
+	// Version is increased on every update of the user (see models.Versioned).
+	Version int64 `json:"version"`
 }
 
 func (u User) Copy() *User {
@@ -107,6 +132,14 @@ func (u User) GetID() string {
 	return u.Nickname
 }
 
+func (u User) GetVersion() int64 {
+	return u.Version
+}
+
+func (u *User) SetVersion(version int64) {
+	u.Version = version
+}
+
 // Options is an umbrella struct to hold all the booleans in one place.
 type Options struct {
 	// Active boolean indicates an activated user's account.